@@ -0,0 +1,165 @@
+package adapters
+
+// 添付ファイルの入口（HTTP → UseCase）
+// --------------------------------------------------------
+// - POST /leave-requests/{id}/attachments … multipart/form-data の "file" を添付する
+// - GET  /leave-requests/{id}/attachments … 添付ファイル一覧
+// - GET  /attachments/{id}                … 添付ファイルの中身をダウンロード
+// --------------------------------------------------------
+// ファイル形式はクライアントが申告した Content-Type を信用せず、中身の先頭から判定する。
+// 形式・サイズ・権限のルールそのものは UseCase/Domain 側に任せる。
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// maxUploadOverhead：multipart のヘッダ等、ファイル本体以外に許容するサイズ
+const maxUploadOverhead = 1 << 20
+
+// AttachmentHandler：添付ファイル関連のUseCaseを持つハンドラ
+type AttachmentHandler struct {
+	Upload   usecase.AttachmentUploader
+	List     usecase.AttachmentLister
+	Download usecase.AttachmentDownloader
+}
+
+func (h AttachmentHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// パスの解析（/leave-requests/{id}/attachments または /attachments/{id}）
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 3 && parts[0] == "leave-requests" && parts[2] == "attachments":
+		switch r.Method {
+		case http.MethodPost:
+			h.upload(w, r, parts[1])
+		case http.MethodGet:
+			h.list(w, r, parts[1])
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case len(parts) == 2 && parts[0] == "attachments":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.download(w, r, parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h AttachmentHandler) upload(w http.ResponseWriter, r *http.Request, requestID string) {
+	// multipart の "file" パートを探す（ファイル全体をメモリに載せずに読む）
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxAttachmentSize+maxUploadOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		writeError(w, validationError("multipart/form-data is required"))
+		return
+	}
+	var part io.Reader
+	var fileName string
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			writeError(w, validationError("bad multipart body"))
+			return
+		}
+		if p.FormName() == "file" {
+			part, fileName = p, p.FileName()
+			break
+		}
+	}
+	if part == nil {
+		writeError(w, validationError(`missing "file" part`))
+		return
+	}
+
+	// 中身の先頭からファイル形式を判定する
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		writeError(w, validationError("bad multipart body"))
+		return
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+
+	// UseCaseの呼び出し
+	a, err := h.Upload.Upload(usecase.UploadInput{
+		ActorID:     actorID(r),
+		RequestID:   requestID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Body:        io.MultiReader(bytes.NewReader(head), part),
+	})
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			err = validationError(domain.ErrAttachmentSize.Error())
+		}
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(toAttachmentJSON(a))
+}
+
+func (h AttachmentHandler) list(w http.ResponseWriter, r *http.Request, requestID string) {
+	as, err := h.List.List(actorID(r), requestID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := make([]attachmentJSON, 0, len(as))
+	for _, a := range as {
+		res = append(res, toAttachmentJSON(a))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (h AttachmentHandler) download(w http.ResponseWriter, r *http.Request, attachmentID string) {
+	a, body, err := h.Download.Download(actorID(r), attachmentID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	defer body.Close()
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	_, _ = io.Copy(w, body)
+}
+
+// attachmentJSON：添付ファイル情報のレスポンス形式（保存先のキーは返さない）
+type attachmentJSON struct {
+	ID          string    `json:"id"`
+	RequestID   string    `json:"requestId"`
+	FileName    string    `json:"fileName"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	UploadedBy  string    `json:"uploadedBy"`
+	UploadedAt  time.Time `json:"uploadedAt"`
+}
+
+func toAttachmentJSON(a domain.Attachment) attachmentJSON {
+	return attachmentJSON{a.ID, a.RequestID, a.FileName, a.ContentType, a.Size, a.UploadedBy, a.UploadedAt}
+}
@@ -0,0 +1,33 @@
+package adapters
+
+// 利用者の特定
+// --------------------------------------------------------
+// HTTPリクエストから「誰が操作しているか」を取り出す。
+// このサンプルでは認証そのものは行わず、前段の認証プロキシ（SSO等）が
+// 検証済みの従業員IDを EmployeeIDHeader に設定して転送してくる前提とする。
+// --------------------------------------------------------
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// EmployeeIDHeader：認証済みの従業員IDを運ぶヘッダ
+const EmployeeIDHeader = "X-Employee-Id"
+
+// actorID は操作している従業員のIDを返す（特定できなければ空文字）。
+// 空文字のときの扱い（401 にするか）は UseCase 側で判断する。
+func actorID(r *http.Request) string {
+	return strings.TrimSpace(r.Header.Get(EmployeeIDHeader))
+}
+
+// bearerMatches は Authorization: Bearer のトークンが want と一致するかを返す（want が空なら常に false）。
+// 従業員ではなくシステム（IdP・連携先）が呼ぶ入口で使う。
+func bearerMatches(r *http.Request, want string) bool {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || want == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(want)) == 1
+}
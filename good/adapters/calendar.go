@@ -0,0 +1,247 @@
+package adapters
+
+// カレンダー配信の入口（HTTP → UseCase → iCalendar）
+// --------------------------------------------------------
+// - GET /calendars/feeds                          … 操作者が購読できるフィードの URL（JSON）
+// - GET /calendars/employees/{id}.ics?token=...   … 従業員ごとのフィード
+// - GET /calendars/teams/{managerID}.ics?token=... … チームごとのフィード
+// --------------------------------------------------------
+// フィードは RFC 5545 の iCalendar 形式で返す。
+// - UID は申請IDから作るので、何度取得しても同じ予定として扱われる
+// - 終日の休暇は日付だけの予定（DTEND は最終日の翌日）
+// - 半日休は Location の時刻で午前・午後の時間帯を持つ予定（UTC で書く）
+// - チームのフィードには休暇の種類を載せない（病気休暇などを本人以外に知らせない）
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// halfDayHours：半日休の時間帯（Location の時刻で [開始, 終了) 時）
+var halfDayHours = map[domain.DayPart][2]int{
+	domain.DayAM: {9, 13},
+	domain.DayPM: {13, 18},
+}
+
+// CalendarHandler：カレンダー配信UseCaseを持つハンドラ
+type CalendarHandler struct {
+	UC       usecase.CalendarFeeder
+	BaseURL  string         // フィードの URL を作るときの公開 URL（例 "https://leave.example.com"）
+	Location *time.Location // 半日休の時刻を数えるタイムゾーン（nil なら UTC）
+}
+
+func (h CalendarHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// パスの解析（/calendars/feeds, /calendars/{employees|teams}/{id}.ics）
+	rest := strings.TrimPrefix(r.URL.Path, "/calendars/")
+	if rest == "feeds" {
+		h.feeds(w, r)
+		return
+	}
+	kind, file, _ := strings.Cut(rest, "/")
+	id, ok := strings.CutSuffix(file, ".ics")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	token := r.URL.Query().Get("token")
+
+	// UseCaseの呼び出し
+	var feed usecase.CalendarFeed
+	var err error
+	switch kind {
+	case "employees":
+		feed, err = h.UC.EmployeeFeed(id, token)
+	case "teams":
+		feed, err = h.UC.TeamFeed(id, token)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// iCalendar への変換
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", kind+"-"+id+".ics"))
+	// URL にトークンが入っているので、共有のキャッシュやリンク元の通知に残さない
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	_, _ = w.Write([]byte(h.render(feed)))
+}
+
+// feeds は操作者が購読できるフィードの URL を返す。
+func (h CalendarHandler) feeds(w http.ResponseWriter, r *http.Request) {
+	out, err := h.UC.FeedTokens(actorID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	body := struct {
+		Employee string `json:"employee"`
+		Team     string `json:"team,omitempty"`
+	}{Employee: h.feedURL("employees", out.EmployeeID, out.EmployeeToken)}
+	if out.TeamToken != "" {
+		body.Team = h.feedURL("teams", out.EmployeeID, out.TeamToken)
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (h CalendarHandler) feedURL(kind, id, token string) string {
+	return strings.TrimSuffix(h.BaseURL, "/") + "/calendars/" + kind + "/" + url.PathEscape(id) + ".ics?token=" + url.QueryEscape(token)
+}
+
+// calendarLabel：フィードに使う文言
+type calendarLabel struct {
+	OwnName  func(name string) string // 従業員ごとのフィードの名前
+	TeamName func(name string) string // チームのフィードの名前
+	Absent   func(name string) string // チームのフィードの予定の件名
+	Types    map[domain.LeaveType]string
+	Parts    map[domain.DayPart]string // 半日休の件名に添える文言
+}
+
+var calendarLabels = map[domain.Locale]calendarLabel{
+	domain.LocaleJapanese: {
+		OwnName:  func(name string) string { return "休暇（" + name + "）" },
+		TeamName: func(name string) string { return name + "のチームの休暇" },
+		Absent:   func(name string) string { return name + "：休暇" },
+		Types: map[domain.LeaveType]string{
+			domain.LeavePaid: "年次有給休暇", domain.LeaveSick: "病気休暇", domain.LeaveSpecial: "特別休暇",
+		},
+		Parts: map[domain.DayPart]string{domain.DayAM: "（午前）", domain.DayPM: "（午後）"},
+	},
+	domain.LocaleEnglish: {
+		OwnName:  func(name string) string { return "Leave - " + name },
+		TeamName: func(name string) string { return name + "'s team leave" },
+		Absent:   func(name string) string { return name + ": out of office" },
+		Types: map[domain.LeaveType]string{
+			domain.LeavePaid: "Paid leave", domain.LeaveSick: "Sick leave", domain.LeaveSpecial: "Special leave",
+		},
+		Parts: map[domain.DayPart]string{domain.DayAM: " (morning)", domain.DayPM: " (afternoon)"},
+	},
+}
+
+// render はフィードを iCalendar のテキストにする（文言はフィードの持ち主の言語）。
+func (h CalendarHandler) render(feed usecase.CalendarFeed) string {
+	l, ok := calendarLabels[feed.Owner.PreferredLocale()]
+	if !ok {
+		l = calendarLabels[domain.DefaultLocale]
+	}
+	host := "leave.invalid"
+	if u, err := url.Parse(h.BaseURL); err == nil && u.Hostname() != "" {
+		host = u.Hostname()
+	}
+	name := l.OwnName(calendarName(feed.Owner))
+	if feed.Team {
+		name = l.TeamName(calendarName(feed.Owner))
+	}
+
+	var c icsWriter
+	c.line("BEGIN:VCALENDAR")
+	c.line("VERSION:2.0")
+	c.line("PRODID:-//ohagi//clean-architecture-examples leave//EN")
+	c.line("CALSCALE:GREGORIAN")
+	c.line("METHOD:PUBLISH")
+	c.line("X-WR-CALNAME:" + icsText(name))
+	c.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	c.line("X-PUBLISHED-TTL:PT1H")
+	for _, e := range feed.Entries {
+		req := e.Request
+		summary := l.Types[req.Type]
+		if summary == "" {
+			summary = string(req.Type)
+		}
+		if feed.Team {
+			summary = l.Absent(calendarName(e.Employee))
+		}
+		summary += l.Parts[req.DayPart]
+		stamp := req.DecidedAt
+		if stamp.IsZero() {
+			stamp = req.CreatedAt
+		}
+
+		c.line("BEGIN:VEVENT")
+		c.line("UID:leave-request-" + icsText(req.ID) + "@" + host)
+		c.line("DTSTAMP:" + icsUTC(stamp))
+		if hours, ok := halfDayHours[req.DayPart]; ok {
+			c.line("DTSTART:" + icsUTC(h.at(req.From, hours[0])))
+			c.line("DTEND:" + icsUTC(h.at(req.From, hours[1])))
+		} else {
+			// 終日の予定の DTEND は含まない（最終日の翌日）
+			c.line("DTSTART;VALUE=DATE:" + req.From.Format("20060102"))
+			c.line("DTEND;VALUE=DATE:" + req.To.AddDate(0, 0, 1).Format("20060102"))
+			c.line("X-MICROSOFT-CDO-ALLDAYEVENT:TRUE")
+		}
+		c.line("SUMMARY:" + icsText(summary))
+		c.line("STATUS:CONFIRMED")
+		if feed.Team {
+			// チームの予定で見る人の予定表を埋めない
+			c.line("TRANSP:TRANSPARENT")
+		} else {
+			c.line("TRANSP:OPAQUE")
+		}
+		c.line("END:VEVENT")
+	}
+	c.line("END:VCALENDAR")
+	return c.b.String()
+}
+
+// at は日付 day の Location での hour 時を返す。
+func (h CalendarHandler) at(day time.Time, hour int) time.Time {
+	loc := h.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, 0, 0, 0, loc)
+}
+
+func calendarName(e domain.Employee) string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.ID
+}
+
+// icsWriter：iCalendar のコンテンツ行を書き出す
+// 行は CRLF で終え、75オクテットを超える行は折り返す（UTF-8 の文字の途中では切らない）。
+type icsWriter struct{ b strings.Builder }
+
+func (c *icsWriter) line(s string) {
+	const limit = 75
+	width := limit
+	for len(s) > width {
+		cut := width
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		c.b.WriteString(s[:cut])
+		c.b.WriteString("\r\n ")
+		s = s[cut:]
+		width = limit - 1 // 続きの行は先頭の空白の分だけ短い
+	}
+	c.b.WriteString(s)
+	c.b.WriteString("\r\n")
+}
+
+// icsText は TEXT 型の値をエスケープする（\ ; , と改行）。
+var icsText = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace
+
+// icsUTC は日時を UTC の DATE-TIME（20250401T000000Z）にする。
+func icsUTC(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
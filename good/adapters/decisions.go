@@ -0,0 +1,79 @@
+package adapters
+
+// 承認者の判断の入口（HTTP → UseCase）
+// --------------------------------------------------------
+// - POST  /leave-requests/{id}/approve … 承認（ボディの comment は省略可）
+// - POST  /leave-requests/{id}/reject  … 却下（comment は必須）
+// - POST  /leave-requests/{id}/return  … 差し戻し（comment は必須）
+// - PATCH /leave-requests/{id}         … {"status": "APPROVED"|"REJECTED"|"RETURNED", "comment": ...}
+// 旧形式の /leave-requests/{id}:approve なども LeaveRouter が読み替えて受け付ける。
+// --------------------------------------------------------
+// 判断の保存後に通知だけが失敗したときは、判断は取り消されないので 200 で結果を返し、
+// notificationFailed で通知できなかったことを知らせる。
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// decisionActions：action サブリソースの名前と判断の対応
+var decisionActions = map[string]domain.Decision{
+	"approve": domain.DecisionApprove,
+	"reject":  domain.DecisionReject,
+	"return":  domain.DecisionReturn,
+}
+
+// DecisionHandler：判断UseCaseを持つハンドラ（POST /leave-requests/{id}/{action}）
+type DecisionHandler struct {
+	UC       usecase.LeaveDecider
+	Decision domain.Decision
+}
+
+func (h DecisionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// ボディは省略可（承認のときはコメントなしでよい）
+	var body struct {
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, validationError("bad json"))
+		return
+	}
+
+	// UseCaseの呼び出し
+	serveDecision(w, h.UC, usecase.DecideInput{
+		ActorID: actorID(r), RequestID: r.PathValue("id"), Decision: h.Decision, Comment: body.Comment,
+	})
+}
+
+// serveDecision は判断UseCaseを呼び、結果をHTTPレスポンスとして返す。
+func serveDecision(w http.ResponseWriter, uc usecase.LeaveDecider, in usecase.DecideInput) {
+	out, err := uc.Decide(in)
+	// 判断の失敗（他の判断・取消との競合は 409）はエラーとして返し、保存後の通知の失敗は結果と一緒に知らせる
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if out.NotifyErr != nil {
+		log.Printf("leave request %s decided but notification failed: %v", out.ID, out.NotifyErr)
+	}
+	writeJSON(w, http.StatusOK, struct {
+		ID                 string             `json:"id"`
+		Status             domain.LeaveStatus `json:"status"`
+		DecidedAt          time.Time          `json:"decidedAt"`
+		Notified           []string           `json:"notified"`
+		NotificationFailed bool               `json:"notificationFailed,omitempty"`
+	}{out.ID, out.Status, out.DecidedAt, append([]string{}, out.Notified...), out.NotifyErr != nil})
+}
@@ -0,0 +1,323 @@
+package adapters
+
+// 従業員管理の入口（HTTP → UseCase）
+// --------------------------------------------------------
+// - GET   /employees                 … 従業員一覧（人事のみ）
+// - POST  /employees                 … 従業員の登録（人事のみ）
+// - GET   /employees/{id}            … 従業員情報（人事と本人のみ）
+// - PATCH /employees/{id}            … 従業員情報の変更（指定した項目だけ変更する）
+// - POST  /employees/{id}:deactivate … 退職処理（ボディの leftOn は省略可）
+// - GET   /employees/{id}/notification-preferences … 通知の受け取り方（人事と本人のみ）
+// - PUT   /employees/{id}/notification-preferences … 通知の受け取り方の変更（人事と本人のみ）
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// EmployeeHandler：従業員管理UseCaseを持つハンドラ
+type EmployeeHandler struct{ UC usecase.EmployeeManager }
+
+func (h EmployeeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// パスの解析（/employees, /employees/{id}, /employees/{id}:deactivate, /employees/{id}/notification-preferences）
+	rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/employees"), "/")
+	if id, ok := strings.CutSuffix(rest, "/notification-preferences"); ok && id != "" && !strings.Contains(id, "/") {
+		switch r.Method {
+		case http.MethodGet:
+			h.getPrefs(w, r, id)
+		case http.MethodPut:
+			h.putPrefs(w, r, id)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+	switch {
+	case rest == "":
+		switch r.Method {
+		case http.MethodGet:
+			h.list(w, r)
+		case http.MethodPost:
+			h.create(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case strings.Contains(rest, "/"):
+		http.NotFound(w, r)
+	case strings.HasSuffix(rest, ":deactivate"):
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.deactivate(w, r, strings.TrimSuffix(rest, ":deactivate"))
+	default:
+		switch r.Method {
+		case http.MethodGet:
+			h.get(w, r, rest)
+		case http.MethodPatch:
+			h.update(w, r, rest)
+		default:
+			w.Header().Set("Allow", "GET, PATCH")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func (h EmployeeHandler) list(w http.ResponseWriter, r *http.Request) {
+	emps, err := h.UC.List(actorID(r))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := make([]employeeJSON, 0, len(emps))
+	for _, e := range emps {
+		res = append(res, toEmployeeJSON(e))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (h EmployeeHandler) get(w http.ResponseWriter, r *http.Request, id string) {
+	e, err := h.UC.Get(actorID(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEmployeeJSON(e))
+}
+
+func (h EmployeeHandler) create(w http.ResponseWriter, r *http.Request) {
+	// HTTPリクエストボディをUseCaseの入力DTOへ変換
+	var body struct {
+		ID         string `json:"id"`
+		Name       string `json:"name"`
+		Email      string `json:"email"`
+		HireDate   string `json:"hireDate"`
+		ManagerID  string `json:"managerId"`
+		Department string `json:"department"`
+		Role       string `json:"role"`
+		Locale     string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, validationError("bad json"))
+		return
+	}
+	hireDate, err := time.Parse("2006-01-02", body.HireDate)
+	if err != nil {
+		writeError(w, validationError("bad hireDate"))
+		return
+	}
+	role, err := domain.ParseRole(body.Role)
+	if err != nil {
+		writeError(w, validationError(err.Error()))
+		return
+	}
+	locale, err := domain.ParseLocale(body.Locale)
+	if err != nil {
+		writeError(w, validationError(err.Error()))
+		return
+	}
+
+	// UseCaseの呼び出し
+	e, err := h.UC.Create(actorID(r), usecase.EmployeeInput{
+		ID: body.ID, Name: body.Name, Email: body.Email, HireDate: hireDate,
+		ManagerID: body.ManagerID, Department: body.Department, Role: role, Locale: locale,
+	})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toEmployeeJSON(e))
+}
+
+func (h EmployeeHandler) update(w http.ResponseWriter, r *http.Request, id string) {
+	// 省略された項目（nil）は変更しない
+	var body struct {
+		Name       *string `json:"name"`
+		Email      *string `json:"email"`
+		HireDate   *string `json:"hireDate"`
+		ManagerID  *string `json:"managerId"`
+		Department *string `json:"department"`
+		Role       *string `json:"role"`
+		Locale     *string `json:"locale"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, validationError("bad json"))
+		return
+	}
+	p := usecase.EmployeePatch{Name: body.Name, Email: body.Email, ManagerID: body.ManagerID, Department: body.Department}
+	if body.HireDate != nil {
+		d, err := time.Parse("2006-01-02", *body.HireDate)
+		if err != nil {
+			writeError(w, validationError("bad hireDate"))
+			return
+		}
+		p.HireDate = &d
+	}
+	if body.Role != nil {
+		role, err := domain.ParseRole(*body.Role)
+		if err != nil {
+			writeError(w, validationError(err.Error()))
+			return
+		}
+		p.Role = &role
+	}
+	if body.Locale != nil {
+		locale, err := domain.ParseLocale(*body.Locale)
+		if err != nil {
+			writeError(w, validationError(err.Error()))
+			return
+		}
+		p.Locale = &locale
+	}
+
+	// UseCaseの呼び出し
+	e, err := h.UC.Update(actorID(r), id, p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toEmployeeJSON(e))
+}
+
+func (h EmployeeHandler) deactivate(w http.ResponseWriter, r *http.Request, id string) {
+	// ボディは省略可（省略時は今日付で退職）
+	var body struct {
+		LeftOn string `json:"leftOn"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, validationError("bad json"))
+		return
+	}
+	var leftOn time.Time
+	if body.LeftOn != "" {
+		d, err := time.Parse("2006-01-02", body.LeftOn)
+		if err != nil {
+			writeError(w, validationError("bad leftOn"))
+			return
+		}
+		leftOn = d
+	}
+
+	// UseCaseの呼び出し
+	out, err := h.UC.Deactivate(actorID(r), id, leftOn)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// 退職処理は保存済みなので、付け替えた申請の通知の失敗は記録して結果と一緒に知らせる
+	if out.NotifyErr != nil {
+		log.Printf("employee %s deactivated but notification failed: %v", out.Employee.ID, out.NotifyErr)
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Employee            employeeJSON `json:"employee"`
+		CancelledRequests   []string     `json:"cancelledRequests"`
+		ReassignedEmployees []string     `json:"reassignedEmployees"`
+		ReassignedRequests  []string     `json:"reassignedRequests"`
+		UnassignedRequests  []string     `json:"unassignedRequests"`
+		NotificationFailed  bool         `json:"notificationFailed,omitempty"`
+	}{
+		toEmployeeJSON(out.Employee), append([]string{}, out.CancelledRequests...),
+		append([]string{}, out.ReassignedEmployees...), append([]string{}, out.ReassignedRequests...),
+		append([]string{}, out.UnassignedRequests...), out.NotifyErr != nil,
+	})
+}
+
+// notificationPrefsJSON：通知の受け取り方の形式
+type notificationPrefsJSON struct {
+	Channels   []domain.Channel `json:"channels"`             // 空なら既定のチャネル
+	QuietHours string           `json:"quietHours,omitempty"` // "22:00-07:00"
+	TimeZone   string           `json:"timeZone,omitempty"`   // 静かな時間を数える時間帯（"Asia/Tokyo" など）
+}
+
+func (h EmployeeHandler) getPrefs(w http.ResponseWriter, r *http.Request, id string) {
+	e, err := h.UC.Get(actorID(r), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toNotificationPrefsJSON(e.Notify))
+}
+
+func (h EmployeeHandler) putPrefs(w http.ResponseWriter, r *http.Request, id string) {
+	// 全体を置き換える（省略した項目は設定なしになる）
+	var body notificationPrefsJSON
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, validationError("bad json"))
+		return
+	}
+	var p domain.NotificationPrefs
+	for _, c := range body.Channels {
+		ch, err := domain.ParseChannel(string(c))
+		if err != nil {
+			writeError(w, validationError(err.Error()))
+			return
+		}
+		p.Channels = append(p.Channels, ch)
+	}
+	q, err := domain.ParseQuietHours(body.QuietHours, body.TimeZone)
+	if err != nil {
+		writeError(w, validationError(err.Error()))
+		return
+	}
+	p.Quiet = q
+
+	// UseCaseの呼び出し
+	e, err := h.UC.SetNotificationPrefs(actorID(r), id, p)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toNotificationPrefsJSON(e.Notify))
+}
+
+func toNotificationPrefsJSON(p domain.NotificationPrefs) notificationPrefsJSON {
+	return notificationPrefsJSON{
+		Channels: append([]domain.Channel{}, p.Channels...), QuietHours: p.Quiet.Range(), TimeZone: p.Quiet.TimeZone,
+	}
+}
+
+// writeJSON は v をJSONで返す。
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// employeeJSON：従業員情報のレスポンス形式
+type employeeJSON struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Email       string        `json:"email,omitempty"`
+	HireDate    string        `json:"hireDate"`
+	ManagerID   string        `json:"managerId,omitempty"`
+	Department  string        `json:"department,omitempty"`
+	Role        domain.Role   `json:"role"`
+	Locale      domain.Locale `json:"locale"` // 通知の言語（未指定なら既定の言語）
+	Active      bool          `json:"active"`
+	LeftOn      string        `json:"leftOn,omitempty"`
+	FrozenQuota *int          `json:"frozenQuota,omitempty"` // 退職者のみ
+}
+
+func toEmployeeJSON(e domain.Employee) employeeJSON {
+	res := employeeJSON{
+		ID: e.ID, Name: e.Name, Email: e.Email, HireDate: e.HireDate.Format("2006-01-02"),
+		ManagerID: e.ManagerID, Department: e.Department, Role: e.Role, Locale: e.PreferredLocale(), Active: e.IsActive(),
+	}
+	if !e.IsActive() {
+		q := e.FrozenQuota
+		res.LeftOn, res.FrozenQuota = e.LeftOn.Format("2006-01-02"), &q
+	}
+	return res
+}
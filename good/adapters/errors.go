@@ -0,0 +1,60 @@
+package adapters
+
+// エラー種類 → 外部表現の対応表
+// --------------------------------------------------------
+// UseCase層のエラー種類を、HTTPステータスやCLIの終了コードへ変換する。
+// HTTP・CLIなど、どのAdapterもこの表だけを使って変換することで、
+// 入口ごとにエラーの扱いがずれないようにする。
+// --------------------------------------------------------
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// ErrorMapping：1つのエラー種類に対する外部表現
+type ErrorMapping struct {
+	Kind       error  // usecase.ErrNotFound など
+	Code       string // 機械判定用のコード
+	HTTPStatus int    // HTTPステータス
+	ExitCode   int    // CLIの終了コード（sysexits.h に準拠）
+}
+
+// errorTable：エラー種類ごとの変換表（最後の行は想定外エラー用）
+var errorTable = []ErrorMapping{
+	{usecase.ErrNotFound, "not_found", http.StatusNotFound, 66},                  // EX_NOINPUT
+	{usecase.ErrValidation, "validation", http.StatusBadRequest, 65},             // EX_DATAERR
+	{usecase.ErrConflict, "conflict", http.StatusConflict, 73},                   // EX_CANTCREAT
+	{usecase.ErrForbidden, "forbidden", http.StatusForbidden, 77},                // EX_NOPERM
+	{usecase.ErrUnauthenticated, "unauthenticated", http.StatusUnauthorized, 77}, // EX_NOPERM
+	{usecase.ErrUnavailable, "unavailable", http.StatusServiceUnavailable, 69},   // EX_UNAVAILABLE
+	{usecase.ErrInternal, "internal", http.StatusInternalServerError, 70},        // EX_SOFTWARE
+}
+
+// MapError はエラーに対応する外部表現を返す。
+func MapError(err error) ErrorMapping {
+	kind := usecase.KindOf(err)
+	for _, m := range errorTable {
+		if m.Kind == kind {
+			return m
+		}
+	}
+	return errorTable[len(errorTable)-1]
+}
+
+// writeError はエラーをHTTPレスポンスとして返す。
+// 利用者には安全なメッセージだけを返し、元エラーはログに残す。
+func writeError(w http.ResponseWriter, err error) {
+	m := MapError(err)
+	if m.HTTPStatus >= 500 {
+		log.Printf("%s: %v", m.Code, err)
+	}
+	http.Error(w, usecase.PublicMessage(err), m.HTTPStatus)
+}
+
+// validationError は入力不正を表すエラーを作る。
+func validationError(msg string) error {
+	return &usecase.Error{Kind: usecase.ErrValidation, Msg: msg}
+}
@@ -0,0 +1,124 @@
+package adapters
+
+// 休暇申請の変更のイベントフィード（HTTP → イベントログ → JSON）
+// --------------------------------------------------------
+// - GET /events?from={offset}&limit={件数} … offset が from 以上のイベントを古い順に返す
+// --------------------------------------------------------
+// 連携先は Authorization: Bearer で Token を送る。
+// 返した next を次の from にして読み進め、処理し終えた offset は連携先が覚えておく。
+// 処理に失敗したときは同じ from でもう一度読めば、同じイベントが同じ offset で返る（at-least-once）。
+// イベントは申請を保存した後に記録するので、保存の直後に障害が起きた変更は、次の起動時に leave.recovered
+// （その申請の今の状態）として遅れて記録される。途中の変更が1件にまとまることがあるので、連携先は
+// 各イベントの request を申請の最新の状態として扱う。
+// --------------------------------------------------------
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+const (
+	eventsDefaultLimit = 100
+	eventsMaxLimit     = 1000
+)
+
+// EventsHandler：イベントフィードの入口
+type EventsHandler struct {
+	Feed  usecase.EventFeed
+	Token string // 連携先と共有するトークン（空ならすべて 401）
+}
+
+func (h EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !bearerMatches(r, h.Token) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="events"`)
+		writeError(w, &usecase.Error{Kind: usecase.ErrUnauthenticated, Msg: "invalid bearer token"})
+		return
+	}
+	// クエリの解析（from は省略なら先頭から、limit は既定 100・上限 1000）
+	q := r.URL.Query()
+	from := int64(1)
+	if v := q.Get("from"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			writeError(w, validationError("from must be a positive integer"))
+			return
+		}
+		from = n
+	}
+	limit := eventsDefaultLimit
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, validationError("limit must be a positive integer"))
+			return
+		}
+		limit = min(n, eventsMaxLimit)
+	}
+
+	// 読み出し（last は読む前に取る。読んでいる間に増えた分は次に読む）
+	last := h.Feed.LastOffset()
+	events, err := h.Feed.Read(from, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := eventPageJSON{Events: []eventJSON{}, Next: from, LastOffset: max(last, from-1)}
+	for _, e := range events {
+		res.Events = append(res.Events, toEventJSON(e))
+		res.Next = e.Offset + 1
+		res.LastOffset = max(res.LastOffset, e.Offset)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// eventPageJSON：イベントフィードのレスポンス形式
+type eventPageJSON struct {
+	Events     []eventJSON `json:"events"`
+	Next       int64       `json:"next"`       // 次に読むときの from
+	LastOffset int64       `json:"lastOffset"` // 読んだ時点で記録されていた最後の offset
+}
+
+type eventJSON struct {
+	Offset     int64             `json:"offset"`
+	Type       usecase.EventType `json:"type"`
+	OccurredAt time.Time         `json:"occurredAt"`
+	ActorID    string            `json:"actorId,omitempty"`
+	Request    eventRequestJSON  `json:"request"`
+}
+
+// eventRequestJSON：変更後の申請（理由・コメントは連携先に渡さない）
+type eventRequestJSON struct {
+	ID         string             `json:"id"`
+	EmployeeID string             `json:"employeeId"`
+	Type       domain.LeaveType   `json:"type"`
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	DayPart    domain.DayPart     `json:"dayPart,omitempty"`
+	Status     domain.LeaveStatus `json:"status"`
+	ApproverID string             `json:"approverId,omitempty"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	DecidedAt  *time.Time         `json:"decidedAt,omitempty"`
+}
+
+func toEventJSON(e usecase.LoggedEvent) eventJSON {
+	req := e.Request
+	rj := eventRequestJSON{
+		ID: req.ID, EmployeeID: req.EmployeeID, Type: req.Type,
+		From: req.From.Format("2006-01-02"), To: req.To.Format("2006-01-02"),
+		DayPart: req.DayPart, Status: req.Status, ApproverID: req.ApproverID, CreatedAt: req.CreatedAt,
+	}
+	if !req.DecidedAt.IsZero() {
+		decided := req.DecidedAt
+		rj.DecidedAt = &decided
+	}
+	return eventJSON{Offset: e.Offset, Type: e.Type, OccurredAt: e.OccurredAt, ActorID: e.ActorID, Request: rj}
+}
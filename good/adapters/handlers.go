@@ -17,6 +17,7 @@ package adapters
 
 import (
 	"encoding/json"
+	"log"
 	"net/http"
 	"time"
 
@@ -26,45 +27,108 @@ import (
 
 // Usecaseのインターフェースを持つ
 // これにより、Handler は具体的な業務ロジックを知らずに UseCase を呼び出せる
-type SubmitHandler struct{ UC usecase.SubmitLeave }
+type SubmitHandler struct{ UC usecase.LeaveSubmitter }
 
 func (h SubmitHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 申請は POST のみ受け付ける（一覧の GET は LeaveRequestHandler）
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// HTTPリクエストをUseCaseの入力DTOへ変換
+	in, err := decodeSubmitInput(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// UseCaseの呼び出し
+	out, err := h.UC.Submit(in)
+	// エラーハンドリング（エラー種類ごとの変換は errors.go の対応表に任せる）
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// 申請は保存済みなので、通知の失敗は記録して結果と一緒に知らせる
+	if out.NotifyErr != nil {
+		log.Printf("leave request %s submitted but notification failed: %v", out.ID, out.NotifyErr)
+	}
+	// 成功レスポンスの返却
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		ID                 string             `json:"id"`
+		Status             domain.LeaveStatus `json:"status"`
+		NotificationFailed bool               `json:"notificationFailed,omitempty"`
+	}{out.ID, out.Status, out.NotifyErr != nil})
+}
+
+// 事前確認UseCaseを持つハンドラ（POST /leave-requests:preview）
+// リクエストボディは SubmitHandler と同じ形式
+type PreviewHandler struct{ UC usecase.LeavePreviewer }
+
+func (h PreviewHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// 事前確認は POST のみ受け付ける
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// HTTPリクエストをUseCaseの入力DTOへ変換
+	in, err := decodeSubmitInput(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// UseCaseの呼び出し
+	out, err := h.UC.Preview(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	// 成功レスポンスの返却（nil スライスは空配列として返す）
+	reasons := append([]domain.IneligibleReason{}, out.Reasons...)
+	warnings := append([]domain.Warning{}, out.Warnings...)
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(struct {
+		Eligible       bool                      `json:"eligible"`
+		Reasons        []domain.IneligibleReason `json:"reasons"`
+		RemainingQuota int                       `json:"remainingQuota"`
+		WorkingDays    float64                   `json:"workingDays"`
+		Warnings       []domain.Warning          `json:"warnings"`
+	}{out.Eligible, reasons, out.RemainingQuota, out.WorkingDays, warnings})
+}
+
+// decodeSubmitInput はHTTPリクエストボディを休暇申請の入力DTOへ変換する。
+func decodeSubmitInput(r *http.Request) (usecase.SubmitInput, error) {
 	// HTTPリクエストボディをGo構造体にパースするためのDTO
 	var body struct {
 		EmployeeID string `json:"employeeId"`
+		Type       string `json:"type"`
 		Reason     string `json:"reason"`
 		From       string `json:"from"`
 		To         string `json:"to"`
+		DayPart    string `json:"dayPart"` // "AM"・"PM"（半日休）。省略で終日
 	}
 	// JSONデコード
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "bad json", 400)
-		return
+		return usecase.SubmitInput{}, validationError("bad json")
 	}
 	// 日付パース
 	from, err1 := time.Parse("2006-01-02", body.From)
 	to, err2 := time.Parse("2006-01-02", body.To)
 	if err1 != nil || err2 != nil {
-		http.Error(w, "bad date", 400)
-		return
+		return usecase.SubmitInput{}, validationError("bad date")
 	}
-	// UseCaseの呼び出し
-	out, err := h.UC.Submit(usecase.SubmitInput{
-		EmployeeID: body.EmployeeID, Reason: body.Reason, From: from, To: to,
-	})
-	// エラーハンドリング
+	// 休暇の種類（省略時は年次有給休暇）
+	typ, err := domain.ParseLeaveType(body.Type)
 	if err != nil {
-		status := 400
-		if err == usecase.ErrNotEligible {
-			status = 403
-		}
-		http.Error(w, err.Error(), status)
-		return
+		return usecase.SubmitInput{}, validationError(err.Error())
 	}
-	// 成功レスポンスの返却
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(struct {
-		ID     string             `json:"id"`
-		Status domain.LeaveStatus `json:"status"`
-	}{out.ID, out.Status})
+	part, err := domain.ParseDayPart(body.DayPart)
+	if err != nil {
+		return usecase.SubmitInput{}, validationError(err.Error())
+	}
+	return usecase.SubmitInput{
+		EmployeeID: body.EmployeeID, Type: typ, Reason: body.Reason, From: from, To: to, DayPart: part,
+	}, nil
 }
@@ -0,0 +1,281 @@
+package adapters
+
+// 一括取込の入口（CSV → UseCase）
+// --------------------------------------------------------
+// この層の責務：
+// - CSVファイル（UTF-8 / Shift_JIS）を読み込み、UseCaseの入力DTO（ImportRow）へ変換する
+// - HTTP と CLI の2つの入口から同じ変換処理・同じUseCaseを呼び出す
+// - UseCaseの結果（行ごとのエラー）をレポートとして返す
+// --------------------------------------------------------
+// 検証ルール（期間・勤続・回数）はUseCase/Domain側に任せ、ここでは形式の変換だけを行う。
+// --------------------------------------------------------
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/internal/japanese"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// maxImportSize：HTTPで受け付けるCSVの最大サイズ
+const maxImportSize = 32 << 20
+
+// CSVの列名（1行目のヘッダで指定する。列の順番は問わない）
+// --------------------------------------------------------
+// employee_id（必須）, from（必須）, to（必須）, type, reason, status, created_at, decided_at
+// - 日付は 2006-01-02 または 2006/01/02 形式
+// - type を省略した場合は PAID（年次有給休暇）
+// - status を省略した場合は APPROVED（過去の取得実績とみなす）
+// - created_at を省略した場合は from と同じ日とみなす
+// --------------------------------------------------------
+var requiredImportColumns = []string{"employee_id", "from", "to"}
+
+// ParseLeaveCSV はCSVを取込用の行へ変換する。
+// 変換できなかった行は rejected に入れ、残りの行の変換は続ける。
+// ヘッダ不正など、ファイル全体を読めない場合だけ error を返す。
+func ParseLeaveCSV(data []byte, enc japanese.Encoding) (rows []usecase.ImportRow, rejected []usecase.RowError, err error) {
+	text, err := japanese.Decode(data, enc)
+	if err != nil {
+		return nil, nil, validationError(err.Error())
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, nil, validationError("missing CSV header")
+	}
+	cols := map[string]int{}
+	for i, name := range header {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := cols[name]; !ok {
+			return nil, nil, validationError("missing column: " + name)
+		}
+	}
+
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return nil, nil, validationError(err.Error())
+			}
+			rejected = append(rejected, usecase.RowError{Line: pe.StartLine, Err: validationError(pe.Err.Error())})
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		row, err := parseImportRecord(rec, cols)
+		if err != nil {
+			rejected = append(rejected, usecase.RowError{Line: line, Err: err})
+			continue
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	return rows, rejected, nil
+}
+
+// parseImportRecord はCSVの1レコードを取込用の行へ変換する。
+func parseImportRecord(rec []string, cols map[string]int) (usecase.ImportRow, error) {
+	field := func(name string) string {
+		if i, ok := cols[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+	from, err := parseCSVDate(field("from"))
+	if err != nil {
+		return usecase.ImportRow{}, validationError("bad from date")
+	}
+	to, err := parseCSVDate(field("to"))
+	if err != nil {
+		return usecase.ImportRow{}, validationError("bad to date")
+	}
+	typ, err := domain.ParseLeaveType(field("type"))
+	if err != nil {
+		return usecase.ImportRow{}, validationError(err.Error() + ": " + field("type"))
+	}
+	status := domain.StatusApproved
+	if s := field("status"); s != "" {
+		if status, err = domain.ParseLeaveStatus(s); err != nil {
+			return usecase.ImportRow{}, validationError(err.Error() + ": " + s)
+		}
+	}
+	createdAt := from
+	if s := field("created_at"); s != "" {
+		if createdAt, err = parseCSVTime(s); err != nil {
+			return usecase.ImportRow{}, validationError("bad created_at")
+		}
+	}
+	var decidedAt time.Time
+	if s := field("decided_at"); s != "" {
+		if decidedAt, err = parseCSVTime(s); err != nil {
+			return usecase.ImportRow{}, validationError("bad decided_at")
+		}
+	}
+	return usecase.ImportRow{
+		EmployeeID: field("employee_id"),
+		Type:       typ,
+		Reason:     field("reason"),
+		From:       from,
+		To:         to,
+		Status:     status,
+		CreatedAt:  createdAt,
+		DecidedAt:  decidedAt,
+	}, nil
+}
+
+// parseCSVDate は 2006-01-02 / 2006/01/02 形式の日付を読む。
+func parseCSVDate(s string) (time.Time, error) {
+	return time.Parse("2006-01-02", strings.ReplaceAll(s, "/", "-"))
+}
+
+// parseCSVTime は RFC3339 形式の日時、または日付だけを読む。
+func parseCSVTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.UTC(), nil
+	}
+	return parseCSVDate(s)
+}
+
+// importReport：取込結果のレポート（HTTP・CLI共通）
+type importReport struct {
+	Total    int              `json:"total"`
+	Valid    int              `json:"valid"`
+	Imported int              `json:"imported"`
+	DryRun   bool             `json:"dryRun"`
+	Errors   []importRowError `json:"errors"`
+}
+
+type importRowError struct {
+	Line    int    `json:"line"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func newImportReport(out usecase.ImportOutput) importReport {
+	rep := importReport{Total: out.Total, Valid: out.Valid, Imported: out.Imported, DryRun: out.DryRun, Errors: []importRowError{}}
+	for _, e := range out.Errors {
+		rep.Errors = append(rep.Errors, importRowError{Line: e.Line, Code: MapError(e.Err).Code, Message: usecase.PublicMessage(e.Err)})
+	}
+	return rep
+}
+
+// 一括取込UseCaseを持つハンドラ（POST /leave-requests:import）
+// --------------------------------------------------------
+// リクエストボディにCSVをそのまま送る。取り込めるのは人事だけ（X-Employee-Id で操作者を示す）。
+// クエリパラメータ：
+// - dryRun=true   … 検証のみ行い保存しない
+// - encoding=sjis … 文字コード（省略時は自動判定）
+// --------------------------------------------------------
+type ImportHandler struct{ UC usecase.LeaveImporter }
+
+func (h ImportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	dryRun, _ := strconv.ParseBool(q.Get("dryRun"))
+	enc, err := japanese.ParseEncoding(q.Get("encoding"))
+	if err != nil {
+		writeError(w, validationError(err.Error()))
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportSize))
+	if err != nil {
+		writeError(w, validationError("request body too large"))
+		return
+	}
+	rows, rejected, err := ParseLeaveCSV(data, enc)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	out, err := h.UC.Import(usecase.ImportInput{ActorID: actorID(r), Rows: rows, Rejected: rejected, DryRun: dryRun})
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(newImportReport(out))
+}
+
+// RunImportCLI は一括取込をコマンドラインから実行し、終了コードを返す。
+// --------------------------------------------------------
+// 使い方：
+//
+//	good import -actor <人事の従業員ID> [-dry-run] [-encoding sjis] [-batch-size 100] <file.csv | ->
+//
+// -actor は必須（HTTP の X-Employee-Id と同じく、取り込む操作者として人事であることを確かめる）。
+// 結果レポート（JSON）は stdout に、エラーは stderr に出力する。
+// --------------------------------------------------------
+func RunImportCLI(args []string, uc usecase.LeaveImporter, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	actor := fs.String("actor", "", "employee ID of the HR operator running the import (required)")
+	dryRun := fs.Bool("dry-run", false, "validate only, do not write")
+	encName := fs.String("encoding", "", "input encoding: utf-8 or shift_jis (default: auto-detect)")
+	batchSize := fs.Int("batch-size", usecase.DefaultImportBatchSize, "number of rows written per batch")
+	if err := fs.Parse(args); err != nil {
+		return 64 // EX_USAGE
+	}
+	if fs.NArg() != 1 || *actor == "" {
+		fmt.Fprintln(stderr, "usage: import -actor <employee-id> [flags] <file.csv | ->")
+		return 64 // EX_USAGE
+	}
+
+	enc, err := japanese.ParseEncoding(*encName)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 64 // EX_USAGE
+	}
+	var data []byte
+	if name := fs.Arg(0); name == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 66 // EX_NOINPUT
+	}
+
+	rows, rejected, err := ParseLeaveCSV(data, enc)
+	if err != nil {
+		fmt.Fprintln(stderr, usecase.PublicMessage(err))
+		return MapError(err).ExitCode
+	}
+	out, err := uc.Import(usecase.ImportInput{ActorID: *actor, Rows: rows, Rejected: rejected, DryRun: *dryRun, BatchSize: *batchSize})
+
+	je := json.NewEncoder(stdout)
+	je.SetIndent("", "  ")
+	_ = je.Encode(newImportReport(out))
+
+	switch {
+	case err != nil:
+		fmt.Fprintln(stderr, err)
+		return MapError(err).ExitCode
+	case len(out.Errors) > 0:
+		return MapError(usecase.ErrValidation).ExitCode
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package adapters
+
+// 休暇申請の参照・変更の入口（HTTP → UseCase）
+// --------------------------------------------------------
+// - GET    /leave-requests?employeeId=&status= … 従業員の申請の一覧（employeeId を省略すると操作者本人）
+// - GET    /leave-requests/{id}                … 申請1件
+// - PATCH  /leave-requests/{id}                … 判断（{"status": "APPROVED"|"REJECTED"|"RETURNED", "comment": ...}）
+// - DELETE /leave-requests/{id}                … 申請者による取り下げ（ステータスを CANCELLED にする）
+// --------------------------------------------------------
+// 申請は消さずに取り消すだけなので、DELETE の後も GET で参照できる。
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// statusDecisions：PATCH で指定できるステータスと判断の対応
+var statusDecisions = map[domain.LeaveStatus]domain.Decision{
+	domain.StatusApproved: domain.DecisionApprove,
+	domain.StatusRejected: domain.DecisionReject,
+	domain.StatusReturned: domain.DecisionReturn,
+}
+
+// LeaveRequestHandler：休暇申請の参照・判断・取り下げのUseCaseを持つハンドラ
+// メソッドごとの振り分けは LeaveRouter が行う。
+type LeaveRequestHandler struct {
+	View   usecase.LeaveViewer
+	Decide usecase.LeaveDecider
+	Cancel usecase.LeaveCanceller
+}
+
+// List は従業員の申請の一覧を返す（GET /leave-requests）。
+func (h LeaveRequestHandler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	in := usecase.ListLeavesInput{ActorID: actorID(r), EmployeeID: q.Get("employeeId")}
+	if v := q.Get("status"); v != "" {
+		st, err := domain.ParseLeaveStatus(v)
+		if err != nil {
+			writeError(w, validationError(err.Error()))
+			return
+		}
+		in.Status = st
+	}
+	reqs, err := h.View.List(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	res := make([]leaveRequestJSON, 0, len(reqs))
+	for _, req := range reqs {
+		res = append(res, toLeaveRequestJSON(req))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// Get は申請1件を返す（GET /leave-requests/{id}）。
+func (h LeaveRequestHandler) Get(w http.ResponseWriter, r *http.Request) {
+	req, err := h.View.Get(actorID(r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toLeaveRequestJSON(req))
+}
+
+// Patch はステータスの変更を判断として記録する（PATCH /leave-requests/{id}）。
+func (h LeaveRequestHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Status  string `json:"status"`
+		Comment string `json:"comment"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, validationError("bad json"))
+		return
+	}
+	st, _ := domain.ParseLeaveStatus(body.Status)
+	decision, ok := statusDecisions[st]
+	if !ok {
+		writeError(w, validationError("status must be APPROVED, REJECTED or RETURNED"))
+		return
+	}
+	serveDecision(w, h.Decide, usecase.DecideInput{
+		ActorID: actorID(r), RequestID: r.PathValue("id"), Decision: decision, Comment: body.Comment,
+	})
+}
+
+// Delete は承認待ちの申請を取り下げる（DELETE /leave-requests/{id}）。
+func (h LeaveRequestHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	req, err := h.Cancel.Cancel(actorID(r), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toLeaveRequestJSON(req))
+}
+
+// leaveRequestJSON：休暇申請のレスポンス形式
+type leaveRequestJSON struct {
+	ID              string             `json:"id"`
+	EmployeeID      string             `json:"employeeId"`
+	Type            domain.LeaveType   `json:"type"`
+	Reason          string             `json:"reason"`
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	DayPart         domain.DayPart     `json:"dayPart,omitempty"`
+	Status          domain.LeaveStatus `json:"status"`
+	ApproverID      string             `json:"approverId,omitempty"`
+	CreatedAt       time.Time          `json:"createdAt"`
+	DecidedAt       *time.Time         `json:"decidedAt,omitempty"`
+	DecisionComment string             `json:"decisionComment,omitempty"`
+}
+
+func toLeaveRequestJSON(req domain.LeaveRequest) leaveRequestJSON {
+	res := leaveRequestJSON{
+		ID: req.ID, EmployeeID: req.EmployeeID, Type: req.Type, Reason: req.Reason,
+		From: req.From.Format("2006-01-02"), To: req.To.Format("2006-01-02"), DayPart: req.DayPart,
+		Status: req.Status, ApproverID: req.ApproverID, CreatedAt: req.CreatedAt, DecisionComment: req.DecisionComment,
+	}
+	if !req.DecidedAt.IsZero() {
+		decided := req.DecidedAt
+		res.DecidedAt = &decided
+	}
+	return res
+}
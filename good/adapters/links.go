@@ -0,0 +1,17 @@
+package adapters
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DeepLinks：通知に載せる画面へのリンクを作る（UseCase層の LinkBuilder の実装）
+// BaseURL はアプリを公開している URL（例 "https://leave.example.com"）。
+type DeepLinks struct {
+	BaseURL string
+}
+
+// LeaveRequestURL は申請の詳細画面の URL を返す。
+func (l DeepLinks) LeaveRequestURL(requestID string) string {
+	return strings.TrimSuffix(l.BaseURL, "/") + "/leave-requests/" + url.PathEscape(requestID)
+}
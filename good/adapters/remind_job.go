@@ -0,0 +1,122 @@
+package adapters
+
+// 催促ジョブの入口（タイマー / CLI → UseCase）
+// --------------------------------------------------------
+// この層の責務：
+// - 定期実行（サーバ内のタイマー）または cron から呼ぶCLIで、催促UseCaseを起動する
+// - UseCaseの結果をログ・レポートとして出力する
+// --------------------------------------------------------
+// 「何日で催促するか」などの判断はDomain層のポリシーに任せ、ここでは起動と出力だけを行う。
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// remindReport：催促ジョブの結果レポート
+type remindReport struct {
+	Reminded     []string        `json:"reminded"`
+	Escalated    []string        `json:"escalated"`
+	AutoApproved []string        `json:"autoApproved"`
+	Expired      []string        `json:"expired"`
+	Skipped      []string        `json:"skipped"`
+	Failed       []remindFailure `json:"failed"`
+}
+
+type remindFailure struct {
+	RequestID string `json:"requestId"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+}
+
+func newRemindReport(out usecase.RemindOutput) remindReport {
+	rep := remindReport{
+		Reminded:     append([]string{}, out.Reminded...),
+		Escalated:    append([]string{}, out.Escalated...),
+		AutoApproved: append([]string{}, out.AutoApproved...),
+		Expired:      append([]string{}, out.Expired...),
+		Skipped:      append([]string{}, out.Skipped...),
+		Failed:       []remindFailure{},
+	}
+	for _, f := range out.Failed {
+		rep.Failed = append(rep.Failed, remindFailure{RequestID: f.RequestID, Code: MapError(f.Err).Code, Message: usecase.PublicMessage(f.Err)})
+	}
+	return rep
+}
+
+// RemindJob：催促UseCaseを一定間隔で実行するタイマー
+type RemindJob struct {
+	UC       usecase.StaleReminder
+	Interval time.Duration
+}
+
+// Start は stop が閉じられるまで、Interval ごとに催促UseCaseを実行する。
+func (j RemindJob) Start(stop <-chan struct{}) {
+	t := time.NewTicker(j.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-t.C:
+			j.runOnce()
+		}
+	}
+}
+
+func (j RemindJob) runOnce() {
+	out, err := j.UC.Run()
+	if err != nil {
+		log.Printf("remind job: %v", err)
+		return
+	}
+	for _, f := range out.Failed {
+		log.Printf("remind job: request %s: %v", f.RequestID, f.Err)
+	}
+	log.Printf("remind job: reminded=%d escalated=%d auto-approved=%d expired=%d skipped=%d failed=%d",
+		len(out.Reminded), len(out.Escalated), len(out.AutoApproved), len(out.Expired), len(out.Skipped), len(out.Failed))
+}
+
+// RunRemindCLI は催促UseCaseを1回だけ実行し、終了コードを返す（cron からの実行用）。
+// --------------------------------------------------------
+// 使い方：
+//
+//	good remind [-remind-after 3] [-escalate-after 5] [-auto-approve] [-auto-approve-min-pending 24h] [-expire-after-start]
+//
+// フラグの既定値は policy（サーバ内のタイマーと同じ設定）で、指定した項目だけを上書きする。
+// 上書きしたポリシーで newUC が作る UseCase（デコレータで包んだもの）を実行する。
+// 結果レポート（JSON）は stdout に、エラーは stderr に出力する。
+// --------------------------------------------------------
+func RunRemindCLI(args []string, policy domain.StalePolicy, newUC func(domain.StalePolicy) usecase.StaleReminder, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("remind", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.IntVar(&policy.RemindAfterDays, "remind-after", policy.RemindAfterDays, "business days before reminding the approver (0 disables)")
+	fs.IntVar(&policy.EscalateAfterDays, "escalate-after", policy.EscalateAfterDays, "business days before escalating to the next approver (0 disables)")
+	fs.BoolVar(&policy.AutoApproveBeforeStart, "auto-approve", policy.AutoApproveBeforeStart, "auto-approve requests still pending on the day before they start")
+	fs.DurationVar(&policy.AutoApproveMinPending, "auto-approve-min-pending", policy.AutoApproveMinPending, "minimum time between submission and auto-approval (0 means 24h)")
+	fs.BoolVar(&policy.ExpireAfterStart, "expire-after-start", policy.ExpireAfterStart, "cancel requests still pending on or after their start date")
+	if err := fs.Parse(args); err != nil {
+		return 64 // EX_USAGE
+	}
+
+	out, err := newUC(policy).Run()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return MapError(err).ExitCode
+	}
+	je := json.NewEncoder(stdout)
+	je.SetIndent("", "  ")
+	_ = je.Encode(newRemindReport(out))
+	if len(out.Failed) > 0 {
+		return MapError(out.Failed[0].Err).ExitCode
+	}
+	return 0
+}
@@ -0,0 +1,195 @@
+package adapters
+
+// 集計レポートの入口（HTTP → UseCase → JSON / CSV / XLSX）
+// --------------------------------------------------------
+// GET /reports/leave?period=month&year=2025&month=4&format=csv
+// - period：month（月次）または fiscal-year（年度）
+// - format：json（省略時）・csv・xlsx
+// --------------------------------------------------------
+// 集計はUseCaseに任せ、ここでは出力形式への変換だけを行う。
+// --------------------------------------------------------
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/internal/xlsx"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// ReportHandler：集計UseCaseを持つハンドラ
+type ReportHandler struct{ UC usecase.LeaveReporter }
+
+func (h ReportHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	// クエリパラメータをUseCaseの入力DTOへ変換
+	q := r.URL.Query()
+	year, err := strconv.Atoi(q.Get("year"))
+	if err != nil {
+		writeError(w, validationError("bad year"))
+		return
+	}
+	in := usecase.ReportInput{ActorID: actorID(r), Kind: usecase.ReportPeriodKind(q.Get("period")), Year: year}
+	if in.Kind == usecase.PeriodMonth {
+		m, err := strconv.Atoi(q.Get("month"))
+		if err != nil {
+			writeError(w, validationError("bad month"))
+			return
+		}
+		in.Month = time.Month(m)
+	}
+	format := q.Get("format")
+	if format != "" && format != "json" && format != "csv" && format != "xlsx" {
+		writeError(w, validationError("format must be json, csv or xlsx"))
+		return
+	}
+
+	// UseCaseの呼び出し
+	rep, err := h.UC.Generate(in)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// 出力形式への変換
+	name := fmt.Sprintf("leave-report-%s", rep.From.Format("2006-01-02"))
+	switch format {
+	case "csv":
+		setDownloadHeaders(w, "text/csv; charset=utf-8", name+".csv")
+		_ = writeReportCSV(w, rep)
+	case "xlsx":
+		setDownloadHeaders(w, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", name+".xlsx")
+		_ = xlsx.Write(w, reportSheets(rep))
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(toReportJSON(rep))
+	}
+}
+
+func setDownloadHeaders(w http.ResponseWriter, contentType, fileName string) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+}
+
+// reportColumns：CSV・XLSX の列見出し
+var reportColumns = []string{"key", "days_taken", "approved", "rejected", "returned", "pending", "rejection_rate", "avg_lead_time_hours"}
+
+// reportGroups は集計結果を (グループ名, 行) の組に並べる。
+func reportGroups(rep usecase.LeaveReport) []struct {
+	name string
+	rows []usecase.ReportRow
+} {
+	return []struct {
+		name string
+		rows []usecase.ReportRow
+	}{
+		{"employee", rep.ByEmployee},
+		{"department", rep.ByDepartment},
+		{"type", rep.ByType},
+		{"total", []usecase.ReportRow{rep.Total}},
+	}
+}
+
+// reportCells は1行分のセルの値を reportColumns の順に返す。
+func reportCells(row usecase.ReportRow) []any {
+	return []any{row.Key, row.DaysTaken, row.Approved, row.Rejected, row.Returned, row.Pending,
+		roundTo(row.RejectionRate, 4), roundTo(row.AvgLeadTime.Hours(), 2)}
+}
+
+// writeReportCSV は全グループを1つのCSVに書き出す（先頭列がグループ名）。
+// Excel で開いたときに文字化けしないよう、先頭に UTF-8 の BOM を付ける。
+func writeReportCSV(w io.Writer, rep usecase.LeaveReport) error {
+	if _, err := w.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write(append([]string{"group"}, reportColumns...))
+	for _, g := range reportGroups(rep) {
+		for _, row := range g.rows {
+			rec := []string{g.name}
+			for _, c := range reportCells(row) {
+				rec = append(rec, fmt.Sprint(c))
+			}
+			_ = cw.Write(rec)
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reportSheets はグループごとに1枚のシートを作る。
+func reportSheets(rep usecase.LeaveReport) []xlsx.Sheet {
+	header := make([]any, len(reportColumns))
+	for i, c := range reportColumns {
+		header[i] = c
+	}
+	var sheets []xlsx.Sheet
+	for _, g := range reportGroups(rep) {
+		rows := [][]any{header}
+		for _, row := range g.rows {
+			rows = append(rows, reportCells(row))
+		}
+		sheets = append(sheets, xlsx.Sheet{Name: g.name, Rows: rows})
+	}
+	return sheets
+}
+
+// reportJSON：集計結果のJSON形式
+type reportJSON struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	ByEmployee   []reportRowJSON `json:"byEmployee"`
+	ByDepartment []reportRowJSON `json:"byDepartment"`
+	ByType       []reportRowJSON `json:"byType"`
+	Total        reportRowJSON   `json:"total"`
+}
+
+type reportRowJSON struct {
+	Key              string  `json:"key"`
+	DaysTaken        float64 `json:"daysTaken"`
+	Approved         int     `json:"approved"`
+	Rejected         int     `json:"rejected"`
+	Returned         int     `json:"returned"`
+	Pending          int     `json:"pending"`
+	RejectionRate    float64 `json:"rejectionRate"`
+	AvgLeadTimeHours float64 `json:"avgLeadTimeHours"`
+}
+
+func toReportJSON(rep usecase.LeaveReport) reportJSON {
+	conv := func(rows []usecase.ReportRow) []reportRowJSON {
+		res := make([]reportRowJSON, 0, len(rows))
+		for _, r := range rows {
+			res = append(res, toReportRowJSON(r))
+		}
+		return res
+	}
+	return reportJSON{
+		From:         rep.From.Format("2006-01-02"),
+		To:           rep.To.Format("2006-01-02"),
+		ByEmployee:   conv(rep.ByEmployee),
+		ByDepartment: conv(rep.ByDepartment),
+		ByType:       conv(rep.ByType),
+		Total:        toReportRowJSON(rep.Total),
+	}
+}
+
+func toReportRowJSON(r usecase.ReportRow) reportRowJSON {
+	return reportRowJSON{r.Key, r.DaysTaken, r.Approved, r.Rejected, r.Returned, r.Pending,
+		roundTo(r.RejectionRate, 4), roundTo(r.AvgLeadTime.Hours(), 2)}
+}
+
+// roundTo は小数点以下 digits 桁に丸める。
+func roundTo(v float64, digits int) float64 {
+	f, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'f', digits, 64), 64)
+	return f
+}
@@ -0,0 +1,96 @@
+package adapters
+
+// 休暇申請の入口のルーティング（/leave-requests 以下）
+// --------------------------------------------------------
+// - POST   /leave-requests              … 申請（SubmitHandler）
+// - GET    /leave-requests              … 一覧（LeaveRequestHandler.List）
+// - POST   /leave-requests:preview      … 事前確認（PreviewHandler）
+// - POST   /leave-requests:import       … 一括取込（ImportHandler）
+// - GET    /leave-requests/{id}         … 1件（LeaveRequestHandler.Get）
+// - PATCH  /leave-requests/{id}         … 判断（LeaveRequestHandler.Patch）
+// - DELETE /leave-requests/{id}         … 取り下げ（LeaveRequestHandler.Delete）
+// - POST   /leave-requests/{id}/approve・reject・return … 判断（DecisionHandler）
+// - GET・POST /leave-requests/{id}/attachments         … 添付ファイル（AttachmentHandler）
+// --------------------------------------------------------
+// メソッドとワイルドカード付きのパターン（Go 1.22 の http.ServeMux）で登録するので、
+// パスは合うがメソッドが違うリクエストには 405 と Allow ヘッダ（そのパスで使えるメソッド）を返す。
+// GET で登録したパスは HEAD も受け付ける。
+// 旧形式の /leave-requests/{id}:approve などは /leave-requests/{id}/approve に読み替える
+// （ワイルドカードはパスのセグメントの一部にはできないため）。
+// --------------------------------------------------------
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// leaveRequestsPrefix：休暇申請の入口のパス
+const leaveRequestsPrefix = "/leave-requests"
+
+// LeaveRoutes：ルータに登録するUseCase
+type LeaveRoutes struct {
+	Submit      usecase.LeaveSubmitter
+	Preview     usecase.LeavePreviewer
+	Import      usecase.LeaveImporter
+	Decide      usecase.LeaveDecider
+	View        usecase.LeaveViewer
+	Cancel      usecase.LeaveCanceller
+	Attachments AttachmentHandler
+}
+
+// LeaveRouter：/leave-requests 以下のリクエストをハンドラに振り分ける
+type LeaveRouter struct {
+	mux *http.ServeMux
+}
+
+// NewLeaveRouter は routes のUseCaseを使うハンドラを登録したルータを作る。
+func NewLeaveRouter(routes LeaveRoutes) *LeaveRouter {
+	mux := http.NewServeMux()
+	leaves := LeaveRequestHandler{View: routes.View, Decide: routes.Decide, Cancel: routes.Cancel}
+
+	mux.Handle("POST /leave-requests", SubmitHandler{UC: routes.Submit})
+	mux.HandleFunc("GET /leave-requests", leaves.List)
+	mux.Handle("POST /leave-requests:preview", PreviewHandler{UC: routes.Preview})
+	mux.Handle("POST /leave-requests:import", ImportHandler{UC: routes.Import})
+	mux.HandleFunc("GET /leave-requests/{id}", leaves.Get)
+	mux.HandleFunc("PATCH /leave-requests/{id}", leaves.Patch)
+	mux.HandleFunc("DELETE /leave-requests/{id}", leaves.Delete)
+	for action, d := range decisionActions {
+		mux.Handle("POST /leave-requests/{id}/"+action, DecisionHandler{UC: routes.Decide, Decision: d})
+	}
+	mux.Handle("GET /leave-requests/{id}/attachments", routes.Attachments)
+	mux.Handle("POST /leave-requests/{id}/attachments", routes.Attachments)
+	return &LeaveRouter{mux: mux}
+}
+
+// Register は mux に /leave-requests 以下のパスをこのルータの担当として登録する。
+func (rt *LeaveRouter) Register(mux *http.ServeMux) {
+	mux.Handle(leaveRequestsPrefix, rt)
+	mux.Handle(leaveRequestsPrefix+"/", rt)
+	mux.Handle(leaveRequestsPrefix+":preview", rt)
+	mux.Handle(leaveRequestsPrefix+":import", rt)
+}
+
+func (rt *LeaveRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if path, ok := legacyDecisionPath(r.URL.Path); ok {
+		r2 := r.Clone(r.Context())
+		r2.URL.Path, r2.URL.RawPath = path, ""
+		r = r2
+	}
+	rt.mux.ServeHTTP(w, r)
+}
+
+// legacyDecisionPath は旧形式の /leave-requests/{id}:{action} を /leave-requests/{id}/{action} に読み替える。
+func legacyDecisionPath(path string) (string, bool) {
+	rest, ok := strings.CutPrefix(path, leaveRequestsPrefix+"/")
+	if !ok {
+		return "", false
+	}
+	id, action, found := strings.Cut(rest, ":")
+	if _, known := decisionActions[action]; !found || !known || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return leaveRequestsPrefix + "/" + id + "/" + action, true
+}
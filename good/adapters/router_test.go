@@ -0,0 +1,149 @@
+package adapters_test
+
+// LeaveRouter のテスト（httptest でルータだけを通す）
+// --------------------------------------------------------
+// UseCase は呼ばれた内容を記録するだけの偽物にして、振り分けだけを確かめる。
+// - パスは合うがメソッドが違えば 405 と Allow ヘッダ
+// - GET で登録したパスは HEAD も受け付け、ボディは返さない
+// - 旧形式の /leave-requests/{id}:approve などは /leave-requests/{id}/approve と同じ判断になる
+// --------------------------------------------------------
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// fakeLeaves：判断・参照・取り下げの UseCase の偽物（呼ばれた内容を記録する）
+type fakeLeaves struct {
+	decided []usecase.DecideInput
+	got     []string
+}
+
+func (f *fakeLeaves) Decide(in usecase.DecideInput) (usecase.DecideOutput, error) {
+	f.decided = append(f.decided, in)
+	return usecase.DecideOutput{ID: in.RequestID, Status: domain.StatusApproved}, nil
+}
+
+func (f *fakeLeaves) List(usecase.ListLeavesInput) ([]domain.LeaveRequest, error) { return nil, nil }
+
+func (f *fakeLeaves) Get(actorID, requestID string) (domain.LeaveRequest, error) {
+	f.got = append(f.got, requestID)
+	return domain.LeaveRequest{ID: requestID, EmployeeID: actorID, Status: domain.StatusPending}, nil
+}
+
+func (f *fakeLeaves) Cancel(actorID, requestID string) (domain.LeaveRequest, error) {
+	return domain.LeaveRequest{ID: requestID, EmployeeID: actorID, Status: domain.StatusCancelled}, nil
+}
+
+// newTestRouter は fakeLeaves を使うルータを mux に登録する。
+func newTestRouter() (*http.ServeMux, *fakeLeaves) {
+	f := &fakeLeaves{}
+	mux := http.NewServeMux()
+	adapters.NewLeaveRouter(adapters.LeaveRoutes{Decide: f, View: f, Cancel: f}).Register(mux)
+	return mux, f
+}
+
+func serve(mux http.Handler, method, path string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, nil)
+	r.Header.Set(adapters.EmployeeIDHeader, "boss")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	return w
+}
+
+func TestLeaveRouterMethodNotAllowed(t *testing.T) {
+	mux, _ := newTestRouter()
+	for _, c := range []struct {
+		method, path string
+		allow        []string
+	}{
+		{http.MethodPut, "/leave-requests", []string{"GET", "HEAD", "POST"}},
+		{http.MethodPost, "/leave-requests/7", []string{"DELETE", "GET", "HEAD", "PATCH"}},
+		{http.MethodGet, "/leave-requests/7/approve", []string{"POST"}},
+		{http.MethodGet, "/leave-requests:preview", []string{"POST"}},
+	} {
+		w := serve(mux, c.method, c.path)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s = %d, want 405", c.method, c.path, w.Code)
+			continue
+		}
+		allow := strings.Split(w.Header().Get("Allow"), ", ")
+		slices.Sort(allow)
+		if !slices.Equal(allow, c.allow) {
+			t.Errorf("%s %s: Allow = %v, want %v", c.method, c.path, allow, c.allow)
+		}
+	}
+}
+
+func TestLeaveRouterHead(t *testing.T) {
+	mux, f := newTestRouter()
+
+	get := serve(mux, http.MethodGet, "/leave-requests/7")
+	head := serve(mux, http.MethodHead, "/leave-requests/7")
+	if get.Code != http.StatusOK || head.Code != http.StatusOK {
+		t.Fatalf("GET = %d, HEAD = %d, want 200 for both", get.Code, head.Code)
+	}
+	if head.Header().Get("Content-Type") != get.Header().Get("Content-Type") {
+		t.Errorf("HEAD Content-Type = %q, want %q like GET", head.Header().Get("Content-Type"), get.Header().Get("Content-Type"))
+	}
+	if !slices.Equal(f.got, []string{"7", "7"}) {
+		t.Errorf("Get called with %v, want 7 for both GET and HEAD", f.got)
+	}
+	// ボディはハンドラが書いても http.Server が送らない
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	req, err := http.NewRequest(http.MethodHead, srv.URL+"/leave-requests/7", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(adapters.EmployeeIDHeader, "boss")
+	res, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if body, _ := io.ReadAll(res.Body); res.StatusCode != http.StatusOK || len(body) != 0 {
+		t.Errorf("HEAD over HTTP = %d with %d bytes of body, want 200 without a body", res.StatusCode, len(body))
+	}
+}
+
+func TestLeaveRouterLegacyDecisionPaths(t *testing.T) {
+	mux, f := newTestRouter()
+	for _, c := range []struct {
+		path     string
+		decision domain.Decision
+	}{
+		{"/leave-requests/7:approve", domain.DecisionApprove},
+		{"/leave-requests/8:reject", domain.DecisionReject},
+		{"/leave-requests/9:return", domain.DecisionReturn},
+		{"/leave-requests/7/approve", domain.DecisionApprove},
+	} {
+		if w := serve(mux, http.MethodPost, c.path); w.Code != http.StatusOK {
+			t.Fatalf("POST %s = %d, want 200: %s", c.path, w.Code, w.Body)
+		}
+		got := f.decided[len(f.decided)-1]
+		id := strings.FieldsFunc(strings.TrimPrefix(c.path, "/leave-requests/"), func(r rune) bool { return r == ':' || r == '/' })[0]
+		if got.RequestID != id || got.Decision != c.decision || got.ActorID != "boss" {
+			t.Errorf("POST %s decided %+v, want %s on %s by boss", c.path, got, c.decision, id)
+		}
+	}
+
+	// 旧形式も POST 以外は 405。知らない action は読み替えないので、ID の一部として扱われ判断されない
+	if w := serve(mux, http.MethodGet, "/leave-requests/7:approve"); w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "POST" {
+		t.Errorf("GET /leave-requests/7:approve = %d Allow %q, want 405 Allow POST", w.Code, w.Header().Get("Allow"))
+	}
+	if w := serve(mux, http.MethodPost, "/leave-requests/7:archive"); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /leave-requests/7:archive = %d, want 405 for /leave-requests/{id}", w.Code)
+	}
+	if len(f.decided) != 4 {
+		t.Errorf("Decide called %d times, want 4", len(f.decided))
+	}
+}
@@ -0,0 +1,821 @@
+package adapters
+
+// SCIM 2.0 によるプロビジョニングの入口（IdP → HTTP → UseCase）
+// --------------------------------------------------------
+// - GET    /scim/v2/Users?filter=&startIndex=&count= … 従業員の検索
+// - POST   /scim/v2/Users                            … 従業員の登録
+// - GET    /scim/v2/Users/{id}                       … 従業員情報
+// - PUT    /scim/v2/Users/{id}                       … 従業員情報の置き換え
+// - PATCH  /scim/v2/Users/{id}                       … 従業員情報の部分変更
+// - DELETE /scim/v2/Users/{id}                       … 受け付けない（405。退職は active を false にする）
+// - /scim/v2/Groups, /scim/v2/Groups/{id}            … 部署（scim_groups.go）
+// - GET    /scim/v2/ServiceProviderConfig, /scim/v2/ResourceTypes
+// --------------------------------------------------------
+// User と従業員の対応
+// - id・userName：従業員ID（IdP 側で userName に従業員IDを送るよう設定する。変更はできない）
+// - displayName（なければ name.formatted、name.familyName と givenName）：氏名
+// - emails の primary（なければ先頭）：メールアドレス
+// - preferredLanguage：通知の言語
+// - active：在籍。false にすると退職処理（承認待ちの申請の取消・申請回数の凍結）を行う。退職者は戻せない
+// - enterprise 拡張の department・manager：部署・上長
+// - 独自拡張（scimLeaveSchema）の hireDate：入社日。登録時に省略したら登録日
+// 人事・一般の区分は SCIM では扱わない（アプリ側で人事が設定する）。externalId とパスワードは保存しない。
+// PUT で拡張スキーマのオブジェクトごと省略した場合は、その拡張の項目を変更しない。
+// 退職者も履歴のために残り GET できるので、User の DELETE は受け付けない（RFC 7644 §3.6 では削除後は 404 を返す必要がある）。
+// --------------------------------------------------------
+// IdP は Authorization: Bearer で Token を送り、ActorID の人事アカウントとして従業員管理UseCaseを呼ぶ。
+// エラーは SCIM のエラー形式（status・scimType・detail）で返す。
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// SCIM のスキーマ
+const (
+	scimUserSchema         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimEnterpriseSchema   = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	scimLeaveSchema        = "urn:ohagi:params:scim:schemas:extension:leave:2.0:User" // 入社日・退職日
+	scimListSchema         = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema        = "urn:ietf:params:scim:api:messages:2.0:Error"
+	scimSPConfigSchema     = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	scimResourceTypeSchema = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+const (
+	scimPathPrefix   = "/scim/v2"
+	scimDefaultCount = 100     // count を省略したときの1ページの件数
+	scimMaxCount     = 200     // 1ページの件数の上限
+	scimMaxBody      = 1 << 20 // リクエストボディの上限
+)
+
+// isSCIMExtension は s が User の拡張スキーマの URN かを返す。
+func isSCIMExtension(s string) bool {
+	return strings.EqualFold(s, scimEnterpriseSchema) || strings.EqualFold(s, scimLeaveSchema)
+}
+
+// SCIMHandler：SCIM 2.0 の入口
+type SCIMHandler struct {
+	UC      usecase.EmployeeManager
+	Token   string        // IdP と共有するトークン（空ならすべて 401）
+	ActorID string        // IdP の操作を行う人事アカウントの従業員ID
+	BaseURL string        // meta.location を作るときの公開 URL（例 "https://leave.example.com"）
+	Clock   usecase.Clock // 入社日を省略して登録したときの入社日（今日）
+}
+
+func (h SCIMHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+		writeSCIMError(w, &usecase.Error{Kind: usecase.ErrUnauthenticated, Msg: "invalid bearer token"})
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, scimMaxBody)
+
+	// パスの解析（/scim/v2/{resource}, /scim/v2/{resource}/{id}）。id は部署名のこともあるのでエスケープを戻す
+	rest := strings.Trim(strings.TrimPrefix(r.URL.EscapedPath(), scimPathPrefix), "/")
+	resource, rawID, hasID := strings.Cut(rest, "/")
+	id, err := url.PathUnescape(rawID)
+	if err != nil || hasID && (id == "" || strings.Contains(rawID, "/")) {
+		writeSCIMError(w, scimNotFound("resource"))
+		return
+	}
+
+	switch {
+	case resource == "Users" && !hasID:
+		serveSCIMMethods(w, r,
+			scimRoute{http.MethodGet, func() { h.listUsers(w, r) }},
+			scimRoute{http.MethodPost, func() { h.createUser(w, r) }})
+	case resource == "Users":
+		serveSCIMMethods(w, r,
+			scimRoute{http.MethodGet, func() { h.getUser(w, r, id) }},
+			scimRoute{http.MethodPut, func() { h.replaceUser(w, r, id) }},
+			scimRoute{http.MethodPatch, func() { h.patchUser(w, r, id) }},
+			scimRoute{http.MethodDelete, func() { h.deleteUser(w) }})
+	case resource == "Groups" && !hasID:
+		serveSCIMMethods(w, r,
+			scimRoute{http.MethodGet, func() { h.listGroups(w, r) }},
+			scimRoute{http.MethodPost, func() { h.createGroup(w, r) }})
+	case resource == "Groups":
+		serveSCIMMethods(w, r,
+			scimRoute{http.MethodGet, func() { h.getGroup(w, r, id) }},
+			scimRoute{http.MethodPut, func() { h.replaceGroup(w, r, id) }},
+			scimRoute{http.MethodPatch, func() { h.patchGroup(w, r, id) }},
+			scimRoute{http.MethodDelete, func() { h.deleteGroup(w, r, id) }})
+	case resource == "ServiceProviderConfig" && !hasID:
+		serveSCIMMethods(w, r, scimRoute{http.MethodGet, func() { writeSCIM(w, http.StatusOK, h.serviceProviderConfig()) }})
+	case resource == "ResourceTypes":
+		serveSCIMMethods(w, r, scimRoute{http.MethodGet, func() { h.resourceTypes(w, id, hasID) }})
+	default:
+		writeSCIMError(w, scimNotFound("resource"))
+	}
+}
+
+// authorized は Authorization ヘッダのトークンが Token と一致するかを返す。
+func (h SCIMHandler) authorized(r *http.Request) bool {
+	return bearerMatches(r, h.Token)
+}
+
+// scimRoute：HTTPメソッドごとの処理
+type scimRoute struct {
+	method string
+	serve  func()
+}
+
+// serveSCIMMethods はメソッドに合う処理を呼ぶ。なければ 405（Allow つき）。
+func serveSCIMMethods(w http.ResponseWriter, r *http.Request, routes ...scimRoute) {
+	allow := make([]string, 0, len(routes))
+	for _, rt := range routes {
+		if rt.method == r.Method {
+			rt.serve()
+			return
+		}
+		allow = append(allow, rt.method)
+	}
+	w.Header().Set("Allow", strings.Join(allow, ", "))
+	writeSCIM(w, http.StatusMethodNotAllowed, scimErrorBody{
+		Schemas: []string{scimErrorSchema}, Status: strconv.Itoa(http.StatusMethodNotAllowed), Detail: "method not allowed",
+	})
+}
+
+// --------------------------------------------------------
+// User
+// --------------------------------------------------------
+
+// scimUser：User リソース
+type scimUser struct {
+	Schemas           []string            `json:"schemas"`
+	ID                string              `json:"id,omitempty"`
+	UserName          string              `json:"userName"`
+	Name              *scimName           `json:"name,omitempty"`
+	DisplayName       string              `json:"displayName,omitempty"`
+	Emails            []scimEmail         `json:"emails,omitempty"`
+	PreferredLanguage string              `json:"preferredLanguage,omitempty"`
+	Active            *scimBool           `json:"active,omitempty"`
+	Groups            []scimMemberRef     `json:"groups,omitempty"` // 読み取り専用（部署）
+	Enterprise        *scimEnterpriseUser `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Leave             *scimLeaveUser      `json:"urn:ohagi:params:scim:schemas:extension:leave:2.0:User,omitempty"`
+	Meta              *scimMeta           `json:"meta,omitempty"`
+}
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimEnterpriseUser struct {
+	Department string       `json:"department,omitempty"`
+	Manager    *scimManager `json:"manager,omitempty"`
+}
+
+// scimManager：上長（value だけを文字列で送ってくる IdP もあるので、その形も受け付ける）
+type scimManager struct {
+	Value       string `json:"value,omitempty"`
+	Ref         string `json:"$ref,omitempty"`
+	DisplayName string `json:"displayName,omitempty"`
+}
+
+func (m *scimManager) UnmarshalJSON(b []byte) error {
+	var s string
+	if json.Unmarshal(b, &s) == nil {
+		*m = scimManager{Value: s}
+		return nil
+	}
+	type plain scimManager
+	return json.Unmarshal(b, (*plain)(m))
+}
+
+// scimLeaveUser：独自拡張（入社日・退職日は "2006-01-02" 形式。退職日は読み取り専用）
+type scimLeaveUser struct {
+	HireDate string `json:"hireDate,omitempty"`
+	LeftOn   string `json:"leftOn,omitempty"`
+}
+
+// scimBool：真偽値（"True"・"False" の文字列で送ってくる IdP もあるので、その形も受け付ける）
+type scimBool bool
+
+func (b *scimBool) UnmarshalJSON(data []byte) error {
+	var s string
+	if json.Unmarshal(data, &s) == nil {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		*b = scimBool(v)
+		return nil
+	}
+	var v bool
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*b = scimBool(v)
+	return nil
+}
+
+// scimMemberRef：グループの所属者・所属グループへの参照
+type scimMemberRef struct {
+	Value   string `json:"value"`
+	Ref     string `json:"$ref,omitempty"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location,omitempty"`
+}
+
+func (h SCIMHandler) location(endpoint, id string) string {
+	return strings.TrimSuffix(h.BaseURL, "/") + scimPathPrefix + "/" + endpoint + "/" + url.PathEscape(id)
+}
+
+// toSCIMUser は従業員を User リソースにする。
+func (h SCIMHandler) toSCIMUser(e domain.Employee) scimUser {
+	active := scimBool(e.IsActive())
+	u := scimUser{
+		Schemas:           []string{scimUserSchema, scimEnterpriseSchema, scimLeaveSchema},
+		ID:                e.ID,
+		UserName:          e.ID,
+		Name:              &scimName{Formatted: e.Name},
+		DisplayName:       e.Name,
+		PreferredLanguage: string(e.Locale),
+		Active:            &active,
+		Enterprise:        &scimEnterpriseUser{Department: e.Department},
+		Leave:             &scimLeaveUser{HireDate: e.HireDate.Format("2006-01-02")},
+		Meta:              &scimMeta{ResourceType: "User", Location: h.location("Users", e.ID)},
+	}
+	if e.Email != "" {
+		u.Emails = []scimEmail{{Value: e.Email, Type: "work", Primary: true}}
+	}
+	if e.ManagerID != "" {
+		u.Enterprise.Manager = &scimManager{Value: e.ManagerID, Ref: h.location("Users", e.ManagerID)}
+	}
+	if e.Department != "" {
+		u.Groups = []scimMemberRef{{Value: e.Department, Ref: h.location("Groups", e.Department), Display: e.Department}}
+	}
+	if !e.IsActive() {
+		u.Leave.LeftOn = e.LeftOn.Format("2006-01-02")
+	}
+	return u
+}
+
+// fullName は氏名を返す（displayName、name.formatted、familyName と givenName の順に使う）。
+func (u scimUser) fullName() string {
+	if s := strings.TrimSpace(u.DisplayName); s != "" {
+		return s
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if s := strings.TrimSpace(u.Name.Formatted); s != "" {
+		return s
+	}
+	return strings.TrimSpace(u.Name.FamilyName + " " + u.Name.GivenName)
+}
+
+// email は primary のメールアドレス（なければ先頭）を返す。
+func (u scimUser) email() string {
+	for _, e := range u.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+	return ""
+}
+
+func (u scimUser) department() string {
+	if u.Enterprise == nil {
+		return ""
+	}
+	return u.Enterprise.Department
+}
+
+func (u scimUser) managerID() string {
+	if u.Enterprise == nil || u.Enterprise.Manager == nil {
+		return ""
+	}
+	return u.Enterprise.Manager.Value
+}
+
+func (u scimUser) hireDate() string {
+	if u.Leave == nil {
+		return ""
+	}
+	return u.Leave.HireDate
+}
+
+func (h SCIMHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSCIMQuery(r)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	emps, err := h.UC.List(h.ActorID)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	res := make([]map[string]any, 0, len(emps))
+	for _, e := range emps {
+		res = append(res, toSCIMMap(h.toSCIMUser(e)))
+	}
+	writeSCIM(w, http.StatusOK, q.page(res))
+}
+
+func (h SCIMHandler) getUser(w http.ResponseWriter, r *http.Request, id string) {
+	q, err := parseSCIMQuery(r)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	e, err := h.UC.Get(h.ActorID, id)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, q.project(toSCIMMap(h.toSCIMUser(e))))
+}
+
+func (h SCIMHandler) createUser(w http.ResponseWriter, r *http.Request) {
+	// SCIM の User を UseCase の入力DTOへ変換
+	var u scimUser
+	if err := decodeSCIM(r, &u); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	in := usecase.EmployeeInput{
+		ID: strings.TrimSpace(u.UserName), Name: u.fullName(), Email: u.email(),
+		ManagerID: u.managerID(), Department: u.department(),
+	}
+	if in.ID == "" {
+		writeSCIMError(w, scimInvalid("invalidValue", "userName is required"))
+		return
+	}
+	locale, err := domain.ParseLocale(u.PreferredLanguage)
+	if err != nil {
+		writeSCIMError(w, scimInvalid("invalidValue", err.Error()))
+		return
+	}
+	in.Locale = locale
+	if in.HireDate, err = h.parseHireDate(u.hireDate()); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+
+	// UseCaseの呼び出し（無効の状態で登録されたら、登録後に退職処理をする）
+	e, err := h.UC.Create(h.ActorID, in)
+	if err != nil {
+		writeSCIMError(w, withSCIMType(usecase.ErrConflict, "uniqueness", err))
+		return
+	}
+	if u.Active != nil && !bool(*u.Active) {
+		out, err := h.UC.Deactivate(h.ActorID, e.ID, time.Time{})
+		if err != nil {
+			writeSCIMError(w, err)
+			return
+		}
+		e = out.Employee
+	}
+	w.Header().Set("Location", h.location("Users", e.ID))
+	writeSCIM(w, http.StatusCreated, h.toSCIMUser(e))
+}
+
+func (h SCIMHandler) replaceUser(w http.ResponseWriter, r *http.Request, id string) {
+	var u scimUser
+	if err := decodeSCIM(r, &u); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	cur, err := h.UC.Get(h.ActorID, id)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	// 省略された拡張は変更しない（部署をグループで管理している IdP が User の PUT で部署を消さないように）
+	before := h.toSCIMUser(cur)
+	if u.Enterprise == nil {
+		u.Enterprise = before.Enterprise
+	}
+	if u.Leave == nil {
+		u.Leave = before.Leave
+	}
+	h.writeUserChange(w, cur, before, u, true)
+}
+
+func (h SCIMHandler) patchUser(w http.ResponseWriter, r *http.Request, id string) {
+	var req scimPatchRequest
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	cur, err := h.UC.Get(h.ActorID, id)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	// 現在の User に操作を反映し、反映後の User との違いを変更内容にする
+	before := h.toSCIMUser(cur)
+	res := toSCIMMap(before)
+	if err := applySCIMPatch(res, req.Operations); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	var after scimUser
+	if err := fromSCIMMap(res, &after); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	h.writeUserChange(w, cur, before, after, false)
+}
+
+// deleteUser は User の削除を断る（405）。IdP には active を false にする設定（無効化）を使ってもらう。
+func (h SCIMHandler) deleteUser(w http.ResponseWriter) {
+	w.Header().Set("Allow", strings.Join([]string{http.MethodGet, http.MethodPut, http.MethodPatch}, ", "))
+	writeSCIM(w, http.StatusMethodNotAllowed, scimErrorBody{
+		Schemas: []string{scimErrorSchema}, Status: strconv.Itoa(http.StatusMethodNotAllowed),
+		Detail: "users cannot be deleted; set active to false to deactivate",
+	})
+}
+
+// writeUserChange：User の置き換え・部分変更の共通処理
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 変更前後の User の違いから変更内容を作る
+// 2. 変更があれば従業員情報を変更する
+// 3. active が false になったら退職処理をする
+// --------------------------------------------------------
+// replace は PUT（after が User 全体）のとき true。
+func (h SCIMHandler) writeUserChange(w http.ResponseWriter, cur domain.Employee, before, after scimUser, replace bool) {
+	// 1. 変更前後の User の違いから変更内容を作る
+	if after.UserName != "" && after.UserName != cur.ID {
+		writeSCIMError(w, scimInvalid("mutability", "userName cannot be changed"))
+		return
+	}
+	p, changed, err := h.userPatch(before, after, replace)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	active := cur.IsActive()
+	if after.Active != nil {
+		active = bool(*after.Active)
+	}
+	if active && !cur.IsActive() {
+		writeSCIMError(w, withSCIMType(usecase.ErrConflict, "mutability",
+			&usecase.Error{Kind: usecase.ErrConflict, Msg: domain.ErrAlreadyLeft.Error()}))
+		return
+	}
+
+	// 2. 変更があれば従業員情報を変更する
+	e := cur
+	if changed {
+		if e, err = h.UC.Update(h.ActorID, cur.ID, p); err != nil {
+			writeSCIMError(w, withSCIMType(usecase.ErrConflict, "mutability", err))
+			return
+		}
+	}
+
+	// 3. active が false になったら退職処理をする
+	if !active && cur.IsActive() {
+		out, err := h.UC.Deactivate(h.ActorID, cur.ID, time.Time{})
+		if err != nil {
+			writeSCIMError(w, err)
+			return
+		}
+		e = out.Employee
+	}
+	writeSCIM(w, http.StatusOK, h.toSCIMUser(e))
+}
+
+// userPatch は変更前後の User の違いを従業員情報の変更内容にする（違いがなければ changed は false）。
+func (h SCIMHandler) userPatch(before, after scimUser, replace bool) (p usecase.EmployeePatch, changed bool, err error) {
+	if name, ok := changedName(before, after, replace); ok {
+		p.Name = &name
+	}
+	if email := after.email(); email != before.email() {
+		p.Email = &email
+	}
+	if after.PreferredLanguage != before.PreferredLanguage {
+		locale, err := domain.ParseLocale(after.PreferredLanguage)
+		if err != nil {
+			return p, false, scimInvalid("invalidValue", err.Error())
+		}
+		p.Locale = &locale
+	}
+	if dept := after.department(); dept != before.department() {
+		p.Department = &dept
+	}
+	if mgr := after.managerID(); mgr != before.managerID() {
+		p.ManagerID = &mgr
+	}
+	if hd := after.hireDate(); hd != "" && hd != before.hireDate() {
+		d, err := h.parseHireDate(hd)
+		if err != nil {
+			return p, false, err
+		}
+		p.HireDate = &d
+	}
+	changed = p.Name != nil || p.Email != nil || p.Locale != nil || p.Department != nil || p.ManagerID != nil || p.HireDate != nil
+	return p, changed, nil
+}
+
+// changedName は氏名が変わっていれば新しい氏名を返す。
+// PUT なら User 全体から氏名を決める。PATCH では変更された項目から決める
+// （name.givenName だけを変えてきたときに、変更前の displayName が優先されないように）。
+func changedName(before, after scimUser, replace bool) (string, bool) {
+	if replace || after.fullName() == "" {
+		name := after.fullName()
+		return name, name != before.fullName()
+	}
+	var bn, an scimName
+	if before.Name != nil {
+		bn = *before.Name
+	}
+	if after.Name != nil {
+		an = *after.Name
+	}
+	switch {
+	case after.DisplayName != before.DisplayName && strings.TrimSpace(after.DisplayName) != "":
+		return strings.TrimSpace(after.DisplayName), true
+	case an.Formatted != bn.Formatted && strings.TrimSpace(an.Formatted) != "":
+		return strings.TrimSpace(an.Formatted), true
+	case an.FamilyName != bn.FamilyName || an.GivenName != bn.GivenName:
+		if name := strings.TrimSpace(an.FamilyName + " " + an.GivenName); name != "" {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// parseHireDate は入社日を解釈する（空なら今日）。
+func (h SCIMHandler) parseHireDate(s string) (time.Time, error) {
+	if s == "" {
+		y, m, d := h.Clock.Now().Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC), nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, scimInvalid("invalidValue", "bad hireDate")
+	}
+	return d, nil
+}
+
+// --------------------------------------------------------
+// 一覧（filter・ページ送り・返す属性）
+// --------------------------------------------------------
+
+// scimQuery：一覧・取得のクエリパラメータ
+type scimQuery struct {
+	filter     scimFilter // nil なら全件
+	startIndex int        // 1 始まり
+	count      int
+	attributes []string // 返す属性（空なら全部）
+	excluded   []string // 返さない属性
+}
+
+func parseSCIMQuery(r *http.Request) (scimQuery, error) {
+	v := r.URL.Query()
+	q := scimQuery{startIndex: 1, count: scimDefaultCount}
+	if s := v.Get("filter"); s != "" {
+		f, err := parseSCIMFilter(s)
+		if err != nil {
+			return q, scimInvalid("invalidFilter", err.Error())
+		}
+		q.filter = f
+	}
+	if s := v.Get("startIndex"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return q, scimInvalid("invalidValue", "bad startIndex")
+		}
+		q.startIndex = max(n, 1)
+	}
+	if s := v.Get("count"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return q, scimInvalid("invalidValue", "bad count")
+		}
+		q.count = min(max(n, 0), scimMaxCount)
+	}
+	q.attributes = splitSCIMAttrs(v.Get("attributes"))
+	q.excluded = splitSCIMAttrs(v.Get("excludedAttributes"))
+	return q, nil
+}
+
+// splitSCIMAttrs は属性名の一覧を最上位の属性名にする（"name.givenName" は "name"）。
+func splitSCIMAttrs(s string) []string {
+	var out []string
+	for _, a := range strings.Split(s, ",") {
+		if a = strings.TrimSpace(a); a == "" {
+			continue
+		}
+		if p, err := parseSCIMAttrPath(a); err == nil && p.schema == "" {
+			a = p.name
+		} else if err == nil {
+			a = p.schema
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// scimListResponse：一覧のレスポンス
+type scimListResponse struct {
+	Schemas      []string         `json:"schemas"`
+	TotalResults int              `json:"totalResults"`
+	StartIndex   int              `json:"startIndex"`
+	ItemsPerPage int              `json:"itemsPerPage"`
+	Resources    []map[string]any `json:"Resources"`
+}
+
+// page は filter に合うリソースのうち、指定されたページの分を返す。
+func (q scimQuery) page(all []map[string]any) scimListResponse {
+	matched := make([]map[string]any, 0, len(all))
+	for _, res := range all {
+		if q.filter == nil || q.filter.match(res) {
+			matched = append(matched, res)
+		}
+	}
+	from := min(q.startIndex-1, len(matched))
+	to := min(from+q.count, len(matched))
+	out := scimListResponse{
+		Schemas: []string{scimListSchema}, TotalResults: len(matched), StartIndex: q.startIndex,
+		Resources: make([]map[string]any, 0, to-from),
+	}
+	for _, res := range matched[from:to] {
+		out.Resources = append(out.Resources, q.project(res))
+	}
+	out.ItemsPerPage = len(out.Resources)
+	return out
+}
+
+// project は attributes・excludedAttributes に従って属性を絞る（schemas・id は常に返す）。
+func (q scimQuery) project(res map[string]any) map[string]any {
+	keep := func(k string) bool {
+		if k == "schemas" || k == "id" {
+			return true
+		}
+		for _, a := range q.excluded {
+			if strings.EqualFold(a, k) {
+				return false
+			}
+		}
+		if len(q.attributes) == 0 {
+			return true
+		}
+		for _, a := range q.attributes {
+			if strings.EqualFold(a, k) {
+				return true
+			}
+		}
+		return false
+	}
+	out := make(map[string]any, len(res))
+	for k, v := range res {
+		if keep(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// --------------------------------------------------------
+// 設定の公開（ServiceProviderConfig・ResourceTypes）
+// --------------------------------------------------------
+
+func (h SCIMHandler) serviceProviderConfig() map[string]any {
+	unsupported := map[string]any{"supported": false}
+	return map[string]any{
+		"schemas":        []string{scimSPConfigSchema},
+		"patch":          map[string]any{"supported": true},
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": scimMaxCount},
+		"changePassword": unsupported,
+		"sort":           unsupported,
+		"etag":           unsupported,
+		"authenticationSchemes": []map[string]any{{
+			"type": "oauthbearertoken", "name": "OAuth Bearer Token", "primary": true,
+			"description": "Authentication scheme using the OAuth Bearer Token Standard",
+		}},
+		"meta": scimMeta{ResourceType: "ServiceProviderConfig", Location: strings.TrimSuffix(h.BaseURL, "/") + scimPathPrefix + "/ServiceProviderConfig"},
+	}
+}
+
+func (h SCIMHandler) resourceTypes(w http.ResponseWriter, id string, hasID bool) {
+	types := []map[string]any{
+		{
+			"schemas": []string{scimResourceTypeSchema}, "id": "User", "name": "User", "endpoint": "/Users",
+			"schema": scimUserSchema,
+			"schemaExtensions": []map[string]any{
+				{"schema": scimEnterpriseSchema, "required": false},
+				{"schema": scimLeaveSchema, "required": false},
+			},
+			"meta": scimMeta{ResourceType: "ResourceType", Location: h.location("ResourceTypes", "User")},
+		},
+		{
+			"schemas": []string{scimResourceTypeSchema}, "id": "Group", "name": "Group", "endpoint": "/Groups",
+			"schema": scimGroupSchema,
+			"meta":   scimMeta{ResourceType: "ResourceType", Location: h.location("ResourceTypes", "Group")},
+		},
+	}
+	if !hasID {
+		writeSCIM(w, http.StatusOK, scimQuery{startIndex: 1, count: len(types)}.page(types))
+		return
+	}
+	for _, t := range types {
+		if t["id"] == id {
+			writeSCIM(w, http.StatusOK, t)
+			return
+		}
+	}
+	writeSCIMError(w, scimNotFound("resource type"))
+}
+
+// --------------------------------------------------------
+// リクエスト・レスポンス・エラー
+// --------------------------------------------------------
+
+// decodeSCIM はリクエストボディの JSON を v に読み込む。
+func decodeSCIM(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return scimInvalid("invalidSyntax", "bad json")
+	}
+	return nil
+}
+
+// writeSCIM は v を SCIM の JSON で返す。
+func writeSCIM(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// scimErrorBody：エラーのレスポンス（RFC 7644 3.12）
+type scimErrorBody struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+// scimTypedError：SCIM の scimType（invalidFilter・uniqueness など）を付けたエラー
+type scimTypedError struct {
+	scimType string
+	err      error
+}
+
+func (e *scimTypedError) Error() string { return e.scimType + ": " + e.err.Error() }
+func (e *scimTypedError) Unwrap() error { return e.err }
+
+// scimInvalid は入力不正のエラーを scimType つきで作る。
+func scimInvalid(scimType, msg string) error {
+	return &scimTypedError{scimType: scimType, err: validationError(msg)}
+}
+
+func scimNotFound(what string) error {
+	return &usecase.Error{Kind: usecase.ErrNotFound, Msg: what + " not found"}
+}
+
+// withSCIMType は err が kind の種類なら scimType を付ける。
+func withSCIMType(kind error, scimType string, err error) error {
+	if usecase.KindOf(err) != kind {
+		return err
+	}
+	return &scimTypedError{scimType: scimType, err: err}
+}
+
+// writeSCIMError はエラーを SCIM のエラー形式で返す。ステータスは他の入口と同じ対応表で決める。
+func writeSCIMError(w http.ResponseWriter, err error) {
+	m := MapError(err)
+	if m.HTTPStatus >= 500 {
+		log.Printf("scim %s: %v", m.Code, err)
+	}
+	body := scimErrorBody{Schemas: []string{scimErrorSchema}, Status: strconv.Itoa(m.HTTPStatus), Detail: usecase.PublicMessage(err)}
+	var te *scimTypedError
+	if errors.As(err, &te) {
+		body.ScimType = te.scimType
+	} else if m.Kind == usecase.ErrValidation {
+		body.ScimType = "invalidValue"
+	}
+	writeSCIM(w, m.HTTPStatus, body)
+}
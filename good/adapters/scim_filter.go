@@ -0,0 +1,487 @@
+package adapters
+
+// SCIM のフィルタと属性パス（RFC 7644 3.4.2.2, 3.5.2）
+// --------------------------------------------------------
+// 一覧の filter パラメータと、PATCH の path を解釈する。
+// どちらもリソースを JSON にした map[string]any を対象に評価する（従業員の型は知らない）。
+// - 比較演算子：eq ne co sw ew gt ge lt le pr
+// - 論理演算子：and or not と括弧（and が or より先に結びつく）
+// - 複数値の属性の絞り込み：emails[type eq "work"]
+// - 属性名は大文字小文字を区別しない。文字列の比較も id・externalId 以外は区別しない
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// scimCoreSchemas：属性パスの前に付いていたら読み飛ばすスキーマ（コア属性は最上位に置くため）
+var scimCoreSchemas = []string{scimUserSchema, scimGroupSchema}
+
+// errInvalidFilter：filter や path が文法どおりでない
+var errInvalidFilter = errors.New("invalid filter")
+
+// scimAttrPath：属性パス（例 "name.givenName"、"urn:...:enterprise:2.0:User:manager.value"）
+type scimAttrPath struct {
+	schema string // 拡張スキーマの URN（コア属性なら空）
+	name   string
+	sub    string // 副属性（なければ空）
+}
+
+// parseSCIMAttrPath は属性パスを解釈する。
+func parseSCIMAttrPath(s string) (scimAttrPath, error) {
+	var p scimAttrPath
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		i := strings.LastIndex(s, ":")
+		p.schema, s = s[:i], s[i+1:]
+		for _, core := range scimCoreSchemas {
+			if strings.EqualFold(p.schema, core) {
+				p.schema = ""
+			}
+		}
+	}
+	p.name, p.sub, _ = strings.Cut(s, ".")
+	if !validAttrName(p.name) || p.sub != "" && !validAttrName(p.sub) {
+		return scimAttrPath{}, fmt.Errorf("%w: bad attribute path %q", errInvalidFilter, s)
+	}
+	return p, nil
+}
+
+func validAttrName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '$':
+		case i > 0 && (c >= '0' && c <= '9' || c == '_' || c == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// container は属性を持つ map を返す（拡張スキーマの属性ならその中の map。なければ nil）。
+func (p scimAttrPath) container(res map[string]any, create bool) map[string]any {
+	if p.schema == "" {
+		return res
+	}
+	key := mapKey(res, p.schema)
+	m, _ := res[key].(map[string]any)
+	if m == nil && create {
+		m = map[string]any{}
+		res[key] = m
+	}
+	return m
+}
+
+// values は属性の値を返す（複数値なら要素ごと。副属性があれば副属性の値）。
+// 副属性を指定せずに複雑な複数値の属性を比べるときは "value" 副属性を比べる（RFC 7644 3.4.2.2）。
+func (p scimAttrPath) values(res map[string]any) []any {
+	c := p.container(res, false)
+	if c == nil {
+		return nil
+	}
+	v, ok := c[mapKey(c, p.name)]
+	if !ok || v == nil {
+		return nil
+	}
+	elems, multi := v.([]any)
+	if !multi {
+		elems = []any{v}
+	}
+	sub := p.sub
+	if sub == "" && multi {
+		sub = "value"
+	}
+	var out []any
+	for _, e := range elems {
+		if m, ok := e.(map[string]any); ok && sub != "" {
+			if sv, ok := m[mapKey(m, sub)]; ok && sv != nil {
+				out = append(out, sv)
+			}
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// mapKey は name と大文字小文字を区別せずに一致するキーを返す（なければ name のまま）。
+func mapKey(m map[string]any, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+// --------------------------------------------------------
+// フィルタ
+// --------------------------------------------------------
+
+// scimFilter：解釈済みのフィルタ
+type scimFilter interface {
+	match(res map[string]any) bool
+}
+
+type (
+	filterAnd     struct{ l, r scimFilter }
+	filterOr      struct{ l, r scimFilter }
+	filterNot     struct{ f scimFilter }
+	filterPresent struct{ path scimAttrPath }
+	filterCompare struct {
+		path  scimAttrPath
+		op    string
+		value any // string, float64, bool, nil
+	}
+	// filterValuePath：複数値の属性のどれか1つの要素が f を満たす
+	filterValuePath struct {
+		path scimAttrPath
+		f    scimFilter
+	}
+)
+
+func (f filterAnd) match(res map[string]any) bool { return f.l.match(res) && f.r.match(res) }
+func (f filterOr) match(res map[string]any) bool  { return f.l.match(res) || f.r.match(res) }
+func (f filterNot) match(res map[string]any) bool { return !f.f.match(res) }
+
+func (f filterPresent) match(res map[string]any) bool {
+	for _, v := range f.path.values(res) {
+		if s, ok := v.(string); !ok || s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filterCompare) match(res map[string]any) bool {
+	if f.op == "ne" {
+		return !filterCompare{f.path, "eq", f.value}.match(res)
+	}
+	if f.value == nil { // eq null は「値がない」
+		return f.op == "eq" && !filterPresent{f.path}.match(res)
+	}
+	caseExact := f.path.sub == "" && (f.path.name == "id" || strings.EqualFold(f.path.name, "externalId"))
+	for _, v := range f.path.values(res) {
+		if compareSCIM(v, f.op, f.value, caseExact) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f filterValuePath) match(res map[string]any) bool {
+	c := f.path.container(res, false)
+	if c == nil {
+		return false
+	}
+	elems, _ := c[mapKey(c, f.path.name)].([]any)
+	for _, e := range elems {
+		if m, ok := e.(map[string]any); ok && f.f.match(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// compareSCIM は属性の値 v と filter の値 want を演算子 op で比べる。型が違えば一致しない。
+func compareSCIM(v any, op string, want any, caseExact bool) bool {
+	switch w := want.(type) {
+	case bool:
+		b, ok := v.(bool)
+		return ok && op == "eq" && b == w
+	case float64:
+		n, ok := v.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return n == w
+		case "gt":
+			return n > w
+		case "ge":
+			return n >= w
+		case "lt":
+			return n < w
+		case "le":
+			return n <= w
+		}
+		return false
+	case string:
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		if !caseExact {
+			s, w = strings.ToLower(s), strings.ToLower(w)
+		}
+		switch op {
+		case "eq":
+			return s == w
+		case "co":
+			return strings.Contains(s, w)
+		case "sw":
+			return strings.HasPrefix(s, w)
+		case "ew":
+			return strings.HasSuffix(s, w)
+		case "gt":
+			return s > w
+		case "ge":
+			return s >= w
+		case "lt":
+			return s < w
+		case "le":
+			return s <= w
+		}
+	}
+	return false
+}
+
+// parseSCIMFilter は filter パラメータを解釈する。
+func parseSCIMFilter(s string) (scimFilter, error) {
+	p := &filterParser{toks: tokenizeFilter(s)}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, p.errorf("unexpected %q", p.peek())
+	}
+	return f, nil
+}
+
+// filterParser：filter の再帰下降パーサ
+type filterParser struct {
+	toks []string
+	pos  int
+}
+
+func (p *filterParser) done() bool { return p.pos >= len(p.toks) }
+
+func (p *filterParser) peek() string {
+	if p.done() {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *filterParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+// keyword は次のトークンがキーワード kw（大文字小文字を区別しない）なら読み進める。
+func (p *filterParser) keyword(kw string) bool {
+	if !p.done() && strings.EqualFold(p.peek(), kw) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(tok string) error {
+	if p.next() != tok {
+		return p.errorf("expected %q", tok)
+	}
+	return nil
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errInvalidFilter, fmt.Sprintf(format, args...))
+}
+
+func (p *filterParser) parseOr() (scimFilter, error) {
+	l, err := p.parseAnd()
+	for err == nil && p.keyword("or") {
+		var r scimFilter
+		if r, err = p.parseAnd(); err == nil {
+			l = filterOr{l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *filterParser) parseAnd() (scimFilter, error) {
+	l, err := p.parseUnary()
+	for err == nil && p.keyword("and") {
+		var r scimFilter
+		if r, err = p.parseUnary(); err == nil {
+			l = filterAnd{l, r}
+		}
+	}
+	return l, err
+}
+
+func (p *filterParser) parseUnary() (scimFilter, error) {
+	if p.keyword("not") {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{f}, p.expect(")")
+	}
+	if p.peek() == "(" {
+		p.next()
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return f, p.expect(")")
+	}
+	return p.parseAttrExp()
+}
+
+func (p *filterParser) parseAttrExp() (scimFilter, error) {
+	tok := p.next()
+	if tok == "" || isFilterPunct(tok) || strings.HasPrefix(tok, `"`) {
+		return nil, p.errorf("expected an attribute, got %q", tok)
+	}
+	path, err := parseSCIMAttrPath(tok)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek() == "[" {
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if path.sub != "" {
+			return nil, p.errorf("unexpected sub-attribute before [")
+		}
+		return filterValuePath{path, inner}, p.expect("]")
+	}
+	op := strings.ToLower(p.next())
+	switch op {
+	case "pr":
+		return filterPresent{path}, nil
+	case "eq", "ne", "co", "sw", "ew", "gt", "ge", "lt", "le":
+	default:
+		return nil, p.errorf("unknown operator %q", op)
+	}
+	value, err := parseFilterValue(p.next())
+	if err != nil {
+		return nil, p.errorf("%v", err)
+	}
+	return filterCompare{path, op, value}, nil
+}
+
+// parseFilterValue は比較する値（文字列・数値・true・false・null）を解釈する。
+func parseFilterValue(tok string) (any, error) {
+	switch strings.ToLower(tok) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "":
+		return nil, errors.New("missing value")
+	}
+	if strings.HasPrefix(tok, `"`) {
+		s, err := strconv.Unquote(tok)
+		if err != nil {
+			return nil, fmt.Errorf("bad string %s", tok)
+		}
+		return s, nil
+	}
+	n, err := strconv.ParseFloat(tok, 64)
+	if err != nil {
+		return nil, fmt.Errorf("bad value %q", tok)
+	}
+	return n, nil
+}
+
+func isFilterPunct(tok string) bool {
+	return tok == "(" || tok == ")" || tok == "[" || tok == "]"
+}
+
+// tokenizeFilter は filter を括弧・文字列・それ以外の語に分ける。
+func tokenizeFilter(s string) []string {
+	var toks []string
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(' || c == ')' || c == '[' || c == ']':
+			toks = append(toks, s[i:i+1])
+			i++
+		case c == '"':
+			j := i + 1
+			for j < len(s) && s[j] != '"' {
+				if s[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(s))
+			toks = append(toks, s[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t()[]\"", rune(s[j])) {
+				j++
+			}
+			toks = append(toks, s[i:j])
+			i = j
+		}
+	}
+	return toks
+}
+
+// --------------------------------------------------------
+// PATCH の path
+// --------------------------------------------------------
+
+// scimPatchPath：PATCH の path（例 `members[value eq "e1"]`、`emails[type eq "work"].value`）
+type scimPatchPath struct {
+	attr   scimAttrPath
+	filter scimFilter // 複数値の要素の絞り込み（なければ nil）
+	sub    string     // 絞り込んだ要素の副属性（なければ空）
+}
+
+// parseSCIMPatchPath は PATCH の path を解釈する。
+func parseSCIMPatchPath(s string) (scimPatchPath, error) {
+	open := strings.Index(s, "[")
+	if open < 0 {
+		a, err := parseSCIMAttrPath(s)
+		return scimPatchPath{attr: a}, err
+	}
+	end := strings.LastIndex(s, "]")
+	if end < open {
+		return scimPatchPath{}, fmt.Errorf("%w: missing ] in path %q", errInvalidFilter, s)
+	}
+	a, err := parseSCIMAttrPath(s[:open])
+	if err != nil {
+		return scimPatchPath{}, err
+	}
+	if a.sub != "" {
+		return scimPatchPath{}, fmt.Errorf("%w: unexpected sub-attribute before [ in %q", errInvalidFilter, s)
+	}
+	f, err := parseSCIMFilter(s[open+1 : end])
+	if err != nil {
+		return scimPatchPath{}, err
+	}
+	p := scimPatchPath{attr: a, filter: f}
+	if rest := s[end+1:]; rest != "" {
+		sub, ok := strings.CutPrefix(rest, ".")
+		if !ok || !validAttrName(sub) {
+			return scimPatchPath{}, fmt.Errorf("%w: bad sub-attribute in %q", errInvalidFilter, s)
+		}
+		p.sub = sub
+	}
+	return p, nil
+}
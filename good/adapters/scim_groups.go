@@ -0,0 +1,288 @@
+package adapters
+
+// SCIM の Group ＝ 部署
+// --------------------------------------------------------
+// - GET    /scim/v2/Groups       … 部署の一覧（filter・ページ送りは User と同じ）
+// - POST   /scim/v2/Groups       … 部署の作成（members の従業員をその部署に所属させる）
+// - GET    /scim/v2/Groups/{id}  … 部署
+// - PUT    /scim/v2/Groups/{id}  … 部署名・所属者の置き換え
+// - PATCH  /scim/v2/Groups/{id}  … 部署名の変更・所属者の追加と削除
+// - DELETE /scim/v2/Groups/{id}  … 所属者全員の部署を空にする
+// --------------------------------------------------------
+// 部署は従業員の属性でしかないため、次のように扱う。
+// - id・displayName は部署名。部署名を変えると id も変わる
+// - 所属する在籍者がいる間だけ存在する（所属者のいない部署は一覧・取得では見えない）
+// - 所属者のいない部署への PUT・PATCH は、空の部署への変更として受け付ける
+//   （空で作ってから所属者を追加してくる IdP があるため）
+// - 所属者の変更は従業員ごとに従業員情報の変更として行う。途中で失敗しても、同じ要求を再送すれば続きから反映される
+// --------------------------------------------------------
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// scimGroup：Group リソース
+type scimGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []scimMemberRef `json:"members"`
+	Meta        *scimMeta       `json:"meta,omitempty"`
+}
+
+// toSCIMGroup は部署とその所属者を Group リソースにする。
+func (h SCIMHandler) toSCIMGroup(dept string, members []domain.Employee) scimGroup {
+	g := scimGroup{
+		Schemas: []string{scimGroupSchema}, ID: dept, DisplayName: dept,
+		Members: make([]scimMemberRef, 0, len(members)),
+		Meta:    &scimMeta{ResourceType: "Group", Location: h.location("Groups", dept)},
+	}
+	for _, e := range members {
+		g.Members = append(g.Members, scimMemberRef{Value: e.ID, Ref: h.location("Users", e.ID), Display: e.Name, Type: "User"})
+	}
+	return g
+}
+
+// departments は在籍者を部署ごとに分けて返す（部署名の順。所属者は従業員ID順）。
+func (h SCIMHandler) departments() ([]string, map[string][]domain.Employee, error) {
+	emps, err := h.UC.List(h.ActorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	members := map[string][]domain.Employee{}
+	var names []string
+	for _, e := range emps {
+		if e.Department == "" || !e.IsActive() {
+			continue
+		}
+		if _, ok := members[e.Department]; !ok {
+			names = append(names, e.Department)
+		}
+		members[e.Department] = append(members[e.Department], e)
+	}
+	slices.Sort(names)
+	return names, members, nil
+}
+
+func (h SCIMHandler) listGroups(w http.ResponseWriter, r *http.Request) {
+	q, err := parseSCIMQuery(r)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	names, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	res := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		res = append(res, toSCIMMap(h.toSCIMGroup(name, members[name])))
+	}
+	writeSCIM(w, http.StatusOK, q.page(res))
+}
+
+func (h SCIMHandler) getGroup(w http.ResponseWriter, r *http.Request, id string) {
+	q, err := parseSCIMQuery(r)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	_, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	if len(members[id]) == 0 {
+		writeSCIMError(w, scimNotFound("group"))
+		return
+	}
+	writeSCIM(w, http.StatusOK, q.project(toSCIMMap(h.toSCIMGroup(id, members[id]))))
+}
+
+func (h SCIMHandler) createGroup(w http.ResponseWriter, r *http.Request) {
+	var g scimGroup
+	if err := decodeSCIM(r, &g); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	name := strings.TrimSpace(g.DisplayName)
+	if name == "" {
+		writeSCIMError(w, scimInvalid("invalidValue", "displayName is required"))
+		return
+	}
+	_, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	if len(members[name]) > 0 {
+		writeSCIMError(w, &scimTypedError{scimType: "uniqueness",
+			err: &usecase.Error{Kind: usecase.ErrConflict, Msg: "group already exists: " + name}})
+		return
+	}
+	res, err := h.syncGroup("", nil, name, g.Members)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	w.Header().Set("Location", h.location("Groups", name))
+	writeSCIM(w, http.StatusCreated, res)
+}
+
+func (h SCIMHandler) replaceGroup(w http.ResponseWriter, r *http.Request, id string) {
+	var g scimGroup
+	if err := decodeSCIM(r, &g); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	_, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	name := strings.TrimSpace(g.DisplayName)
+	if name == "" {
+		name = id
+	}
+	h.writeGroupChange(w, id, members, name, g.Members)
+}
+
+func (h SCIMHandler) patchGroup(w http.ResponseWriter, r *http.Request, id string) {
+	var req scimPatchRequest
+	if err := decodeSCIM(r, &req); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	_, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	// 現在の Group に操作を反映し、反映後の部署名・所属者にそろえる
+	res := toSCIMMap(h.toSCIMGroup(id, members[id]))
+	if err := applySCIMPatch(res, req.Operations); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	var after scimGroup
+	if err := fromSCIMMap(res, &after); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	if after.ID != id {
+		writeSCIMError(w, scimInvalid("mutability", "id cannot be changed"))
+		return
+	}
+	name := strings.TrimSpace(after.DisplayName)
+	if name == "" {
+		writeSCIMError(w, scimInvalid("invalidValue", "displayName is required"))
+		return
+	}
+	h.writeGroupChange(w, id, members, name, after.Members)
+}
+
+func (h SCIMHandler) deleteGroup(w http.ResponseWriter, r *http.Request, id string) {
+	_, members, err := h.departments()
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	if len(members[id]) == 0 {
+		writeSCIMError(w, scimNotFound("group"))
+		return
+	}
+	if _, err := h.syncGroup(id, members[id], id, nil); err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeGroupChange は部署 id を部署名 name・所属者 want にそろえて、変更後の Group を返す。
+// 別の部署の名前への変更は、2つの部署が1つになってしまうので受け付けない。
+func (h SCIMHandler) writeGroupChange(w http.ResponseWriter, id string, members map[string][]domain.Employee, name string, want []scimMemberRef) {
+	if name != id && len(members[name]) > 0 {
+		writeSCIMError(w, &scimTypedError{scimType: "uniqueness",
+			err: &usecase.Error{Kind: usecase.ErrConflict, Msg: "group already exists: " + name}})
+		return
+	}
+	res, err := h.syncGroup(id, members[id], name, want)
+	if err != nil {
+		writeSCIMError(w, err)
+		return
+	}
+	writeSCIM(w, http.StatusOK, res)
+}
+
+// syncGroup：部署の所属者をそろえる
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 所属者の一覧を検証する（グループの入れ子は扱わない）
+// 2. 外れた所属者の部署を空にし、残る所属者は部署名の変更を反映する
+// 3. 新しい所属者をその部署に所属させる
+// 4. 変更後の所属者で Group を作る
+// --------------------------------------------------------
+func (h SCIMHandler) syncGroup(oldName string, current []domain.Employee, name string, want []scimMemberRef) (scimGroup, error) {
+	// 1. 所属者の一覧を検証する
+	wanted := map[string]bool{}
+	var ids []string
+	for _, m := range want {
+		if m.Type != "" && !strings.EqualFold(m.Type, "User") {
+			return scimGroup{}, scimInvalid("invalidValue", "nested groups are not supported")
+		}
+		if m.Value == "" {
+			return scimGroup{}, scimInvalid("invalidValue", "member value is required")
+		}
+		if !wanted[m.Value] {
+			wanted[m.Value] = true
+			ids = append(ids, m.Value)
+		}
+	}
+	slices.Sort(ids)
+
+	// 2. 外れた所属者の部署を空にし、残る所属者は部署名の変更を反映する
+	empty := ""
+	var result []domain.Employee
+	for _, e := range current {
+		switch {
+		case !wanted[e.ID]:
+			if _, err := h.UC.Update(h.ActorID, e.ID, usecase.EmployeePatch{Department: &empty}); err != nil {
+				return scimGroup{}, err
+			}
+		case name != oldName:
+			u, err := h.UC.Update(h.ActorID, e.ID, usecase.EmployeePatch{Department: &name})
+			if err != nil {
+				return scimGroup{}, err
+			}
+			result = append(result, u)
+		default:
+			result = append(result, e)
+		}
+		delete(wanted, e.ID)
+	}
+
+	// 3. 新しい所属者をその部署に所属させる（存在しない従業員は入力不正として返す）
+	for _, id := range ids {
+		if !wanted[id] {
+			continue
+		}
+		u, err := h.UC.Update(h.ActorID, id, usecase.EmployeePatch{Department: &name})
+		if usecase.KindOf(err) == usecase.ErrNotFound {
+			return scimGroup{}, scimInvalid("invalidValue", "member not found: "+id)
+		}
+		if err != nil {
+			return scimGroup{}, err
+		}
+		result = append(result, u)
+	}
+
+	// 4. 変更後の所属者で Group を作る
+	slices.SortFunc(result, func(a, b domain.Employee) int { return strings.Compare(a.ID, b.ID) })
+	return h.toSCIMGroup(name, result), nil
+}
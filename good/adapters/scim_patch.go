@@ -0,0 +1,251 @@
+package adapters
+
+// SCIM の PATCH（RFC 7644 3.5.2）
+// --------------------------------------------------------
+// リソースを JSON にした map[string]any に、add・replace・remove の操作を順に反映する。
+// 反映した結果を User・Group の型に戻し、元の値との違いを UseCase の変更内容にする。
+// - path がなければ value のオブジェクトの各属性を反映する（拡張スキーマの URN をキーにしたものも可）
+// - 絞り込みのある path で要素が見つからなければ、add・replace は `attr eq "値"` の形に限り要素を作る
+// - remove で path が複数値の属性そのもので value に要素があれば、その要素だけを消す
+//   （RFC では属性全体を消すが、members を消すのにこの形で送ってくる IdP があるため）
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// scimPatchRequest：PATCH のリクエストボディ
+type scimPatchRequest struct {
+	Schemas    []string      `json:"schemas"`
+	Operations []scimPatchOp `json:"Operations"`
+}
+
+// scimPatchOp：PATCH の操作1つ
+type scimPatchOp struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// applySCIMPatch は操作を順に res へ反映する。
+func applySCIMPatch(res map[string]any, ops []scimPatchOp) error {
+	if len(ops) == 0 {
+		return scimInvalid("invalidSyntax", "no Operations")
+	}
+	for _, op := range ops {
+		if err := applySCIMOp(res, strings.ToLower(op.Op), op.Path, op.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func applySCIMOp(res map[string]any, kind, path string, value any) error {
+	switch kind {
+	case "add", "replace", "remove":
+	default:
+		return scimInvalid("invalidSyntax", "unknown op "+kind)
+	}
+	if path == "" {
+		if kind == "remove" {
+			return scimInvalid("noTarget", "remove requires a path")
+		}
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return scimInvalid("invalidValue", "value must be an object when path is omitted")
+		}
+		for k, v := range obj {
+			// 拡張スキーマの URN をキーにしたオブジェクトは、その中の属性ごとに反映する
+			if sub, ok := v.(map[string]any); ok && isSCIMExtension(k) {
+				for sk, sv := range sub {
+					if err := applySCIMOp(res, kind, k+":"+sk, sv); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if err := applySCIMOp(res, kind, k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parseSCIMPatchPath(path)
+	if err != nil {
+		return scimInvalid("invalidPath", err.Error())
+	}
+	c := p.attr.container(res, kind != "remove")
+	if c == nil {
+		return nil // ない拡張スキーマの属性を消す
+	}
+	key := mapKey(c, p.attr.name)
+	switch {
+	case p.filter != nil:
+		return applySCIMFiltered(c, key, p, kind, value)
+	case p.attr.sub != "":
+		return applySCIMSub(c, key, p.attr.sub, kind, value)
+	}
+	switch kind {
+	case "remove":
+		if elems, ok := c[key].([]any); ok && value != nil {
+			c[key] = removeSCIMValues(elems, value)
+			return nil
+		}
+		delete(c, key)
+	case "add":
+		if cur, ok := c[key].([]any); ok {
+			if vs, ok := value.([]any); ok {
+				c[key] = append(cur, vs...)
+			} else {
+				c[key] = append(cur, value)
+			}
+			return nil
+		}
+		c[key] = mergeSCIMValue(c[key], value)
+	case "replace":
+		c[key] = mergeSCIMValue(c[key], value)
+	}
+	return nil
+}
+
+// applySCIMSub は副属性（name.givenName など）に反映する。複数値の属性なら全要素に反映する。
+func applySCIMSub(c map[string]any, key, sub, kind string, value any) error {
+	set := func(m map[string]any) {
+		if kind == "remove" {
+			delete(m, mapKey(m, sub))
+		} else {
+			m[mapKey(m, sub)] = value
+		}
+	}
+	switch cur := c[key].(type) {
+	case []any:
+		for _, e := range cur {
+			if m, ok := e.(map[string]any); ok {
+				set(m)
+			}
+		}
+	case map[string]any:
+		set(cur)
+	case nil:
+		if kind != "remove" {
+			c[key] = map[string]any{sub: value}
+		}
+	default:
+		return scimInvalid("invalidPath", key+" has no sub-attributes")
+	}
+	return nil
+}
+
+// applySCIMFiltered は絞り込んだ複数値の要素（members[value eq "e1"] など）に反映する。
+func applySCIMFiltered(c map[string]any, key string, p scimPatchPath, kind string, value any) error {
+	elems, _ := c[key].([]any)
+	kept := make([]any, 0, len(elems))
+	matched := false
+	for _, e := range elems {
+		m, ok := e.(map[string]any)
+		if !ok || !p.filter.match(m) {
+			kept = append(kept, e)
+			continue
+		}
+		matched = true
+		switch {
+		case kind == "remove" && p.sub == "":
+			continue // 要素ごと消す
+		case kind == "remove":
+			delete(m, mapKey(m, p.sub))
+		case p.sub != "":
+			m[mapKey(m, p.sub)] = value
+		default:
+			vm, ok := value.(map[string]any)
+			if !ok {
+				return scimInvalid("invalidValue", "value must be an object for "+key)
+			}
+			for k, v := range vm {
+				m[mapKey(m, k)] = v
+			}
+		}
+		kept = append(kept, m)
+	}
+	if !matched && kind != "remove" {
+		// `type eq "work"` のような単純な絞り込みなら、その要素を作る
+		eq, ok := p.filter.(filterCompare)
+		if !ok || eq.op != "eq" || eq.path.schema != "" || eq.path.sub != "" {
+			return scimInvalid("noTarget", "no value matches the path filter")
+		}
+		m := map[string]any{eq.path.name: eq.value}
+		if p.sub != "" {
+			m[p.sub] = value
+		} else if vm, ok := value.(map[string]any); ok {
+			for k, v := range vm {
+				m[k] = v
+			}
+		}
+		kept = append(kept, m)
+	}
+	c[key] = kept
+	return nil
+}
+
+// mergeSCIMValue は複雑な属性どうしなら value の副属性だけを置き換え、それ以外は value にする。
+func mergeSCIMValue(cur, value any) any {
+	cm, ok1 := cur.(map[string]any)
+	vm, ok2 := value.(map[string]any)
+	if !ok1 || !ok2 {
+		return value
+	}
+	for k, v := range vm {
+		cm[mapKey(cm, k)] = v
+	}
+	return cm
+}
+
+// removeSCIMValues は value に挙げた要素（"value" 副属性で比べる）を elems から消す。
+func removeSCIMValues(elems []any, value any) []any {
+	vs, ok := value.([]any)
+	if !ok {
+		vs = []any{value}
+	}
+	drop := map[string]bool{}
+	for _, v := range vs {
+		if m, ok := v.(map[string]any); ok {
+			if id, ok := m[mapKey(m, "value")].(string); ok {
+				drop[id] = true
+			}
+		}
+	}
+	kept := make([]any, 0, len(elems))
+	for _, e := range elems {
+		if m, ok := e.(map[string]any); ok {
+			if id, ok := m[mapKey(m, "value")].(string); ok && drop[id] {
+				continue
+			}
+		}
+		kept = append(kept, e)
+	}
+	return kept
+}
+
+// toSCIMMap はリソースを PATCH・filter の対象にする map にする。
+func toSCIMMap(v any) map[string]any {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err) // 自分で定義した型なので失敗しない
+	}
+	var m map[string]any
+	_ = json.Unmarshal(b, &m)
+	return m
+}
+
+// fromSCIMMap は PATCH を反映した map をリソースの型に戻す。
+func fromSCIMMap(m map[string]any, v any) error {
+	b, err := json.Marshal(m)
+	if err != nil {
+		return scimInvalid("invalidValue", err.Error())
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return scimInvalid("invalidValue", "patched resource is invalid: "+err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,460 @@
+package adapters_test
+
+// SCIM の入口のテスト（internal/scimtest）
+// --------------------------------------------------------
+// メモリの保存先につないだ SCIMHandler に、IdP と同じく scimtest のクライアントで要求を送る。
+// - Bearer トークンの検証（401）
+// - 一覧の filter（比較・論理演算子・複数値の絞り込み・拡張スキーマ）と文法の誤り
+// - User の PATCH（path あり・なし、絞り込み、退職処理）と誤った操作
+// - Group の所属者の追加・削除・部署間の移動・部署名の変更
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/internal/scimtest"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+const scimToken = "scim-secret"
+
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+// scimFixture：テスト用の SCIM サーバとその保存先
+type scimFixture struct {
+	srv    *httptest.Server
+	client *scimtest.Client
+	store  *drivers.MemoryStore
+}
+
+// newSCIMFixture は次の従業員を登録した SCIM サーバを起動する。
+//
+//	hr（人事）・e1（dev、上長 hr）・e2（dev）・e3（sales）・e4（部署なし）
+func newSCIMFixture(t *testing.T) scimFixture {
+	t.Helper()
+	hired := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	store := drivers.NewMemoryStore()
+	store.Seed(
+		domain.Employee{ID: "hr", Name: "人事 太郎", Email: "hr@example.com", HireDate: hired, Role: domain.RoleHR, Department: "HR"},
+		domain.Employee{ID: "e1", Name: "山田 花子", Email: "hanako@example.com", HireDate: hired, ManagerID: "hr", Department: "dev"},
+		domain.Employee{ID: "e2", Name: "Bob Smith", Email: "bob@example.org", HireDate: hired, Department: "dev", Locale: domain.LocaleEnglish},
+		domain.Employee{ID: "e3", Name: "佐藤 一郎", Email: "ichiro@example.com", HireDate: hired, Department: "sales"},
+		domain.Employee{ID: "e4", Name: "鈴木 次郎", HireDate: hired},
+	)
+	clock := fixedClock{time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)}
+	uc := usecase.ManageEmployees{
+		EmployeesRepo:  store.Employees(),
+		EmployeeList:   store.Employees(),
+		EmployeeWriter: store.Employees(),
+		LeavesRepo:     store.Leaves(),
+		EmployeeLeaves: store.Leaves(),
+		Clock:          clock,
+		YearStart:      domain.FiscalYearStart,
+	}
+	h := adapters.SCIMHandler{UC: uc, Token: scimToken, ActorID: "hr", BaseURL: "https://leave.example.com", Clock: clock}
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return scimFixture{srv: srv, client: scimtest.NewClient(srv.URL+"/scim/v2", scimToken), store: store}
+}
+
+// employee は保存先の従業員を返す。
+func (f scimFixture) employee(t *testing.T, id string) domain.Employee {
+	t.Helper()
+	e, err := f.store.Employees().FindByID(id)
+	if err != nil {
+		t.Fatalf("FindByID(%s): %v", id, err)
+	}
+	return e
+}
+
+// department は保存先の従業員の部署を返す。
+func (f scimFixture) department(t *testing.T, id string) string {
+	t.Helper()
+	return f.employee(t, id).Department
+}
+
+// ids はリソースの id を並べて返す。
+func ids(rs []scimtest.Resource) []string {
+	out := make([]string, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r.String("id"))
+	}
+	slices.Sort(out)
+	return out
+}
+
+// memberIDs は Group の members の value を並べて返す。
+func memberIDs(g scimtest.Resource) []string {
+	members, _ := g["members"].([]any)
+	out := []string{}
+	for _, m := range members {
+		if mm, ok := m.(map[string]any); ok {
+			s, _ := mm["value"].(string)
+			out = append(out, s)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// wantSCIMError は err が status・scimType の SCIM のエラーかを確かめる（scimType が空なら比べない）。
+func wantSCIMError(t *testing.T, err error, status int, scimType string) {
+	t.Helper()
+	var se *scimtest.Error
+	if !errors.As(err, &se) {
+		t.Fatalf("err = %v, want a SCIM error %d %s", err, status, scimType)
+	}
+	if se.Status != status || scimType != "" && se.ScimType != scimType {
+		t.Errorf("err = %v, want %d %s", se, status, scimType)
+	}
+}
+
+func TestSCIMRejectsBadBearerToken(t *testing.T) {
+	f := newSCIMFixture(t)
+
+	cases := []struct {
+		name   string
+		header string
+	}{
+		{"no header", ""},
+		{"wrong token", "Bearer not-the-token"},
+		{"other scheme", "Basic " + scimToken},
+		{"token without scheme", scimToken},
+	}
+	for _, c := range cases {
+		req, _ := http.NewRequest(http.MethodGet, f.srv.URL+"/scim/v2/Users", nil)
+		if c.header != "" {
+			req.Header.Set("Authorization", c.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("%s: status = %d, want 401", c.name, resp.StatusCode)
+		}
+		if got := resp.Header.Get("WWW-Authenticate"); got != `Bearer realm="scim"` {
+			t.Errorf("%s: WWW-Authenticate = %q", c.name, got)
+		}
+	}
+
+	// 書き込みも、認証の前に何も変更しない
+	bad := scimtest.NewClient(f.srv.URL+"/scim/v2", "wrong")
+	_, err := bad.Patch("/Users/e1", scimtest.Replace("active", false))
+	wantSCIMError(t, err, http.StatusUnauthorized, "")
+	if !f.employee(t, "e1").IsActive() {
+		t.Error("e1 was deactivated by an unauthenticated request")
+	}
+
+	// トークンを設定していなければ、どのトークンも受け付けない
+	open := httptest.NewServer(adapters.SCIMHandler{ActorID: "hr"})
+	defer open.Close()
+	_, err = scimtest.NewClient(open.URL+"/scim/v2", "anything").Get("/Users/e1")
+	wantSCIMError(t, err, http.StatusUnauthorized, "")
+}
+
+func TestSCIMUserFilter(t *testing.T) {
+	f := newSCIMFixture(t)
+	const enterprise = scimtest.EnterpriseSchema
+
+	cases := []struct {
+		filter string
+		want   []string
+	}{
+		{`userName eq "e1"`, []string{"e1"}},
+		{`USERNAME EQ "E1"`, []string{"e1"}}, // 属性名・演算子・文字列は大文字小文字を区別しない
+		{`id eq "E1"`, []string{}},           // id は区別する
+		{`userName ne "e1"`, []string{"e2", "e3", "e4", "hr"}},
+		{`displayName co "ob"`, []string{"e2"}},
+		{`displayName sw "山田"`, []string{"e1"}},
+		{`emails ew "example.org"`, []string{"e2"}},
+		{`emails pr`, []string{"e1", "e2", "e3", "hr"}},
+		{`not (emails pr)`, []string{"e4"}},
+		{`emails[type eq "work" and value co "hanako"]`, []string{"e1"}},
+		{`emails.value sw "ichiro"`, []string{"e3"}},
+		{`name.formatted eq "佐藤 一郎"`, []string{"e3"}},
+		{`preferredLanguage eq "en"`, []string{"e2"}},
+		{`active eq true and userName sw "e"`, []string{"e1", "e2", "e3", "e4"}},
+		{enterprise + `:department eq "dev"`, []string{"e1", "e2"}},
+		{enterprise + `:manager.value eq "hr"`, []string{"e1"}},
+		{`urn:ietf:params:scim:schemas:core:2.0:User:userName eq "e3"`, []string{"e3"}},
+		// and は or より先に結びつく
+		{`userName eq "e3" or userName sw "e" and displayName co "Bob"`, []string{"e2", "e3"}},
+		{`(userName eq "e3" or userName sw "e") and displayName co "Bob"`, []string{"e2"}},
+		{`groups[display eq "sales"]`, []string{"e3"}},
+		{`meta.resourceType eq "User" and userName gt "e3"`, []string{"e4", "hr"}},
+	}
+	for _, c := range cases {
+		got, err := f.client.ListAll("/Users", c.filter, 2)
+		if err != nil {
+			t.Errorf("filter %s: %v", c.filter, err)
+			continue
+		}
+		if !slices.Equal(ids(got), c.want) {
+			t.Errorf("filter %s = %v, want %v", c.filter, ids(got), c.want)
+		}
+	}
+
+	for _, bad := range []string{
+		`userName eq`,
+		`userName foo "e1"`,
+		`userName eq "e1" and`,
+		`(userName eq "e1"`,
+		`emails[type eq "work"`,
+		`not userName eq "e1"`,
+		`"e1" eq userName`,
+		`user.name.given eq "x"`,
+		`userName eq e1`,
+	} {
+		_, err := f.client.List("/Users", bad, 1, 10)
+		wantSCIMError(t, err, http.StatusBadRequest, "invalidFilter")
+	}
+}
+
+func TestSCIMUserPaging(t *testing.T) {
+	f := newSCIMFixture(t)
+	page, err := f.client.List("/Users", `userName sw "e"`, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.TotalResults != 4 || page.StartIndex != 2 || page.ItemsPerPage != 2 || len(page.Resources) != 2 {
+		t.Errorf("page = total %d, start %d, items %d (%d resources), want 4, 2, 2",
+			page.TotalResults, page.StartIndex, page.ItemsPerPage, len(page.Resources))
+	}
+}
+
+func TestSCIMUserPatch(t *testing.T) {
+	f := newSCIMFixture(t)
+
+	// path ありの操作：氏名の一部・絞り込んだメールアドレス・拡張スキーマの属性
+	u, err := f.client.Patch("/Users/e1",
+		scimtest.Replace("name.familyName", "田中"),
+		scimtest.Replace("name.givenName", "花子"),
+		scimtest.Replace(`emails[type eq "work"].value`, "tanaka@example.com"),
+		scimtest.Replace(scimtest.EnterpriseSchema+":department", "qa"),
+		scimtest.Remove(scimtest.EnterpriseSchema+":manager"),
+		scimtest.Add("preferredLanguage", "en"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := f.employee(t, "e1")
+	if e.Name != "田中 花子" || e.Email != "tanaka@example.com" || e.Department != "qa" || e.ManagerID != "" || e.Locale != domain.LocaleEnglish {
+		t.Errorf("after patch = %+v", e)
+	}
+	if u.String("displayName") != "田中 花子" {
+		t.Errorf("response displayName = %q", u.String("displayName"))
+	}
+
+	// path なしの操作：value のオブジェクトの属性ごと（拡張スキーマの URN をキーにしたものも）
+	_, err = f.client.Patch("/Users/e2", scimtest.Op{Op: "replace", Value: map[string]any{
+		"displayName":             "Robert Smith",
+		scimtest.EnterpriseSchema: map[string]any{"manager": map[string]any{"value": "e1"}},
+		"urn:ohagi:params:scim:schemas:extension:leave:2.0:User": map[string]any{"hireDate": "2021-07-01"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e = f.employee(t, "e2")
+	if e.Name != "Robert Smith" || e.ManagerID != "e1" || !e.HireDate.Equal(time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)) || e.Department != "dev" {
+		t.Errorf("after path-less patch = %+v", e)
+	}
+
+	// 絞り込みに合う要素がなければ、eq の条件から要素を作る
+	if _, err := f.client.Patch("/Users/e4", scimtest.Add(`emails[type eq "work"].value`, "jiro@example.com")); err != nil {
+		t.Fatal(err)
+	}
+	if e := f.employee(t, "e4"); e.Email != "jiro@example.com" {
+		t.Errorf("e4 email = %q, want jiro@example.com", e.Email)
+	}
+
+	// active を false（文字列の "False" も受け付ける）にすると退職処理をする
+	u, err = f.client.Patch("/Users/e3", scimtest.Replace("active", "False"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := f.employee(t, "e3"); e.IsActive() {
+		t.Error("e3 is still active")
+	}
+	if u["active"] != false {
+		t.Errorf("response active = %v, want false", u["active"])
+	}
+}
+
+func TestSCIMUserPatchErrors(t *testing.T) {
+	f := newSCIMFixture(t)
+	before := f.employee(t, "e1")
+
+	cases := []struct {
+		name     string
+		ops      []scimtest.Op
+		status   int
+		scimType string
+	}{
+		{"no operations", nil, http.StatusBadRequest, "invalidSyntax"},
+		{"unknown op", []scimtest.Op{{Op: "move", Path: "displayName", Value: "x"}}, http.StatusBadRequest, "invalidSyntax"},
+		{"remove without path", []scimtest.Op{{Op: "remove"}}, http.StatusBadRequest, "noTarget"},
+		{"path-less value is not an object", []scimtest.Op{{Op: "replace", Value: "x"}}, http.StatusBadRequest, "invalidValue"},
+		{"bad path", []scimtest.Op{scimtest.Replace("emails[type eq", "x")}, http.StatusBadRequest, "invalidPath"},
+		{"no element matches a complex filter", []scimtest.Op{scimtest.Replace(`emails[type eq "home" or primary eq false].value`, "x")}, http.StatusBadRequest, "noTarget"},
+		{"userName is immutable", []scimtest.Op{scimtest.Replace("userName", "e9")}, http.StatusBadRequest, "mutability"},
+		{"unknown language", []scimtest.Op{scimtest.Replace("preferredLanguage", "fr")}, http.StatusBadRequest, "invalidValue"},
+		{"bad hire date", []scimtest.Op{scimtest.Replace("urn:ohagi:params:scim:schemas:extension:leave:2.0:User:hireDate", "2021/07/01")}, http.StatusBadRequest, "invalidValue"},
+		{"later op fails", []scimtest.Op{scimtest.Replace("displayName", "changed"), {Op: "move"}}, http.StatusBadRequest, "invalidSyntax"},
+	}
+	for _, c := range cases {
+		_, err := f.client.Patch("/Users/e1", c.ops...)
+		if err == nil {
+			t.Errorf("%s: err = nil", c.name)
+			continue
+		}
+		wantSCIMError(t, err, c.status, c.scimType)
+	}
+	// どの操作も反映されていない
+	if after := f.employee(t, "e1"); !reflect.DeepEqual(after, before) {
+		t.Errorf("employee changed by failed patches: %+v", after)
+	}
+
+	_, err := f.client.Patch("/Users/nobody", scimtest.Replace("displayName", "x"))
+	wantSCIMError(t, err, http.StatusNotFound, "")
+
+	// 退職者は戻せない
+	if _, err := f.client.Patch("/Users/e3", scimtest.Replace("active", false)); err != nil {
+		t.Fatal(err)
+	}
+	_, err = f.client.Patch("/Users/e3", scimtest.Replace("active", true))
+	wantSCIMError(t, err, http.StatusConflict, "mutability")
+}
+
+func TestSCIMUserDeleteIsRefused(t *testing.T) {
+	f := newSCIMFixture(t)
+	before := f.employee(t, "e1")
+
+	// 退職者も GET できるので削除は受け付けず、active を false にしてもらう
+	err := f.client.Delete("/Users/e1")
+	wantSCIMError(t, err, http.StatusMethodNotAllowed, "")
+	if after := f.employee(t, "e1"); !reflect.DeepEqual(after, before) {
+		t.Errorf("employee changed by a refused delete: %+v", after)
+	}
+	if _, err := f.client.Patch("/Users/e1", scimtest.Replace("active", false)); err != nil {
+		t.Fatal(err)
+	}
+	u, err := f.client.Get("/Users/e1")
+	if err != nil {
+		t.Fatalf("Get after deactivation: %v", err)
+	}
+	if u["active"] != false {
+		t.Errorf("active = %v, want false", u["active"])
+	}
+}
+
+func TestSCIMGroupMembership(t *testing.T) {
+	f := newSCIMFixture(t)
+	member := func(id string) map[string]any { return map[string]any{"value": id} }
+
+	// 部署を作ると、所属者は元の部署から移る
+	g, err := f.client.Create("/Groups", scimtest.Resource{
+		"schemas": []string{scimtest.GroupSchema}, "displayName": "qa", "members": []any{member("e2")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.String("id") != "qa" || !slices.Equal(memberIDs(g), []string{"e2"}) {
+		t.Errorf("created group = %v", g)
+	}
+	if d := f.department(t, "e2"); d != "qa" {
+		t.Errorf("e2 department = %q, want qa", d)
+	}
+	dev, err := f.client.Get("/Groups/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(memberIDs(dev), []string{"e1"}) {
+		t.Errorf("dev members = %v, want [e1]", memberIDs(dev))
+	}
+
+	// 所属者の追加で、別の部署（sales）から移す。所属者のいなくなった部署は見えなくなる
+	g, err = f.client.Patch("/Groups/qa", scimtest.Add("members", []any{member("e3")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(memberIDs(g), []string{"e2", "e3"}) {
+		t.Errorf("qa members = %v, want [e2 e3]", memberIDs(g))
+	}
+	_, err = f.client.Get("/Groups/sales")
+	wantSCIMError(t, err, http.StatusNotFound, "")
+
+	// 所属者の削除（絞り込みの path と、value に要素を挙げる形の両方）
+	if _, err := f.client.Patch("/Groups/qa", scimtest.Remove(`members[value eq "e2"]`)); err != nil {
+		t.Fatal(err)
+	}
+	if d := f.department(t, "e2"); d != "" {
+		t.Errorf("e2 department = %q, want empty", d)
+	}
+	if _, err := f.client.Patch("/Groups/dev", scimtest.Op{Op: "remove", Path: "members", Value: []any{member("e1")}}); err != nil {
+		t.Fatal(err)
+	}
+	if d := f.department(t, "e1"); d != "" {
+		t.Errorf("e1 department = %q, want empty", d)
+	}
+
+	// 部署名の変更は所属者の部署を変える。既にある部署の名前にはできない
+	g, err = f.client.Patch("/Groups/qa", scimtest.Replace("displayName", "quality"), scimtest.Add("members", []any{member("e1")}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.String("id") != "quality" || !slices.Equal(memberIDs(g), []string{"e1", "e3"}) {
+		t.Errorf("renamed group = %v", g)
+	}
+	if d1, d3 := f.department(t, "e1"), f.department(t, "e3"); d1 != "quality" || d3 != "quality" {
+		t.Errorf("departments = %q, %q, want quality", d1, d3)
+	}
+	_, err = f.client.Patch("/Groups/quality", scimtest.Replace("displayName", "HR"))
+	wantSCIMError(t, err, http.StatusConflict, "uniqueness")
+	_, err = f.client.Patch("/Groups/quality", scimtest.Replace("id", "other"))
+	wantSCIMError(t, err, http.StatusBadRequest, "mutability")
+
+	// PUT は所属者を置き換える
+	g, err = f.client.Replace("/Groups/quality", scimtest.Resource{
+		"schemas": []string{scimtest.GroupSchema}, "displayName": "quality", "members": []any{member("e2"), member("e3")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(memberIDs(g), []string{"e2", "e3"}) || f.department(t, "e1") != "" {
+		t.Errorf("replaced group = %v, e1 department %q", memberIDs(g), f.department(t, "e1"))
+	}
+
+	// 存在しない従業員・グループの入れ子は受け付けない
+	_, err = f.client.Patch("/Groups/quality", scimtest.Add("members", []any{member("nobody")}))
+	wantSCIMError(t, err, http.StatusBadRequest, "invalidValue")
+	_, err = f.client.Patch("/Groups/quality", scimtest.Add("members", []any{map[string]any{"value": "HR", "type": "Group"}}))
+	wantSCIMError(t, err, http.StatusBadRequest, "invalidValue")
+
+	// 部署の削除は所属者全員の部署を空にする
+	if err := f.client.Delete("/Groups/quality"); err != nil {
+		t.Fatal(err)
+	}
+	if d2, d3 := f.department(t, "e2"), f.department(t, "e3"); d2 != "" || d3 != "" {
+		t.Errorf("departments after delete = %q, %q, want empty", d2, d3)
+	}
+	groups, err := f.client.ListAll("/Groups", "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ids(groups), []string{"HR"}) {
+		t.Errorf("groups = %v, want [HR]", ids(groups))
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/rand"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+)
+
+// newFeedTokens は CALENDAR_SECRET を秘密鍵にした購読トークンを作る。
+// 未設定なら起動ごとに乱数の鍵を使う（再起動で購読 URL が使えなくなるので本番では必ず設定する）。
+func newFeedTokens() drivers.HMACFeedTokens {
+	if v := os.Getenv("CALENDAR_SECRET"); v != "" {
+		return drivers.HMACFeedTokens{Secret: []byte(v)}
+	}
+	slog.Warn("CALENDAR_SECRET is not set; calendar feed URLs will change on restart")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return drivers.HMACFeedTokens{Secret: secret}
+}
+
+// calendarLocation は半日休の時刻を数えるタイムゾーン（CALENDAR_TIME_ZONE。既定は Asia/Tokyo）を返す。
+func calendarLocation() *time.Location {
+	name := os.Getenv("CALENDAR_TIME_ZONE")
+	if name == "" {
+		name = "Asia/Tokyo"
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		slog.Warn("unknown CALENDAR_TIME_ZONE; using UTC", "tz", name, "err", err)
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,39 @@
+package main
+
+// rebuild-counters サブコマンド（年度内の申請回数の集計表を作り直す）
+// --------------------------------------------------------
+// SQL データベースの leave_counters を leave_requests の全件から作り直す。
+// 年度の定義や数え方を変えたとき、集計表がずれたときに使う。接続先は DATABASE_URL（方言は LEAVE_STORE）。
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+)
+
+// runRebuildCounters は rebuild-counters サブコマンドを実行し、終了コードを返す。
+func runRebuildCounters(args []string, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		fmt.Fprintln(stderr, "usage: rebuild-counters")
+		return 64 // EX_USAGE
+	}
+	db, d, err := openDB()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 69 // EX_UNAVAILABLE
+	}
+	defer db.Close()
+	if err := drivers.NewMigrator(db, d).CheckVersion(); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 69
+	}
+	n, err := drivers.SQLLeaveRepo{DB: db, Dialect: d, YearStart: fiscalYearStart}.RebuildCounters()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 70 // EX_SOFTWARE
+	}
+	fmt.Fprintf(stdout, "rebuilt %d counters\n", n)
+	return 0
+}
@@ -0,0 +1,67 @@
+package domain
+
+// 添付ファイル（診断書・証明書など）
+// --------------------------------------------------------
+// 休暇申請に紐づく添付ファイルの情報と、添付・閲覧に関するルールを定義する。
+// ファイルの中身の保存先（ファイルシステム・クラウドストレージ等）はここでは扱わない。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"time"
+)
+
+// Attachment（添付ファイル）
+// ドメインオブジェクト：休暇申請に添付されたファイルの情報（中身は BlobKey で参照する）
+type Attachment struct {
+	ID          string
+	RequestID   string // 添付先の休暇申請
+	FileName    string
+	ContentType string
+	Size        int64
+	BlobKey     string // 保存先でのキー
+	UploadedBy  string // 添付した従業員
+	UploadedAt  time.Time
+}
+
+// MaxAttachmentSize：添付ファイルの上限サイズ（10MB）
+const MaxAttachmentSize = 10 << 20
+
+// allowedAttachmentTypes：添付できるファイル形式（PDF と画像）
+var allowedAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
+
+var (
+	ErrAttachmentType  = errors.New("attachment must be a PDF, JPEG or PNG file")
+	ErrAttachmentSize  = errors.New("attachment is too large")
+	ErrAttachmentEmpty = errors.New("attachment is empty")
+)
+
+// ValidateAttachment は添付ファイルの形式とサイズを検証する。
+func ValidateAttachment(contentType string, size int64) error {
+	if !allowedAttachmentTypes[contentType] {
+		return ErrAttachmentType
+	}
+	if size == 0 {
+		return ErrAttachmentEmpty
+	}
+	if size > MaxAttachmentSize {
+		return ErrAttachmentSize
+	}
+	return nil
+}
+
+// ビジネスルール
+// 添付できるのは申請者本人と人事のみ
+func CanUploadAttachment(actor Employee, req LeaveRequest) bool {
+	return actor.ID == req.EmployeeID || actor.Role == RoleHR
+}
+
+// ビジネスルール
+// 添付ファイルを閲覧できるのは申請者本人・承認者・人事のみ
+func CanViewAttachment(actor Employee, req LeaveRequest) bool {
+	return actor.ID == req.EmployeeID || (req.ApproverID != "" && actor.ID == req.ApproverID) || actor.Role == RoleHR
+}
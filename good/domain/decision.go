@@ -0,0 +1,91 @@
+package domain
+
+// 承認者の判断（承認・却下・差し戻し）に関するルール
+// --------------------------------------------------------
+// 判断できるのは現在の承認者だけで、判断できるのは承認待ちの申請だけ。
+// 却下・差し戻しは申請者が次に何をすればよいか分かるよう、コメントを必須にする。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Decision（承認者の判断）
+type Decision string
+
+const (
+	DecisionApprove Decision = "APPROVE" // 承認
+	DecisionReject  Decision = "REJECT"  // 却下
+	DecisionReturn  Decision = "RETURN"  // 差し戻し（修正して再申請してもらう）
+)
+
+var (
+	ErrUnknownDecision         = errors.New("unknown decision")
+	ErrNotApprover             = errors.New("only the assigned approver can decide on the request")
+	ErrDecisionCommentRequired = errors.New("a comment is required to reject or return a request")
+	ErrDecisionCommentTooLong  = errors.New("decision comment is too long")
+)
+
+// MaxDecisionCommentLength：判断コメントの最大文字数
+const MaxDecisionCommentLength = 1000
+
+// ParseDecision は文字列を Decision に変換する（大文字・小文字は区別しない）。
+func ParseDecision(s string) (Decision, error) {
+	switch d := Decision(strings.ToUpper(strings.TrimSpace(s))); d {
+	case DecisionApprove, DecisionReject, DecisionReturn:
+		return d, nil
+	}
+	return "", ErrUnknownDecision
+}
+
+// Status は判断後の申請のステータスを返す。
+func (d Decision) Status() LeaveStatus {
+	switch d {
+	case DecisionApprove:
+		return StatusApproved
+	case DecisionReject:
+		return StatusRejected
+	case DecisionReturn:
+		return StatusReturned
+	}
+	return ""
+}
+
+// Decide は承認者 approverID の判断を申請に反映する。
+func (r *LeaveRequest) Decide(approverID string, d Decision, comment string, now time.Time) error {
+	comment = strings.TrimSpace(comment)
+	switch {
+	case d.Status() == "":
+		return ErrUnknownDecision
+	case r.Status != StatusPending:
+		return ErrNotPending
+	case r.ApproverID == "" || r.ApproverID != approverID:
+		return ErrNotApprover
+	case d != DecisionApprove && comment == "":
+		return ErrDecisionCommentRequired
+	case len([]rune(comment)) > MaxDecisionCommentLength:
+		return ErrDecisionCommentTooLong
+	}
+	r.Status = d.Status()
+	r.DecisionComment = comment
+	r.DecidedAt = now
+	return nil
+}
+
+// Teammates は e と同じ上長の下にいる在籍中の従業員（e 自身を除く）を返す。
+// 承認された休暇を知らせる相手（不在中に業務を代わる可能性がある人）として使う。
+// 上長のいない従業員にはチームがないものとみなす。
+func Teammates(all []Employee, e Employee) []Employee {
+	if e.ManagerID == "" {
+		return nil
+	}
+	var team []Employee
+	for _, m := range all {
+		if m.ID != e.ID && m.ManagerID == e.ManagerID && m.IsActive() {
+			team = append(team, m)
+		}
+	}
+	return team
+}
@@ -0,0 +1,112 @@
+package domain
+
+// 従業員情報の登録・変更に関するルール
+// --------------------------------------------------------
+// 入社日や上長の指定が業務上正しいかを判定する純粋なルール。
+// 上長が実在するかなど、データの取得が必要な確認は UseCase層が行う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+var (
+	ErrEmployeeIDRequired   = errors.New("employee id is required")
+	ErrEmployeeNameRequired = errors.New("employee name is required")
+	ErrInvalidEmail         = errors.New("invalid email address")
+	ErrHireDateRequired     = errors.New("hire date is required")
+	ErrHireDateTooFar       = errors.New("hire date is too far in the future")
+	ErrSelfManager          = errors.New("employee cannot be their own manager")
+	ErrManagerCycle         = errors.New("manager assignment would create a cycle")
+	ErrInactiveManager      = errors.New("manager has left the company")
+	ErrUnknownRole          = errors.New("unknown role")
+	ErrUnknownLocale        = errors.New("unknown locale")
+	ErrAlreadyLeft          = errors.New("employee has already left")
+	ErrLeftBeforeHired      = errors.New("leaving date is before hire date")
+)
+
+// MaxHireDateAheadMonths：入社予定日として登録できるのは何か月先までか
+const MaxHireDateAheadMonths = 6
+
+// ParseRole は文字列を Role に変換する。空文字は一般の従業員とみなす。
+func ParseRole(s string) (Role, error) {
+	switch r := Role(strings.ToUpper(strings.TrimSpace(s))); r {
+	case "":
+		return RoleEmployee, nil
+	case RoleEmployee, RoleHR:
+		return r, nil
+	}
+	return "", ErrUnknownRole
+}
+
+// ParseLocale は "ja" "en-US" などの表記を Locale に変換する。空文字は未指定（既定の言語）とみなす。
+func ParseLocale(s string) (Locale, error) {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "-")
+	switch l := Locale(lang); l {
+	case "", LocaleJapanese, LocaleEnglish:
+		return l, nil
+	}
+	return "", ErrUnknownLocale
+}
+
+// ValidateEmployee は従業員情報が業務上正しいかを検証する。
+// 上長については自分自身を指定していないかだけを見る（実在・循環の確認は ValidateManagerChain）。
+func ValidateEmployee(e Employee, now time.Time) error {
+	switch {
+	case strings.TrimSpace(e.ID) == "":
+		return ErrEmployeeIDRequired
+	case strings.TrimSpace(e.Name) == "":
+		return ErrEmployeeNameRequired
+	case e.Email != "" && !validEmail(e.Email):
+		return ErrInvalidEmail
+	case e.HireDate.IsZero():
+		return ErrHireDateRequired
+	case e.HireDate.After(now.AddDate(0, MaxHireDateAheadMonths, 0)):
+		return ErrHireDateTooFar
+	case e.ManagerID != "" && e.ManagerID == e.ID:
+		return ErrSelfManager
+	}
+	if _, err := ParseRole(string(e.Role)); err != nil {
+		return err
+	}
+	if l, err := ParseLocale(string(e.Locale)); err != nil || l != e.Locale {
+		return ErrUnknownLocale
+	}
+	return ValidateNotificationPrefs(e.Notify)
+}
+
+// ValidateManagerChain は上長の連鎖が正しいかを検証する。
+// chain は e の上長から順に最上位までたどった従業員の並び（e.ManagerID が空なら空）。
+// 連鎖の途中に e 自身が現れたら循環とみなす。
+func ValidateManagerChain(e Employee, chain []Employee) error {
+	if len(chain) > 0 && !chain[0].IsActive() {
+		return ErrInactiveManager
+	}
+	for _, m := range chain {
+		if m.ID == e.ID {
+			return ErrManagerCycle
+		}
+	}
+	return nil
+}
+
+// Leave は退職処理を行う。残り申請回数を凍結し、以降は申請できなくなる。
+func (e *Employee) Leave(leftOn time.Time, remainingQuota int) error {
+	if !e.IsActive() {
+		return ErrAlreadyLeft
+	}
+	if leftOn.Before(e.HireDate) {
+		return ErrLeftBeforeHired
+	}
+	e.LeftOn = leftOn
+	e.FrozenQuota = remainingQuota
+	return nil
+}
+
+// validEmail はメールアドレスとして最低限の形式（local@domain）を満たすかを返す。
+func validEmail(s string) bool {
+	at := strings.LastIndex(s, "@")
+	return at > 0 && at < len(s)-1 && !strings.ContainsAny(s, " \t\r\n<>")
+}
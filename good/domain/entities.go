@@ -11,22 +11,172 @@ package domain
 //   外部の技術的要素から最も独立しており、どの環境でも再利用できる。
 // --------------------------------------------------------
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+)
 
 type LeaveStatus string
 
 const (
-	StatusPending  LeaveStatus = "PENDING"
-	StatusApproved LeaveStatus = "APPROVED"
-	StatusRejected LeaveStatus = "REJECTED"
-	StatusReturned LeaveStatus = "RETURNED" // 差し戻し
+	StatusPending   LeaveStatus = "PENDING"
+	StatusApproved  LeaveStatus = "APPROVED"
+	StatusRejected  LeaveStatus = "REJECTED"
+	StatusReturned  LeaveStatus = "RETURNED"  // 差し戻し
+	StatusCancelled LeaveStatus = "CANCELLED" // 取消（退職時の自動取消など）
+)
+
+// CountsTowardQuota は、このステータスの申請を年度内の申請回数に数えるかを返す。
+// 承認待ちと承認済みだけを数える。却下・差し戻し（修正した申請は新しく出し直す）・取消は数えない。
+func (s LeaveStatus) CountsTowardQuota() bool {
+	return s == StatusPending || s == StatusApproved
+}
+
+// QuotaDelta はステータスが before から after に変わったときの申請回数の増減（-1, 0, 1）を返す。
+// 新しく登録したときは before を空にする。
+func QuotaDelta(before, after LeaveStatus) int {
+	d := 0
+	if before.CountsTowardQuota() {
+		d--
+	}
+	if after.CountsTowardQuota() {
+		d++
+	}
+	return d
+}
+
+// ErrUnknownStatus：定義されていないステータス
+var ErrUnknownStatus = errors.New("unknown leave status")
+
+// ParseLeaveStatus は文字列を LeaveStatus に変換する（大文字・小文字は区別しない）。
+func ParseLeaveStatus(s string) (LeaveStatus, error) {
+	switch st := LeaveStatus(strings.ToUpper(strings.TrimSpace(s))); st {
+	case StatusPending, StatusApproved, StatusRejected, StatusReturned, StatusCancelled:
+		return st, nil
+	}
+	return "", ErrUnknownStatus
+}
+
+// LeaveType（休暇の種類）
+type LeaveType string
+
+const (
+	LeavePaid    LeaveType = "PAID"    // 年次有給休暇
+	LeaveSick    LeaveType = "SICK"    // 病気休暇
+	LeaveSpecial LeaveType = "SPECIAL" // 特別休暇（慶弔など）
+)
+
+// ErrUnknownLeaveType：定義されていない休暇の種類
+var ErrUnknownLeaveType = errors.New("unknown leave type")
+
+// ParseLeaveType は文字列を LeaveType に変換する。空文字は年次有給休暇とみなす。
+func ParseLeaveType(s string) (LeaveType, error) {
+	switch t := LeaveType(strings.ToUpper(strings.TrimSpace(s))); t {
+	case "":
+		return LeavePaid, nil
+	case LeavePaid, LeaveSick, LeaveSpecial:
+		return t, nil
+	}
+	return "", ErrUnknownLeaveType
+}
+
+// RequiresCertificate は証明書（診断書など）の添付が必要な休暇かを返す。
+func (t LeaveType) RequiresCertificate() bool {
+	return t == LeaveSick || t == LeaveSpecial
+}
+
+// DayPart（休暇を取る時間帯）
+// 半日休（午前・午後）は1日だけの申請で指定できる。
+type DayPart string
+
+const (
+	DayFull DayPart = ""   // 終日
+	DayAM   DayPart = "AM" // 午前休
+	DayPM   DayPart = "PM" // 午後休
 )
 
+var (
+	ErrUnknownDayPart = errors.New("unknown day part")
+	ErrHalfDayPeriod  = errors.New("half-day leave must be a single day")
+)
+
+// ParseDayPart は文字列を DayPart に変換する。空文字と "FULL" は終日とみなす。
+func ParseDayPart(s string) (DayPart, error) {
+	switch p := DayPart(strings.ToUpper(strings.TrimSpace(s))); p {
+	case "", "FULL":
+		return DayFull, nil
+	case DayAM, DayPM:
+		return p, nil
+	}
+	return "", ErrUnknownDayPart
+}
+
+// IsHalf は半日休かを返す。
+func (p DayPart) IsHalf() bool { return p == DayAM || p == DayPM }
+
+// ValidateDayPart は期間 [from, to] に時間帯 p を指定できるかを検証する。
+func ValidateDayPart(p DayPart, from, to time.Time) error {
+	if _, err := ParseDayPart(string(p)); err != nil {
+		return err
+	}
+	if p.IsHalf() && !dateOf(from).Equal(dateOf(to)) {
+		return ErrHalfDayPeriod
+	}
+	return nil
+}
+
+// Role（従業員の役割）
+type Role string
+
+const (
+	RoleEmployee Role = "EMPLOYEE" // 一般の従業員
+	RoleHR       Role = "HR"       // 人事担当（全申請を参照できる）
+)
+
+// Locale（通知などに使う言語）
+type Locale string
+
+const (
+	LocaleJapanese Locale = "ja"
+	LocaleEnglish  Locale = "en"
+)
+
+// DefaultLocale：言語が指定されていない従業員に使う言語
+const DefaultLocale = LocaleJapanese
+
 // Employee（従業員）
 // ドメインオブジェクト：システム内で従業員を表す純粋なモデル
 type Employee struct {
-	ID       string
-	HireDate time.Time
+	ID          string
+	Name        string
+	Email       string
+	HireDate    time.Time
+	ManagerID   string // 上長（承認者）。最上位の場合は空
+	Department  string // 所属部署
+	Role        Role
+	LeftOn      time.Time         // 退職日（在籍中はゼロ値）
+	FrozenQuota int               // 退職時点で凍結した年度内の残り申請回数（在籍中は使わない）
+	Locale      Locale            // 通知の言語（空なら DefaultLocale）
+	Notify      NotificationPrefs // 通知の受け取り方（チャネル・静かな時間）
+}
+
+// PreferredLocale は通知に使う言語を返す。
+func (e Employee) PreferredLocale() Locale {
+	if e.Locale == "" {
+		return DefaultLocale
+	}
+	return e.Locale
+}
+
+// IsActive は在籍中かを返す。
+func (e Employee) IsActive() bool {
+	return e.LeftOn.IsZero()
+}
+
+// WasActiveAt は時刻 t の時点で在籍していたかを返す（退職日以降は在籍していない）。
+func (e Employee) WasActiveAt(t time.Time) bool {
+	return e.LeftOn.IsZero() || t.Before(e.LeftOn)
 }
 
 // LeaveRequest（休暇申請）
@@ -34,18 +184,188 @@ type Employee struct {
 type LeaveRequest struct {
 	ID         string
 	EmployeeID string
+	Type       LeaveType
 	Reason     string
 	From       time.Time
 	To         time.Time
+	DayPart    DayPart // 半日休の時間帯（終日なら空）
 	Status     LeaveStatus
 	CreatedAt  time.Time
+	ApproverID string    // 現在の承認者
+	AssignedAt time.Time // 現在の承認者に回ってきた日時
+	RemindedAt time.Time // 最後に催促した日時（未催促ならゼロ値）
+	DecidedAt  time.Time // 承認・却下・差し戻しされた日時（未決定ならゼロ値）
+	// DecisionComment：承認者のコメント（却下・差し戻しの理由など）
+	DecisionComment string
+}
+
+// ErrNotPending：承認待ちでない申請に対する操作
+var ErrNotPending = errors.New("leave request is not pending")
+
+// Cancel は承認待ちの申請を取り消す。
+func (r *LeaveRequest) Cancel(now time.Time) error {
+	if r.Status != StatusPending {
+		return ErrNotPending
+	}
+	r.Status = StatusCancelled
+	r.DecidedAt = now
+	return nil
 }
 
 // ビジネスルール
-// 半年以上勤務している & 年度内5回未満なら申請可能
+// 申請を閲覧できるのは申請者本人・承認者・人事のみ
+func CanViewLeaveRequest(actor Employee, req LeaveRequest) bool {
+	return actor.ID == req.EmployeeID || (req.ApproverID != "" && actor.ID == req.ApproverID) || actor.Role == RoleHR
+}
+
+// ビジネスルール
+// 従業員の申請の一覧を見られるのは本人・直属の上長・人事のみ
+func CanListLeaveRequests(actor, owner Employee) bool {
+	return actor.ID == owner.ID || (owner.ManagerID != "" && actor.ID == owner.ManagerID) || actor.Role == RoleHR
+}
+
+// ビジネスルール
+// 申請を取り下げられるのは申請者本人と人事のみ
+func CanCancelLeaveRequest(actor Employee, req LeaveRequest) bool {
+	return actor.ID == req.EmployeeID || actor.Role == RoleHR
+}
+
+// IsDecided は承認者の判断が済んでいるか（承認待ちでないか）を返す。
+func (r LeaveRequest) IsDecided() bool {
+	return r.Status != StatusPending
+}
+
+// DaysTakenBetween は期間 [from, to] に含まれる取得日数（平日。半日休は 0.5 日）を返す。
+// 承認済みの申請だけを取得とみなす。
+func (r LeaveRequest) DaysTakenBetween(from, to time.Time) float64 {
+	if r.Status != StatusApproved {
+		return 0
+	}
+	start, end := r.From, r.To
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	if start.After(end) {
+		return 0
+	}
+	return LeaveDays(start, end, r.DayPart)
+}
+
+// FiscalYearStart は t を含む会計年度の開始日（4月1日、UTC）を返す。年度は開始日から1年間。
+func FiscalYearStart(t time.Time) time.Time {
+	t = t.UTC()
+	start := time.Date(t.Year(), time.April, 1, 0, 0, 0, 0, time.UTC)
+	if t.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+// ビジネスルールの定数
+const (
+	MinTenureMonths          = 6 // 申請に必要な勤続月数
+	MaxRequestsPerFiscalYear = 5 // 年度内に申請できる回数
+)
+
+// IneligibleReason（申請できない理由）
+type IneligibleReason string
+
+const (
+	ReasonTenureTooShort IneligibleReason = "TENURE_TOO_SHORT" // 勤続が半年未満
+	ReasonQuotaExceeded  IneligibleReason = "QUOTA_EXCEEDED"   // 年度内の申請回数を使い切っている
+	ReasonInactive       IneligibleReason = "INACTIVE"         // 退職済み
+)
+
+// Warning（申請はできるが注意が必要な点）
+type Warning string
+
+const (
+	WarnStartsInPast    Warning = "STARTS_IN_PAST"    // 開始日が過去
+	WarnNoWorkingDays   Warning = "NO_WORKING_DAYS"   // 期間に平日が含まれない
+	WarnLastRequestLeft Warning = "LAST_REQUEST_LEFT" // この申請で年度内の申請回数を使い切る
+	WarnNeedCertificate Warning = "NEED_CERTIFICATE"  // 証明書の添付が必要な休暇
+)
+
+// ErrInvalidPeriod：開始日が終了日より後になっている
+var ErrInvalidPeriod = errors.New("from must not be after to")
+
+// ビジネスルール
+// 在籍中 & 半年以上勤務している & 年度内5回未満なら申請可能
 func CanSubmit(e Employee, submittedCountThisFiscal int, now time.Time) bool {
-	if e.HireDate.AddDate(0, 6, 0).After(now) {
-		return false
+	return len(CheckSubmit(e, submittedCountThisFiscal, now)) == 0
+}
+
+// CheckSubmit は申請できない理由をすべて返す（空なら申請可能）。
+func CheckSubmit(e Employee, submittedCountThisFiscal int, now time.Time) []IneligibleReason {
+	var reasons []IneligibleReason
+	if !e.IsActive() {
+		reasons = append(reasons, ReasonInactive)
+	}
+	if e.HireDate.AddDate(0, MinTenureMonths, 0).After(now) {
+		reasons = append(reasons, ReasonTenureTooShort)
 	}
-	return submittedCountThisFiscal < 5
+	if submittedCountThisFiscal >= MaxRequestsPerFiscalYear {
+		reasons = append(reasons, ReasonQuotaExceeded)
+	}
+	return reasons
+}
+
+// RemainingQuota は年度内に残っている申請回数を返す。
+func RemainingQuota(submittedCountThisFiscal int) int {
+	return max(MaxRequestsPerFiscalYear-submittedCountThisFiscal, 0)
+}
+
+// ValidatePeriod は休暇期間として正しいかを検証する。
+func ValidatePeriod(from, to time.Time) error {
+	if from.After(to) {
+		return ErrInvalidPeriod
+	}
+	return nil
+}
+
+// WorkingDays は期間（両端を含む）に含まれる平日の日数を返す。
+func WorkingDays(from, to time.Time) int {
+	days := 0
+	for d := dateOf(from); !d.After(dateOf(to)); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
+
+// LeaveDays は期間（両端を含む）を時間帯 p で休むときの取得日数を返す（平日のみ。半日休は 0.5 日）。
+func LeaveDays(from, to time.Time, p DayPart) float64 {
+	days := float64(WorkingDays(from, to))
+	if p.IsHalf() {
+		return days / 2
+	}
+	return days
+}
+
+// Warnings は申請内容について注意すべき点を返す。
+func Warnings(t LeaveType, from, to time.Time, submittedCountThisFiscal int, now time.Time) []Warning {
+	var ws []Warning
+	if dateOf(from).Before(dateOf(now)) {
+		ws = append(ws, WarnStartsInPast)
+	}
+	if WorkingDays(from, to) == 0 {
+		ws = append(ws, WarnNoWorkingDays)
+	}
+	if RemainingQuota(submittedCountThisFiscal) == 1 {
+		ws = append(ws, WarnLastRequestLeft)
+	}
+	if t.RequiresCertificate() {
+		ws = append(ws, WarnNeedCertificate)
+	}
+	return ws
+}
+
+// dateOf は時刻を切り捨てて日付だけにする。
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaveDaysCountsHalfDaysAsHalf(t *testing.T) {
+	// 2025-05-09（金）〜 05-12（月）は平日2日
+	from := time.Date(2025, 5, 9, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		part DayPart
+		want float64
+	}{{DayFull, 2}, {DayAM, 1}, {DayPM, 1}} {
+		if got := LeaveDays(from, to, tc.part); got != tc.want {
+			t.Errorf("LeaveDays(%q) = %v, want %v", tc.part, got, tc.want)
+		}
+	}
+
+	req := LeaveRequest{Status: StatusApproved, From: from, To: from, DayPart: DayAM}
+	if got := req.DaysTakenBetween(from, to); got != 0.5 {
+		t.Errorf("DaysTakenBetween for a morning off = %v, want 0.5", got)
+	}
+}
@@ -0,0 +1,167 @@
+package domain
+
+// 通知の受け取り方に関するルール
+// --------------------------------------------------------
+// 従業員ごとに、通知を受け取るチャネル（メール・チャット）と通知を控える時間帯（静かな時間）を設定できる。
+// - チャネルを設定していなければ、システムの既定のチャネルで受け取る
+// - 静かな時間には、相手の手を止める（すぐに気づかせる）チャネルを使わない
+//   それで使えるチャネルがなくなるときは、あとで読めるメールで受け取る
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Channel（通知のチャネル）
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelChat  Channel = "chat"
+)
+
+var (
+	ErrUnknownChannel    = errors.New("unknown notification channel")
+	ErrInvalidQuietHours = errors.New("invalid quiet hours (want HH:MM-HH:MM)")
+	ErrUnknownTimeZone   = errors.New("unknown time zone")
+)
+
+// ParseChannel は文字列を Channel に変換する（大文字・小文字は区別しない）。
+func ParseChannel(s string) (Channel, error) {
+	switch c := Channel(strings.ToLower(strings.TrimSpace(s))); c {
+	case ChannelEmail, ChannelChat:
+		return c, nil
+	}
+	return "", ErrUnknownChannel
+}
+
+// Interrupts は受け取った人の手をすぐに止めるチャネルか（静かな時間に使わないか）を返す。
+func (c Channel) Interrupts() bool {
+	return c == ChannelChat
+}
+
+// QuietHours（静かな時間）
+// Start から End まで（End は含まない）。Start が End より遅ければ日付をまたぐ（22:00-07:00 など）。
+// 時刻は分単位で、TimeZone（IANA の名前。空なら UTC）で数える。Start と End が同じなら設定なし。
+type QuietHours struct {
+	Start    int // 0時からの分
+	End      int
+	TimeZone string
+}
+
+// IsZero は静かな時間が設定されていないかを返す。
+func (q QuietHours) IsZero() bool { return q.Start == q.End }
+
+// ParseQuietHours は "22:00-07:00" と時間帯の名前から QuietHours を作る。空文字は設定なし。
+func ParseQuietHours(s, timeZone string) (QuietHours, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return QuietHours{}, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		return QuietHours{}, ErrInvalidQuietHours
+	}
+	start, err := parseClock(from)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	end, err := parseClock(to)
+	if err != nil {
+		return QuietHours{}, err
+	}
+	q := QuietHours{Start: start, End: end, TimeZone: strings.TrimSpace(timeZone)}
+	if _, err := q.location(); err != nil {
+		return QuietHours{}, err
+	}
+	return q, nil
+}
+
+func parseClock(s string) (int, error) {
+	var h, m int
+	if n, err := fmt.Sscanf(strings.TrimSpace(s), "%d:%d", &h, &m); err != nil || n != 2 || h < 0 || h > 24 || m < 0 || m > 59 || (h == 24 && m != 0) {
+		return 0, ErrInvalidQuietHours
+	}
+	return h*60 + m, nil
+}
+
+// Range は "22:00-07:00" の形の文字列を返す（設定なしなら空文字）。
+func (q QuietHours) Range() string {
+	if q.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", q.Start/60, q.Start%60, q.End/60, q.End%60)
+}
+
+func (q QuietHours) location() (*time.Location, error) {
+	if q.TimeZone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return nil, ErrUnknownTimeZone
+	}
+	return loc, nil
+}
+
+// Contains は時刻 t が静かな時間に入っているかを返す。
+func (q QuietHours) Contains(t time.Time) bool {
+	if q.IsZero() {
+		return false
+	}
+	loc, err := q.location()
+	if err != nil {
+		loc = time.UTC // 検証済みの値なら起きない
+	}
+	lt := t.In(loc)
+	m := lt.Hour()*60 + lt.Minute()
+	if q.Start < q.End {
+		return q.Start <= m && m < q.End
+	}
+	return m >= q.Start || m < q.End
+}
+
+// NotificationPrefs（通知の受け取り方）
+type NotificationPrefs struct {
+	Channels []Channel // 空なら既定のチャネル
+	Quiet    QuietHours
+}
+
+// ValidateNotificationPrefs は通知の受け取り方が正しいかを検証する。
+func ValidateNotificationPrefs(p NotificationPrefs) error {
+	for _, c := range p.Channels {
+		if _, err := ParseChannel(string(c)); err != nil {
+			return err
+		}
+	}
+	if p.Quiet.Start < 0 || p.Quiet.Start > 24*60 || p.Quiet.End < 0 || p.Quiet.End > 24*60 {
+		return ErrInvalidQuietHours
+	}
+	_, err := p.Quiet.location()
+	return err
+}
+
+// ChannelsAt は時刻 now に通知を送るチャネルを返す。
+// defaults は従業員がチャネルを設定していないときに使うチャネル。
+func (p NotificationPrefs) ChannelsAt(now time.Time, defaults []Channel) []Channel {
+	chans := p.Channels
+	if len(chans) == 0 {
+		chans = defaults
+	}
+	if !p.Quiet.Contains(now) {
+		return chans
+	}
+	var quiet []Channel
+	for _, c := range chans {
+		if !c.Interrupts() {
+			quiet = append(quiet, c)
+		}
+	}
+	if len(quiet) == 0 {
+		quiet = []Channel{ChannelEmail}
+	}
+	return quiet
+}
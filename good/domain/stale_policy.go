@@ -0,0 +1,110 @@
+package domain
+
+// 滞留している申請の扱い（催促・エスカレーション・自動承認）
+// --------------------------------------------------------
+// 承認待ちのまま放置された申請を、ポリシーに従ってどう扱うかを決める純粋なルール。
+// 実際の通知や保存は UseCase層が行う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"time"
+)
+
+// StaleAction：滞留している申請に対して行う処理
+type StaleAction int
+
+const (
+	StaleNone        StaleAction = iota // 何もしない
+	StaleRemind                         // 現在の承認者へ催促する
+	StaleEscalate                       // 次の承認者（承認者の上長）へ回す
+	StaleAutoApprove                    // 自動承認する
+	StaleExpire                         // 期限切れとして取り消す（開始日を過ぎても承認待ちの申請）
+)
+
+// StalePolicy：滞留している申請の扱い方
+type StalePolicy struct {
+	RemindAfterDays        int  // 承認者に回ってから（または前回の催促から）何営業日で催促するか（0なら催促しない）
+	EscalateAfterDays      int  // 承認者に回ってから何営業日で次の承認者へ回すか（0ならエスカレーションしない）
+	AutoApproveBeforeStart bool // 開始日の前日になっても承認待ちなら自動承認するか（開始日以降は自動承認しない）
+	// AutoApproveMinPending：申請から自動承認までに最低限置く時間（承認者が確認する時間。0 なら DefaultAutoApproveMinPending）
+	AutoApproveMinPending time.Duration
+	ExpireAfterStart      bool // 開始日になっても承認待ちなら期限切れとして取り消すか
+}
+
+// DefaultAutoApproveMinPending：申請から自動承認までに最低限置く時間の既定値
+// 開始日の前日に出された申請を、誰も見ないうちに自動承認しないため。
+const DefaultAutoApproveMinPending = 24 * time.Hour
+
+// ErrNotAutoApprovable：ポリシー上、今は自動承認できない申請
+var ErrNotAutoApprovable = errors.New("leave request cannot be auto-approved now")
+
+// DefaultStalePolicy：標準のポリシー（3営業日で催促、5営業日でエスカレーション、自動承認なし）
+var DefaultStalePolicy = StalePolicy{RemindAfterDays: 3, EscalateAfterDays: 5}
+
+// Next は承認待ちの申請に対して今行うべき処理を返す。
+// 複数当てはまる場合は 期限切れ・自動承認 > エスカレーション > 催促 の順で優先する。
+// 自動承認するのは開始日の前日だけ（ジョブが止まっていて前日を過ぎた申請を、休みが始まった後で承認しない）。
+// 申請から AutoApproveMinPending がたっていない申請は自動承認せず、催促・エスカレーションだけを判断する。
+// 開始日を迎えた申請は、ExpireAfterStart なら取り消し、そうでなければ承認者が事後に判断できるよう催促・エスカレーションを続ける。
+func (p StalePolicy) Next(req LeaveRequest, now time.Time) StaleAction {
+	if req.Status != StatusPending {
+		return StaleNone
+	}
+	// 開始日は暦の日付として、now と同じタイムゾーンの0時に置いて比べる
+	y, m, d := req.From.Date()
+	today, start := dateOf(now), time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch {
+	case !today.Before(start):
+		if p.ExpireAfterStart {
+			return StaleExpire
+		}
+	case p.AutoApproveBeforeStart && today.Equal(start.AddDate(0, 0, -1)) && now.Sub(req.CreatedAt) >= p.autoApproveMinPending():
+		return StaleAutoApprove
+	}
+	assigned := req.AssignedAt
+	if assigned.IsZero() {
+		assigned = req.CreatedAt
+	}
+	if p.EscalateAfterDays > 0 && BusinessDaysBetween(assigned, now) >= p.EscalateAfterDays {
+		return StaleEscalate
+	}
+	last := assigned
+	if req.RemindedAt.After(last) {
+		last = req.RemindedAt
+	}
+	if p.RemindAfterDays > 0 && BusinessDaysBetween(last, now) >= p.RemindAfterDays {
+		return StaleRemind
+	}
+	return StaleNone
+}
+
+// AutoApprove は自動承認を申請に反映する。今 Next が StaleAutoApprove を返す申請だけを承認する。
+// 承認者の判断（LeaveRequest.Decide）と同じく、判断の済んだ申請は ErrNotPending で断る。
+func (p StalePolicy) AutoApprove(r *LeaveRequest, now time.Time) error {
+	if r.Status != StatusPending {
+		return ErrNotPending
+	}
+	if p.Next(*r, now) != StaleAutoApprove {
+		return ErrNotAutoApprovable
+	}
+	r.Status = StatusApproved
+	r.DecidedAt = now
+	return nil
+}
+
+func (p StalePolicy) autoApproveMinPending() time.Duration {
+	if p.AutoApproveMinPending <= 0 {
+		return DefaultAutoApproveMinPending
+	}
+	return p.AutoApproveMinPending
+}
+
+// BusinessDaysBetween は from の翌日から to までに含まれる平日の日数を返す。
+func BusinessDaysBetween(from, to time.Time) int {
+	start := dateOf(from).AddDate(0, 0, 1)
+	if start.After(dateOf(to)) {
+		return 0
+	}
+	return WorkingDays(start, to)
+}
@@ -0,0 +1,86 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStalePolicyNextAroundStart(t *testing.T) {
+	// 2025-05-12（月）開始の申請。前日の 05-11 は日曜
+	created := time.Date(2025, 5, 9, 9, 0, 0, 0, time.UTC)
+	req := LeaveRequest{
+		Status: StatusPending, From: time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 5, 13, 0, 0, 0, 0, time.UTC),
+		CreatedAt: created, AssignedAt: created,
+	}
+	at := func(month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, time.UTC)
+	}
+	auto := StalePolicy{AutoApproveBeforeStart: true}
+	autoExpire := StalePolicy{AutoApproveBeforeStart: true, ExpireAfterStart: true}
+	remind := StalePolicy{RemindAfterDays: 1}
+
+	cases := []struct {
+		name   string
+		policy StalePolicy
+		now    time.Time
+		want   StaleAction
+	}{
+		{"two days before", auto, at(5, 10, 9), StaleNone},
+		{"day before, morning", auto, at(5, 11, 0), StaleAutoApprove},
+		{"day before, night", auto, at(5, 11, 23), StaleAutoApprove},
+		{"start day is not auto-approved", auto, at(5, 12, 9), StaleNone},
+		{"after start is not auto-approved", auto, at(5, 20, 9), StaleNone},
+		{"start day expires", autoExpire, at(5, 12, 0), StaleExpire},
+		{"after start expires", autoExpire, at(5, 20, 9), StaleExpire},
+		{"day before still auto-approves", autoExpire, at(5, 11, 9), StaleAutoApprove},
+		{"after start keeps reminding", remind, at(5, 13, 9), StaleRemind},
+		{"expire wins over remind", StalePolicy{RemindAfterDays: 1, ExpireAfterStart: true}, at(5, 13, 9), StaleExpire},
+	}
+	for _, c := range cases {
+		if got := c.policy.Next(req, c.now); got != c.want {
+			t.Errorf("%s: Next(%s) = %v, want %v", c.name, c.now.Format("01-02 15:04"), got, c.want)
+		}
+	}
+
+	// 開始日（UTC の0時で保存された日付）は now のタイムゾーンの暦の日付として比べる
+	jst := time.FixedZone("JST", 9*60*60)
+	if got := auto.Next(req, time.Date(2025, 5, 11, 8, 0, 0, 0, jst)); got != StaleAutoApprove {
+		t.Errorf("Next(05-11 08:00 JST) = %v, want StaleAutoApprove", got)
+	}
+
+	decided := req
+	decided.Status = StatusApproved
+	if got := autoExpire.Next(decided, at(5, 20, 9)); got != StaleNone {
+		t.Errorf("Next(approved request) = %v, want StaleNone", got)
+	}
+}
+
+func TestStalePolicyAutoApproveNeedsTimeToReview(t *testing.T) {
+	// 2025-05-14（水）開始の申請を、前日の 05-13 に出した
+	created := time.Date(2025, 5, 13, 9, 0, 0, 0, time.UTC)
+	req := LeaveRequest{
+		Status: StatusPending, From: time.Date(2025, 5, 14, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 5, 14, 0, 0, 0, 0, time.UTC),
+		CreatedAt: created, AssignedAt: created,
+	}
+	auto := StalePolicy{AutoApproveBeforeStart: true}
+	later := created.Add(time.Hour)
+	if got := auto.Next(req, later); got != StaleNone {
+		t.Errorf("Next(1h after submission) = %v, want StaleNone", got)
+	}
+	if err := auto.AutoApprove(&req, later); !errors.Is(err, ErrNotAutoApprovable) || req.Status != StatusPending {
+		t.Errorf("AutoApprove(1h after submission) = %v (status %s), want ErrNotAutoApprovable", err, req.Status)
+	}
+
+	// 確認の時間を短くすれば、同じ日のうちに自動承認する
+	short := StalePolicy{AutoApproveBeforeStart: true, AutoApproveMinPending: time.Hour}
+	if got := short.Next(req, later); got != StaleAutoApprove {
+		t.Errorf("Next with a 1h minimum = %v, want StaleAutoApprove", got)
+	}
+	if err := short.AutoApprove(&req, later); err != nil || req.Status != StatusApproved || !req.DecidedAt.Equal(later) {
+		t.Errorf("AutoApprove = %v, request %+v, want approved at %v", err, req, later)
+	}
+	if err := short.AutoApprove(&req, later); !errors.Is(err, ErrNotPending) {
+		t.Errorf("AutoApprove(approved request) = %v, want ErrNotPending", err)
+	}
+}
@@ -0,0 +1,64 @@
+package drivers
+
+import (
+	"database/sql"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// SQLAttachmentRepo は添付ファイルの情報を SQL データベース（PostgreSQL・MySQL）に保存・取得するリポジトリ。
+// UseCase層の AttachmentRepo インターフェースを満たす。ファイルの中身は BlobStore 側に保存する。
+type SQLAttachmentRepo struct {
+	DB      *sql.DB
+	Dialect Dialect // nil なら PostgreSQL
+}
+
+func (r SQLAttachmentRepo) conn() sqlConn { return newSQLConn(r.DB, r.Dialect) }
+
+// attachmentColumns：attachments から読み出す列（scanAttachment と順番を合わせる）
+const attachmentColumns = `id, request_id, file_name, content_type, size, blob_key, uploaded_by, uploaded_at`
+
+// Create は添付ファイルの情報を登録し、採番されたIDを a.ID に設定する。
+func (r SQLAttachmentRepo) Create(a *domain.Attachment) error {
+	id, err := r.conn().InsertID(
+		`INSERT INTO attachments(request_id,file_name,content_type,size,blob_key,uploaded_by,uploaded_at)
+		 VALUES($1,$2,$3,$4,$5,$6,$7)`,
+		a.RequestID, a.FileName, a.ContentType, a.Size, a.BlobKey, a.UploadedBy, a.UploadedAt)
+	if err != nil {
+		return translateSQLError("SQLAttachmentRepo.Create", err)
+	}
+	a.ID = id
+	return nil
+}
+
+// FindByID は添付ファイルIDで情報を取得する。
+func (r SQLAttachmentRepo) FindByID(id string) (domain.Attachment, error) {
+	a, err := scanAttachment(r.conn().QueryRow(`SELECT `+attachmentColumns+` FROM attachments WHERE id=$1`, id))
+	return a, translateLookupError("SQLAttachmentRepo.FindByID", err, usecase.ErrAttachmentNotFound)
+}
+
+// ListByRequest は休暇申請の添付ファイルを添付順に取得する。
+func (r SQLAttachmentRepo) ListByRequest(requestID string) ([]domain.Attachment, error) {
+	rows, err := r.conn().Query(
+		`SELECT `+attachmentColumns+` FROM attachments WHERE request_id=$1 ORDER BY uploaded_at, id`, requestID)
+	if err != nil {
+		return nil, translateSQLError("SQLAttachmentRepo.ListByRequest", err)
+	}
+	defer rows.Close()
+	var as []domain.Attachment
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, translateSQLError("SQLAttachmentRepo.ListByRequest", err)
+		}
+		as = append(as, a)
+	}
+	return as, translateSQLError("SQLAttachmentRepo.ListByRequest", rows.Err())
+}
+
+func scanAttachment(s rowScanner) (domain.Attachment, error) {
+	var a domain.Attachment
+	err := s.Scan(&a.ID, &a.RequestID, &a.FileName, &a.ContentType, &a.Size, &a.BlobKey, &a.UploadedBy, dbTime{&a.UploadedAt})
+	return a, err
+}
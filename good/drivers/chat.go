@@ -0,0 +1,264 @@
+package drivers
+
+// Framework & Drivers層（チャットへの通知）
+// --------------------------------------------------------
+// ChatNotifier は UseCase層の Mailer を、チャットの Incoming Webhook への投稿で実装する。
+// - 投稿先は宛先の社員の部署ごとに設定する（部署の設定がなければ既定の投稿先、それもなければ送らない）
+// - 本文は {"text": "..."} の JSON。Slack と Teams（Incoming Webhook）のどちらでも受け付ける形
+//   （強調やリンクの書き方だけが違うので、投稿先ごとに ChatFormat で選ぶ）
+// - 429 と 5xx は Retry-After（なければ指数的に伸ばした待ち時間）に従って再送する
+// - 文面（件名・日付・休暇の種類）はメールと同じく宛先の言語で作る
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// ChatFormat：投稿するテキストの書式
+type ChatFormat string
+
+const (
+	ChatFormatSlack ChatFormat = "slack" // Slack の mrkdwn（*太字*・<URL|文字>）
+	ChatFormatTeams ChatFormat = "teams" // Teams の Markdown（**太字**・[文字](URL)）
+)
+
+// ChatWebhook：投稿先の Incoming Webhook
+type ChatWebhook struct {
+	URL    string
+	Format ChatFormat // 空なら Slack
+}
+
+// ChatNotifier：UseCase層の Mailer をチャットへの投稿で実装したもの
+type ChatNotifier struct {
+	Webhooks map[string]ChatWebhook // 部署 → 投稿先
+	Default  ChatWebhook            // 部署の設定がないときの投稿先（URL が空なら送らない）
+	Client   *http.Client           // nil なら10秒で打ち切るクライアント
+	// Retry：429・5xx・通信エラーの再送方法（MaxAttempts が 0 なら4回まで、Backoff が 0 なら1秒から）
+	Retry RetryPolicy
+	// MaxRetryAfter：Retry-After で待つ上限。これより長く待てと言われたら諦める（0 なら1分）
+	MaxRetryAfter time.Duration
+	Now           func() time.Time    // 現在時刻（nil なら time.Now。Retry-After の日付を待ち時間にするのに使う）
+	Sleep         func(time.Duration) // 再送までの待ち方（nil なら time.Sleep。テストで待たずに済ませるのに使う）
+}
+
+// NotifyManagerNewRequest は承認者（上長）に新しい申請を知らせる。
+func (c ChatNotifier) NotifyManagerNewRequest(n usecase.Notification) error {
+	return c.send("ChatNotifier.NotifyManagerNewRequest", mailKindNewRequest, n)
+}
+
+// NotifyApproverReminder は承認待ちの申請を承認者に催促する。
+func (c ChatNotifier) NotifyApproverReminder(n usecase.Notification) error {
+	return c.send("ChatNotifier.NotifyApproverReminder", mailKindReminder, n)
+}
+
+// NotifyEscalation はエスカレーション先の承認者に申請を知らせる。
+func (c ChatNotifier) NotifyEscalation(n usecase.Notification) error {
+	return c.send("ChatNotifier.NotifyEscalation", mailKindEscalation, n)
+}
+
+// NotifyRequesterDecision は申請者に判断の結果を知らせる。
+func (c ChatNotifier) NotifyRequesterDecision(n usecase.Notification) error {
+	return c.send("ChatNotifier.NotifyRequesterDecision", mailKindDecision, n)
+}
+
+// NotifyTeamAbsence はチームのメンバーに承認済みの休暇を知らせる。
+func (c ChatNotifier) NotifyTeamAbsence(n usecase.Notification) error {
+	return c.send("ChatNotifier.NotifyTeamAbsence", mailKindTeamAbsence, n)
+}
+
+// Reaches は宛先の部署に投稿先があるかを返す（NotificationRouter がチャネルを選ぶときに使う）。
+func (c ChatNotifier) Reaches(e domain.Employee) bool {
+	_, ok := c.webhookFor(e)
+	return ok
+}
+
+// webhookFor は宛先の部署の投稿先を返す。
+func (c ChatNotifier) webhookFor(e domain.Employee) (ChatWebhook, bool) {
+	if wh, ok := c.Webhooks[e.Department]; ok && wh.URL != "" {
+		return wh, true
+	}
+	return c.Default, c.Default.URL != ""
+}
+
+// send は通知の種類 kind のメッセージを組み立てて宛先の部署の投稿先へ送る。
+func (c ChatNotifier) send(op, kind string, n usecase.Notification) error {
+	wh, ok := c.webhookFor(n.Recipient)
+	if !ok {
+		return nil // チャットを使っていない部署
+	}
+	text, err := chatMessage(kind, n, wh.Format)
+	if err != nil {
+		return usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	payload, _ := json.Marshal(struct {
+		Text string `json:"text"`
+	}{text})
+	return c.post(op, wh.URL, payload)
+}
+
+// chatLabel：チャットのメッセージに使う見出し
+type chatLabel struct {
+	To, Requester, Type, Dates, Open string
+	days                             func(n float64) string
+}
+
+var chatLabels = map[domain.Locale]chatLabel{
+	domain.LocaleJapanese: {
+		To: "宛先", Requester: "申請者", Type: "種類", Dates: "期間", Open: "申請を確認する",
+		days: func(n float64) string { return "（" + formatDays(n) + "日）" },
+	},
+	domain.LocaleEnglish: {
+		To: "To", Requester: "Requested by", Type: "Type", Dates: "Dates", Open: "Review the request",
+		days: func(n float64) string {
+			if n == 1 {
+				return " (1 working day)"
+			}
+			return " (" + formatDays(n) + " working days)"
+		},
+	},
+}
+
+// formatDays は日数を表示用にする（2 → "2"、0.5 → "0.5"）。
+func formatDays(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
+
+// chatMessage はチャットに投稿するテキストを組み立てる。
+// チャンネルには宛先以外の人もいるので、宛先を明記し、申請理由・判断コメントは載せない（リンク先で確認してもらう）。
+// チームへの連絡にはさらに休暇の種類とリンクも載せない。
+func chatMessage(kind string, n usecase.Notification, format ChatFormat) (string, error) {
+	view, loc := newMailView(n)
+	subject, err := renderSubject(kind, view, loc)
+	if err != nil {
+		return "", err
+	}
+	l := chatLabels[loc]
+	esc, bold, link, sep := chatSlackEscape, "*%s*", "<%s|%s>", "\n"
+	if format == ChatFormatTeams {
+		esc, bold, link, sep = chatTeamsEscape, "**%s**", "[%[2]s](%[1]s)", "\n\n" // Teams は改行1つでは段落にならない
+	}
+	lines := []string{
+		fmt.Sprintf(bold, esc(subject)),
+		l.To + ": " + esc(view.RecipientName),
+		l.Requester + ": " + esc(view.RequesterName),
+	}
+	dates := view.From
+	if view.To != view.From {
+		dates += " - " + view.To
+	}
+	if kind != mailKindTeamAbsence {
+		lines = append(lines, l.Type+": "+esc(view.Type))
+	}
+	lines = append(lines, l.Dates+": "+esc(dates+l.days(view.Days)))
+	if kind != mailKindTeamAbsence && view.Link != "" {
+		lines = append(lines, fmt.Sprintf(link, view.Link, l.Open))
+	}
+	return strings.Join(lines, sep), nil
+}
+
+// chatSlackEscape は Slack の mrkdwn で特別な意味を持つ文字をエスケープする。
+var chatSlackEscape = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace
+
+// chatTeamsEscape は Teams の Markdown で書式として解釈される文字をエスケープする。
+var chatTeamsEscape = strings.NewReplacer(`\`, `\\`, "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`, "<", "&lt;", ">", "&gt;").Replace
+
+// post は payload を投稿する。429・5xx・通信エラーは再送し、それ以外の失敗はすぐに返す。
+func (c ChatNotifier) post(op, endpoint string, payload []byte) error {
+	client := c.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	attempts, wait := c.Retry.MaxAttempts, c.Retry.Backoff
+	if attempts <= 0 {
+		attempts = 4
+	}
+	if wait <= 0 {
+		wait = time.Second
+	}
+	maxRetryAfter := c.MaxRetryAfter
+	if maxRetryAfter <= 0 {
+		maxRetryAfter = time.Minute
+	}
+	for attempt := 1; ; attempt++ {
+		kind, delay, err := c.postOnce(client, endpoint, payload)
+		if err == nil {
+			return nil
+		}
+		if kind != usecase.ErrUnavailable || attempt >= attempts {
+			return usecase.NewError(kind, op, err)
+		}
+		if delay == 0 {
+			delay = wait
+		}
+		if delay > maxRetryAfter {
+			return usecase.NewError(usecase.ErrUnavailable, op, fmt.Errorf("%w (retry after %s is too long)", err, delay))
+		}
+		sleep := c.Sleep
+		if sleep == nil {
+			sleep = time.Sleep
+		}
+		sleep(delay)
+		wait *= 2
+	}
+}
+
+// postOnce は1回投稿する。失敗したときはエラーの種類（ErrUnavailable なら再送してよい）と、
+// サーバが Retry-After で指定した待ち時間（指定がなければ 0）を返す。
+func (c ChatNotifier) postOnce(client *http.Client, endpoint string, payload []byte) (kind error, retryAfter time.Duration, err error) {
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		// Webhook の URL は秘密の値なので、ログに残るエラーには含めない
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = fmt.Errorf("%s webhook: %w", ue.Op, ue.Err)
+		}
+		return usecase.ErrUnavailable, 0, err
+	}
+	// 接続を使い回せるよう本文は読み切る（エラーの説明として先頭だけ残す）
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return nil, 0, nil
+	}
+	err = fmt.Errorf("webhook responded %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		now := time.Now
+		if c.Now != nil {
+			now = c.Now
+		}
+		return usecase.ErrUnavailable, parseRetryAfter(resp.Header.Get("Retry-After"), now()), err
+	}
+	// 4xx は URL の失効や本文の誤りなど、再送しても直らないもの
+	return usecase.ErrInternal, 0, err
+}
+
+// parseRetryAfter は Retry-After ヘッダ（秒数または HTTP 日付）を待ち時間にする。読めなければ 0。
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
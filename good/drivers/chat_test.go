@@ -0,0 +1,218 @@
+package drivers_test
+
+// ChatNotifier の再送のテスト（internal/webhooktest）
+// --------------------------------------------------------
+// Sleep と Now を差し替えて、実際には待たずに待ち時間だけを確かめる。
+// - 429 の Retry-After（秒数・HTTP 日付）に従う
+// - Retry-After がなければ Backoff から倍々に伸ばす
+// - MaxAttempts で諦める・Retry-After が MaxRetryAfter を超えたら待たずに諦める
+// - 4xx は再送しない
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/internal/webhooktest"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// chatNow：テストの現在時刻（Retry-After の HTTP 日付はこれを基準にする）
+var chatNow = time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)
+
+// newRetryingChat は srv に投稿し、待ち時間を sleeps に記録するだけの ChatNotifier を返す。
+func newRetryingChat(srv *webhooktest.Server, retry drivers.RetryPolicy) (drivers.ChatNotifier, *[]time.Duration) {
+	var sleeps []time.Duration
+	c := drivers.ChatNotifier{
+		Default: drivers.ChatWebhook{URL: srv.URL + "/hr"},
+		Retry:   retry,
+		Now:     func() time.Time { return chatNow },
+		Sleep:   func(d time.Duration) { sleeps = append(sleeps, d) },
+	}
+	return c, &sleeps
+}
+
+func chatNotification() usecase.Notification {
+	return usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest}
+}
+
+func TestChatNotifierRetry(t *testing.T) {
+	const backoff = 100 * time.Millisecond
+	cases := []struct {
+		name       string
+		failures   []webhooktest.Failure
+		retry      drivers.RetryPolicy
+		maxAfter   time.Duration
+		wantSleeps []time.Duration
+		wantKind   error // nil なら最後は投稿できる
+	}{
+		{
+			name:       "429 with Retry-After seconds",
+			failures:   []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: "3"}},
+			wantSleeps: []time.Duration{3 * time.Second},
+		},
+		{
+			name:       "429 with Retry-After date",
+			failures:   []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: chatNow.Add(7 * time.Second).Format(http.TimeFormat)}},
+			wantSleeps: []time.Duration{7 * time.Second},
+		},
+		{
+			// 過去の日付や読めない値は指定がないものとみなす
+			name: "unusable Retry-After falls back to backoff",
+			failures: []webhooktest.Failure{
+				{Status: http.StatusTooManyRequests, RetryAfter: chatNow.Add(-time.Minute).Format(http.TimeFormat)},
+				{Status: http.StatusTooManyRequests, RetryAfter: "soon"},
+				{Status: http.StatusTooManyRequests, RetryAfter: "-5"},
+			},
+			retry:      drivers.RetryPolicy{Backoff: backoff},
+			wantSleeps: []time.Duration{backoff, 2 * backoff, 4 * backoff},
+		},
+		{
+			name: "5xx backs off exponentially",
+			failures: []webhooktest.Failure{
+				{Status: http.StatusInternalServerError},
+				{Status: http.StatusBadGateway},
+				{Status: http.StatusServiceUnavailable},
+			},
+			retry:      drivers.RetryPolicy{Backoff: backoff},
+			wantSleeps: []time.Duration{backoff, 2 * backoff, 4 * backoff},
+		},
+		{
+			name: "Retry-After still advances the backoff",
+			failures: []webhooktest.Failure{
+				{Status: http.StatusServiceUnavailable},
+				{Status: http.StatusTooManyRequests, RetryAfter: "2"},
+				{Status: http.StatusServiceUnavailable},
+			},
+			retry:      drivers.RetryPolicy{Backoff: backoff},
+			wantSleeps: []time.Duration{backoff, 2 * time.Second, 4 * backoff},
+		},
+		{
+			name:       "default policy: 4 attempts starting at 1s",
+			failures:   repeatFailure(webhooktest.Failure{Status: http.StatusServiceUnavailable}, 4),
+			wantSleeps: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+			wantKind:   usecase.ErrUnavailable,
+		},
+		{
+			name:       "gives up after MaxAttempts",
+			failures:   repeatFailure(webhooktest.Failure{Status: http.StatusServiceUnavailable}, 3),
+			retry:      drivers.RetryPolicy{MaxAttempts: 2, Backoff: backoff},
+			wantSleeps: []time.Duration{backoff},
+			wantKind:   usecase.ErrUnavailable,
+		},
+		{
+			name:     "MaxAttempts 1 does not retry",
+			failures: []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: "1"}},
+			retry:    drivers.RetryPolicy{MaxAttempts: 1},
+			wantKind: usecase.ErrUnavailable,
+		},
+		{
+			name:     "Retry-After beyond the default MaxRetryAfter",
+			failures: []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: "61"}},
+			wantKind: usecase.ErrUnavailable,
+		},
+		{
+			name:     "Retry-After date beyond MaxRetryAfter",
+			failures: []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: chatNow.Add(10 * time.Second).Format(http.TimeFormat)}},
+			maxAfter: 5 * time.Second,
+			wantKind: usecase.ErrUnavailable,
+		},
+		{
+			name:       "Retry-After equal to MaxRetryAfter is honoured",
+			failures:   []webhooktest.Failure{{Status: http.StatusTooManyRequests, RetryAfter: "5"}},
+			maxAfter:   5 * time.Second,
+			wantSleeps: []time.Duration{5 * time.Second},
+		},
+		{
+			// 4xx は URL の失効などで、再送しても直らない
+			name:     "4xx is not retried",
+			failures: []webhooktest.Failure{{Status: http.StatusNotFound}},
+			wantKind: usecase.ErrInternal,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := webhooktest.NewServer()
+			defer srv.Close()
+			for _, f := range c.failures {
+				srv.FailNext(f.Status, f.RetryAfter)
+			}
+			chat, sleeps := newRetryingChat(srv, c.retry)
+			chat.MaxRetryAfter = c.maxAfter
+
+			err := chat.NotifyManagerNewRequest(chatNotification())
+
+			if fmt.Sprint(*sleeps) != fmt.Sprint(c.wantSleeps) {
+				t.Errorf("slept %v, want %v", *sleeps, c.wantSleeps)
+			}
+			if c.wantKind == nil {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				if got := len(srv.Posts()); got != 1 {
+					t.Errorf("posts = %d, want 1", got)
+				}
+				if got, want := srv.Attempts(), len(c.failures)+1; got != want {
+					t.Errorf("attempts = %d, want %d", got, want)
+				}
+				return
+			}
+			if !errors.Is(err, c.wantKind) {
+				t.Errorf("err = %v, want %v", err, c.wantKind)
+			}
+			if got, want := srv.Attempts(), len(*sleeps)+1; got != want {
+				t.Errorf("attempts = %d, want %d (one more than the sleeps)", got, want)
+			}
+			if len(srv.Posts()) != 0 {
+				t.Errorf("posts = %d, want 0", len(srv.Posts()))
+			}
+		})
+	}
+}
+
+func TestChatNotifierRetryAfterTooLongSaysWhy(t *testing.T) {
+	srv := webhooktest.NewServer()
+	defer srv.Close()
+	srv.FailNext(http.StatusTooManyRequests, "3600")
+	chat, sleeps := newRetryingChat(srv, drivers.RetryPolicy{})
+
+	err := chat.NotifyManagerNewRequest(chatNotification())
+	if err == nil || !strings.Contains(err.Error(), "retry after 1h0m0s is too long") {
+		t.Errorf("err = %v, want it to mention the long Retry-After", err)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("slept %v, want no sleep", *sleeps)
+	}
+}
+
+func TestChatNotifierConnectionErrorHidesURL(t *testing.T) {
+	srv := webhooktest.NewServer()
+	chat, sleeps := newRetryingChat(srv, drivers.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond})
+	srv.Close()
+
+	err := chat.NotifyManagerNewRequest(chatNotification())
+	if !errors.Is(err, usecase.ErrUnavailable) {
+		t.Errorf("err = %v, want ErrUnavailable", err)
+	}
+	// 通信エラーも再送する
+	if want := []time.Duration{time.Millisecond, 2 * time.Millisecond}; fmt.Sprint(*sleeps) != fmt.Sprint(want) {
+		t.Errorf("slept %v, want %v", *sleeps, want)
+	}
+	// Webhook の URL は秘密の値なのでエラーに含めない
+	if err != nil && strings.Contains(err.Error(), srv.URL) {
+		t.Errorf("err = %v, contains the webhook URL", err)
+	}
+}
+
+func repeatFailure(f webhooktest.Failure, n int) []webhooktest.Failure {
+	out := make([]webhooktest.Failure, n)
+	for i := range out {
+		out[i] = f
+	}
+	return out
+}
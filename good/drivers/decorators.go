@@ -0,0 +1,383 @@
+package drivers
+
+// ポート・UseCaseのデコレータ（横断的関心事）
+// --------------------------------------------------------
+// この層の責務：
+// - ログ・計測・リトライといった技術的な処理を、既存の実装を包む形で追加する
+// - 包む対象と同じインターフェースを満たすので、UseCaseや各ドライバは変更不要
+// --------------------------------------------------------
+// 例：EmployeeRepoDecorator{Next: SQLEmployeeRepo{...}, Obs: obs} は
+//     SQLEmployeeRepo と同じように usecase.EmployeeRepo として使える。
+// 組み立て（どれをどれで包むか）は main.go で行う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// RetryPolicy：一時的なエラー（usecase.ErrUnavailable）のリトライ方法
+type RetryPolicy struct {
+	MaxAttempts int           // 最大試行回数（1ならリトライしない）
+	Backoff     time.Duration // 1回目のリトライまでの待ち時間（以降は倍々に伸ばす）
+	RetryWrites bool          // 書き込み（保存・通知）もリトライするか。二重登録・二重送信の可能性がある
+}
+
+// DefaultRetryPolicy：読み取りだけを3回まで試行する
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 3, Backoff: 50 * time.Millisecond}
+
+// Observer：デコレータが共有するログ・計測・リトライの設定
+type Observer struct {
+	Logger  *slog.Logger
+	Metrics *Metrics
+	Retry   RetryPolicy
+}
+
+// opKind：操作の種類（リトライ可否の判断に使う）
+type opKind int
+
+const (
+	opRead    opKind = iota // 読み取り：リトライする
+	opWrite                 // 書き込み：RetryWrites のときだけリトライする
+	opUseCase               // UseCase：リトライしない（必要なリトライは各ポートで行う）
+)
+
+// observe は fn を実行し、所要時間・結果を記録する。一時的なエラーはポリシーに従ってリトライする。
+func observe[T any](o *Observer, op string, kind opKind, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	maxAttempts := 1
+	if kind == opRead || (kind == opWrite && o.Retry.RetryWrites) {
+		maxAttempts = max(o.Retry.MaxAttempts, 1)
+	}
+	var (
+		v        T
+		err      error
+		attempts int
+	)
+	for wait := o.Retry.Backoff; ; wait *= 2 {
+		attempts++
+		v, err = fn()
+		if err == nil || attempts >= maxAttempts || !errors.Is(err, usecase.ErrUnavailable) {
+			break
+		}
+		time.Sleep(wait)
+	}
+	d := time.Since(start)
+
+	if o.Metrics != nil {
+		o.Metrics.Observe(op, d, attempts, err)
+	}
+	if o.Logger != nil {
+		attrs := []any{slog.String("op", op), slog.Duration("duration", d), slog.Int("attempts", attempts)}
+		if err != nil {
+			o.Logger.Warn("call failed", append(attrs, slog.String("kind", usecase.KindOf(err).Error()), slog.Any("err", err))...)
+		} else {
+			o.Logger.Debug("call succeeded", attrs...)
+		}
+	}
+	return v, err
+}
+
+// observeErr は戻り値がエラーだけの関数用の observe。
+func observeErr(o *Observer, op string, kind opKind, fn func() error) error {
+	_, err := observe(o, op, kind, func() (struct{}, error) { return struct{}{}, fn() })
+	return err
+}
+
+// ---- ポートのデコレータ ----
+
+// EmployeeRepoDecorator：usecase.EmployeeRepo を包むデコレータ
+type EmployeeRepoDecorator struct {
+	Next usecase.EmployeeRepo
+	Obs  *Observer
+}
+
+func (d EmployeeRepoDecorator) FindByID(id string) (domain.Employee, error) {
+	return observe(d.Obs, "EmployeeRepo.FindByID", opRead, func() (domain.Employee, error) { return d.Next.FindByID(id) })
+}
+
+// LeaveRepoDecorator：usecase.LeaveRepo を包むデコレータ
+type LeaveRepoDecorator struct {
+	Next usecase.LeaveRepo
+	Obs  *Observer
+}
+
+func (d LeaveRepoDecorator) CountThisFiscalYear(employeeID string, start time.Time) (int, error) {
+	return observe(d.Obs, "LeaveRepo.CountThisFiscalYear", opRead, func() (int, error) { return d.Next.CountThisFiscalYear(employeeID, start) })
+}
+
+func (d LeaveRepoDecorator) Create(req *domain.LeaveRequest) error {
+	return observeErr(d.Obs, "LeaveRepo.Create", opWrite, func() error { return d.Next.Create(req) })
+}
+
+// batchLeaveRepoDecorator：一括保存（usecase.LeaveBatchCreator）にも対応した LeaveRepoDecorator
+type batchLeaveRepoDecorator struct {
+	LeaveRepoDecorator
+	batch usecase.LeaveBatchCreator
+}
+
+func (d batchLeaveRepoDecorator) CreateBatch(reqs []*domain.LeaveRequest) error {
+	return observeErr(d.Obs, "LeaveRepo.CreateBatch", opWrite, func() error { return d.batch.CreateBatch(reqs) })
+}
+
+// DecorateLeaveRepo は LeaveRepo を包む。
+// 包む対象が一括保存に対応していれば、包んだ後も一括保存に対応したまま（型アサーションで見える）にする。
+func DecorateLeaveRepo(next usecase.LeaveRepo, obs *Observer) usecase.LeaveRepo {
+	d := LeaveRepoDecorator{Next: next, Obs: obs}
+	if bc, ok := next.(usecase.LeaveBatchCreator); ok {
+		return batchLeaveRepoDecorator{LeaveRepoDecorator: d, batch: bc}
+	}
+	return d
+}
+
+// PendingLeaveRepoDecorator：usecase.PendingLeaveRepo を包むデコレータ
+type PendingLeaveRepoDecorator struct {
+	Next usecase.PendingLeaveRepo
+	Obs  *Observer
+}
+
+func (d PendingLeaveRepoDecorator) ListPending() ([]domain.LeaveRequest, error) {
+	return observe(d.Obs, "PendingLeaveRepo.ListPending", opRead, d.Next.ListPending)
+}
+
+func (d PendingLeaveRepoDecorator) Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error {
+	return observeErr(d.Obs, "PendingLeaveRepo.Update", opWrite, func() error { return d.Next.Update(req, expected) })
+}
+
+// DecisionLeaveRepoDecorator：usecase.DecisionLeaveRepo を包むデコレータ
+type DecisionLeaveRepoDecorator struct {
+	Next usecase.DecisionLeaveRepo
+	Obs  *Observer
+}
+
+func (d DecisionLeaveRepoDecorator) FindByID(id string) (domain.LeaveRequest, error) {
+	return observe(d.Obs, "DecisionLeaveRepo.FindByID", opRead, func() (domain.LeaveRequest, error) { return d.Next.FindByID(id) })
+}
+
+func (d DecisionLeaveRepoDecorator) Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error {
+	return observeErr(d.Obs, "DecisionLeaveRepo.Update", opWrite, func() error { return d.Next.Update(req, expected) })
+}
+
+// MailerDecorator：usecase.Mailer を包むデコレータ
+type MailerDecorator struct {
+	Next usecase.Mailer
+	Obs  *Observer
+}
+
+func (d MailerDecorator) NotifyManagerNewRequest(n usecase.Notification) error {
+	return observeErr(d.Obs, "Mailer.NotifyManagerNewRequest", opWrite, func() error { return d.Next.NotifyManagerNewRequest(n) })
+}
+
+func (d MailerDecorator) NotifyApproverReminder(n usecase.Notification) error {
+	return observeErr(d.Obs, "Mailer.NotifyApproverReminder", opWrite, func() error { return d.Next.NotifyApproverReminder(n) })
+}
+
+func (d MailerDecorator) NotifyEscalation(n usecase.Notification) error {
+	return observeErr(d.Obs, "Mailer.NotifyEscalation", opWrite, func() error { return d.Next.NotifyEscalation(n) })
+}
+
+func (d MailerDecorator) NotifyRequesterDecision(n usecase.Notification) error {
+	return observeErr(d.Obs, "Mailer.NotifyRequesterDecision", opWrite, func() error { return d.Next.NotifyRequesterDecision(n) })
+}
+
+func (d MailerDecorator) NotifyTeamAbsence(n usecase.Notification) error {
+	return observeErr(d.Obs, "Mailer.NotifyTeamAbsence", opWrite, func() error { return d.Next.NotifyTeamAbsence(n) })
+}
+
+// EventPublisherDecorator：usecase.EventPublisher を包むデコレータ
+type EventPublisherDecorator struct {
+	Next usecase.EventPublisher
+	Obs  *Observer
+}
+
+func (d EventPublisherDecorator) Publish(events ...usecase.Event) error {
+	return observeErr(d.Obs, "EventPublisher.Publish", opWrite, func() error { return d.Next.Publish(events...) })
+}
+
+// ---- UseCase（入力ポート）のデコレータ ----
+
+// SubmitterDecorator：usecase.LeaveSubmitter を包むデコレータ
+type SubmitterDecorator struct {
+	Next usecase.LeaveSubmitter
+	Obs  *Observer
+}
+
+func (d SubmitterDecorator) Submit(in usecase.SubmitInput) (usecase.SubmitOutput, error) {
+	return observe(d.Obs, "SubmitLeave.Submit", opUseCase, func() (usecase.SubmitOutput, error) { return d.Next.Submit(in) })
+}
+
+// PreviewerDecorator：usecase.LeavePreviewer を包むデコレータ
+type PreviewerDecorator struct {
+	Next usecase.LeavePreviewer
+	Obs  *Observer
+}
+
+func (d PreviewerDecorator) Preview(in usecase.SubmitInput) (usecase.PreviewOutput, error) {
+	return observe(d.Obs, "PreviewLeave.Preview", opUseCase, func() (usecase.PreviewOutput, error) { return d.Next.Preview(in) })
+}
+
+// ImporterDecorator：usecase.LeaveImporter を包むデコレータ
+type ImporterDecorator struct {
+	Next usecase.LeaveImporter
+	Obs  *Observer
+}
+
+func (d ImporterDecorator) Import(in usecase.ImportInput) (usecase.ImportOutput, error) {
+	return observe(d.Obs, "ImportLeaves.Import", opUseCase, func() (usecase.ImportOutput, error) { return d.Next.Import(in) })
+}
+
+// ReminderDecorator：usecase.StaleReminder を包むデコレータ
+type ReminderDecorator struct {
+	Next usecase.StaleReminder
+	Obs  *Observer
+}
+
+func (d ReminderDecorator) Run() (usecase.RemindOutput, error) {
+	return observe(d.Obs, "RemindStaleRequests.Run", opUseCase, d.Next.Run)
+}
+
+// DeciderDecorator：usecase.LeaveDecider を包むデコレータ
+type DeciderDecorator struct {
+	Next usecase.LeaveDecider
+	Obs  *Observer
+}
+
+func (d DeciderDecorator) Decide(in usecase.DecideInput) (usecase.DecideOutput, error) {
+	return observe(d.Obs, "DecideLeave.Decide", opUseCase, func() (usecase.DecideOutput, error) { return d.Next.Decide(in) })
+}
+
+// ViewerDecorator：usecase.LeaveViewer を包むデコレータ
+type ViewerDecorator struct {
+	Next usecase.LeaveViewer
+	Obs  *Observer
+}
+
+func (d ViewerDecorator) List(in usecase.ListLeavesInput) ([]domain.LeaveRequest, error) {
+	return observe(d.Obs, "ViewLeaves.List", opUseCase, func() ([]domain.LeaveRequest, error) { return d.Next.List(in) })
+}
+
+func (d ViewerDecorator) Get(actorID, requestID string) (domain.LeaveRequest, error) {
+	return observe(d.Obs, "ViewLeaves.Get", opUseCase, func() (domain.LeaveRequest, error) { return d.Next.Get(actorID, requestID) })
+}
+
+// CancellerDecorator：usecase.LeaveCanceller を包むデコレータ
+type CancellerDecorator struct {
+	Next usecase.LeaveCanceller
+	Obs  *Observer
+}
+
+func (d CancellerDecorator) Cancel(actorID, requestID string) (domain.LeaveRequest, error) {
+	return observe(d.Obs, "CancelLeave.Cancel", opUseCase, func() (domain.LeaveRequest, error) { return d.Next.Cancel(actorID, requestID) })
+}
+
+// ReporterDecorator：usecase.LeaveReporter を包むデコレータ
+type ReporterDecorator struct {
+	Next usecase.LeaveReporter
+	Obs  *Observer
+}
+
+func (d ReporterDecorator) Generate(in usecase.ReportInput) (usecase.LeaveReport, error) {
+	return observe(d.Obs, "LeaveReports.Generate", opUseCase, func() (usecase.LeaveReport, error) { return d.Next.Generate(in) })
+}
+
+// EmployeeManagerDecorator：usecase.EmployeeManager を包むデコレータ
+type EmployeeManagerDecorator struct {
+	Next usecase.EmployeeManager
+	Obs  *Observer
+}
+
+func (d EmployeeManagerDecorator) Get(actorID, id string) (domain.Employee, error) {
+	return observe(d.Obs, "ManageEmployees.Get", opUseCase, func() (domain.Employee, error) { return d.Next.Get(actorID, id) })
+}
+
+func (d EmployeeManagerDecorator) List(actorID string) ([]domain.Employee, error) {
+	return observe(d.Obs, "ManageEmployees.List", opUseCase, func() ([]domain.Employee, error) { return d.Next.List(actorID) })
+}
+
+func (d EmployeeManagerDecorator) Create(actorID string, in usecase.EmployeeInput) (domain.Employee, error) {
+	return observe(d.Obs, "ManageEmployees.Create", opUseCase, func() (domain.Employee, error) { return d.Next.Create(actorID, in) })
+}
+
+func (d EmployeeManagerDecorator) Update(actorID, id string, p usecase.EmployeePatch) (domain.Employee, error) {
+	return observe(d.Obs, "ManageEmployees.Update", opUseCase, func() (domain.Employee, error) { return d.Next.Update(actorID, id, p) })
+}
+
+func (d EmployeeManagerDecorator) SetNotificationPrefs(actorID, id string, p domain.NotificationPrefs) (domain.Employee, error) {
+	return observe(d.Obs, "ManageEmployees.SetNotificationPrefs", opUseCase, func() (domain.Employee, error) {
+		return d.Next.SetNotificationPrefs(actorID, id, p)
+	})
+}
+
+func (d EmployeeManagerDecorator) Deactivate(actorID, id string, leftOn time.Time) (usecase.DeactivateOutput, error) {
+	return observe(d.Obs, "ManageEmployees.Deactivate", opUseCase, func() (usecase.DeactivateOutput, error) {
+		return d.Next.Deactivate(actorID, id, leftOn)
+	})
+}
+
+// UploaderDecorator：usecase.AttachmentUploader を包むデコレータ
+type UploaderDecorator struct {
+	Next usecase.AttachmentUploader
+	Obs  *Observer
+}
+
+func (d UploaderDecorator) Upload(in usecase.UploadInput) (domain.Attachment, error) {
+	return observe(d.Obs, "UploadAttachment.Upload", opUseCase, func() (domain.Attachment, error) { return d.Next.Upload(in) })
+}
+
+// AttachmentListerDecorator：usecase.AttachmentLister を包むデコレータ
+type AttachmentListerDecorator struct {
+	Next usecase.AttachmentLister
+	Obs  *Observer
+}
+
+func (d AttachmentListerDecorator) List(actorID, requestID string) ([]domain.Attachment, error) {
+	return observe(d.Obs, "ListAttachments.List", opUseCase, func() ([]domain.Attachment, error) { return d.Next.List(actorID, requestID) })
+}
+
+// DownloaderDecorator：usecase.AttachmentDownloader を包むデコレータ
+// 計測するのは中身を開くまで（中身の読み出しは呼び出し側で行う）。
+type DownloaderDecorator struct {
+	Next usecase.AttachmentDownloader
+	Obs  *Observer
+}
+
+func (d DownloaderDecorator) Download(actorID, attachmentID string) (domain.Attachment, io.ReadCloser, error) {
+	type download struct {
+		a    domain.Attachment
+		body io.ReadCloser
+	}
+	res, err := observe(d.Obs, "DownloadAttachment.Download", opUseCase, func() (download, error) {
+		a, body, err := d.Next.Download(actorID, attachmentID)
+		return download{a, body}, err
+	})
+	return res.a, res.body, err
+}
+
+// CalendarFeederDecorator：usecase.CalendarFeeder を包むデコレータ
+type CalendarFeederDecorator struct {
+	Next usecase.CalendarFeeder
+	Obs  *Observer
+}
+
+func (d CalendarFeederDecorator) EmployeeFeed(employeeID, token string) (usecase.CalendarFeed, error) {
+	return observe(d.Obs, "CalendarFeeds.EmployeeFeed", opUseCase, func() (usecase.CalendarFeed, error) {
+		return d.Next.EmployeeFeed(employeeID, token)
+	})
+}
+
+func (d CalendarFeederDecorator) TeamFeed(managerID, token string) (usecase.CalendarFeed, error) {
+	return observe(d.Obs, "CalendarFeeds.TeamFeed", opUseCase, func() (usecase.CalendarFeed, error) {
+		return d.Next.TeamFeed(managerID, token)
+	})
+}
+
+func (d CalendarFeederDecorator) FeedTokens(actorID string) (usecase.FeedTokensOutput, error) {
+	return observe(d.Obs, "CalendarFeeds.FeedTokens", opUseCase, func() (usecase.FeedTokensOutput, error) {
+		return d.Next.FeedTokens(actorID)
+	})
+}
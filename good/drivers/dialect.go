@@ -0,0 +1,282 @@
+package drivers
+
+// SQL の方言（データベースごとの書き方の違い）
+// --------------------------------------------------------
+// SQL 版リポジトリ（SQLEmployeeRepo など）とスキーマ移行は、クエリを PostgreSQL の書き方
+// （$1, $2 … のプレースホルダ）で書き、データベースごとの違いは Dialect に任せる。
+// - プレースホルダ：PostgreSQL は $N、MySQL は ?（$N の番号どおりに引数を並べ直す）
+// - 採番したIDの受け取り：PostgreSQL は RETURNING id、MySQL は LastInsertId
+// - 時刻：MySQL の DATETIME はタイムゾーンを持たないので UTC で保存し、UTC として読む
+// - ロック・加算の upsert・複数の文を含むスクリプトの実行
+// --------------------------------------------------------
+// 方言を増やすときは、Dialect を実装し、migrations/<Name()> に同じバージョンのスキーマ変更を置く。
+// --------------------------------------------------------
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Querier：*sql.DB と *sql.Tx の共通部分
+type Querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// Dialect：データベースごとの SQL の違い
+type Dialect interface {
+	// Name は方言の名前（LEAVE_STORE の値・migrations のディレクトリ名）を返す。
+	Name() string
+	// Rebind は $1, $2 … で書いたクエリと引数を、この方言のプレースホルダと値にする。
+	Rebind(query string, args []any) (string, []any)
+	// InsertID は Rebind 済みの INSERT を実行し、採番された id を返す。
+	InsertID(q Querier, query string, args []any) (string, error)
+	// ForUpdate は更新するために行をロックする SELECT の末尾に付ける句を返す。
+	ForUpdate() string
+	// LockTable は table への書き込みを止める方法を返す。
+	// stmt はトランザクションの最初に実行する文、suffix は table を読む SELECT の末尾に付ける句（どちらも空のことがある）。
+	// exclusive なら他のトランザクションのロックも待たせる。
+	LockTable(table string, exclusive bool) (stmt, suffix string)
+	// AddOnConflict は INSERT の末尾に付け、keys が重複したら col に新しい値を足す句を返す。
+	AddOnConflict(table string, keys []string, col string) string
+	// Statements は複数の文を含むスクリプト（スキーマ変更）を、1回の Exec で実行できる単位に分ける。
+	Statements(script string) []string
+}
+
+// PostgresDialect：PostgreSQL（lib/pq・pgx の database/sql ドライバ）
+type PostgresDialect struct{}
+
+func (PostgresDialect) Name() string { return "postgres" }
+
+// Rebind はクエリも引数もそのまま返す（クエリは PostgreSQL の書き方で書いてある）。
+func (PostgresDialect) Rebind(query string, args []any) (string, []any) { return query, args }
+
+func (PostgresDialect) InsertID(q Querier, query string, args []any) (string, error) {
+	var id string
+	err := q.QueryRow(query+" RETURNING id", args...).Scan(&id)
+	return id, err
+}
+
+func (PostgresDialect) ForUpdate() string { return " FOR UPDATE" }
+
+func (PostgresDialect) LockTable(table string, exclusive bool) (string, string) {
+	if exclusive {
+		return "LOCK TABLE " + table + " IN EXCLUSIVE MODE", ""
+	}
+	return "LOCK TABLE " + table + " IN SHARE MODE", ""
+}
+
+func (PostgresDialect) AddOnConflict(table string, keys []string, col string) string {
+	return fmt.Sprintf("ON CONFLICT (%s) DO UPDATE SET %s = %s.%s + EXCLUDED.%s",
+		strings.Join(keys, ", "), col, table, col, col)
+}
+
+// Statements はスクリプトを分けない（PostgreSQL は引数のない Exec で複数の文を実行できる）。
+func (PostgresDialect) Statements(script string) []string { return []string{script} }
+
+// MySQLDialect：MySQL 8.0（go-sql-driver/mysql）
+// DATETIME には UTC で保存するので、接続文字列で loc を変えないこと（parseTime はどちらでもよい）。
+// スキーマ変更の DDL は MySQL ではトランザクションに入らないため、途中で失敗したバージョンは手で戻す必要がある。
+type MySQLDialect struct{}
+
+func (MySQLDialect) Name() string { return "mysql" }
+
+// Rebind は $N を ? にし、引数を $N の出てくる順に並べ直す（同じ番号が2回出てくれば2回渡す）。
+// 時刻は UTC にする。文字列リテラルの中の $ は置き換えない。
+func (MySQLDialect) Rebind(query string, args []any) (string, []any) {
+	var b strings.Builder
+	out := make([]any, 0, len(args))
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if c == '\'' {
+			inQuote = !inQuote
+		}
+		if c != '$' || inQuote {
+			b.WriteByte(c)
+			continue
+		}
+		j := i + 1
+		for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+			j++
+		}
+		n, err := strconv.Atoi(query[i+1 : j])
+		if err != nil || n < 1 || n > len(args) {
+			b.WriteByte(c) // $N ではない（番号のない $ や範囲外の番号はそのまま残す）
+			continue
+		}
+		b.WriteByte('?')
+		out = append(out, utcValue(args[n-1]))
+		i = j - 1
+	}
+	return b.String(), out
+}
+
+// utcValue は時刻の引数を UTC にする（DATETIME に保存されるのは壁時計の時刻だけなので）。
+func utcValue(v any) any {
+	switch t := v.(type) {
+	case time.Time:
+		return t.UTC()
+	case sql.NullTime:
+		if !t.Valid {
+			return nil
+		}
+		return t.Time.UTC()
+	}
+	return v
+}
+
+func (MySQLDialect) InsertID(q Querier, query string, args []any) (string, error) {
+	res, err := q.Exec(query, args...)
+	if err != nil {
+		return "", err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+func (MySQLDialect) ForUpdate() string { return " FOR UPDATE" }
+
+// LockTable は読んだ行（InnoDB では読んだ範囲の隙間も）をロックする句を返す。
+// LOCK TABLES はトランザクションを終わらせてしまうので使わない。
+func (MySQLDialect) LockTable(table string, exclusive bool) (string, string) {
+	if exclusive {
+		return "", " FOR UPDATE"
+	}
+	return "", " LOCK IN SHARE MODE"
+}
+
+func (MySQLDialect) AddOnConflict(table string, keys []string, col string) string {
+	return fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s + VALUES(%s)", col, col, col)
+}
+
+// Statements は行末の ; で文を分ける（ドライバは既定で1回の Exec に1つの文しか受け付けない）。
+// 文字列リテラルの中と -- のコメントの中の ; では分けない。
+func (MySQLDialect) Statements(script string) []string {
+	var stmts []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" && !onlyComments(s) {
+			stmts = append(stmts, s)
+		}
+		cur.Reset()
+	}
+	inQuote := false
+	for _, line := range strings.SplitAfter(script, "\n") {
+		code := line
+		if !inQuote {
+			if i := strings.Index(line, "--"); i >= 0 && strings.Count(line[:i], "'")%2 == 0 {
+				code = line[:i]
+			}
+		}
+		cur.WriteString(line)
+		if strings.Count(code, "'")%2 == 1 {
+			inQuote = !inQuote
+		}
+		if !inQuote && strings.HasSuffix(strings.TrimSpace(code), ";") {
+			flush()
+		}
+	}
+	flush()
+	return stmts
+}
+
+// onlyComments は s が -- のコメント行だけからなるかを返す。
+func onlyComments(s string) bool {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "--") {
+			return false
+		}
+	}
+	return true
+}
+
+// LookupDialect は名前（"postgres"・"mysql"）から方言を返す。
+func LookupDialect(name string) (Dialect, bool) {
+	for _, d := range []Dialect{PostgresDialect{}, MySQLDialect{}} {
+		if d.Name() == name {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// orPostgres は d が nil なら PostgreSQL の方言を返す（方言を指定しなかったリポジトリ用）。
+func orPostgres(d Dialect) Dialect {
+	if d == nil {
+		return PostgresDialect{}
+	}
+	return d
+}
+
+// sqlConn：方言に合わせてクエリを実行する（*sql.DB・*sql.Tx のどちらでも）
+type sqlConn struct {
+	q Querier
+	d Dialect
+}
+
+func newSQLConn(q Querier, d Dialect) sqlConn { return sqlConn{q: q, d: orPostgres(d)} }
+
+func (c sqlConn) Exec(query string, args ...any) (sql.Result, error) {
+	query, args = c.d.Rebind(query, args)
+	return c.q.Exec(query, args...)
+}
+
+func (c sqlConn) Query(query string, args ...any) (*sql.Rows, error) {
+	query, args = c.d.Rebind(query, args)
+	return c.q.Query(query, args...)
+}
+
+func (c sqlConn) QueryRow(query string, args ...any) *sql.Row {
+	query, args = c.d.Rebind(query, args)
+	return c.q.QueryRow(query, args...)
+}
+
+// InsertID は INSERT を実行し、採番された id を返す（query に RETURNING は書かない）。
+func (c sqlConn) InsertID(query string, args ...any) (string, error) {
+	query, args = c.d.Rebind(query, args)
+	return c.d.InsertID(c.q, query, args)
+}
+
+// dbTime：DATE・TIMESTAMP 列を time.Time に読み込む Scanner（NULL はゼロ値）
+// ドライバによって time.Time・文字列・[]byte のどれで返ってくるかが違うので、どれでも読めるようにする。
+// 文字列はタイムゾーンを持たない DATETIME の値なので UTC として読む。
+type dbTime struct{ t *time.Time }
+
+// dbTimeLayouts：文字列で返ってきた時刻の書式
+var dbTimeLayouts = []string{"2006-01-02 15:04:05.999999999", "2006-01-02", time.RFC3339Nano}
+
+func (s dbTime) Scan(v any) error {
+	switch x := v.(type) {
+	case nil:
+		*s.t = time.Time{}
+		return nil
+	case time.Time:
+		*s.t = x
+		return nil
+	case []byte:
+		v = string(x)
+	}
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("cannot scan %T into time.Time", v)
+	}
+	if strings.HasPrefix(str, "0000-00-00") { // MySQL のゼロの日付
+		*s.t = time.Time{}
+		return nil
+	}
+	for _, layout := range dbTimeLayouts {
+		if t, err := time.ParseInLocation(layout, str, time.UTC); err == nil {
+			*s.t = t
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as time", str)
+}
@@ -0,0 +1,185 @@
+package drivers
+
+// 従業員情報の読み取りキャッシュ
+// --------------------------------------------------------
+// CachedEmployeeRepo は usecase.EmployeeRepo を包み、FindByID の結果をメモリに保持する。
+// 休暇申請のたびに従業員情報を読むが、従業員情報はめったに変わらないため。
+// - TTL を過ぎた情報は使わない（他のプロセスが変更した分は、最長で TTL だけ古い情報が見える）
+// - MaxEntries を超えたら最も長く使われていないもの（LRU）から捨てる
+// - 同じ従業員の読み込みが同時に起きたら、Next への問い合わせは1回にまとめる
+// - 従業員情報を変更したユースケースが InvalidateEmployee を呼んだら、その従業員の情報を捨てる
+// - 見つからない・失敗したという結果は保持しない（登録直後の従業員をすぐ読めるように）
+// ヒット・ミスなどの件数は Stats で取得でき、String で expvar として公開できる。
+// --------------------------------------------------------
+
+import (
+	"container/list"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// EmployeeCacheConfig：キャッシュの設定
+type EmployeeCacheConfig struct {
+	TTL        time.Duration    // 保持する時間（0 なら1分）
+	MaxEntries int              // 保持する従業員の数の上限（0 なら1000）
+	Now        func() time.Time // 現在時刻（nil なら time.Now。テストで時間を進めるのに使う）
+}
+
+// CacheStats：キャッシュの利用状況
+type CacheStats struct {
+	Hits          int64 `json:"hits"`          // キャッシュから返した回数
+	Misses        int64 `json:"misses"`        // Next に問い合わせた回数
+	Coalesced     int64 `json:"coalesced"`     // 他の呼び出しの問い合わせ結果を待って返した回数
+	Evictions     int64 `json:"evictions"`     // 上限を超えて捨てた件数
+	Invalidations int64 `json:"invalidations"` // InvalidateEmployee で捨てた件数
+	Entries       int   `json:"entries"`       // 今保持している件数
+}
+
+// CachedEmployeeRepo：FindByID の結果を保持する EmployeeRepo
+type CachedEmployeeRepo struct {
+	next usecase.EmployeeRepo
+	ttl  time.Duration
+	max  int
+	now  func() time.Time
+
+	mu      sync.Mutex
+	lru     *list.List               // 先頭ほど最近使った。要素は *cacheEntry
+	entries map[string]*list.Element // 従業員ID → lru の要素
+	loading map[string]*employeeLoad // 従業員ID → Next に問い合わせ中の呼び出し
+	stats   CacheStats
+}
+
+// cacheEntry：保持している従業員情報1件
+type cacheEntry struct {
+	id      string
+	e       domain.Employee
+	expires time.Time
+}
+
+// employeeLoad：Next への問い合わせ1回（同じ従業員を待つ呼び出しで共有する）
+type employeeLoad struct {
+	done  chan struct{}
+	e     domain.Employee
+	err   error
+	stale bool // 問い合わせ中に InvalidateEmployee が呼ばれた（結果を保持しない）
+}
+
+// NewCachedEmployeeRepo は next を包むキャッシュを作る。
+func NewCachedEmployeeRepo(next usecase.EmployeeRepo, cfg EmployeeCacheConfig) *CachedEmployeeRepo {
+	if cfg.TTL <= 0 {
+		cfg.TTL = time.Minute
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = 1000
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &CachedEmployeeRepo{
+		next: next, ttl: cfg.TTL, max: cfg.MaxEntries, now: cfg.Now,
+		lru: list.New(), entries: map[string]*list.Element{}, loading: map[string]*employeeLoad{},
+	}
+}
+
+// FindByID は従業員情報を返す。保持していなければ Next に問い合わせる。
+func (c *CachedEmployeeRepo) FindByID(id string) (domain.Employee, error) {
+	c.mu.Lock()
+	if el, ok := c.entries[id]; ok {
+		ent := el.Value.(*cacheEntry)
+		if c.now().Before(ent.expires) {
+			c.lru.MoveToFront(el)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return cloneEmployee(ent.e), nil
+		}
+		c.remove(el)
+	}
+	if ld, ok := c.loading[id]; ok {
+		c.stats.Coalesced++
+		c.mu.Unlock()
+		<-ld.done
+		return cloneEmployee(ld.e), ld.err
+	}
+	ld := &employeeLoad{done: make(chan struct{})}
+	c.loading[id] = ld
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	ld.e, ld.err = c.next.FindByID(id)
+
+	c.mu.Lock()
+	if c.loading[id] == ld {
+		delete(c.loading, id)
+	}
+	if ld.err == nil && !ld.stale {
+		c.put(id, ld.e)
+	}
+	c.mu.Unlock()
+	close(ld.done)
+	return cloneEmployee(ld.e), ld.err
+}
+
+// InvalidateEmployee は従業員の情報を捨てる（usecase.EmployeeCacheInvalidator の実装）。
+// 問い合わせ中の結果も保持しないので、この後の FindByID は必ず新しい情報を読む。
+func (c *CachedEmployeeRepo) InvalidateEmployee(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[id]; ok {
+		c.remove(el)
+		c.stats.Invalidations++
+	}
+	if ld, ok := c.loading[id]; ok {
+		ld.stale = true
+		delete(c.loading, id) // 次の呼び出しは古い問い合わせを待たずに読み直す
+	}
+}
+
+// Stats はキャッシュの利用状況を返す。
+func (c *CachedEmployeeRepo) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := c.stats
+	s.Entries = c.lru.Len()
+	return s
+}
+
+// String は利用状況を JSON で返す（expvar.Var の実装）。
+func (c *CachedEmployeeRepo) String() string {
+	b, err := json.Marshal(c.Stats())
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
+
+// put は従業員情報を保持し、上限を超えたら最も長く使われていないものを捨てる（ロックを取って呼ぶ）。
+func (c *CachedEmployeeRepo) put(id string, e domain.Employee) {
+	ent := &cacheEntry{id: id, e: e, expires: c.now().Add(c.ttl)}
+	if el, ok := c.entries[id]; ok {
+		el.Value = ent
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[id] = c.lru.PushFront(ent)
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+}
+
+// remove は1件を捨てる（ロックを取って呼ぶ）。
+func (c *CachedEmployeeRepo) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*cacheEntry).id)
+}
+
+// cloneEmployee は呼び出し側が変更しても保持している情報に影響しないようコピーを返す。
+func cloneEmployee(e domain.Employee) domain.Employee {
+	e.Notify.Channels = slices.Clone(e.Notify.Channels)
+	return e
+}
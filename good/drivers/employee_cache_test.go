@@ -0,0 +1,308 @@
+package drivers_test
+
+// CachedEmployeeRepo のテスト
+// --------------------------------------------------------
+// 問い合わせた回数を数える従業員リポジトリを包み、EmployeeCacheConfig.Now で時間を進めて確かめる。
+// - TTL を過ぎたら読み直す
+// - MaxEntries を超えたら最も長く使われていないものを捨てる
+// - 同時の読み込みは1回の問い合わせにまとめる
+// - 問い合わせ中に InvalidateEmployee されたら、その結果は保持しない
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// countingEmployees：問い合わせた回数を数える従業員リポジトリ
+type countingEmployees struct {
+	mu      sync.Mutex
+	emps    map[string]domain.Employee
+	calls   map[string]int
+	entered chan string   // 問い合わせが始まるたびに従業員IDを送る（nil なら送らない）
+	release chan struct{} // 閉じるまで問い合わせを止める（nil なら止めない）
+}
+
+func newCountingEmployees(emps ...domain.Employee) *countingEmployees {
+	f := &countingEmployees{emps: map[string]domain.Employee{}, calls: map[string]int{}}
+	for _, e := range emps {
+		f.emps[e.ID] = e
+	}
+	return f
+}
+
+// FindByID は呼ばれた時点の従業員情報を返す（止められていれば release を待ってから）。
+func (f *countingEmployees) FindByID(id string) (domain.Employee, error) {
+	f.mu.Lock()
+	f.calls[id]++
+	e, ok := f.emps[id]
+	entered, release := f.entered, f.release
+	f.mu.Unlock()
+	if entered != nil {
+		entered <- id
+	}
+	if release != nil {
+		<-release
+	}
+	if !ok {
+		return domain.Employee{}, usecase.NewError(usecase.ErrEmployeeNotFound, "countingEmployees.FindByID", nil)
+	}
+	return e, nil
+}
+
+func (f *countingEmployees) set(e domain.Employee) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.emps[e.ID] = e
+}
+
+// block は以降の問い合わせを止め、始まったことを entered で知らせるようにする。
+func (f *countingEmployees) block() (entered chan string, release chan struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entered, f.release = make(chan string, 16), make(chan struct{})
+	return f.entered, f.release
+}
+
+// unblock は以降の問い合わせを止めないようにする（止めている問い合わせはそのまま）。
+func (f *countingEmployees) unblock() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entered, f.release = nil, nil
+}
+
+func (f *countingEmployees) count(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[id]
+}
+
+// testClock：EmployeeCacheConfig.Now に渡す、手で進める時計
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func employeeNamed(id, name string) domain.Employee {
+	return domain.Employee{ID: id, Name: name, HireDate: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func mustFind(t *testing.T, c *drivers.CachedEmployeeRepo, id string) domain.Employee {
+	t.Helper()
+	e, err := c.FindByID(id)
+	if err != nil {
+		t.Fatalf("FindByID(%s): %v", id, err)
+	}
+	return e
+}
+
+// wantStats は利用状況のうち件数の項目を比べる。
+func wantStats(t *testing.T, c *drivers.CachedEmployeeRepo, want drivers.CacheStats) {
+	t.Helper()
+	if got := c.Stats(); got != want {
+		t.Errorf("Stats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestEmployeeCacheTTL(t *testing.T) {
+	next := newCountingEmployees(employeeNamed("e1", "v1"))
+	clock := &testClock{now: time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)}
+	c := drivers.NewCachedEmployeeRepo(next, drivers.EmployeeCacheConfig{TTL: time.Minute, Now: clock.Now})
+
+	mustFind(t, c, "e1")
+	next.set(employeeNamed("e1", "v2"))
+	clock.advance(time.Minute - time.Nanosecond)
+	if e := mustFind(t, c, "e1"); e.Name != "v1" {
+		t.Errorf("within TTL: Name = %q, want the cached v1", e.Name)
+	}
+	wantStats(t, c, drivers.CacheStats{Hits: 1, Misses: 1, Entries: 1})
+
+	// ちょうど TTL が過ぎたら読み直し、新しい期限で保持する
+	clock.advance(time.Nanosecond)
+	if e := mustFind(t, c, "e1"); e.Name != "v2" {
+		t.Errorf("after TTL: Name = %q, want v2", e.Name)
+	}
+	clock.advance(time.Minute - time.Nanosecond)
+	mustFind(t, c, "e1")
+	wantStats(t, c, drivers.CacheStats{Hits: 2, Misses: 2, Entries: 1})
+	if n := next.count("e1"); n != 2 {
+		t.Errorf("next called %d times, want 2", n)
+	}
+}
+
+func TestEmployeeCacheLRU(t *testing.T) {
+	next := newCountingEmployees(employeeNamed("a", "A"), employeeNamed("b", "B"), employeeNamed("c", "C"))
+	clock := &testClock{now: time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)}
+	c := drivers.NewCachedEmployeeRepo(next, drivers.EmployeeCacheConfig{MaxEntries: 2, Now: clock.Now})
+
+	mustFind(t, c, "a")
+	mustFind(t, c, "b")
+	mustFind(t, c, "a") // a を使ったので、最も長く使われていないのは b
+	mustFind(t, c, "c") // b を捨てる
+	wantStats(t, c, drivers.CacheStats{Hits: 1, Misses: 3, Evictions: 1, Entries: 2})
+
+	mustFind(t, c, "a")
+	mustFind(t, c, "c")
+	if next.count("a") != 1 || next.count("c") != 1 {
+		t.Errorf("a, c read %d, %d times, want 1 each", next.count("a"), next.count("c"))
+	}
+	mustFind(t, c, "b") // 捨てたので読み直し、今度は a を捨てる
+	if n := next.count("b"); n != 2 {
+		t.Errorf("b read %d times, want 2", n)
+	}
+	mustFind(t, c, "a")
+	wantStats(t, c, drivers.CacheStats{Hits: 3, Misses: 5, Evictions: 3, Entries: 2})
+}
+
+func TestEmployeeCacheCoalescesConcurrentMisses(t *testing.T) {
+	const n = 8
+	next := newCountingEmployees(employeeNamed("e1", "v1"))
+	c := drivers.NewCachedEmployeeRepo(next, drivers.EmployeeCacheConfig{})
+	entered, release := next.block()
+
+	var wg sync.WaitGroup
+	results := make([]domain.Employee, n)
+	errs := make([]error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.FindByID("e1")
+		}(i)
+	}
+	<-entered
+	// 残りの呼び出しがすべて最初の問い合わせを待つまで、問い合わせを終わらせない
+	waitFor(t, func() bool { return c.Stats().Coalesced == n-1 })
+	close(release)
+	wg.Wait()
+
+	for i := range results {
+		if errs[i] != nil || results[i].Name != "v1" {
+			t.Errorf("caller %d got %+v, %v", i, results[i], errs[i])
+		}
+	}
+	if got := next.count("e1"); got != 1 {
+		t.Errorf("next called %d times, want 1", got)
+	}
+	wantStats(t, c, drivers.CacheStats{Misses: 1, Coalesced: n - 1, Entries: 1})
+	select {
+	case id := <-entered:
+		t.Errorf("unexpected second load of %s", id)
+	default:
+	}
+}
+
+func TestEmployeeCacheInvalidateDuringLoad(t *testing.T) {
+	next := newCountingEmployees(employeeNamed("e1", "v1"))
+	c := drivers.NewCachedEmployeeRepo(next, drivers.EmployeeCacheConfig{})
+	entered, release := next.block()
+
+	// 1. 最初の呼び出しが v1 を読んでいる途中で、従業員情報が v2 に変わり InvalidateEmployee される
+	first := make(chan domain.Employee)
+	go func() {
+		e, _ := c.FindByID("e1")
+		first <- e
+	}()
+	<-entered
+	next.unblock()
+	next.set(employeeNamed("e1", "v2"))
+	c.InvalidateEmployee("e1")
+
+	// 2. この後の呼び出しは古い問い合わせを待たずに読み直す
+	second := make(chan domain.Employee, 1)
+	go func() {
+		e, _ := c.FindByID("e1")
+		second <- e
+	}()
+	select {
+	case e := <-second:
+		if e.Name != "v2" {
+			t.Errorf("after invalidation: Name = %q, want v2", e.Name)
+		}
+	case <-time.After(time.Second):
+		close(release)
+		t.Fatal("FindByID after InvalidateEmployee waited for the stale load")
+	}
+
+	// 3. 古い問い合わせが終わっても、その結果（v1）で上書きしない
+	close(release)
+	if e := <-first; e.Name != "v1" {
+		t.Errorf("first caller: Name = %q, want v1 (read before the change)", e.Name)
+	}
+	if e := mustFind(t, c, "e1"); e.Name != "v2" {
+		t.Errorf("cached Name = %q, want v2", e.Name)
+	}
+	if got := next.count("e1"); got != 2 {
+		t.Errorf("next called %d times, want 2", got)
+	}
+	// 保持していなかったので Invalidations は数えない
+	wantStats(t, c, drivers.CacheStats{Hits: 1, Misses: 2, Entries: 1})
+
+	// 保持している情報の無効化
+	c.InvalidateEmployee("e1")
+	c.InvalidateEmployee("unknown")
+	wantStats(t, c, drivers.CacheStats{Hits: 1, Misses: 2, Invalidations: 1})
+}
+
+func TestEmployeeCacheDoesNotKeepErrors(t *testing.T) {
+	next := newCountingEmployees()
+	c := drivers.NewCachedEmployeeRepo(next, drivers.EmployeeCacheConfig{})
+
+	if _, err := c.FindByID("e1"); !errors.Is(err, usecase.ErrEmployeeNotFound) {
+		t.Fatalf("err = %v, want ErrEmployeeNotFound", err)
+	}
+	// 登録直後の従業員はすぐに読める
+	next.set(employeeNamed("e1", "v1"))
+	if e := mustFind(t, c, "e1"); e.Name != "v1" {
+		t.Errorf("Name = %q, want v1", e.Name)
+	}
+	wantStats(t, c, drivers.CacheStats{Misses: 2, Entries: 1})
+}
+
+func TestEmployeeCacheReturnsCopies(t *testing.T) {
+	e := employeeNamed("e1", "v1")
+	e.Notify.Channels = []domain.Channel{domain.ChannelEmail}
+	c := drivers.NewCachedEmployeeRepo(newCountingEmployees(e), drivers.EmployeeCacheConfig{})
+
+	got := mustFind(t, c, "e1")
+	got.Notify.Channels[0] = domain.ChannelChat
+	if again := mustFind(t, c, "e1"); again.Notify.Channels[0] != domain.ChannelEmail {
+		t.Errorf("cached channels changed to %v", again.Notify.Channels)
+	}
+
+	var stats drivers.CacheStats
+	if err := json.Unmarshal([]byte(c.String()), &stats); err != nil || stats != c.Stats() {
+		t.Errorf("String() = %s (%v), want the JSON of %+v", c.String(), err, c.Stats())
+	}
+}
+
+// waitFor は cond が成り立つまで待つ（1秒で諦める）。
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met within %s", time.Second)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
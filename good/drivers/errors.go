@@ -0,0 +1,108 @@
+package drivers
+
+// Drivers層のエラー翻訳
+// --------------------------------------------------------
+// DBドライバなど技術固有のエラーを、UseCase層が定義したエラー種類へ翻訳する。
+// - 内側（UseCase）や外側（Adapter）が sql.ErrNoRows などを知らずに済むようにする
+// - 元のエラーは Unwrap で辿れるように残し、ログ調査に使えるようにする
+// - IDで1件を探す処理は、何が見つからなかったか（usecase.ErrEmployeeNotFound など）まで翻訳する
+// --------------------------------------------------------
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// sqlStateError は SQLSTATE を返せるドライバエラー（pgx, lib/pq など）の共通部分
+type sqlStateError interface{ SQLState() string }
+
+// mysqlErrorStates：MySQL のエラー番号と、同じ意味の PostgreSQL の SQLSTATE
+// go-sql-driver/mysql のエラーは SQLState() を持たず、番号も "Error 1062 (23000): ..." の文字列でしか分からない。
+var mysqlErrorStates = map[int]string{
+	1062: "23505", // ER_DUP_ENTRY
+	1451: "23503", // ER_ROW_IS_REFERENCED_2
+	1452: "23503", // ER_NO_REFERENCED_ROW_2
+	1048: "23502", // ER_BAD_NULL_ERROR
+	3819: "23514", // ER_CHECK_CONSTRAINT_VIOLATED
+	1213: "40P01", // ER_LOCK_DEADLOCK
+	1205: "40001", // ER_LOCK_WAIT_TIMEOUT
+	1040: "53300", // ER_CON_COUNT_ERROR
+	2006: "08006", // CR_SERVER_GONE_ERROR
+	2013: "08006", // CR_SERVER_LOST
+}
+
+// translateSQLError は database/sql のエラーを usecase のエラー種類へ翻訳する。
+func translateSQLError(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return usecase.NewError(sqlErrorKind(err), op, err)
+}
+
+// translateLookupError は IDで1件を探す・更新する処理のエラーを翻訳する。
+// 見つからない場合は notFound（usecase.ErrEmployeeNotFound など）にする。
+// 数値の列に数値でないIDを渡した場合（22P02）も、そのIDのデータは存在しないので同じ扱いにする。
+func translateLookupError(op string, err error, notFound error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) || sqlState(err) == "22P02" { // invalid_text_representation
+		return usecase.NewError(notFound, op, err)
+	}
+	return translateSQLError(op, err)
+}
+
+// sqlState は SQLSTATE を返す（ドライバが対応していなければ空）。
+// MySQL のエラーは mysqlErrorStates で同じ意味の SQLSTATE にする。
+func sqlState(err error) string {
+	var st sqlStateError
+	if errors.As(err, &st) {
+		return st.SQLState()
+	}
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		rest, ok := strings.CutPrefix(e.Error(), "Error ")
+		if !ok {
+			continue
+		}
+		num, _, _ := strings.Cut(rest, ":")
+		num, _, _ = strings.Cut(num, " ")
+		if n, err := strconv.Atoi(num); err == nil {
+			return mysqlErrorStates[n]
+		}
+	}
+	return ""
+}
+
+func sqlErrorKind(err error) error {
+	var ne net.Error
+	code := sqlState(err)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return usecase.ErrNotFound
+	case code == "23505": // unique_violation
+		return usecase.ErrConflict
+	case code == "23503", // foreign_key_violation
+		code == "23502", // not_null_violation
+		code == "23514": // check_violation
+		return usecase.ErrValidation
+	case code == "40001", // serialization_failure
+		code == "40P01",               // deadlock_detected
+		code == "53300",               // too_many_connections
+		code == "57P01",               // admin_shutdown
+		code == "57P03",               // cannot_connect_now
+		strings.HasPrefix(code, "08"), // connection_exception
+		errors.Is(err, driver.ErrBadConn),
+		errors.Is(err, sql.ErrConnDone),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.As(err, &ne):
+		return usecase.ErrUnavailable
+	}
+	return usecase.ErrInternal
+}
@@ -0,0 +1,114 @@
+package drivers
+
+// Framework & Drivers層（休暇申請の変更のイベントバス）
+// --------------------------------------------------------
+// usecase.EventPublisher の実装。発行されたイベントを
+//  1. イベントログ（EventLog）に記録する（外部の連携先は offset を指定してログを読む）
+//  2. プロセス内の購読者（Subscribe したハンドラ）に、発行した goroutine のまま順に渡す
+// --------------------------------------------------------
+// - ログへの記録に失敗したら購読者には渡さず、エラーを返す（ログにない変更を知らせない）
+//   ユースケースはこのエラーを利用者に返さない（保存は済んでいる）ので、記録できなかったイベントは
+//   中身ごと Error レベルでログに残す。その変更は次の起動時に usecase.RecoverEvents が保存されている申請から補う
+// - 購読者のエラー・パニックはログに残すだけで、発行元（ユースケース）には返さない
+//   （保存も記録も済んでいるので、購読者が取りこぼしたイベントはログから読み直せる）
+// - 購読者は同期的に呼ばれるので、時間のかかる処理は購読者の側で別の goroutine に回す
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// EventHandler：プロセス内の購読者
+type EventHandler func(usecase.Event) error
+
+// EventBus：イベントログへの記録と、プロセス内の購読者への配信
+type EventBus struct {
+	Log    *EventLog    // nil ならプロセス内の購読者に渡すだけ
+	Logger *slog.Logger // nil なら slog.Default()
+
+	mu     sync.RWMutex
+	subs   map[int]subscription
+	nextID int
+}
+
+type subscription struct {
+	name    string
+	handler EventHandler
+}
+
+// NewEventBus は log に記録する EventBus を作る。
+func NewEventBus(log *EventLog, logger *slog.Logger) *EventBus {
+	return &EventBus{Log: log, Logger: logger}
+}
+
+// Subscribe は購読者を登録し、登録を解除する関数を返す（name はログに出すための名前）。
+func (b *EventBus) Subscribe(name string, h EventHandler) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs == nil {
+		b.subs = map[int]subscription{}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = subscription{name: name, handler: h}
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subs, id)
+	}
+}
+
+// Publish はイベントをログに記録し、購読者に渡す（usecase.EventPublisher）。
+func (b *EventBus) Publish(events ...usecase.Event) error {
+	if b.Log != nil {
+		if _, err := b.Log.Append(events...); err != nil {
+			for _, e := range events {
+				b.logger().Error("event not recorded",
+					slog.String("type", string(e.Type)), slog.Time("occurredAt", e.OccurredAt),
+					slog.String("actorId", e.ActorID), slog.String("requestId", e.Request.ID),
+					slog.String("status", string(e.Request.Status)), slog.Any("err", err))
+			}
+			return err
+		}
+	}
+	b.mu.RLock()
+	subs := make([]subscription, 0, len(b.subs))
+	for id := 0; id < b.nextID; id++ { // 登録した順に渡す
+		if s, ok := b.subs[id]; ok {
+			subs = append(subs, s)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, e := range events {
+		for _, s := range subs {
+			if err := b.deliver(s, e); err != nil {
+				b.logger().Warn("event handler failed",
+					slog.String("subscriber", s.name), slog.String("type", string(e.Type)),
+					slog.String("requestId", e.Request.ID), slog.Any("err", err))
+			}
+		}
+	}
+	return nil
+}
+
+// deliver は購読者にイベントを渡す。パニックはエラーにする。
+func (b *EventBus) deliver(s subscription, e usecase.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return s.handler(e)
+}
+
+func (b *EventBus) logger() *slog.Logger {
+	if b.Logger != nil {
+		return b.Logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,310 @@
+package drivers
+
+// Framework & Drivers層（休暇申請の変更のイベントログ）
+// --------------------------------------------------------
+// 発行したイベントを、追記専用の JSON Lines ファイル（events.jsonl）に1行ずつ記録する。
+// 外部の連携先は offset を指定して読み、処理し終えた offset を覚えておく（記録したイベントは at-least-once で届く）。
+// 記録するのは申請を保存した後なので、保存と記録の間でプロセスが落ちる・記録に失敗すると、そのイベントは
+// その場では記録されない。次の起動時に usecase.RecoverEvents が保存されている申請と比べ、leave.recovered として補う。
+// - offset は記録した順に 1 から振る番号（＝行番号）。一度振った番号は変わらない
+// - 追記は fsync してから完了とする。書きかけの最終行は開くときに切り捨てる（ジャーナルと同じ）
+// - 読む側は Read で任意の offset から読み直せる（処理に失敗したら同じ offset から読めばよい）
+// - プロセス内の読み手は Consume を使うと、名前ごとに処理し終えた offset を consumers/<名前>.offset に保存する
+// --------------------------------------------------------
+// ログは消さない（古いイベントを捨てる仕組みはまだない）。
+// --------------------------------------------------------
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+const (
+	eventLogFile      = "events.jsonl"
+	eventConsumersDir = "consumers"
+)
+
+// eventRecord：イベントログの1行
+type eventRecord struct {
+	Offset     int64               `json:"offset"`
+	Type       usecase.EventType   `json:"type"`
+	OccurredAt time.Time           `json:"occurredAt"`
+	ActorID    string              `json:"actorId,omitempty"`
+	Request    domain.LeaveRequest `json:"request"`
+}
+
+// consumerName：Consume に渡す名前（ファイル名にするので使える文字を絞る）
+var consumerName = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+
+// EventLog：追記専用のイベントログ
+type EventLog struct {
+	Dir string
+
+	mu     sync.Mutex // 追記を直列化する
+	f      *os.File
+	starts []int64 // offset-1 番目の行の先頭位置
+	size   int64   // ログの正常な末尾
+
+	consumeMu sync.Mutex // Consume を直列化する（同じ名前で同時に読んで二重に処理しないように）
+}
+
+// OpenEventLog は dir のイベントログを開く。ディレクトリがなければ作る。
+func OpenEventLog(dir string) (*EventLog, error) {
+	const op = "OpenEventLog"
+	if err := os.MkdirAll(filepath.Join(dir, eventConsumersDir), 0o750); err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, eventLogFile), os.O_RDWR|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	l := &EventLog{Dir: dir, f: f}
+	if err := l.index(); err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	// 書きかけの最終行を切り捨て、追記位置を正常な末尾に合わせる
+	if err := f.Truncate(l.size); err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if _, err := f.Seek(l.size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	return l, nil
+}
+
+// index はログを先頭から読み、各行の先頭位置と正常な末尾を求める。
+func (l *EventLog) index() error {
+	br := bufio.NewReader(l.f)
+	for line := int64(1); ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return nil // 改行で終わっていない最終行は書きかけなので捨てる
+		}
+		if err != nil {
+			return err
+		}
+		var rec eventRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return fmt.Errorf("%s line %d is corrupt: %w", eventLogFile, line, err)
+		}
+		if rec.Offset != line {
+			return fmt.Errorf("%s line %d has offset %d", eventLogFile, line, rec.Offset)
+		}
+		l.starts = append(l.starts, l.size)
+		l.size += int64(len(b))
+	}
+}
+
+// Publish はイベントを記録する（usecase.EventPublisher）。
+func (l *EventLog) Publish(events ...usecase.Event) error {
+	_, err := l.Append(events...)
+	return err
+}
+
+// Append はイベントを記録し、最後に振った offset を返す。
+// まとめて1回の書き込みと fsync で記録する。失敗したら1件も記録しない。
+func (l *EventLog) Append(events ...usecase.Event) (int64, error) {
+	const op = "EventLog.Append"
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return 0, usecase.NewError(usecase.ErrUnavailable, op, errors.New("event log is closed"))
+	}
+	next := int64(len(l.starts)) + 1
+	var buf []byte
+	starts := make([]int64, 0, len(events))
+	for i, e := range events {
+		b, err := json.Marshal(eventRecord{
+			Offset: next + int64(i), Type: e.Type, OccurredAt: e.OccurredAt, ActorID: e.ActorID, Request: e.Request,
+		})
+		if err != nil {
+			return 0, usecase.NewError(usecase.ErrInternal, op, err)
+		}
+		starts = append(starts, l.size+int64(len(buf)))
+		buf = append(append(buf, b...), '\n')
+	}
+	if _, err := l.f.Write(buf); err != nil {
+		l.rollback()
+		return 0, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if err := l.f.Sync(); err != nil {
+		l.rollback()
+		return 0, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	l.starts = append(l.starts, starts...)
+	l.size += int64(len(buf))
+	return int64(len(l.starts)), nil
+}
+
+// rollback は書き込みに失敗した分を切り捨てる（切り捨てにも失敗したら以降の追記を拒否する）。
+func (l *EventLog) rollback() {
+	if l.f.Truncate(l.size) == nil {
+		if _, err := l.f.Seek(l.size, io.SeekStart); err == nil {
+			return
+		}
+	}
+	l.f.Close()
+	l.f = nil
+}
+
+// LastOffset は最後に記録したイベントの offset を返す（まだなければ 0）。
+func (l *EventLog) LastOffset() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int64(len(l.starts))
+}
+
+// Read は offset が from 以上のイベントを古い順に最大 limit 件返す（from が 1 未満なら先頭から）。
+func (l *EventLog) Read(from int64, limit int) ([]usecase.LoggedEvent, error) {
+	const op = "EventLog.Read"
+	from = max(from, 1)
+	l.mu.Lock()
+	last, size := int64(len(l.starts)), l.size
+	var start int64
+	if from <= last {
+		start = l.starts[from-1]
+	}
+	l.mu.Unlock()
+	if from > last || limit <= 0 {
+		return nil, nil
+	}
+
+	// 追記用とは別に開いて読む（読んでいる間も追記を止めない）
+	f, err := os.Open(filepath.Join(l.Dir, eventLogFile))
+	if err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	defer f.Close()
+	br := bufio.NewReader(io.NewSectionReader(f, start, size-start))
+	n := min(int64(limit), last-from+1)
+	events := make([]usecase.LoggedEvent, 0, n)
+	for int64(len(events)) < n {
+		b, err := br.ReadBytes('\n')
+		if err != nil {
+			return nil, usecase.NewError(usecase.ErrInternal, op, err)
+		}
+		var rec eventRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return nil, usecase.NewError(usecase.ErrInternal, op, err)
+		}
+		events = append(events, usecase.LoggedEvent{Offset: rec.Offset, Event: usecase.Event{
+			Type: rec.Type, OccurredAt: rec.OccurredAt, ActorID: rec.ActorID, Request: rec.Request,
+		}})
+	}
+	return events, nil
+}
+
+// Committed は consumer が処理し終えた offset を返す（まだなければ 0）。
+func (l *EventLog) Committed(consumer string) (int64, error) {
+	const op = "EventLog.Committed"
+	path, err := l.offsetPath(consumer)
+	if err != nil {
+		return 0, usecase.NewError(usecase.ErrValidation, op, err)
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	return n, nil
+}
+
+// Commit は consumer が offset まで処理し終えたことを保存する（一時ファイルに書いてから置き換える）。
+func (l *EventLog) Commit(consumer string, offset int64) error {
+	const op = "EventLog.Commit"
+	path, err := l.offsetPath(consumer)
+	if err != nil {
+		return usecase.NewError(usecase.ErrValidation, op, err)
+	}
+	tmp := path + ".tmp"
+	if err := writeFileSync(tmp, []byte(strconv.FormatInt(offset, 10)+"\n")); err != nil {
+		return usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	return nil
+}
+
+// Consume は consumer が処理し終えた次のイベントから最大 limit 件を handle に渡し、処理した件数を返す。
+// 1件処理するたびに Commit するので、handle が失敗したイベントは次の Consume でもう一度渡される。
+// Commit の前に落ちた場合も同じイベントがもう一度渡されるので、handle は同じイベントを2回受けても困らないようにする。
+func (l *EventLog) Consume(consumer string, limit int, handle func(usecase.LoggedEvent) error) (int, error) {
+	l.consumeMu.Lock()
+	defer l.consumeMu.Unlock()
+	done, err := l.Committed(consumer)
+	if err != nil {
+		return 0, err
+	}
+	events, err := l.Read(done+1, limit)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range events {
+		if err := handle(e); err != nil {
+			return i, err
+		}
+		if err := l.Commit(consumer, e.Offset); err != nil {
+			return i, err
+		}
+	}
+	return len(events), nil
+}
+
+// Close はログを閉じる。閉じた後の追記はエラーになる。
+func (l *EventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+func (l *EventLog) offsetPath(consumer string) (string, error) {
+	if !consumerName.MatchString(consumer) {
+		return "", fmt.Errorf("invalid consumer name %q", consumer)
+	}
+	return filepath.Join(l.Dir, eventConsumersDir, consumer+".offset"), nil
+}
+
+// writeFileSync はファイルを書いて fsync する。
+func writeFileSync(path string, b []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,39 @@
+package drivers
+
+// Framework & Drivers層（カレンダーの購読トークン）
+// --------------------------------------------------------
+// HMACFeedTokens は UseCase層の FeedTokens を、秘密鍵による HMAC-SHA256 で実装する。
+// - トークンはフィードのキーから計算するので保存しなくてよい（同じキーには常に同じトークン）
+// - 秘密鍵を変えると、発行済みの購読 URL はすべて使えなくなる（漏れたときの失効手段）
+// --------------------------------------------------------
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// HMACFeedTokens：秘密鍵から購読トークンを計算する
+type HMACFeedTokens struct {
+	Secret []byte
+}
+
+// Token はフィード feed のトークンを返す。
+func (t HMACFeedTokens) Token(feed string) string {
+	return base64.RawURLEncoding.EncodeToString(t.sum(feed))
+}
+
+// Verify は token がフィード feed のトークンかを返す（比較にかかる時間は内容によらない）。
+func (t HMACFeedTokens) Verify(feed, token string) bool {
+	got, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(t.Secret) == 0 {
+		return false
+	}
+	return hmac.Equal(got, t.sum(feed))
+}
+
+func (t HMACFeedTokens) sum(feed string) []byte {
+	mac := hmac.New(sha256.New, t.Secret)
+	mac.Write([]byte(feed))
+	return mac.Sum(nil)
+}
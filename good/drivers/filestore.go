@@ -0,0 +1,453 @@
+package drivers
+
+// Framework & Drivers層（ローカルディレクトリへの保存）
+// --------------------------------------------------------
+// DBサーバなしで従業員・休暇申請・添付ファイルの情報を永続化するリポジトリ。
+// 少人数のチームやオフラインでのデモ向け。
+// - 変更は追記専用の JSON Lines ジャーナル（journal.jsonl）に1行ずつ書き、fsync してから完了とする
+// - 起動時にジャーナルを先頭から再生して状態を復元する
+//   （書きかけの最終行はクラッシュの跡とみなして切り捨てる。途中の壊れた行はエラーにする）
+// - 追記が CompactEvery 行を超えたら、現在の状態だけを書いた新しいジャーナルに置き換える（コンパクション）
+// --------------------------------------------------------
+// 検索・検証・採番は MemoryStore に任せ、このファイルは「ジャーナルへの記録と復元」だけを担う。
+// そのためエラーの種類や並び順は MemoryStore（＝SQL 版）と同じになる。
+// --------------------------------------------------------
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// DefaultCompactEvery：コンパクションまでに追記する行数の既定値
+const DefaultCompactEvery = 1000
+
+const (
+	journalFile    = "journal.jsonl"
+	journalTmpFile = "journal.jsonl.tmp"
+)
+
+// journalRecord：ジャーナルの1行。Type に応じていずれか1つ（batch なら Batch）が入る。
+// 1行が1つのコミット単位で、batch は複数件をまとめて原子的に記録するのに使う。
+type journalRecord struct {
+	Type       string               `json:"type"` // employee / leave / attachment / batch
+	Employee   *domain.Employee     `json:"employee,omitempty"`
+	Leave      *domain.LeaveRequest `json:"leave,omitempty"`
+	Attachment *domain.Attachment   `json:"attachment,omitempty"`
+	Batch      []journalRecord      `json:"batch,omitempty"`
+}
+
+// errFileStoreClosed：閉じた後の書き込みを拒否するためのエラー
+var errFileStoreClosed = errors.New("file store is closed")
+
+// FileStore：ジャーナルで永続化するリポジトリが共有するデータ
+type FileStore struct {
+	Dir          string
+	CompactEvery int // 0 以下なら DefaultCompactEvery
+
+	mem      *MemoryStore
+	mu       sync.Mutex // 書き込み（メモリへの反映とジャーナルへの追記）を直列化する
+	f        *os.File
+	size     int64 // ジャーナルの正常な末尾
+	appended int   // 前回のコンパクション以降に追記した行数
+	broken   error // ジャーナルの状態が分からなくなったときのエラー（以降の書き込みを拒否する）
+}
+
+// OpenFileStore は dir のジャーナルを再生して FileStore を開く。ディレクトリがなければ作る。
+func OpenFileStore(dir string) (*FileStore, error) {
+	const op = "OpenFileStore"
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	// コンパクションの途中で落ちた場合の残骸（置き換え前なので元のジャーナルが正しい）
+	_ = os.Remove(filepath.Join(dir, journalTmpFile))
+
+	s := &FileStore{Dir: dir, mem: NewMemoryStore()}
+	f, err := os.OpenFile(filepath.Join(dir, journalFile), os.O_RDWR|os.O_CREATE, 0o640)
+	if err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	size, err := s.replay(f)
+	if err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	// 書きかけの最終行を切り捨て、追記位置を正常な末尾に合わせる
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	s.f, s.size = f, size
+	return s, nil
+}
+
+// replay はジャーナルを先頭から再生し、正常に読めた末尾の位置を返す。
+func (s *FileStore) replay(r io.Reader) (int64, error) {
+	br := bufio.NewReader(r)
+	var size int64
+	for line := 1; ; line++ {
+		b, err := br.ReadBytes('\n')
+		if err == io.EOF {
+			return size, nil // 改行で終わっていない最終行は書きかけなので捨てる
+		}
+		if err != nil {
+			return 0, err
+		}
+		var rec journalRecord
+		if err := json.Unmarshal(b, &rec); err != nil {
+			return 0, fmt.Errorf("%s line %d is corrupt: %w", journalFile, line, err)
+		}
+		if err := s.apply(rec); err != nil {
+			return 0, fmt.Errorf("%s line %d: %w", journalFile, line, err)
+		}
+		size += int64(len(b))
+	}
+}
+
+// apply は1行分の記録をメモリ上の状態に反映する。
+func (s *FileStore) apply(rec journalRecord) error {
+	switch {
+	case rec.Type == "employee" && rec.Employee != nil:
+		s.mem.putEmployee(*rec.Employee)
+	case rec.Type == "leave" && rec.Leave != nil:
+		s.mem.putLeave(*rec.Leave)
+	case rec.Type == "attachment" && rec.Attachment != nil:
+		s.mem.putAttachment(*rec.Attachment)
+	case rec.Type == "batch":
+		for _, r := range rec.Batch {
+			if err := s.apply(r); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unknown record type %q", rec.Type)
+	}
+	return nil
+}
+
+// Close はジャーナルを閉じる。以降の書き込みは ErrUnavailable になる。
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broken == errFileStoreClosed {
+		return nil
+	}
+	s.broken = errFileStoreClosed
+	return s.f.Close()
+}
+
+// Employees は従業員リポジトリを返す。
+func (s *FileStore) Employees() FileEmployeeRepo { return FileEmployeeRepo{s} }
+
+// Leaves は休暇申請リポジトリを返す。
+func (s *FileStore) Leaves() FileLeaveRepo { return FileLeaveRepo{s} }
+
+// Attachments は添付ファイル情報のリポジトリを返す。
+func (s *FileStore) Attachments() FileAttachmentRepo { return FileAttachmentRepo{s} }
+
+// commit：書き込みの共通手順
+// --------------------------------------------------------
+// 1. メモリ上で変更する（検証・採番もここで行われる）
+// 2. 変更後の内容をジャーナルに1行追記して fsync する
+// 3. 追記に失敗したらメモリ上の変更を undo で巻き戻す
+// 4. 追記が溜まっていればコンパクションする（失敗しても次の書き込みで再試行する）
+// --------------------------------------------------------
+func (s *FileStore) commit(op string, change func() (journalRecord, func(), error)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broken != nil {
+		return usecase.NewError(usecase.ErrUnavailable, op, s.broken)
+	}
+	rec, undo, err := change()
+	if err != nil {
+		return err
+	}
+	if err := s.append(rec); err != nil {
+		undo()
+		return usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if s.appended >= s.compactEvery() {
+		_ = s.compact()
+	}
+	return nil
+}
+
+// append は1行を追記して fsync する。失敗したら書きかけの分を切り詰める。
+func (s *FileStore) append(rec journalRecord) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	b = append(b, '\n')
+	if _, err := s.f.Write(b); err != nil {
+		s.rewind()
+		return err
+	}
+	if err := s.f.Sync(); err != nil {
+		s.rewind()
+		return err
+	}
+	s.size += int64(len(b))
+	s.appended++
+	return nil
+}
+
+// rewind はジャーナルを正常な末尾まで切り詰める。できなければ以降の書き込みを拒否する。
+func (s *FileStore) rewind() {
+	if err := s.f.Truncate(s.size); err != nil {
+		s.broken = fmt.Errorf("journal is in an unknown state: %w", err)
+		return
+	}
+	if _, err := s.f.Seek(s.size, io.SeekStart); err != nil {
+		s.broken = fmt.Errorf("journal is in an unknown state: %w", err)
+	}
+}
+
+func (s *FileStore) compactEvery() int {
+	if s.CompactEvery > 0 {
+		return s.CompactEvery
+	}
+	return DefaultCompactEvery
+}
+
+// Compact は現在の状態だけを書いた新しいジャーナルに置き換える。
+func (s *FileStore) Compact() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.broken != nil {
+		return usecase.NewError(usecase.ErrUnavailable, "FileStore.Compact", s.broken)
+	}
+	if err := s.compact(); err != nil {
+		return usecase.NewError(usecase.ErrUnavailable, "FileStore.Compact", err)
+	}
+	return nil
+}
+
+// compact：コンパクション（呼び出し側でロックを取っていること）
+// 一時ファイルに書き切って fsync してから rename するので、途中で落ちても元のジャーナルが残る。
+func (s *FileStore) compact() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	emps, reqs, atts := s.mem.snapshot()
+	for i := range emps {
+		_ = enc.Encode(journalRecord{Type: "employee", Employee: &emps[i]})
+	}
+	for i := range reqs {
+		_ = enc.Encode(journalRecord{Type: "leave", Leave: &reqs[i]})
+	}
+	for i := range atts {
+		_ = enc.Encode(journalRecord{Type: "attachment", Attachment: &atts[i]})
+	}
+
+	tmpPath, path := filepath.Join(s.Dir, journalTmpFile), filepath.Join(s.Dir, journalFile)
+	tmp, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	syncDir(s.Dir)
+
+	// 以降は新しいジャーナルに追記する
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND, 0o640)
+	if err != nil {
+		s.broken = fmt.Errorf("reopen compacted journal: %w", err)
+		return s.broken
+	}
+	s.f.Close()
+	s.f, s.size, s.appended = f, int64(buf.Len()), 0
+	return nil
+}
+
+// syncDir は rename をディスクに反映させるためにディレクトリを fsync する（失敗は無視する）。
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		_ = d.Sync()
+		d.Close()
+	}
+}
+
+// --------------------------------------------------------
+// 従業員
+// --------------------------------------------------------
+
+// FileEmployeeRepo はジャーナルで永続化する従業員リポジトリ。
+// EmployeeRepo・EmployeeLister・EmployeeWriter を満たす。
+type FileEmployeeRepo struct{ s *FileStore }
+
+// FindByID は従業員IDで Employee を検索する。
+func (r FileEmployeeRepo) FindByID(id string) (domain.Employee, error) {
+	return r.s.mem.Employees().FindByID(id)
+}
+
+// List は全従業員をID順に取得する。
+func (r FileEmployeeRepo) List() ([]domain.Employee, error) { return r.s.mem.Employees().List() }
+
+// Create は従業員を登録する。
+func (r FileEmployeeRepo) Create(e *domain.Employee) error {
+	return r.s.commit("FileEmployeeRepo.Create", func() (journalRecord, func(), error) {
+		if err := r.s.mem.Employees().Create(e); err != nil {
+			return journalRecord{}, nil, err
+		}
+		saved := cloneEmployee(*e)
+		return journalRecord{Type: "employee", Employee: &saved}, func() { r.s.mem.removeEmployee(e.ID) }, nil
+	})
+}
+
+// Update は従業員情報を更新する。
+func (r FileEmployeeRepo) Update(e *domain.Employee) error {
+	return r.s.commit("FileEmployeeRepo.Update", func() (journalRecord, func(), error) {
+		prev, err := r.s.mem.Employees().FindByID(e.ID)
+		if err != nil {
+			return journalRecord{}, nil, err
+		}
+		if err := r.s.mem.Employees().Update(e); err != nil {
+			return journalRecord{}, nil, err
+		}
+		saved := cloneEmployee(*e)
+		return journalRecord{Type: "employee", Employee: &saved}, func() { r.s.mem.putEmployee(prev) }, nil
+	})
+}
+
+// --------------------------------------------------------
+// 休暇申請
+// --------------------------------------------------------
+
+// FileLeaveRepo はジャーナルで永続化する休暇申請リポジトリ。
+// LeaveRepo・LeaveBatchCreator・LeaveFinder・LeaveLister・PendingLeaveRepo・LeavePeriodRepo・EmployeeLeavesRepo を満たす。
+type FileLeaveRepo struct{ s *FileStore }
+
+// CountThisFiscalYear は年度内（start から1年間）に作成された申請のうち、申請回数に数えるものの件数を数える。
+func (r FileLeaveRepo) CountThisFiscalYear(empID string, start time.Time) (int, error) {
+	return r.s.mem.Leaves().CountThisFiscalYear(empID, start)
+}
+
+// FindByID は申請IDで休暇申請を取得する。
+func (r FileLeaveRepo) FindByID(id string) (domain.LeaveRequest, error) {
+	return r.s.mem.Leaves().FindByID(id)
+}
+
+// ListPending は承認待ちの申請を古い順に取得する。
+func (r FileLeaveRepo) ListPending() ([]domain.LeaveRequest, error) {
+	return r.s.mem.Leaves().ListPending()
+}
+
+// ListPendingByEmployee は指定した従業員の承認待ちの申請を古い順に取得する。
+func (r FileLeaveRepo) ListPendingByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.s.mem.Leaves().ListPendingByEmployee(employeeID)
+}
+
+// ListByEmployee は指定した従業員の申請をステータスを問わず古い順に取得する。
+func (r FileLeaveRepo) ListByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.s.mem.Leaves().ListByEmployee(employeeID)
+}
+
+// ListOverlapping は期間 [from, to] に一部でも重なる休暇申請を取得する。
+func (r FileLeaveRepo) ListOverlapping(from, to time.Time) ([]domain.LeaveRequest, error) {
+	return r.s.mem.Leaves().ListOverlapping(from, to)
+}
+
+// Create は休暇申請を登録し、採番したIDを req.ID に設定する。
+func (r FileLeaveRepo) Create(req *domain.LeaveRequest) error {
+	return r.s.commit("FileLeaveRepo.Create", func() (journalRecord, func(), error) {
+		if err := r.s.mem.Leaves().Create(req); err != nil {
+			return journalRecord{}, nil, err
+		}
+		saved := *req
+		return journalRecord{Type: "leave", Leave: &saved}, func() { r.s.mem.removeLeave(req.ID) }, nil
+	})
+}
+
+// CreateBatch は複数の休暇申請を1行にまとめて記録する（全件登録されるか、1件も登録されないか）。
+func (r FileLeaveRepo) CreateBatch(reqs []*domain.LeaveRequest) error {
+	return r.s.commit("FileLeaveRepo.CreateBatch", func() (journalRecord, func(), error) {
+		if err := r.s.mem.Leaves().CreateBatch(reqs); err != nil {
+			return journalRecord{}, nil, err
+		}
+		rec := journalRecord{Type: "batch"}
+		for _, req := range reqs {
+			saved := *req
+			rec.Batch = append(rec.Batch, journalRecord{Type: "leave", Leave: &saved})
+		}
+		undo := func() {
+			for _, req := range reqs {
+				r.s.mem.removeLeave(req.ID)
+			}
+		}
+		return rec, undo, nil
+	})
+}
+
+// Update は保存されているステータスが expected のときだけ、申請の状態（ステータス・承認者・催促日時・決定日時）を更新する。
+func (r FileLeaveRepo) Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error {
+	return r.s.commit("FileLeaveRepo.Update", func() (journalRecord, func(), error) {
+		prev, err := r.s.mem.Leaves().FindByID(req.ID)
+		if err != nil {
+			return journalRecord{}, nil, err
+		}
+		if err := r.s.mem.Leaves().Update(req, expected); err != nil {
+			return journalRecord{}, nil, err
+		}
+		// Update で変わらない項目も含めて、保存後の内容をそのまま記録する
+		saved, _ := r.s.mem.Leaves().FindByID(req.ID)
+		return journalRecord{Type: "leave", Leave: &saved}, func() { r.s.mem.putLeave(prev) }, nil
+	})
+}
+
+// --------------------------------------------------------
+// 添付ファイル
+// --------------------------------------------------------
+
+// FileAttachmentRepo はジャーナルで永続化する添付ファイル情報のリポジトリ。AttachmentRepo を満たす。
+type FileAttachmentRepo struct{ s *FileStore }
+
+// Create は添付ファイルの情報を登録し、採番したIDを a.ID に設定する。
+func (r FileAttachmentRepo) Create(a *domain.Attachment) error {
+	return r.s.commit("FileAttachmentRepo.Create", func() (journalRecord, func(), error) {
+		if err := r.s.mem.Attachments().Create(a); err != nil {
+			return journalRecord{}, nil, err
+		}
+		saved := *a
+		return journalRecord{Type: "attachment", Attachment: &saved}, func() { r.s.mem.removeAttachment(a.ID) }, nil
+	})
+}
+
+// FindByID は添付ファイルIDで情報を取得する。
+func (r FileAttachmentRepo) FindByID(id string) (domain.Attachment, error) {
+	return r.s.mem.Attachments().FindByID(id)
+}
+
+// ListByRequest は休暇申請の添付ファイルを添付順に取得する。
+func (r FileAttachmentRepo) ListByRequest(requestID string) ([]domain.Attachment, error) {
+	return r.s.mem.Attachments().ListByRequest(requestID)
+}
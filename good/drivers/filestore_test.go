@@ -0,0 +1,232 @@
+package drivers_test
+
+// FileStore の復元とクラッシュ耐性のテスト
+// --------------------------------------------------------
+// 契約テスト（stores_test.go）は1回開いたストアの振る舞いだけを見るので、ここではジャーナルのファイルを直接確かめる。
+// - 閉じて開き直すと、ジャーナルの再生で同じ状態に戻る
+// - 書きかけの最終行は切り捨て、途中の壊れた行はエラーにする
+// - コンパクションの後もジャーナルから同じ状態に戻り、残骸の一時ファイルは開くときに消す
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// fileStoreDay：テストの申請の基準日
+var fileStoreDay = time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC)
+
+// openFileStore は dir の FileStore を開く（テストの終わりに閉じる）。
+func openFileStore(t *testing.T, dir string) *drivers.FileStore {
+	t.Helper()
+	s, err := drivers.OpenFileStore(dir)
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// seedFileStore は従業員 e1 と、その承認済みの申請1件を保存して申請を返す。
+func seedFileStore(t *testing.T, s *drivers.FileStore) domain.LeaveRequest {
+	t.Helper()
+	e := domain.Employee{ID: "e1", Name: "Taro", HireDate: fileStoreDay.AddDate(-3, 0, 0),
+		Notify: domain.NotificationPrefs{Channels: []domain.Channel{domain.ChannelEmail}}}
+	if err := s.Employees().Create(&e); err != nil {
+		t.Fatalf("Create employee: %v", err)
+	}
+	req := domain.LeaveRequest{EmployeeID: "e1", Type: domain.LeavePaid, From: fileStoreDay, To: fileStoreDay,
+		Status: domain.StatusPending, CreatedAt: fileStoreDay.AddDate(0, 0, -7)}
+	if err := s.Leaves().Create(&req); err != nil {
+		t.Fatalf("Create leave: %v", err)
+	}
+	req.Status, req.DecidedAt = domain.StatusApproved, fileStoreDay.AddDate(0, 0, -6)
+	if err := s.Leaves().Update(&req, domain.StatusPending); err != nil {
+		t.Fatalf("Update leave: %v", err)
+	}
+	return req
+}
+
+// wantSeeded は seedFileStore で保存した内容が読めることを確かめる。
+func wantSeeded(t *testing.T, s *drivers.FileStore, want domain.LeaveRequest) {
+	t.Helper()
+	e, err := s.Employees().FindByID("e1")
+	if err != nil || e.Name != "Taro" || len(e.Notify.Channels) != 1 {
+		t.Errorf("FindByID(e1) = %+v, %v", e, err)
+	}
+	got, err := s.Leaves().FindByID(want.ID)
+	if err != nil {
+		t.Fatalf("FindByID(%s): %v", want.ID, err)
+	}
+	if got.Status != want.Status || !got.DecidedAt.Equal(want.DecidedAt) {
+		t.Errorf("leave = %s decided %v, want %s decided %v", got.Status, got.DecidedAt, want.Status, want.DecidedAt)
+	}
+}
+
+func journalPath(dir string) string { return filepath.Join(dir, "journal.jsonl") }
+
+func readJournal(t *testing.T, dir string) []byte {
+	t.Helper()
+	b, err := os.ReadFile(journalPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestFileStoreReopenReplaysJournal(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	req := seedFileStore(t, s)
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := s.Employees().Update(&domain.Employee{ID: "e1", Name: "x"}); !errors.Is(err, usecase.ErrUnavailable) {
+		t.Errorf("write after Close = %v, want ErrUnavailable", err)
+	}
+
+	s = openFileStore(t, dir)
+	wantSeeded(t, s, req)
+	// 採番は再生した申請の後から続く
+	next := domain.LeaveRequest{EmployeeID: "e1", Type: domain.LeavePaid, From: fileStoreDay, To: fileStoreDay,
+		Status: domain.StatusPending, CreatedAt: fileStoreDay}
+	if err := s.Leaves().Create(&next); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if next.ID == req.ID {
+		t.Errorf("new request reused ID %s", req.ID)
+	}
+}
+
+func TestFileStoreDropsHalfWrittenLastLine(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	req := seedFileStore(t, s)
+	s.Close()
+	complete := readJournal(t, dir)
+
+	// 追記の途中で落ちた跡（改行で終わらない最終行）
+	torn := append(bytes.Clone(complete), `{"type":"employee","employee":{"ID":"e2","Na`...)
+	if err := os.WriteFile(journalPath(dir), torn, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openFileStore(t, dir)
+	wantSeeded(t, s, req)
+	if _, err := s.Employees().FindByID("e2"); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("FindByID(e2) = %v, want the half-written employee dropped", err)
+	}
+	if got := readJournal(t, dir); !bytes.Equal(got, complete) {
+		t.Errorf("journal was not truncated to its last complete line:\n%s", got)
+	}
+
+	// 切り捨てた後の追記も、開き直して読める
+	e2 := domain.Employee{ID: "e2", Name: "Hanako", HireDate: fileStoreDay}
+	if err := s.Employees().Create(&e2); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	s.Close()
+	s = openFileStore(t, dir)
+	if _, err := s.Employees().FindByID("e2"); err != nil {
+		t.Errorf("FindByID(e2) after reopen: %v", err)
+	}
+}
+
+func TestFileStoreRejectsCorruptLineInTheMiddle(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	seedFileStore(t, s)
+	s.Close()
+	journal := readJournal(t, dir)
+
+	// 2行目を壊す。最終行ではないので書きかけではなく、黙って捨てると変更を失う
+	lines := bytes.SplitAfter(journal, []byte("\n"))
+	lines[1] = []byte("{not json}\n")
+	if err := os.WriteFile(journalPath(dir), bytes.Join(lines, nil), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := drivers.OpenFileStore(dir)
+	if !errors.Is(err, usecase.ErrInternal) || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("OpenFileStore = %v, want ErrInternal pointing at line 2", err)
+	}
+	if got := readJournal(t, dir); !bytes.Equal(got, bytes.Join(lines, nil)) {
+		t.Errorf("journal was changed by a failed open")
+	}
+}
+
+func TestFileStoreCompaction(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	s.CompactEvery = 4
+	seedFileStore(t, s)
+	e, err := s.Employees().FindByID("e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"A", "B", "C", "D", "E"} {
+		e.Name = name
+		if err := s.Employees().Update(&e); err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+	// 8行の追記は4行ごとにコンパクションされ、従業員と申請の最新の状態だけが残る
+	if n := bytes.Count(readJournal(t, dir), []byte("\n")); n != 2 {
+		t.Errorf("journal has %d lines, want 2 (one employee and one leave)", n)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.jsonl.tmp")); !os.IsNotExist(err) {
+		t.Errorf("temporary journal left behind: %v", err)
+	}
+
+	// 明示的なコンパクションの後の追記も、開き直して読める
+	e.Name = "F"
+	if err := s.Employees().Update(&e); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatalf("Compact: %v", err)
+	}
+	e.Name = "G"
+	if err := s.Employees().Update(&e); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	s = openFileStore(t, dir)
+	if got, err := s.Employees().FindByID("e1"); err != nil || got.Name != "G" {
+		t.Errorf("FindByID(e1) = %q, %v, want the latest name G", got.Name, err)
+	}
+	if reqs, err := s.Leaves().ListByEmployee("e1"); err != nil || len(reqs) != 1 || reqs[0].Status != domain.StatusApproved {
+		t.Errorf("ListByEmployee = %+v, %v, want the approved request", reqs, err)
+	}
+}
+
+func TestFileStoreRemovesLeftoverTemporaryJournal(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	req := seedFileStore(t, s)
+	s.Close()
+
+	// コンパクションの途中（rename の前）で落ちた跡。元のジャーナルが正しい
+	tmp := filepath.Join(dir, "journal.jsonl.tmp")
+	if err := os.WriteFile(tmp, []byte(`{"type":"employee","employee":{"ID":"ghost"}}`+"\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openFileStore(t, dir)
+	if _, err := os.Stat(tmp); !os.IsNotExist(err) {
+		t.Errorf("leftover %s was not removed: %v", tmp, err)
+	}
+	wantSeeded(t, s, req)
+	if _, err := s.Employees().FindByID("ghost"); !errors.Is(err, usecase.ErrNotFound) {
+		t.Errorf("FindByID(ghost) = %v, want the temporary journal ignored", err)
+	}
+}
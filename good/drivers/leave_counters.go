@@ -0,0 +1,95 @@
+package drivers
+
+// 年度内の申請回数の集計表（SQL データベース）
+// --------------------------------------------------------
+// 申請のたびに leave_requests を COUNT(*) しないよう、従業員×会計年度ごとの件数を leave_counters に持つ。
+// - 申請の登録・ステータスの変更と同じトランザクションで増減させる（集計表だけがずれることはない）
+// - 何を数えるかは Domain層のルール（domain.QuotaDelta）に従う。却下・差し戻し・取消は数えない
+// - 年度は申請の作成日時から SQLLeaveRepo.YearStart で決める
+// - 集計表が壊れた・数え方を変えたときは RebuildCounters で leave_requests から作り直す
+// --------------------------------------------------------
+
+import (
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// yearStart は作成日時 t の申請を数える年度の開始日を返す。
+func (r SQLLeaveRepo) yearStart(t time.Time) time.Time {
+	if r.YearStart != nil {
+		return r.YearStart(t)
+	}
+	return domain.FiscalYearStart(t)
+}
+
+// counterDate は年度の開始日を DATE 列に渡す文字列にする。
+// time.Time のまま渡すと、セッションのタイムゾーンで日付に変換されて前日になることがある。
+func counterDate(t time.Time) string { return t.Format("2006-01-02") }
+
+// addToCounter は作成日時 createdAt の申請の年度の件数を delta だけ増減させる（delta が 0 なら何もしない）。
+func (r SQLLeaveRepo) addToCounter(c sqlConn, empID string, createdAt time.Time, delta int) error {
+	if delta == 0 {
+		return nil
+	}
+	_, err := c.Exec(
+		`INSERT INTO leave_counters(employee_id, fiscal_year_start, requests) VALUES($1, $2, $3) `+
+			c.d.AddOnConflict("leave_counters", []string{"employee_id", "fiscal_year_start"}, "requests"),
+		empID, counterDate(r.yearStart(createdAt)), delta)
+	return err
+}
+
+// RebuildCounters は leave_requests の全件から集計表を作り直し、作った行数を返す。
+// 作り直している間は申請の登録・更新を待たせる（数え漏れを防ぐため）。
+func (r SQLLeaveRepo) RebuildCounters() (int, error) {
+	const op = "SQLLeaveRepo.RebuildCounters"
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return 0, translateSQLError(op, err)
+	}
+	defer tx.Rollback()
+	c := newSQLConn(tx, r.Dialect)
+	lock, suffix := c.d.LockTable("leave_requests", false)
+	if lock != "" {
+		if _, err := c.Exec(lock); err != nil {
+			return 0, translateSQLError(op, err)
+		}
+	}
+
+	type counterKey struct {
+		employeeID string
+		yearStart  string
+	}
+	counts := map[counterKey]int{}
+	rows, err := c.Query(`SELECT employee_id, status, created_at FROM leave_requests` + suffix)
+	if err != nil {
+		return 0, translateSQLError(op, err)
+	}
+	for rows.Next() {
+		var empID string
+		var status domain.LeaveStatus
+		var createdAt time.Time
+		if err := rows.Scan(&empID, &status, dbTime{&createdAt}); err != nil {
+			rows.Close()
+			return 0, translateSQLError(op, err)
+		}
+		if status.CountsTowardQuota() {
+			counts[counterKey{empID, counterDate(r.yearStart(createdAt))}]++
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, translateSQLError(op, err)
+	}
+
+	if _, err := c.Exec(`DELETE FROM leave_counters`); err != nil {
+		return 0, translateSQLError(op, err)
+	}
+	for k, n := range counts {
+		if _, err := c.Exec(`INSERT INTO leave_counters(employee_id, fiscal_year_start, requests) VALUES($1, $2, $3)`,
+			k.employeeID, k.yearStart, n); err != nil {
+			return 0, translateSQLError(op, err)
+		}
+	}
+	return len(counts), translateSQLError(op, tx.Commit())
+}
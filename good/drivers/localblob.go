@@ -0,0 +1,103 @@
+package drivers
+
+// Framework & Drivers層（ローカルファイルシステムへのファイル保存）
+// --------------------------------------------------------
+// usecase.BlobStore の具象実装。添付ファイルの中身を指定ディレクトリに保存する。
+// - キーはランダムに採番する（利用者が指定したファイル名はパスに使わない）
+// - 一時ファイルに書き切ってから rename するので、書きかけのファイルは見えない
+// --------------------------------------------------------
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// LocalBlobStore はファイルの中身を Dir 配下に保存する。
+type LocalBlobStore struct{ Dir string }
+
+// Put は r の中身を保存し、採番したキーを返す。
+func (s LocalBlobStore) Put(r io.Reader) (string, error) {
+	const op = "LocalBlobStore.Put"
+	if err := os.MkdirAll(s.Dir, 0o750); err != nil {
+		return "", usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	key, err := newBlobKey()
+	if err != nil {
+		return "", usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	tmp, err := os.CreateTemp(s.Dir, ".upload-*")
+	if err != nil {
+		return "", usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	defer os.Remove(tmp.Name()) // rename 後は何も起きない
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return "", err // 読み込み側のエラー（サイズ超過など）はそのまま返す
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return "", usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	return key, nil
+}
+
+// Get はキーに対応する中身を開く。
+func (s LocalBlobStore) Get(key string) (io.ReadCloser, error) {
+	const op = "LocalBlobStore.Get"
+	if !validBlobKey(key) {
+		return nil, usecase.NewError(usecase.ErrNotFound, op, nil)
+	}
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, usecase.NewError(usecase.ErrNotFound, op, err)
+	}
+	if err != nil {
+		return nil, usecase.NewError(usecase.ErrUnavailable, op, err)
+	}
+	return f, nil
+}
+
+// Delete はキーに対応する中身を削除する。存在しなければ何もしない。
+func (s LocalBlobStore) Delete(key string) error {
+	if !validBlobKey(key) {
+		return nil
+	}
+	if err := os.Remove(s.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return usecase.NewError(usecase.ErrUnavailable, "LocalBlobStore.Delete", err)
+	}
+	return nil
+}
+
+func (s LocalBlobStore) path(key string) string { return filepath.Join(s.Dir, key) }
+
+// newBlobKey は 128bit のランダムなキーを16進文字列で返す。
+func newBlobKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// validBlobKey はキーが newBlobKey の形式か（パスとして安全か）を返す。
+func validBlobKey(key string) bool {
+	if len(key) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
@@ -8,8 +8,349 @@ package drivers
 // -------------------------------------------------------
 // - 業務ロジックを含まない（技術的な処理のみ）
 // --------------------------------------------------------
+// SMTPMailer は net/smtp でメールを送る。
+// - 宛先・申請・申請者は usecase.Notification で受け取る（この層では引き直さない）
+// - 件名・本文は宛先の言語のテンプレートから作る（mail_templates.go）
+// - STARTTLS（使えれば使う・必須・使わない）と AUTH PLAIN に対応する
+// - 件名は ISO-2022-JP（表せない文字を含むときや日本語以外のメールは UTF-8）の encoded-word にする
+// - 本文はテキストと HTML の multipart/alternative（どちらも UTF-8 の base64）
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/internal/japanese"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// StartTLSMode：STARTTLS の使い方
+type StartTLSMode string
+
+const (
+	StartTLSOpportunistic StartTLSMode = ""         // サーバが対応していれば使う（既定）
+	StartTLSRequired      StartTLSMode = "required" // 対応していなければ送らない
+	StartTLSOff           StartTLSMode = "off"      // 使わない
+)
+
+// 件名の文字コード
+const (
+	CharsetISO2022JP = "ISO-2022-JP"
+	CharsetUTF8      = "UTF-8"
+)
+
+// SMTPConfig：SMTP サーバへの接続設定
+type SMTPConfig struct {
+	Host      string
+	Port      int    // 0 なら 587
+	Username  string // 空なら認証しない
+	Password  string
+	From      string // 差出人（"休暇申請システム <noreply@example.com>" の形式も可）
+	StartTLS  StartTLSMode
+	TLSConfig *tls.Config   // nil なら ServerName に Host を使う既定の設定
+	Timeout   time.Duration // 接続から送信完了までの制限時間。0 なら 30 秒
+	LocalName string        // EHLO で名乗るホスト名。空なら "localhost"
+	Charset   string        // 件名の文字コード。空なら ISO-2022-JP
+}
+
+// SMTPMailer：UseCase層の Mailer を SMTP で実装したもの
+type SMTPMailer struct {
+	Config SMTPConfig
+}
+
+// NotifyManagerNewRequest は承認者（上長）に新しい申請を知らせる。
+func (m SMTPMailer) NotifyManagerNewRequest(n usecase.Notification) error {
+	return m.send("SMTPMailer.NotifyManagerNewRequest", mailKindNewRequest, n)
+}
+
+// NotifyApproverReminder は承認待ちの申請を承認者に催促する。
+func (m SMTPMailer) NotifyApproverReminder(n usecase.Notification) error {
+	return m.send("SMTPMailer.NotifyApproverReminder", mailKindReminder, n)
+}
+
+// NotifyEscalation はエスカレーション先の承認者に申請を知らせる。
+func (m SMTPMailer) NotifyEscalation(n usecase.Notification) error {
+	return m.send("SMTPMailer.NotifyEscalation", mailKindEscalation, n)
+}
+
+// NotifyRequesterDecision は申請者に判断の結果（承認者のコメントつき）を知らせる。
+func (m SMTPMailer) NotifyRequesterDecision(n usecase.Notification) error {
+	return m.send("SMTPMailer.NotifyRequesterDecision", mailKindDecision, n)
+}
+
+// NotifyTeamAbsence はチームのメンバーに承認済みの休暇を知らせる。
+func (m SMTPMailer) NotifyTeamAbsence(n usecase.Notification) error {
+	return m.send("SMTPMailer.NotifyTeamAbsence", mailKindTeamAbsence, n)
+}
+
+// send は通知の種類 kind のメールを組み立てて宛先に1通送る。
+func (m SMTPMailer) send(op, kind string, n usecase.Notification) error {
+	to := n.Recipient
+	if to.Email == "" {
+		return usecase.NewError(usecase.ErrValidation, op, fmt.Errorf("employee %q has no email address", to.ID))
+	}
+	from, err := mail.ParseAddress(m.Config.From)
+	if err != nil {
+		return usecase.NewError(usecase.ErrInternal, op, fmt.Errorf("bad From address %q: %w", m.Config.From, err))
+	}
+	content, err := renderMail(kind, n)
+	if err != nil {
+		return usecase.NewError(usecase.ErrInternal, op, err)
+	}
+	rcpt := &mail.Address{Name: to.Name, Address: to.Email}
+	msg := buildMessage(from, rcpt, content, m.Config.Charset, time.Now())
+	if err := m.Config.deliver(from.Address, []string{rcpt.Address}, msg); err != nil {
+		return usecase.NewError(smtpErrorKind(err), op, err)
+	}
+	return nil
+}
+
+// deliver は SMTP サーバに接続して msg を送る。
+func (c SMTPConfig) deliver(from string, to []string, msg []byte) error {
+	port, timeout := c.Port, c.Timeout
+	if port == 0 {
+		port = 587
+	}
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(c.Host, strconv.Itoa(port)), timeout)
+	if err != nil {
+		return err
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+	client, err := smtp.NewClient(conn, c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	localName := c.LocalName
+	if localName == "" {
+		localName = "localhost"
+	}
+	if err := client.Hello(localName); err != nil {
+		return err
+	}
+	if c.StartTLS != StartTLSOff {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			tc := &tls.Config{ServerName: c.Host}
+			if c.TLSConfig != nil {
+				tc = c.TLSConfig.Clone()
+				if tc.ServerName == "" {
+					tc.ServerName = c.Host
+				}
+			}
+			if err := client.StartTLS(tc); err != nil {
+				return err
+			}
+		} else if c.StartTLS == StartTLSRequired {
+			return errStartTLSUnsupported
+		}
+	}
+	if c.Username != "" {
+		// PlainAuth は TLS なしの接続では localhost 以外に資格情報を送らない
+		if err := client.Auth(smtp.PlainAuth("", c.Username, c.Password, c.Host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+var errStartTLSUnsupported = errors.New("smtp: server does not support STARTTLS")
+
+// smtpErrorKind は SMTP のエラーを usecase のエラー種類へ翻訳する。
+// 4xx 応答と通信エラーは一時的なもの（再試行で直りうる）、5xx 応答は恒久的なものとみなす。
+func smtpErrorKind(err error) error {
+	var tpErr *textproto.Error
+	switch {
+	case errors.As(err, &tpErr) && tpErr.Code >= 500:
+		return usecase.ErrInternal
+	case errors.Is(err, errStartTLSUnsupported):
+		return usecase.ErrInternal
+	}
+	return usecase.ErrUnavailable
+}
+
+// buildMessage はヘッダと本文からなるメール（RFC 5322 / MIME）を組み立てる。
+// 本文はテキストと HTML の multipart/alternative にする（どちらも UTF-8 の base64）。
+func buildMessage(from, to *mail.Address, content renderedMail, charset string, now time.Time) []byte {
+	var b bytes.Buffer
+	header := func(k, v string) { fmt.Fprintf(&b, "%s: %s\r\n", k, v) }
+	// 件名の文字コードは日本語のメールだけ ISO-2022-JP にする（英語のメールは UTF-8 で十分）
+	if content.Locale != domain.LocaleJapanese {
+		charset = CharsetUTF8
+	}
+	mw := multipart.NewWriter(&b)
+	header("From", from.String())
+	header("To", to.String())
+	header("Subject", encodeSubject(content.Subject, charset))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", newMessageID(from.Address))
+	header("Content-Language", string(content.Locale))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+mw.Boundary()+`"`)
+	b.WriteString("\r\n")
+	// 受信側はあとの部分ほど優先して表示するので、HTML を後に置く
+	for _, part := range []struct{ typ, body string }{
+		{"text/plain", content.Text},
+		{"text/html", content.HTML},
+	} {
+		w, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.typ + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		var pb bytes.Buffer
+		writeBase64Lines(&pb, []byte(toCRLF(part.body)))
+		_, _ = w.Write(pb.Bytes()) // bytes.Buffer への書き込みは失敗しない
+	}
+	_ = mw.Close()
+	return b.Bytes()
+}
+
+// toCRLF は改行を CRLF にそろえる。
+func toCRLF(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\n", "\r\n")
+}
+
+// encodeSubject は件名をヘッダに書ける形にする。
+// ASCII だけならそのまま、それ以外は encoded-word（RFC 2047）にする。
+func encodeSubject(s, charset string) string {
+	if isASCII(s) {
+		return s
+	}
+	if !strings.EqualFold(charset, CharsetUTF8) {
+		if words, ok := iso2022jpWords(s); ok {
+			return strings.Join(words, "\r\n ")
+		}
+	}
+	return mime.BEncoding.Encode(CharsetUTF8, s)
+}
+
+// maxEncodedWord：encoded-word 1つの長さの上限（RFC 2047）
+const maxEncodedWord = 75
+
+// iso2022jpWords は s を ISO-2022-JP の encoded-word に分けて変換する。
+// 1つの encoded-word が75文字を超えないよう、文字の境目で区切る（各 word は ASCII の状態で終わる）。
+func iso2022jpWords(s string) ([]string, bool) {
+	const prefix, suffix = "=?" + CharsetISO2022JP + "?B?", "?="
+	word := func(b []byte) string { return prefix + base64.StdEncoding.EncodeToString(b) + suffix }
+	var words []string
+	runes := []rune(s)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		best, ok := japanese.EncodeISO2022JP(string(runes[start:end]))
+		if !ok {
+			return nil, false
+		}
+		for end < len(runes) {
+			b, ok := japanese.EncodeISO2022JP(string(runes[start : end+1]))
+			if !ok {
+				return nil, false
+			}
+			if len(word(b)) > maxEncodedWord {
+				break
+			}
+			best, end = b, end+1
+		}
+		words = append(words, word(best))
+		start = end
+	}
+	return words, true
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
+
+// writeBase64Lines は b を base64 にして76文字ごとに改行しながら書く。
+func writeBase64Lines(buf *bytes.Buffer, b []byte) {
+	enc := base64.StdEncoding.EncodeToString(b)
+	for len(enc) > 76 {
+		buf.WriteString(enc[:76])
+		buf.WriteString("\r\n")
+		enc = enc[76:]
+	}
+	buf.WriteString(enc)
+	buf.WriteString("\r\n")
+}
+
+// newMessageID は差出人のドメインを使って一意な Message-ID を作る。
+func newMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
+
+// LogMailer はメールを送らずにログへ書くだけの Mailer（SMTP サーバがないローカル環境用）。
+type LogMailer struct{ Logger *slog.Logger }
+
+func (m LogMailer) NotifyManagerNewRequest(n usecase.Notification) error {
+	return m.log(mailKindNewRequest, n)
+}
+
+func (m LogMailer) NotifyApproverReminder(n usecase.Notification) error {
+	return m.log(mailKindReminder, n)
+}
+
+func (m LogMailer) NotifyEscalation(n usecase.Notification) error {
+	return m.log(mailKindEscalation, n)
+}
+
+func (m LogMailer) NotifyRequesterDecision(n usecase.Notification) error {
+	return m.log(mailKindDecision, n)
+}
 
-type SMTPMailer struct{}
+func (m LogMailer) NotifyTeamAbsence(n usecase.Notification) error {
+	return m.log(mailKindTeamAbsence, n)
+}
 
-// メール送信の具象実装
-func (m SMTPMailer) NotifyManagerNewRequest(id string) error { /* 実送信 */ return nil }
+func (m LogMailer) log(kind string, n usecase.Notification) error {
+	m.Logger.Info("mail not sent (SMTP is not configured)", "kind", kind,
+		"request_id", n.Request.ID, "recipient_id", n.Recipient.ID, "locale", n.Recipient.PreferredLocale(), "link", n.Link)
+	return nil
+}
@@ -0,0 +1,169 @@
+package drivers
+
+// Framework & Drivers層（通知メールの文面）
+// --------------------------------------------------------
+// 通知の種類ごとの件名・本文を、埋め込んだテンプレートから組み立てる。
+// - mailtemplates/<言語>.txt.tmpl  … "<種類>.subject" と "<種類>.text"（text/template）
+// - mailtemplates/<言語>.html.tmpl … "<種類>.html"（html/template。申請理由などはエスケープされる）
+// - 言語は宛先の社員の PreferredLocale で選ぶ。テンプレートがない言語は既定の言語にする
+// --------------------------------------------------------
+// チームのメンバーへの連絡（team_absence）には休暇の種類・理由を載せない（病気休暇などを本人以外に知らせない）。
+// 文面を変えるときは Go のコードではなくテンプレートを直す。
+// 言語を増やすときは両方のテンプレートと leaveTypeLabels / formatMailDate に追加する。
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+//go:embed mailtemplates/*.tmpl
+var mailTemplateFiles embed.FS
+
+// 通知の種類（テンプレート名の接頭辞）
+const (
+	mailKindNewRequest  = "new_request"
+	mailKindReminder    = "reminder"
+	mailKindEscalation  = "escalation"
+	mailKindDecision    = "decision"     // 申請者への判断結果
+	mailKindTeamAbsence = "team_absence" // チームのメンバーへの休暇の連絡
+)
+
+// mailLocales：テンプレートを用意している言語
+var mailLocales = []domain.Locale{domain.LocaleJapanese, domain.LocaleEnglish}
+
+var (
+	mailTextTemplates = map[domain.Locale]*texttemplate.Template{}
+	mailHTMLTemplates = map[domain.Locale]*htmltemplate.Template{}
+)
+
+func init() {
+	for _, loc := range mailLocales {
+		// 埋め込みファイルの誤りはビルドした時点の不具合なので Must でよい
+		mailTextTemplates[loc] = texttemplate.Must(texttemplate.ParseFS(mailTemplateFiles, "mailtemplates/"+string(loc)+".txt.tmpl"))
+		mailHTMLTemplates[loc] = htmltemplate.Must(htmltemplate.ParseFS(mailTemplateFiles, "mailtemplates/"+string(loc)+".html.tmpl"))
+	}
+}
+
+// mailView：テンプレートに渡す値（言語に合わせて整形済み）
+type mailView struct {
+	RecipientName string
+	RequesterName string
+	RequestID     string
+	Type          string // 休暇の種類の表示名
+	From          string
+	To            string
+	Days          float64 // 取得する日数（平日のみ。半日休は 0.5 日）
+	Reason        string
+	Link          string
+	ApproverName  string // 判断した承認者（判断結果・休暇の連絡のときだけ）
+	Status        string // 申請のステータス（APPROVED など。テンプレートで文面を分けるのに使う）
+	Comment       string // 承認者のコメント
+}
+
+// renderedMail：組み立てたメールの中身
+type renderedMail struct {
+	Locale  domain.Locale
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// leaveTypeLabels：休暇の種類の表示名
+var leaveTypeLabels = map[domain.Locale]map[domain.LeaveType]string{
+	domain.LocaleJapanese: {
+		domain.LeavePaid:    "年次有給休暇",
+		domain.LeaveSick:    "病気休暇",
+		domain.LeaveSpecial: "特別休暇",
+	},
+	domain.LocaleEnglish: {
+		domain.LeavePaid:    "Paid leave",
+		domain.LeaveSick:    "Sick leave",
+		domain.LeaveSpecial: "Special leave",
+	},
+}
+
+var japaneseWeekdays = [...]string{"日", "月", "火", "水", "木", "金", "土"}
+
+// formatMailDate は日付を言語に合わせた表記にする。
+func formatMailDate(t time.Time, loc domain.Locale) string {
+	if loc == domain.LocaleEnglish {
+		return t.Format("Mon, Jan 2, 2006")
+	}
+	return fmt.Sprintf("%d年%d月%d日（%s）", t.Year(), t.Month(), t.Day(), japaneseWeekdays[t.Weekday()])
+}
+
+// newMailView は通知の内容を宛先の言語に合わせて整形する（メール以外の通知でも使う）。
+func newMailView(n usecase.Notification) (mailView, domain.Locale) {
+	loc := n.Recipient.PreferredLocale()
+	if _, ok := mailTextTemplates[loc]; !ok {
+		loc = domain.DefaultLocale
+	}
+	typeLabel := leaveTypeLabels[loc][n.Request.Type]
+	if typeLabel == "" {
+		typeLabel = string(n.Request.Type)
+	}
+	view := mailView{
+		RecipientName: displayName(n.Recipient),
+		RequesterName: displayName(n.Requester),
+		RequestID:     n.Request.ID,
+		Type:          typeLabel,
+		From:          formatMailDate(n.Request.From, loc),
+		To:            formatMailDate(n.Request.To, loc),
+		Days:          domain.LeaveDays(n.Request.From, n.Request.To, n.Request.DayPart),
+		Reason:        n.Request.Reason,
+		Link:          n.Link,
+		Status:        string(n.Request.Status),
+		Comment:       n.Request.DecisionComment,
+	}
+	if n.Approver.ID != "" {
+		view.ApproverName = displayName(n.Approver)
+	}
+	return view, loc
+}
+
+// renderSubject は通知の種類 kind の件名（1行）を組み立てる。
+func renderSubject(kind string, view mailView, loc domain.Locale) (string, error) {
+	var b bytes.Buffer
+	if err := mailTextTemplates[loc].ExecuteTemplate(&b, kind+".subject", view); err != nil {
+		return "", err
+	}
+	// 件名は1行にする（申請者の名前などに改行が入っていてもヘッダを壊さない）
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// renderMail は通知の種類 kind の件名・本文を宛先の言語で組み立てる。
+func renderMail(kind string, n usecase.Notification) (renderedMail, error) {
+	view, loc := newMailView(n)
+	subject, err := renderSubject(kind, view, loc)
+	if err != nil {
+		return renderedMail{}, err
+	}
+	out := renderedMail{Locale: loc, Subject: subject}
+	var b bytes.Buffer
+	if err := mailTextTemplates[loc].ExecuteTemplate(&b, kind+".text", view); err != nil {
+		return renderedMail{}, err
+	}
+	out.Text = b.String()
+	b.Reset()
+	if err := mailHTMLTemplates[loc].ExecuteTemplate(&b, kind+".html", view); err != nil {
+		return renderedMail{}, err
+	}
+	out.HTML = b.String()
+	return out, nil
+}
+
+func displayName(e domain.Employee) string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.ID
+}
@@ -0,0 +1,467 @@
+package drivers_test
+
+// SMTPMailer のテスト（internal/smtptest）
+// --------------------------------------------------------
+// プロセス内の SMTP サーバに実際に送り、受け取ったメールを確かめる。
+// - エンベロープ（MAIL FROM・RCPT TO）とヘッダの宛先
+// - STARTTLS・AUTH PLAIN と、STARTTLS 必須なのにサーバが対応していないとき
+// - 件名の ISO-2022-JP の encoded-word（長さの上限・英語のメールは UTF-8）
+// - テンプレートから組み立てた本文（テキストと HTML、自動承認・期限切れの文面）
+// - 送れなかったときのエラーの種類
+// --------------------------------------------------------
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/internal/japanese"
+	"github.com/ohagi/clean-architecture-examples/good/internal/smtptest"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+const mailFrom = "休暇申請システム <noreply@example.com>"
+
+var (
+	mailAlice   = domain.Employee{ID: "alice", Name: "山田 花子", Email: "alice@example.com"}
+	mailManager = domain.Employee{ID: "manager", Name: "佐藤 一郎", Email: "manager@example.com"}
+	mailRequest = domain.LeaveRequest{
+		ID: "42", EmployeeID: "alice", Type: domain.LeavePaid, Reason: "通院 <午後>",
+		From: time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 5, 13, 0, 0, 0, 0, time.UTC),
+		Status: domain.StatusPending,
+	}
+)
+
+// receivedMail：受け取ったメールを読みやすくしたもの
+type receivedMail struct {
+	smtptest.Message
+	Header mail.Header
+	Text   string
+	HTML   string
+}
+
+// mailConfig は srv に送る SMTPConfig を返す。
+func mailConfig(srv *smtptest.Server) drivers.SMTPConfig {
+	return drivers.SMTPConfig{Host: srv.Host, Port: srv.Port, From: mailFrom, Timeout: 5 * time.Second}
+}
+
+// sendOne は send で1通送り、サーバが受け取ったメールを返す。
+func sendOne(t *testing.T, srv *smtptest.Server, send func() error) receivedMail {
+	t.Helper()
+	srv.Reset()
+	if err := send(); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(msgs))
+	}
+	return parseReceived(t, msgs[0])
+}
+
+// parseReceived はヘッダを読み、multipart/alternative の各部分を base64 から戻す。
+func parseReceived(t *testing.T, m smtptest.Message) receivedMail {
+	t.Helper()
+	msg, err := mail.ReadMessage(bytes.NewReader(m.Data))
+	if err != nil {
+		t.Fatalf("read message: %v", err)
+	}
+	out := receivedMail{Message: m, Header: msg.Header}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		if enc := part.Header.Get("Content-Transfer-Encoding"); enc != "base64" {
+			t.Errorf("Content-Transfer-Encoding = %q, want base64", enc)
+		}
+		body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		switch ct := part.Header.Get("Content-Type"); ct {
+		case "text/plain; charset=UTF-8":
+			out.Text = string(body)
+		case "text/html; charset=UTF-8":
+			out.HTML = string(body)
+		default:
+			t.Errorf("unexpected part %q", ct)
+		}
+	}
+	if out.Text == "" || out.HTML == "" {
+		t.Fatalf("message has no text or HTML part:\n%s", m.Data)
+	}
+	return out
+}
+
+// decodeSubject は Subject ヘッダの encoded-word を戻す（ISO-2022-JP はテスト側で Shift_JIS 経由で読む）。
+func decodeSubject(t *testing.T, h mail.Header) string {
+	t.Helper()
+	dec := mime.WordDecoder{CharsetReader: func(charset string, r io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, drivers.CharsetISO2022JP) {
+			return nil, fmt.Errorf("unexpected charset %q", charset)
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		s, err := decodeISO2022JP(b)
+		return strings.NewReader(s), err
+	}}
+	s, err := dec.DecodeHeader(h.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject %q: %v", h.Get("Subject"), err)
+	}
+	return s
+}
+
+// decodeISO2022JP は ASCII と JIS X 0208 だけの ISO-2022-JP を読む。
+// JIS コードを Shift_JIS に計算で直してから japanese.DecodeShiftJIS で Unicode にする。
+// 各 encoded-word は ASCII の状態で終わっていなければならない。
+func decodeISO2022JP(b []byte) (string, error) {
+	var sjis []byte
+	kanji := false
+	for i := 0; i < len(b); {
+		switch {
+		case bytes.HasPrefix(b[i:], []byte("\x1b$B")):
+			kanji, i = true, i+3
+		case bytes.HasPrefix(b[i:], []byte("\x1b(B")):
+			kanji, i = false, i+3
+		case !kanji:
+			sjis, i = append(sjis, b[i]), i+1
+		case i+1 < len(b):
+			j1, j2 := b[i], b[i+1]
+			s1 := (j1+1)/2 + 0x70
+			if j1 >= 0x5F {
+				s1 += 0x40
+			}
+			s2 := j2 + 0x7E
+			if j1%2 == 1 {
+				s2 = j2 + 0x1F
+				if j2 >= 0x60 {
+					s2++
+				}
+			}
+			sjis, i = append(sjis, s1, s2), i+2
+		default:
+			return "", errors.New("truncated JIS X 0208 character")
+		}
+	}
+	if kanji {
+		return "", errors.New("encoded word does not end in ASCII")
+	}
+	return japanese.DecodeShiftJIS(sjis)
+}
+
+func TestSMTPMailerEnvelope(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	m := drivers.SMTPMailer{Config: mailConfig(srv)}
+
+	got := sendOne(t, srv, func() error {
+		return m.NotifyManagerNewRequest(usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest})
+	})
+
+	// エンベロープにはアドレスだけを使い、宛先は通知の受け手の1人だけ
+	if got.From != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q, want noreply@example.com", got.From)
+	}
+	if fmt.Sprint(got.To) != "[manager@example.com]" {
+		t.Errorf("RCPT TO = %v, want [manager@example.com]", got.To)
+	}
+	if got.TLS || got.Auth != "" {
+		t.Errorf("TLS = %v, Auth = %q, want neither", got.TLS, got.Auth)
+	}
+	to, err := got.Header.AddressList("To")
+	if err != nil || len(to) != 1 || to[0].Name != mailManager.Name || to[0].Address != mailManager.Email {
+		t.Errorf("To = %v (%v), want %s <%s>", to, err, mailManager.Name, mailManager.Email)
+	}
+	from, err := got.Header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Name != "休暇申請システム" {
+		t.Errorf("From = %v (%v), want 休暇申請システム <noreply@example.com>", from, err)
+	}
+	if id := got.Header.Get("Message-ID"); !strings.HasSuffix(id, "@example.com>") {
+		t.Errorf("Message-ID = %q, want the sender's domain", id)
+	}
+	if got.Header.Get("Content-Language") != "ja" {
+		t.Errorf("Content-Language = %q, want ja", got.Header.Get("Content-Language"))
+	}
+}
+
+func TestSMTPMailerStartTLSAndAuth(t *testing.T) {
+	srv := smtptest.NewStartTLSServer()
+	defer srv.Close()
+	srv.Username, srv.Password = "mailer", "secret"
+	cfg := mailConfig(srv)
+	cfg.Username, cfg.Password = "mailer", "secret"
+	cfg.StartTLS = drivers.StartTLSRequired
+	cfg.TLSConfig = srv.ClientTLSConfig()
+	n := usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest}
+
+	got := sendOne(t, srv, func() error { return drivers.SMTPMailer{Config: cfg}.NotifyApproverReminder(n) })
+	if !got.TLS || got.Auth != "mailer" {
+		t.Errorf("TLS = %v, Auth = %q, want TLS and mailer", got.TLS, got.Auth)
+	}
+
+	// パスワードの誤りは恒久的なエラー（535）
+	cfg.Password = "wrong"
+	if err := (drivers.SMTPMailer{Config: cfg}).NotifyApproverReminder(n); !errors.Is(err, usecase.ErrInternal) {
+		t.Errorf("wrong password: err = %v, want ErrInternal", err)
+	}
+
+	// STARTTLS を使わない設定なら、TLS なしで送る
+	plain := smtptest.NewStartTLSServer()
+	defer plain.Close()
+	cfg = mailConfig(plain)
+	cfg.StartTLS = drivers.StartTLSOff
+	if got := sendOne(t, plain, func() error { return drivers.SMTPMailer{Config: cfg}.NotifyApproverReminder(n) }); got.TLS {
+		t.Error("StartTLSOff: message was sent over TLS")
+	}
+}
+
+func TestSMTPMailerStartTLSRequiredButUnsupported(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	cfg := mailConfig(srv)
+	cfg.StartTLS = drivers.StartTLSRequired
+
+	err := drivers.SMTPMailer{Config: cfg}.NotifyManagerNewRequest(usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest})
+	if !errors.Is(err, usecase.ErrInternal) {
+		t.Errorf("err = %v, want ErrInternal", err)
+	}
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Errorf("server received %d messages without TLS, want 0", len(msgs))
+	}
+}
+
+func TestSMTPMailerSubjectEncoding(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	m := drivers.SMTPMailer{Config: mailConfig(srv)}
+	// 名前を長くして、件名が複数の encoded-word に分かれるようにする
+	requester := mailAlice
+	requester.Name = "寿限無寿限無五劫の擦り切れ海砂利水魚の水行末雲来末風来末"
+	n := usecase.Notification{Recipient: mailManager, Requester: requester, Request: mailRequest}
+
+	got := sendOne(t, srv, func() error { return m.NotifyEscalation(n) })
+	raw := got.Header.Get("Subject")
+	words := strings.Fields(raw)
+	if len(words) < 2 {
+		t.Fatalf("Subject = %q, want several encoded words", raw)
+	}
+	for _, w := range words {
+		if !strings.HasPrefix(w, "=?ISO-2022-JP?B?") || !strings.HasSuffix(w, "?=") {
+			t.Errorf("word %q is not an ISO-2022-JP encoded word", w)
+		}
+		if len(w) > 75 {
+			t.Errorf("word %q is %d chars, want at most 75", w, len(w))
+		}
+	}
+	if s, want := decodeSubject(t, got.Header), "【エスカレーション】"+requester.Name+"さんの休暇申請の承認をお願いします"; s != want {
+		t.Errorf("Subject = %q, want %q", s, want)
+	}
+
+	// ISO-2022-JP で表せない文字（絵文字）があれば UTF-8 にする
+	requester.Name = "花子🌸"
+	n.Requester = requester
+	got = sendOne(t, srv, func() error { return m.NotifyEscalation(n) })
+	if raw := got.Header.Get("Subject"); !strings.HasPrefix(raw, "=?UTF-8?") {
+		t.Errorf("Subject with emoji = %q, want UTF-8", raw)
+	}
+	if s := decodeSubject(t, got.Header); !strings.Contains(s, "花子🌸さん") {
+		t.Errorf("Subject with emoji = %q", s)
+	}
+
+	// 設定で UTF-8 を選べる
+	cfg := mailConfig(srv)
+	cfg.Charset = drivers.CharsetUTF8
+	got = sendOne(t, srv, func() error { return drivers.SMTPMailer{Config: cfg}.NotifyEscalation(n) })
+	if raw := got.Header.Get("Subject"); !strings.HasPrefix(raw, "=?UTF-8?") {
+		t.Errorf("Subject with Charset UTF-8 = %q", raw)
+	}
+
+	// 英語のメールの件名は ASCII のまま
+	manager := mailManager
+	manager.Locale = domain.LocaleEnglish
+	n = usecase.Notification{Recipient: manager, Requester: mailAlice, Request: mailRequest}
+	got = sendOne(t, srv, func() error { return m.NotifyEscalation(n) })
+	if raw, want := got.Header.Get("Subject"), "[Escalation] Please review the leave request from 山田 花子"; decodeSubject(t, got.Header) != want || strings.Contains(raw, "ISO-2022-JP") {
+		t.Errorf("English Subject = %q, want %q in UTF-8", raw, want)
+	}
+	if got.Header.Get("Content-Language") != "en" {
+		t.Errorf("Content-Language = %q, want en", got.Header.Get("Content-Language"))
+	}
+}
+
+func TestSMTPMailerBodies(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	m := drivers.SMTPMailer{Config: mailConfig(srv)}
+	approved := mailRequest
+	approved.Status = domain.StatusApproved
+	rejected := mailRequest
+	rejected.Status, rejected.DecisionComment = domain.StatusRejected, "繁忙期のため"
+	expired := mailRequest
+	expired.Status = domain.StatusCancelled
+	english := mailAlice
+	english.Locale = domain.LocaleEnglish
+
+	cases := []struct {
+		name      string
+		send      func(usecase.Notification) error
+		n         usecase.Notification
+		subject   string
+		text      []string
+		notInText []string
+		html      []string
+		notInHTML []string
+	}{
+		{
+			name:    "new request",
+			send:    m.NotifyManagerNewRequest,
+			n:       usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest, Link: "https://leave.example.com/r/42"},
+			subject: "【休暇申請】山田 花子さんから休暇申請が届きました",
+			text:    []string{"佐藤 一郎さん", "申請ID：42", "種類：年次有給休暇", "期間：2025年5月12日（月） 〜 2025年5月13日（火）（2日）", "理由：通院 <午後>", "申請の確認：https://leave.example.com/r/42"},
+			// HTML では申請理由をエスケープする
+			html:      []string{"通院 &lt;午後&gt;", "https://leave.example.com/r/42"},
+			notInHTML: []string{"<午後>"},
+		},
+		{
+			name:    "approved by a manager",
+			send:    m.NotifyRequesterDecision,
+			n:       usecase.Notification{Recipient: mailAlice, Requester: mailAlice, Request: approved, Approver: mailManager},
+			subject: "【承認】休暇申請が承認されました",
+			text:    []string{"山田 花子さん", "あなたの休暇申請が佐藤 一郎さんに承認されました。"},
+			html:    []string{"佐藤 一郎さんに<strong>承認</strong>されました"},
+		},
+		{
+			name:      "auto-approved",
+			send:      m.NotifyRequesterDecision,
+			n:         usecase.Notification{Recipient: mailAlice, Requester: mailAlice, Request: approved},
+			subject:   "【承認】休暇申請が承認されました",
+			text:      []string{"開始日の前日まで承認待ちだったため、自動で承認されました。"},
+			notInText: []string{"さんに承認されました"},
+			html:      []string{"<strong>自動で承認</strong>されました"},
+		},
+		{
+			name:    "rejected with a comment",
+			send:    m.NotifyRequesterDecision,
+			n:       usecase.Notification{Recipient: mailAlice, Requester: mailAlice, Request: rejected, Approver: mailManager},
+			subject: "【却下】休暇申請が却下されました",
+			text:    []string{"佐藤 一郎さんに却下されました。", "承認者のコメント：\r\n繁忙期のため"},
+			html:    []string{"繁忙期のため"},
+		},
+		{
+			name:    "expired",
+			send:    m.NotifyRequesterDecision,
+			n:       usecase.Notification{Recipient: mailAlice, Requester: mailAlice, Request: expired},
+			subject: "【期限切れ】休暇申請が取り消されました",
+			text:    []string{"開始日までに承認されなかったため、期限切れとして取り消されました。"},
+		},
+		{
+			name:    "expired in English",
+			send:    m.NotifyRequesterDecision,
+			n:       usecase.Notification{Recipient: english, Requester: english, Request: expired},
+			subject: "[Expired] Your leave request has been cancelled",
+			text:    []string{"Hi 山田 花子,", "was not approved before its start date, so it has been cancelled as expired.", "Mon, May 12, 2025"},
+		},
+		{
+			// チームへの連絡には種類・理由を載せない
+			name:      "team absence",
+			send:      m.NotifyTeamAbsence,
+			n:         usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: approved, Approver: mailManager},
+			subject:   "【休暇のお知らせ】山田 花子さんが2025年5月12日（月）から休みます",
+			text:      []string{"期間：2025年5月12日（月） 〜 2025年5月13日（火）（2日）"},
+			notInText: []string{"年次有給休暇", "通院"},
+			notInHTML: []string{"年次有給休暇", "通院"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := sendOne(t, srv, func() error { return c.send(c.n) })
+			if s := decodeSubject(t, got.Header); s != c.subject {
+				t.Errorf("Subject = %q, want %q", s, c.subject)
+			}
+			// 本文の改行は CRLF にそろえる
+			if strings.Contains(strings.ReplaceAll(got.Text, "\r\n", ""), "\n") {
+				t.Errorf("text has bare LF:\n%q", got.Text)
+			}
+			for _, s := range c.text {
+				if !strings.Contains(got.Text, s) {
+					t.Errorf("text does not contain %q:\n%s", s, got.Text)
+				}
+			}
+			for _, s := range c.notInText {
+				if strings.Contains(got.Text, s) {
+					t.Errorf("text contains %q:\n%s", s, got.Text)
+				}
+			}
+			for _, s := range c.html {
+				if !strings.Contains(got.HTML, s) {
+					t.Errorf("HTML does not contain %q:\n%s", s, got.HTML)
+				}
+			}
+			for _, s := range c.notInHTML {
+				if strings.Contains(got.HTML, s) {
+					t.Errorf("HTML contains %q:\n%s", s, got.HTML)
+				}
+			}
+		})
+	}
+}
+
+func TestSMTPMailerErrors(t *testing.T) {
+	srv := smtptest.NewServer()
+	defer srv.Close()
+	srv.RejectRcpt = func(addr string) bool { return addr == "gone@example.com" }
+	m := drivers.SMTPMailer{Config: mailConfig(srv)}
+	n := usecase.Notification{Recipient: mailManager, Requester: mailAlice, Request: mailRequest}
+
+	noEmail := n
+	noEmail.Recipient.Email = ""
+	if err := m.NotifyManagerNewRequest(noEmail); !errors.Is(err, usecase.ErrValidation) {
+		t.Errorf("no email: err = %v, want ErrValidation", err)
+	}
+
+	// 550（宛先なし）は再試行しても直らない
+	rejected := n
+	rejected.Recipient.Email = "gone@example.com"
+	if err := m.NotifyManagerNewRequest(rejected); !errors.Is(err, usecase.ErrInternal) {
+		t.Errorf("rejected recipient: err = %v, want ErrInternal", err)
+	}
+
+	// つながらないサーバは一時的なエラー
+	down := smtptest.NewServer()
+	cfg := mailConfig(down)
+	down.Close()
+	if err := (drivers.SMTPMailer{Config: cfg}).NotifyManagerNewRequest(n); !errors.Is(err, usecase.ErrUnavailable) {
+		t.Errorf("server down: err = %v, want ErrUnavailable", err)
+	}
+
+	bad := mailConfig(srv)
+	bad.From = "not an address"
+	if err := (drivers.SMTPMailer{Config: bad}).NotifyManagerNewRequest(n); !errors.Is(err, usecase.ErrInternal) {
+		t.Errorf("bad From: err = %v, want ErrInternal", err)
+	}
+	if msgs := srv.Messages(); len(msgs) != 0 {
+		t.Errorf("server received %d messages, want 0", len(msgs))
+	}
+}
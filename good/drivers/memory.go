@@ -0,0 +1,441 @@
+package drivers
+
+// Framework & Drivers層（メモリ上のリポジトリ）
+// --------------------------------------------------------
+// UseCase層のリポジトリ系ポートをすべてメモリ上で実装する。
+// - ローカルでのデモや単体テストで、PostgreSQL なしにアプリを動かすために使う
+// - 1つの MemoryStore を複数のリポジトリで共有し、sync.RWMutex で排他制御する
+// - 採番・並び順・エラーの種類（見つからない・重複・参照先なし）は SQL 版と揃える
+// --------------------------------------------------------
+// 保存・取得のたびにコピーする（通知チャネルのスライスも複製する）ので、呼び出し側が書き換えても保存内容は変わらない。
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// MemoryStore：メモリ上のリポジトリが共有するデータ
+type MemoryStore struct {
+	mu          sync.RWMutex
+	employees   map[string]domain.Employee
+	leaves      map[string]memoryLeave
+	attachments map[string]memoryAttachment
+	leaveSeq    int64 // 休暇申請の採番（Postgres の serial に相当）
+	attachSeq   int64 // 添付ファイルの採番
+}
+
+// memoryLeave：休暇申請と採番順（並び替えの同順位の決定に使う）
+type memoryLeave struct {
+	req domain.LeaveRequest
+	seq int64
+}
+
+// memoryAttachment：添付ファイルの情報と採番順
+type memoryAttachment struct {
+	a   domain.Attachment
+	seq int64
+}
+
+// NewMemoryStore は空の MemoryStore を返す。
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		employees:   map[string]domain.Employee{},
+		leaves:      map[string]memoryLeave{},
+		attachments: map[string]memoryAttachment{},
+	}
+}
+
+// Seed は従業員を検証なしでまとめて登録する（デモ・テストの初期データ用）。
+// 同じIDの従業員がいれば上書きする。
+func (s *MemoryStore) Seed(emps ...domain.Employee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range emps {
+		s.employees[e.ID] = cloneEmployee(e)
+	}
+}
+
+// Employees は従業員リポジトリを返す。
+func (s *MemoryStore) Employees() MemoryEmployeeRepo { return MemoryEmployeeRepo{s} }
+
+// Leaves は休暇申請リポジトリを返す。
+func (s *MemoryStore) Leaves() MemoryLeaveRepo { return MemoryLeaveRepo{s} }
+
+// Attachments は添付ファイル情報のリポジトリを返す。
+func (s *MemoryStore) Attachments() MemoryAttachmentRepo { return MemoryAttachmentRepo{s} }
+
+// nextID は seq を進めて次のIDを採番する（呼び出し側でロックを取っていること）。
+func nextID(seq *int64) (string, int64) {
+	*seq++
+	return strconv.FormatInt(*seq, 10), *seq
+}
+
+// memoryError は SQL 版の translateSQLError と同じ種類のエラーを作る。
+func memoryError(kind error, op, format string, args ...any) error {
+	return usecase.NewError(kind, op, fmt.Errorf(format, args...))
+}
+
+// --------------------------------------------------------
+// 従業員
+// --------------------------------------------------------
+
+// MemoryEmployeeRepo はメモリ上の従業員リポジトリ。
+// EmployeeRepo・EmployeeLister・EmployeeWriter を満たす。
+type MemoryEmployeeRepo struct{ s *MemoryStore }
+
+// FindByID は従業員IDで Employee を検索する。
+func (r MemoryEmployeeRepo) FindByID(id string) (domain.Employee, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	e, ok := r.s.employees[id]
+	if !ok {
+		return domain.Employee{}, memoryError(usecase.ErrEmployeeNotFound, "MemoryEmployeeRepo.FindByID", "id %q", id)
+	}
+	return cloneEmployee(e), nil
+}
+
+// List は全従業員をID順に取得する。
+func (r MemoryEmployeeRepo) List() ([]domain.Employee, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	emps := make([]domain.Employee, 0, len(r.s.employees))
+	for _, e := range r.s.employees {
+		emps = append(emps, cloneEmployee(e))
+	}
+	sort.Slice(emps, func(i, j int) bool { return emps[i].ID < emps[j].ID })
+	return emps, nil
+}
+
+// Create は従業員を登録する。IDの重複は ErrEmployeeExists、存在しない上長の指定は ErrValidation。
+func (r MemoryEmployeeRepo) Create(e *domain.Employee) error {
+	const op = "MemoryEmployeeRepo.Create"
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.employees[e.ID]; ok {
+		return memoryError(usecase.ErrEmployeeExists, op, "id %q", e.ID)
+	}
+	if err := r.checkManager(op, e); err != nil {
+		return err
+	}
+	r.s.employees[e.ID] = cloneEmployee(*e)
+	return nil
+}
+
+// Update は従業員情報を更新する。
+func (r MemoryEmployeeRepo) Update(e *domain.Employee) error {
+	const op = "MemoryEmployeeRepo.Update"
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.employees[e.ID]; !ok {
+		return memoryError(usecase.ErrEmployeeNotFound, op, "id %q", e.ID)
+	}
+	if err := r.checkManager(op, e); err != nil {
+		return err
+	}
+	r.s.employees[e.ID] = cloneEmployee(*e)
+	return nil
+}
+
+// checkManager は上長の参照先が存在するかを確認する（外部キー制約に相当）。
+func (r MemoryEmployeeRepo) checkManager(op string, e *domain.Employee) error {
+	if e.ManagerID == "" || e.ManagerID == e.ID {
+		return nil
+	}
+	if _, ok := r.s.employees[e.ManagerID]; !ok {
+		return memoryError(usecase.ErrValidation, op, "manager %q not found", e.ManagerID)
+	}
+	return nil
+}
+
+// --------------------------------------------------------
+// 休暇申請
+// --------------------------------------------------------
+
+// MemoryLeaveRepo はメモリ上の休暇申請リポジトリ。
+// LeaveRepo・LeaveBatchCreator・LeaveFinder・LeaveLister・PendingLeaveRepo・LeavePeriodRepo・EmployeeLeavesRepo を満たす。
+type MemoryLeaveRepo struct{ s *MemoryStore }
+
+// CountThisFiscalYear は年度内（start から1年間）に作成された申請のうち、申請回数に数えるものの件数を数える。
+// メモリ上なので集計表は持たず、その都度数える。
+func (r MemoryLeaveRepo) CountThisFiscalYear(empID string, start time.Time) (int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	end := start.AddDate(1, 0, 0)
+	c := 0
+	for _, l := range r.s.leaves {
+		if l.req.EmployeeID == empID && !l.req.CreatedAt.Before(start) && l.req.CreatedAt.Before(end) &&
+			l.req.Status.CountsTowardQuota() {
+			c++
+		}
+	}
+	return c, nil
+}
+
+// Create は休暇申請を登録し、採番したIDを req.ID に設定する。
+func (r MemoryLeaveRepo) Create(req *domain.LeaveRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if err := r.checkEmployee("MemoryLeaveRepo.Create", req); err != nil {
+		return err
+	}
+	r.insert(req)
+	return nil
+}
+
+// CreateBatch は複数の休暇申請をまとめて登録する。1件でも登録できなければ何も登録しない。
+func (r MemoryLeaveRepo) CreateBatch(reqs []*domain.LeaveRequest) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, req := range reqs {
+		if err := r.checkEmployee("MemoryLeaveRepo.CreateBatch", req); err != nil {
+			return err
+		}
+	}
+	for _, req := range reqs {
+		r.insert(req)
+	}
+	return nil
+}
+
+// FindByID は申請IDで休暇申請を取得する。
+func (r MemoryLeaveRepo) FindByID(id string) (domain.LeaveRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	l, ok := r.s.leaves[id]
+	if !ok {
+		return domain.LeaveRequest{}, memoryError(usecase.ErrLeaveRequestNotFound, "MemoryLeaveRepo.FindByID", "id %q", id)
+	}
+	return l.req, nil
+}
+
+// ListPending は承認待ちの申請を古い順に取得する。
+func (r MemoryLeaveRepo) ListPending() ([]domain.LeaveRequest, error) {
+	return r.list(func(req domain.LeaveRequest) bool { return req.Status == domain.StatusPending }, byCreatedAt), nil
+}
+
+// ListPendingByEmployee は指定した従業員の承認待ちの申請を古い順に取得する。
+func (r MemoryLeaveRepo) ListPendingByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.list(func(req domain.LeaveRequest) bool {
+		return req.EmployeeID == employeeID && req.Status == domain.StatusPending
+	}, byCreatedAt), nil
+}
+
+// ListByEmployee は指定した従業員の申請をステータスを問わず古い順に取得する。
+func (r MemoryLeaveRepo) ListByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.list(func(req domain.LeaveRequest) bool { return req.EmployeeID == employeeID }, byCreatedAt), nil
+}
+
+// ListOverlapping は期間 [from, to] に一部でも重なる休暇申請を取得する。
+func (r MemoryLeaveRepo) ListOverlapping(from, to time.Time) ([]domain.LeaveRequest, error) {
+	return r.list(func(req domain.LeaveRequest) bool {
+		return !req.From.After(to) && !req.To.Before(from)
+	}, byFromDate), nil
+}
+
+// Update は保存されているステータスが expected のときだけ、申請の状態（ステータス・承認者・催促日時・決定日時・
+// 判断コメント）を更新する。SQL 版と同じく、それ以外の項目は変更しない。
+func (r MemoryLeaveRepo) Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	l, ok := r.s.leaves[req.ID]
+	if !ok {
+		return memoryError(usecase.ErrLeaveRequestNotFound, "MemoryLeaveRepo.Update", "id %q", req.ID)
+	}
+	if l.req.Status != expected {
+		return memoryError(usecase.ErrLeaveRequestChanged, "MemoryLeaveRepo.Update", "id %q is %s, not %s", req.ID, l.req.Status, expected)
+	}
+	l.req.Status = req.Status
+	l.req.ApproverID = req.ApproverID
+	l.req.AssignedAt = req.AssignedAt
+	l.req.RemindedAt = req.RemindedAt
+	l.req.DecidedAt = req.DecidedAt
+	l.req.DecisionComment = req.DecisionComment
+	r.s.leaves[req.ID] = l
+	return nil
+}
+
+// checkEmployee は申請者が存在するかを確認する（外部キー制約に相当）。
+func (r MemoryLeaveRepo) checkEmployee(op string, req *domain.LeaveRequest) error {
+	if _, ok := r.s.employees[req.EmployeeID]; !ok {
+		return memoryError(usecase.ErrValidation, op, "employee %q not found", req.EmployeeID)
+	}
+	return nil
+}
+
+// insert は採番して保存する（呼び出し側でロックを取っていること）。
+func (r MemoryLeaveRepo) insert(req *domain.LeaveRequest) {
+	id, seq := nextID(&r.s.leaveSeq)
+	req.ID = id
+	r.s.leaves[id] = memoryLeave{req: *req, seq: seq}
+}
+
+// 並び順（同順位は採番順）
+var (
+	byCreatedAt = func(a, b memoryLeave) bool {
+		if !a.req.CreatedAt.Equal(b.req.CreatedAt) {
+			return a.req.CreatedAt.Before(b.req.CreatedAt)
+		}
+		return a.seq < b.seq
+	}
+	byFromDate = func(a, b memoryLeave) bool {
+		if !a.req.From.Equal(b.req.From) {
+			return a.req.From.Before(b.req.From)
+		}
+		return a.seq < b.seq
+	}
+)
+
+// list は条件に合う申請を less の順に並べて返す。
+func (r MemoryLeaveRepo) list(match func(domain.LeaveRequest) bool, less func(a, b memoryLeave) bool) []domain.LeaveRequest {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var hits []memoryLeave
+	for _, l := range r.s.leaves {
+		if match(l.req) {
+			hits = append(hits, l)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return less(hits[i], hits[j]) })
+	var reqs []domain.LeaveRequest
+	for _, l := range hits {
+		reqs = append(reqs, l.req)
+	}
+	return reqs
+}
+
+// --------------------------------------------------------
+// 添付ファイル
+// --------------------------------------------------------
+
+// MemoryAttachmentRepo はメモリ上の添付ファイル情報のリポジトリ。AttachmentRepo を満たす。
+type MemoryAttachmentRepo struct{ s *MemoryStore }
+
+// Create は添付ファイルの情報を登録し、採番したIDを a.ID に設定する。
+func (r MemoryAttachmentRepo) Create(a *domain.Attachment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.leaves[a.RequestID]; !ok {
+		return memoryError(usecase.ErrValidation, "MemoryAttachmentRepo.Create", "leave request %q not found", a.RequestID)
+	}
+	id, seq := nextID(&r.s.attachSeq)
+	a.ID = id
+	r.s.attachments[id] = memoryAttachment{a: *a, seq: seq}
+	return nil
+}
+
+// FindByID は添付ファイルIDで情報を取得する。
+func (r MemoryAttachmentRepo) FindByID(id string) (domain.Attachment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	m, ok := r.s.attachments[id]
+	if !ok {
+		return domain.Attachment{}, memoryError(usecase.ErrAttachmentNotFound, "MemoryAttachmentRepo.FindByID", "id %q", id)
+	}
+	return m.a, nil
+}
+
+// ListByRequest は休暇申請の添付ファイルを添付順に取得する。
+func (r MemoryAttachmentRepo) ListByRequest(requestID string) ([]domain.Attachment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var hits []memoryAttachment
+	for _, m := range r.s.attachments {
+		if m.a.RequestID == requestID {
+			hits = append(hits, m)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if !hits[i].a.UploadedAt.Equal(hits[j].a.UploadedAt) {
+			return hits[i].a.UploadedAt.Before(hits[j].a.UploadedAt)
+		}
+		return hits[i].seq < hits[j].seq
+	})
+	var as []domain.Attachment
+	for _, m := range hits {
+		as = append(as, m.a)
+	}
+	return as, nil
+}
+
+// --------------------------------------------------------
+// 検証なしの直接書き換え（FileStore の復元・巻き戻し用）
+// --------------------------------------------------------
+
+func (s *MemoryStore) putEmployee(e domain.Employee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.employees[e.ID] = cloneEmployee(e)
+}
+
+func (s *MemoryStore) removeEmployee(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.employees, id)
+}
+
+// putLeave は申請をIDのまま保存し、採番をそのIDより先へ進める。
+func (s *MemoryStore) putLeave(req domain.LeaveRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq, _ := strconv.ParseInt(req.ID, 10, 64)
+	s.leaves[req.ID] = memoryLeave{req: req, seq: seq}
+	s.leaveSeq = max(s.leaveSeq, seq)
+}
+
+func (s *MemoryStore) removeLeave(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.leaves, id)
+}
+
+// putAttachment は添付ファイルの情報をIDのまま保存し、採番をそのIDより先へ進める。
+func (s *MemoryStore) putAttachment(a domain.Attachment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seq, _ := strconv.ParseInt(a.ID, 10, 64)
+	s.attachments[a.ID] = memoryAttachment{a: a, seq: seq}
+	s.attachSeq = max(s.attachSeq, seq)
+}
+
+func (s *MemoryStore) removeAttachment(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attachments, id)
+}
+
+// snapshot は保存内容の全体を（従業員はID順、それ以外は採番順に）返す。
+func (s *MemoryStore) snapshot() ([]domain.Employee, []domain.LeaveRequest, []domain.Attachment) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	emps := make([]domain.Employee, 0, len(s.employees))
+	for _, e := range s.employees {
+		emps = append(emps, cloneEmployee(e))
+	}
+	sort.Slice(emps, func(i, j int) bool { return emps[i].ID < emps[j].ID })
+	ls := make([]memoryLeave, 0, len(s.leaves))
+	for _, l := range s.leaves {
+		ls = append(ls, l)
+	}
+	sort.Slice(ls, func(i, j int) bool { return ls[i].seq < ls[j].seq })
+	reqs := make([]domain.LeaveRequest, len(ls))
+	for i, l := range ls {
+		reqs[i] = l.req
+	}
+	as := make([]memoryAttachment, 0, len(s.attachments))
+	for _, m := range s.attachments {
+		as = append(as, m)
+	}
+	sort.Slice(as, func(i, j int) bool { return as[i].seq < as[j].seq })
+	atts := make([]domain.Attachment, len(as))
+	for i, m := range as {
+		atts[i] = m.a
+	}
+	return emps, reqs, atts
+}
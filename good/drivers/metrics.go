@@ -0,0 +1,88 @@
+package drivers
+
+// 計測（メトリクス）の具象実装
+// --------------------------------------------------------
+// 操作ごとの呼び出し回数・エラー回数（種類別）・リトライ回数・所要時間の分布（ヒストグラム）を集計する。
+// 集計結果は expvar で公開し、/debug/vars から JSON で参照できる。
+// --------------------------------------------------------
+
+import (
+	"encoding/json"
+	"expvar"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// latencyBucketsMs：所要時間ヒストグラムの境界（ミリ秒、上限を含む）
+var latencyBucketsMs = []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+// Metrics：操作ごとの計測値の集計
+type Metrics struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+// opStats：1つの操作の計測値
+type opStats struct {
+	Calls        int64            `json:"calls"`
+	Errors       int64            `json:"errors"`
+	ErrorsByKind map[string]int64 `json:"errorsByKind"`
+	Retries      int64            `json:"retries"`
+	LatencySumMs float64          `json:"latencySumMs"`
+	Buckets      []bucket         `json:"latencyBucketsMs"`
+}
+
+// bucket：ヒストグラムの1区間（LeMs 以下の件数。累積ではない）
+type bucket struct {
+	LeMs  float64 `json:"le"` // 最後の区間は -1（上限なし）
+	Count int64   `json:"count"`
+}
+
+// NewMetrics は集計器を作り、name で expvar に公開する。
+// name が空なら公開しない（テストなどで複数作る場合）。
+func NewMetrics(name string) *Metrics {
+	m := &Metrics{ops: map[string]*opStats{}}
+	if name != "" {
+		expvar.Publish(name, m)
+	}
+	return m
+}
+
+// Observe は1回の呼び出し結果を記録する。attempts はリトライを含めた試行回数。
+func (m *Metrics) Observe(op string, d time.Duration, attempts int, err error) {
+	ms := float64(d) / float64(time.Millisecond)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.ops[op]
+	if !ok {
+		s = &opStats{ErrorsByKind: map[string]int64{}, Buckets: make([]bucket, len(latencyBucketsMs)+1)}
+		for i, le := range latencyBucketsMs {
+			s.Buckets[i].LeMs = le
+		}
+		s.Buckets[len(latencyBucketsMs)].LeMs = -1
+		m.ops[op] = s
+	}
+	s.Calls++
+	s.Retries += int64(attempts - 1)
+	s.LatencySumMs += ms
+	i := sort.SearchFloat64s(latencyBucketsMs, ms)
+	s.Buckets[i].Count++
+	if err != nil {
+		s.Errors++
+		s.ErrorsByKind[usecase.KindOf(err).Error()]++
+	}
+}
+
+// String は集計結果をJSONで返す（expvar.Var の実装）。
+func (m *Metrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, err := json.Marshal(m.ops)
+	if err != nil {
+		return "{}"
+	}
+	return string(b)
+}
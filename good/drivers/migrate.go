@@ -0,0 +1,254 @@
+package drivers
+
+// Framework & Drivers層（SQL データベースのスキーマ移行）
+// --------------------------------------------------------
+// SQL 版リポジトリが使うテーブルの DDL を、方言ごと・バージョン付きのファイルとして埋め込む。
+// - migrations/<方言>/NNNN_名前.up.sql と NNNN_名前.down.sql を1組として扱う（NNNN は 1 からの連番）
+// - どの方言も同じ番号で同じ変更を持つ（バージョンの意味を方言によらず揃える）
+// - 適用済みのバージョンは schema_migrations テーブルに記録する
+// - 1つのバージョンを1トランザクションで適用・取り消しする
+// --------------------------------------------------------
+// スキーマを変えるときは、既存のファイルは書き換えずに、すべての方言に新しい番号のファイルを追加する。
+// --------------------------------------------------------
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// Migration：1つのバージョンのスキーマ変更
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// LoadMigrations は fsys 直下の *.up.sql / *.down.sql を読み込み、バージョン順に返す。
+// 番号の抜け・重複や、up と down の片方しかないバージョンはエラーにする。
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, name := range names {
+		base, dir, ok := strings.Cut(strings.TrimSuffix(name, ".sql"), ".")
+		if !ok || (dir != "up" && dir != "down") {
+			return nil, fmt.Errorf("migration %s: file name must end with .up.sql or .down.sql", name)
+		}
+		num, label, _ := strings.Cut(base, "_")
+		v, err := strconv.Atoi(num)
+		if err != nil || v <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version number", name)
+		}
+		body, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		m := byVersion[v]
+		if m == nil {
+			m = &Migration{Version: v, Name: label}
+			byVersion[v] = m
+		} else if m.Name != label {
+			return nil, fmt.Errorf("migration %s: version %d is also used by %q", name, v, m.Name)
+		}
+		if dir == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+	ms := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		ms = append(ms, *m)
+	}
+	sort.Slice(ms, func(i, j int) bool { return ms[i].Version < ms[j].Version })
+	for i, m := range ms {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration version %d is missing", i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %s: both up and down files are required", m.FileName())
+		}
+	}
+	return ms, nil
+}
+
+// EmbeddedMigrations はこのパッケージに埋め込まれた方言 d のスキーマ変更を返す。
+func EmbeddedMigrations(d Dialect) []Migration {
+	sub, _ := fs.Sub(migrationFiles, "migrations/"+d.Name())
+	ms, err := LoadMigrations(sub)
+	if err != nil {
+		panic(err) // 埋め込みファイルの誤りはビルドした時点の不具合
+	}
+	return ms
+}
+
+// Migrator：スキーマ変更の適用・取り消しを行う
+type Migrator struct {
+	DB         *sql.DB
+	Dialect    Dialect     // nil なら PostgreSQL
+	Migrations []Migration // バージョン順
+}
+
+// NewMigrator は方言 d（nil なら PostgreSQL）の埋め込まれたスキーマ変更を使う Migrator を返す。
+func NewMigrator(db *sql.DB, d Dialect) Migrator {
+	d = orPostgres(d)
+	return Migrator{DB: db, Dialect: d, Migrations: EmbeddedMigrations(d)}
+}
+
+// Latest はこのアプリが前提とするスキーマのバージョンを返す。
+func (m Migrator) Latest() int { return len(m.Migrations) }
+
+// createSchemaMigrations：方言ごとの schema_migrations の DDL
+var createSchemaMigrations = map[string]string{
+	"postgres": `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    name       TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`,
+	"mysql": `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version    INTEGER PRIMARY KEY,
+    name       VARCHAR(255) NOT NULL,
+    applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+}
+
+// Current は適用済みの最新バージョンを返す（未適用なら 0）。
+func (m Migrator) Current() (int, error) {
+	ddl, ok := createSchemaMigrations[m.dialect().Name()]
+	if !ok {
+		return 0, usecase.NewError(usecase.ErrInternal, "Migrator.Current",
+			fmt.Errorf("no schema_migrations table for dialect %q", m.dialect().Name()))
+	}
+	if _, err := m.DB.Exec(ddl); err != nil {
+		return 0, translateSQLError("Migrator.Current", err)
+	}
+	var v int
+	err := m.DB.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&v)
+	return v, translateSQLError("Migrator.Current", err)
+}
+
+// Up は target のバージョンまで順に適用し、適用したものを返す。target が 0 以下なら最新まで。
+func (m Migrator) Up(target int) ([]Migration, error) {
+	if target <= 0 || target > m.Latest() {
+		target = m.Latest()
+	}
+	cur, err := m.Current()
+	if err != nil {
+		return nil, err
+	}
+	if cur > m.Latest() {
+		return nil, usecase.NewError(usecase.ErrConflict, "Migrator.Up",
+			fmt.Errorf("schema version %d is newer than this binary knows (%d)", cur, m.Latest()))
+	}
+	if cur >= target {
+		return nil, nil
+	}
+	var applied []Migration
+	for _, mg := range m.Migrations[cur:target] {
+		if err := m.step(mg, true); err != nil {
+			return applied, err
+		}
+		applied = append(applied, mg)
+	}
+	return applied, nil
+}
+
+// Down は target のバージョンまで新しい順に取り消し、取り消したものを返す。
+func (m Migrator) Down(target int) ([]Migration, error) {
+	if target < 0 {
+		target = 0
+	}
+	cur, err := m.Current()
+	if err != nil {
+		return nil, err
+	}
+	if cur > m.Latest() {
+		return nil, usecase.NewError(usecase.ErrConflict, "Migrator.Down",
+			fmt.Errorf("schema version %d is newer than this binary knows (%d)", cur, m.Latest()))
+	}
+	var reverted []Migration
+	for v := cur; v > target; v-- {
+		mg := m.Migrations[v-1]
+		if err := m.step(mg, false); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, mg)
+	}
+	return reverted, nil
+}
+
+func (m Migrator) dialect() Dialect { return orPostgres(m.Dialect) }
+
+// step は1つのバージョンを1トランザクションで適用（up）または取り消す（down）。
+// schema_migrations をロックしてから現在のバージョンを確かめるので、同時に実行しても二重には適用されない。
+// （MySQL は DDL の時点でトランザクションを確定させるので、途中で失敗すると一部だけ適用された状態になる）
+func (m Migrator) step(mg Migration, up bool) error {
+	op := "Migrator.step(" + mg.FileName() + ")"
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return translateSQLError(op, err)
+	}
+	defer tx.Rollback() // Commit 後は何も起きない
+
+	c := newSQLConn(tx, m.Dialect)
+	lock, suffix := c.d.LockTable("schema_migrations", true)
+	if lock != "" {
+		if _, err := c.Exec(lock); err != nil {
+			return translateSQLError(op, err)
+		}
+	}
+	var cur int
+	if err := c.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations` + suffix).Scan(&cur); err != nil {
+		return translateSQLError(op, err)
+	}
+	want := mg.Version - 1 // up の前提となるバージョン
+	body, record := mg.Up, `INSERT INTO schema_migrations(version, name) VALUES($1, $2)`
+	if !up {
+		want = mg.Version
+		body, record = mg.Down, `DELETE FROM schema_migrations WHERE version=$1 AND name=$2`
+	}
+	if cur != want {
+		return usecase.NewError(usecase.ErrConflict, op, fmt.Errorf("schema version changed concurrently (now %d)", cur))
+	}
+	for _, stmt := range c.d.Statements(body) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return translateSQLError(op, err)
+		}
+	}
+	if _, err := c.Exec(record, mg.Version, mg.Name); err != nil {
+		return translateSQLError(op, err)
+	}
+	return translateSQLError(op, tx.Commit())
+}
+
+// CheckVersion はスキーマがこのアプリの前提とするバージョンかを確認する。
+// 古い・新しいいずれの場合も ErrUnavailable を返し、`migrate` サブコマンドでの対処を促す。
+func (m Migrator) CheckVersion() error {
+	cur, err := m.Current()
+	if err != nil {
+		return err
+	}
+	if cur != m.Latest() {
+		return usecase.NewError(usecase.ErrUnavailable, "Migrator.CheckVersion",
+			fmt.Errorf("schema version is %d but this binary expects %d (run `migrate up`)", cur, m.Latest()))
+	}
+	return nil
+}
+
+// FileName は表示用のファイル名（拡張子なし）を返す。
+func (mg Migration) FileName() string {
+	return fmt.Sprintf("%04d_%s", mg.Version, mg.Name)
+}
@@ -0,0 +1,15 @@
+-- 従業員
+CREATE TABLE employees (
+    id           VARCHAR(64) PRIMARY KEY,
+    name         VARCHAR(255) NOT NULL DEFAULT '',
+    email        VARCHAR(255),
+    hire_date    DATE NOT NULL,
+    manager_id   VARCHAR(64),
+    department   VARCHAR(255),
+    role         VARCHAR(16) NOT NULL DEFAULT 'EMPLOYEE' CHECK (role IN ('EMPLOYEE', 'HR')),
+    left_on      DATE,
+    frozen_quota INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (manager_id) REFERENCES employees (id)
+);
+
+CREATE INDEX employees_manager_id_idx ON employees (manager_id);
@@ -0,0 +1,25 @@
+-- 休暇申請（MySQL。時刻は UTC の DATETIME で持つ）
+CREATE TABLE leave_requests (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    employee_id VARCHAR(64) NOT NULL,
+    leave_type  VARCHAR(32) NOT NULL DEFAULT 'PAID',
+    reason      TEXT NOT NULL,
+    from_date   DATE NOT NULL,
+    to_date     DATE NOT NULL,
+    status      VARCHAR(32) NOT NULL,
+    created_at  DATETIME(6) NOT NULL,
+    approver_id VARCHAR(64),
+    assigned_at DATETIME(6),
+    reminded_at DATETIME(6),
+    decided_at  DATETIME(6),
+    CHECK (from_date <= to_date),
+    FOREIGN KEY (employee_id) REFERENCES employees (id),
+    FOREIGN KEY (approver_id) REFERENCES employees (id)
+);
+
+-- 年度内の申請回数（CountThisFiscalYear）
+CREATE INDEX leave_requests_employee_created_idx ON leave_requests (employee_id, created_at);
+-- 承認待ちの一覧（ListPending / ListPendingByEmployee）
+CREATE INDEX leave_requests_status_created_idx ON leave_requests (status, created_at);
+-- 期間で絞り込む集計（ListOverlapping）
+CREATE INDEX leave_requests_period_idx ON leave_requests (from_date, to_date);
@@ -0,0 +1,15 @@
+-- 添付ファイル（中身は BlobStore に保存し、ここには保存先のキーだけを持つ）
+CREATE TABLE attachments (
+    id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    request_id   BIGINT NOT NULL,
+    file_name    VARCHAR(255) NOT NULL,
+    content_type VARCHAR(255) NOT NULL,
+    size         BIGINT NOT NULL,
+    blob_key     VARCHAR(255) NOT NULL UNIQUE,
+    uploaded_by  VARCHAR(64) NOT NULL,
+    uploaded_at  DATETIME(6) NOT NULL,
+    FOREIGN KEY (request_id) REFERENCES leave_requests (id),
+    FOREIGN KEY (uploaded_by) REFERENCES employees (id)
+);
+
+CREATE INDEX attachments_request_uploaded_idx ON attachments (request_id, uploaded_at);
@@ -0,0 +1,2 @@
+-- 通知メールの言語（ja / en。NULL なら既定の言語）
+ALTER TABLE employees ADD COLUMN locale VARCHAR(16);
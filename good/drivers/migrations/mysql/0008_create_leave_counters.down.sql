@@ -0,0 +1 @@
+DROP TABLE leave_counters;
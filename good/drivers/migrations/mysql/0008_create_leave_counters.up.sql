@@ -0,0 +1,19 @@
+-- 従業員×会計年度ごとの申請回数（年度内の申請回数の判定に使う集計表）
+-- leave_requests を登録・更新するトランザクションの中で一緒に更新する。
+CREATE TABLE leave_counters (
+    employee_id       VARCHAR(64) NOT NULL,
+    fiscal_year_start DATE NOT NULL,
+    requests          INTEGER NOT NULL DEFAULT 0 CHECK (requests >= 0),
+    PRIMARY KEY (employee_id, fiscal_year_start),
+    FOREIGN KEY (employee_id) REFERENCES employees (id)
+);
+
+-- 既存の申請から作る（承認待ち・承認済みだけを数え、年度は4月1日（UTC）から。created_at は UTC で保存してある）。
+-- 年度の定義を変えている場合は、移行の後に `rebuild-counters` で作り直すこと。
+INSERT INTO leave_counters (employee_id, fiscal_year_start, requests)
+SELECT employee_id,
+       DATE(CONCAT(YEAR(created_at) - IF(MONTH(created_at) < 4, 1, 0), '-04-01')),
+       COUNT(*)
+FROM leave_requests
+WHERE status IN ('PENDING', 'APPROVED')
+GROUP BY 1, 2;
@@ -0,0 +1,14 @@
+-- 従業員
+CREATE TABLE employees (
+    id           TEXT PRIMARY KEY,
+    name         TEXT NOT NULL DEFAULT '',
+    email        TEXT,
+    hire_date    DATE NOT NULL,
+    manager_id   TEXT REFERENCES employees (id),
+    department   TEXT,
+    role         TEXT NOT NULL DEFAULT 'EMPLOYEE' CHECK (role IN ('EMPLOYEE', 'HR')),
+    left_on      DATE,
+    frozen_quota INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX employees_manager_id_idx ON employees (manager_id);
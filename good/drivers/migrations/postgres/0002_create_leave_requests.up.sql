@@ -0,0 +1,23 @@
+-- 休暇申請
+CREATE TABLE leave_requests (
+    id          BIGSERIAL PRIMARY KEY,
+    employee_id TEXT NOT NULL REFERENCES employees (id),
+    leave_type  TEXT NOT NULL DEFAULT 'PAID',
+    reason      TEXT NOT NULL DEFAULT '',
+    from_date   DATE NOT NULL,
+    to_date     DATE NOT NULL,
+    status      TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    approver_id TEXT REFERENCES employees (id),
+    assigned_at TIMESTAMPTZ,
+    reminded_at TIMESTAMPTZ,
+    decided_at  TIMESTAMPTZ,
+    CHECK (from_date <= to_date)
+);
+
+-- 年度内の申請回数（CountThisFiscalYear）
+CREATE INDEX leave_requests_employee_created_idx ON leave_requests (employee_id, created_at);
+-- 承認待ちの一覧（ListPending / ListPendingByEmployee）
+CREATE INDEX leave_requests_status_created_idx ON leave_requests (status, created_at);
+-- 期間で絞り込む集計（ListOverlapping）
+CREATE INDEX leave_requests_period_idx ON leave_requests (from_date, to_date);
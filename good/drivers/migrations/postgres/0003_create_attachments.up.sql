@@ -0,0 +1,13 @@
+-- 添付ファイル（中身は BlobStore に保存し、ここには保存先のキーだけを持つ）
+CREATE TABLE attachments (
+    id           BIGSERIAL PRIMARY KEY,
+    request_id   BIGINT NOT NULL REFERENCES leave_requests (id),
+    file_name    TEXT NOT NULL,
+    content_type TEXT NOT NULL,
+    size         BIGINT NOT NULL,
+    blob_key     TEXT NOT NULL UNIQUE,
+    uploaded_by  TEXT NOT NULL REFERENCES employees (id),
+    uploaded_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX attachments_request_uploaded_idx ON attachments (request_id, uploaded_at);
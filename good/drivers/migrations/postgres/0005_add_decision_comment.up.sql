@@ -0,0 +1,2 @@
+-- 承認者の判断コメント（却下・差し戻しの理由など）
+ALTER TABLE leave_requests ADD COLUMN decision_comment TEXT;
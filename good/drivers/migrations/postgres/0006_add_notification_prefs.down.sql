@@ -0,0 +1,4 @@
+ALTER TABLE employees
+    DROP COLUMN notify_channels,
+    DROP COLUMN quiet_hours,
+    DROP COLUMN quiet_time_zone;
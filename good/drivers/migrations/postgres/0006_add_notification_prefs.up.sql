@@ -0,0 +1,7 @@
+-- 通知の受け取り方（NULL ならシステムの既定）
+-- notify_channels：カンマ区切りのチャネル（email, chat）
+-- quiet_hours    ：通知を控える時間帯（"22:00-07:00"）。quiet_time_zone の時刻で数える
+ALTER TABLE employees
+    ADD COLUMN notify_channels TEXT,
+    ADD COLUMN quiet_hours     TEXT,
+    ADD COLUMN quiet_time_zone TEXT;
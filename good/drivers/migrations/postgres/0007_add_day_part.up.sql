@@ -0,0 +1,2 @@
+-- 半日休の時間帯（AM / PM。NULL なら終日）
+ALTER TABLE leave_requests ADD COLUMN day_part TEXT;
@@ -0,0 +1,19 @@
+-- 従業員×会計年度ごとの申請回数（年度内の申請回数の判定に使う集計表）
+-- leave_requests を登録・更新するトランザクションの中で一緒に更新する。
+CREATE TABLE leave_counters (
+    employee_id       TEXT NOT NULL REFERENCES employees (id),
+    fiscal_year_start DATE NOT NULL,
+    requests          INTEGER NOT NULL DEFAULT 0 CHECK (requests >= 0),
+    PRIMARY KEY (employee_id, fiscal_year_start)
+);
+
+-- 既存の申請から作る（承認待ち・承認済みだけを数え、年度は4月1日（UTC）から）。
+-- 年度の定義を変えている場合は、移行の後に `rebuild-counters` で作り直すこと。
+INSERT INTO leave_counters (employee_id, fiscal_year_start, requests)
+SELECT employee_id,
+       make_date(EXTRACT(YEAR FROM created_at AT TIME ZONE 'UTC')::int
+                 - CASE WHEN EXTRACT(MONTH FROM created_at AT TIME ZONE 'UTC') < 4 THEN 1 ELSE 0 END, 4, 1),
+       COUNT(*)
+FROM leave_requests
+WHERE status IN ('PENDING', 'APPROVED')
+GROUP BY 1, 2;
@@ -0,0 +1,350 @@
+package drivers_test
+
+// MySQL の方言のテスト（internal/sqlfake）
+// --------------------------------------------------------
+// 本物の MySQL なしで、SQLLeaveRepo などが MySQLDialect で組み立てる SQL を確かめる。
+// - プレースホルダ（$N → ?）と引数の並べ直し・時刻の UTC 化
+// - 採番した ID の受け取り（LastInsertId）
+// - 行・表のロックの句（FOR UPDATE・LOCK IN SHARE MODE）
+// - スキーマ変更のスクリプトの分割
+// - エラー番号（1062・1452 など）の翻訳
+// --------------------------------------------------------
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/internal/sqlfake"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+var jst = time.FixedZone("JST", 9*60*60)
+
+// mysqlError：go-sql-driver/mysql の *MySQLError と同じ書式のエラー（SQLState() は持たない）
+type mysqlError struct {
+	number uint16
+	state  string
+	msg    string
+}
+
+func (e mysqlError) Error() string { return fmt.Sprintf("Error %d (%s): %s", e.number, e.state, e.msg) }
+
+// newMySQLLeaveRepo は sqlfake につながった MySQL の SQLLeaveRepo を返す。
+func newMySQLLeaveRepo(t *testing.T) (drivers.SQLLeaveRepo, *sqlfake.Fake) {
+	t.Helper()
+	db, fake := sqlfake.Open()
+	t.Cleanup(func() { db.Close() })
+	return drivers.SQLLeaveRepo{DB: db, Dialect: drivers.MySQLDialect{}}, fake
+}
+
+// wantLog は受け付けたクエリの先頭の語（BEGIN・SELECT・COMMIT など）の並びを確かめる。
+func wantLog(t *testing.T, fake *sqlfake.Fake, want ...string) {
+	t.Helper()
+	var got []string
+	for _, q := range fake.Log() {
+		got = append(got, strings.Fields(q)[0])
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("executed %v, want %v", got, want)
+	}
+}
+
+func mustDone(t *testing.T, fake *sqlfake.Fake) {
+	t.Helper()
+	if err := fake.Done(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMySQLRebind(t *testing.T) {
+	created := time.Date(2025, 5, 1, 9, 0, 0, 0, jst)
+	query, args := drivers.MySQLDialect{}.Rebind(
+		`UPDATE t SET a=$2, b=$3, note='$1 stays' WHERE id=$1 AND a<>$2 AND c=$4 AND d=$5`,
+		[]any{"id1", "A", created, sql.NullTime{Time: created, Valid: true}, sql.NullTime{}})
+
+	if want := `UPDATE t SET a=?, b=?, note='$1 stays' WHERE id=? AND a<>? AND c=? AND d=?`; query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+	utc := created.UTC()
+	want := []any{"A", utc, "id1", "A", utc, nil}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if tm, ok := args[i].(time.Time); ok {
+			if !tm.Equal(utc) || tm.Location() != time.UTC {
+				t.Errorf("args[%d] = %v, want %v in UTC", i, tm, utc)
+			}
+			continue
+		}
+		if args[i] != want[i] {
+			t.Errorf("args[%d] = %#v, want %#v", i, args[i], want[i])
+		}
+	}
+}
+
+// Create は INSERT を ? で実行して LastInsertId を ID にし、同じトランザクションで集計表に足す
+func TestMySQLLeaveCreate(t *testing.T) {
+	repo, fake := newMySQLLeaveRepo(t)
+	created := time.Date(2025, 5, 1, 9, 0, 0, 0, jst)
+	req := domain.LeaveRequest{
+		EmployeeID: "e1", Type: domain.LeavePaid, Reason: "旅行",
+		From: time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 5, 8, 0, 0, 0, 0, time.UTC),
+		Status: domain.StatusPending, CreatedAt: created, ApproverID: "boss", AssignedAt: created,
+	}
+	fake.ExpectExec(`INSERT INTO leave_requests(employee_id,leave_type,reason,from_date,to_date,status,created_at,approver_id,assigned_at,reminded_at,decided_at,decision_comment,day_part)
+		VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?)`).
+		WithArgs("e1", "PAID", "旅行", req.From, req.To, "PENDING", created.UTC(), "boss", created.UTC(), nil, nil, nil, nil).
+		WillReturnResult(42, 1)
+	fake.ExpectExec(`INSERT INTO leave_counters(employee_id, fiscal_year_start, requests) VALUES(?, ?, ?)
+		ON DUPLICATE KEY UPDATE requests = requests + VALUES(requests)`).
+		WithArgs("e1", "2025-04-01", 1)
+
+	if err := repo.Create(&req); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if req.ID != "42" {
+		t.Errorf("ID = %q, want the LastInsertId 42", req.ID)
+	}
+	mustDone(t, fake)
+	wantLog(t, fake, "BEGIN", "INSERT", "INSERT", "COMMIT")
+}
+
+// Update は行を FOR UPDATE で読み、$N の順に並べ直した引数で UPDATE し、件数の差を集計表に足す
+func TestMySQLLeaveUpdate(t *testing.T) {
+	repo, fake := newMySQLLeaveRepo(t)
+	decided := time.Date(2025, 5, 2, 18, 30, 0, 0, jst)
+	req := domain.LeaveRequest{ID: "7", Status: domain.StatusRejected, ApproverID: "boss", DecidedAt: decided, DecisionComment: "繁忙期"}
+	fake.ExpectQuery(`SELECT employee_id, status, created_at FROM leave_requests WHERE id=? FOR UPDATE`).
+		WithArgs("7").
+		WillReturnRows([]string{"employee_id", "status", "created_at"}, []any{"e1", "PENDING", "2025-03-31 23:30:00"})
+	fake.ExpectExec(`UPDATE leave_requests SET status=?, approver_id=?, assigned_at=?, reminded_at=?, decided_at=?, decision_comment=? WHERE id=? AND status=?`).
+		WithArgs("REJECTED", "boss", nil, nil, decided.UTC(), "繁忙期", "7", "PENDING")
+	// created_at は UTC として読むので、前年度（2024-04-01 始まり）の申請として数え直す
+	fake.ExpectExec(`INSERT INTO leave_counters`).WithArgs("e1", "2024-04-01", -1)
+
+	if err := repo.Update(&req, domain.StatusPending); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	mustDone(t, fake)
+	wantLog(t, fake, "BEGIN", "SELECT", "UPDATE", "INSERT", "COMMIT")
+}
+
+// 読んだステータスが expected と違えば UPDATE せずにロールバックする
+func TestMySQLLeaveUpdateStatusChanged(t *testing.T) {
+	repo, fake := newMySQLLeaveRepo(t)
+	fake.ExpectQuery(`SELECT employee_id, status, created_at FROM leave_requests WHERE id=? FOR UPDATE`).
+		WillReturnRows([]string{"employee_id", "status", "created_at"}, []any{"e1", "CANCELLED", "2025-05-01 00:00:00"})
+
+	req := domain.LeaveRequest{ID: "7", Status: domain.StatusApproved}
+	err := repo.Update(&req, domain.StatusPending)
+	if !errors.Is(err, usecase.ErrLeaveRequestChanged) || !errors.Is(err, usecase.ErrConflict) {
+		t.Fatalf("Update = %v, want ErrLeaveRequestChanged", err)
+	}
+	mustDone(t, fake)
+	wantLog(t, fake, "BEGIN", "SELECT", "ROLLBACK")
+}
+
+// DATETIME の文字列は UTC として、DATE は日付として読む
+func TestMySQLLeaveFindByIDReadsUTC(t *testing.T) {
+	repo, fake := newMySQLLeaveRepo(t)
+	fake.ExpectQuery(`FROM leave_requests WHERE id=?`).WithArgs("7").WillReturnRows(
+		[]string{"id", "employee_id", "leave_type", "reason", "from_date", "to_date", "status", "created_at",
+			"approver_id", "assigned_at", "reminded_at", "decided_at", "decision_comment", "day_part"},
+		[]any{int64(7), "e1", "PAID", "", []byte("2025-05-07"), []byte("2025-05-08"), "APPROVED", []byte("2025-05-01 00:30:00.123456"),
+			"boss", "0000-00-00 00:00:00", nil, "2025-05-02 09:30:00", nil, nil})
+
+	req, err := repo.FindByID("7")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	checks := []struct {
+		name      string
+		got, want time.Time
+	}{
+		{"From", req.From, time.Date(2025, 5, 7, 0, 0, 0, 0, time.UTC)},
+		{"CreatedAt", req.CreatedAt, time.Date(2025, 5, 1, 0, 30, 0, 123456000, time.UTC)},
+		{"DecidedAt", req.DecidedAt, time.Date(2025, 5, 2, 9, 30, 0, 0, time.UTC)},
+	}
+	for _, c := range checks {
+		if !c.got.Equal(c.want) || c.got.Location() != time.UTC {
+			t.Errorf("%s = %v, want %v in UTC", c.name, c.got, c.want)
+		}
+	}
+	if !req.AssignedAt.IsZero() || !req.RemindedAt.IsZero() {
+		t.Errorf("zero and NULL DATETIME read as %v / %v, want zero times", req.AssignedAt, req.RemindedAt)
+	}
+	if req.ID != "7" || req.ApproverID != "boss" {
+		t.Errorf("FindByID = %+v", req)
+	}
+	mustDone(t, fake)
+}
+
+// 一覧の取得はどれも同じ手順（queryLeaves）で行を読み、失敗はメソッドの名前で翻訳する
+func TestMySQLLeaveLists(t *testing.T) {
+	cols := []string{"id", "employee_id", "leave_type", "reason", "from_date", "to_date", "status", "created_at",
+		"approver_id", "assigned_at", "reminded_at", "decided_at", "decision_comment", "day_part"}
+	row := func(id int64) []any {
+		return []any{id, "e1", "PAID", "", "2025-05-07", "2025-05-08", "PENDING", "2025-05-01 00:00:00", "boss", nil, nil, nil, nil, nil}
+	}
+	from, to := time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name  string
+		query string
+		args  []any
+		list  func(drivers.SQLLeaveRepo) ([]domain.LeaveRequest, error)
+	}{
+		{"ListPending", `WHERE status=? ORDER BY created_at, id`, []any{"PENDING"},
+			func(r drivers.SQLLeaveRepo) ([]domain.LeaveRequest, error) { return r.ListPending() }},
+		{"ListPendingByEmployee", `WHERE employee_id=? AND status=? ORDER BY created_at, id`, []any{"e1", "PENDING"},
+			func(r drivers.SQLLeaveRepo) ([]domain.LeaveRequest, error) { return r.ListPendingByEmployee("e1") }},
+		{"ListByEmployee", `WHERE employee_id=? ORDER BY created_at, id`, []any{"e1"},
+			func(r drivers.SQLLeaveRepo) ([]domain.LeaveRequest, error) { return r.ListByEmployee("e1") }},
+		{"ListOverlapping", `WHERE from_date <= ? AND to_date >= ? ORDER BY from_date, id`, []any{to, from},
+			func(r drivers.SQLLeaveRepo) ([]domain.LeaveRequest, error) { return r.ListOverlapping(from, to) }},
+	}
+	for _, c := range cases {
+		repo, fake := newMySQLLeaveRepo(t)
+		fake.ExpectQuery(c.query).WithArgs(c.args...).WillReturnRows(cols, row(3), row(5))
+		reqs, err := c.list(repo)
+		if err != nil || len(reqs) != 2 || reqs[0].ID != "3" || reqs[1].ID != "5" {
+			t.Errorf("%s = %+v, %v, want requests 3 and 5", c.name, reqs, err)
+		}
+		mustDone(t, fake)
+
+		repo, fake = newMySQLLeaveRepo(t)
+		fake.ExpectQuery(c.query).WillReturnError(mysqlError{1205, "HY000", "Lock wait timeout exceeded"})
+		_, err = c.list(repo)
+		if !errors.Is(err, usecase.ErrUnavailable) || !strings.Contains(err.Error(), "SQLLeaveRepo."+c.name) {
+			t.Errorf("%s: err = %v, want ErrUnavailable from SQLLeaveRepo.%s", c.name, err, c.name)
+		}
+		mustDone(t, fake)
+	}
+}
+
+// MySQL には LOCK TABLE の文がないので、読む SELECT に LOCK IN SHARE MODE を付ける
+func TestMySQLRebuildCountersLocksWithSelect(t *testing.T) {
+	repo, fake := newMySQLLeaveRepo(t)
+	fake.ExpectQuery(`SELECT employee_id, status, created_at FROM leave_requests LOCK IN SHARE MODE`).
+		WillReturnRows([]string{"employee_id", "status", "created_at"},
+			[]any{"e1", "PENDING", "2025-05-01 00:00:00"},
+			[]any{"e1", "REJECTED", "2025-05-02 00:00:00"},
+			[]any{"e1", "APPROVED", "2025-06-01 00:00:00"})
+	fake.ExpectExec(`DELETE FROM leave_counters`)
+	fake.ExpectExec(`INSERT INTO leave_counters(employee_id, fiscal_year_start, requests) VALUES(?, ?, ?)`).
+		WithArgs("e1", "2025-04-01", 2)
+
+	n, err := repo.RebuildCounters()
+	if err != nil || n != 1 {
+		t.Fatalf("RebuildCounters = %d, %v; want 1 row", n, err)
+	}
+	mustDone(t, fake)
+	wantLog(t, fake, "BEGIN", "SELECT", "DELETE", "INSERT", "COMMIT")
+}
+
+// エラー番号を同じ意味の SQLSTATE に読み替えて、usecase のエラー種類にする
+func TestMySQLErrorTranslation(t *testing.T) {
+	cases := []struct {
+		name string
+		err  mysqlError
+		want error
+	}{
+		{"duplicate entry", mysqlError{1062, "23000", "Duplicate entry '42' for key 'PRIMARY'"}, usecase.ErrConflict},
+		{"no referenced row", mysqlError{1452, "23000", "Cannot add or update a child row: a foreign key constraint fails"}, usecase.ErrValidation},
+		{"lock wait timeout", mysqlError{1205, "HY000", "Lock wait timeout exceeded"}, usecase.ErrUnavailable},
+		{"unknown", mysqlError{1064, "42000", "You have an error in your SQL syntax"}, usecase.ErrInternal},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repo, fake := newMySQLLeaveRepo(t)
+			fake.ExpectExec(`INSERT INTO leave_requests`).WillReturnError(c.err)
+
+			err := repo.Create(&domain.LeaveRequest{EmployeeID: "e1", Status: domain.StatusPending})
+			if got := usecase.KindOf(err); got != c.want {
+				t.Errorf("Create = %v (kind %v), want kind %v", err, got, c.want)
+			}
+			if !errors.As(err, new(mysqlError)) {
+				t.Errorf("Create = %v, want the driver error kept for Unwrap", err)
+			}
+			wantLog(t, fake, "BEGIN", "INSERT", "ROLLBACK")
+		})
+	}
+
+	// 従業員の主キーの重複は ErrEmployeeExists
+	db, fake := sqlfake.Open()
+	defer db.Close()
+	fake.ExpectExec(`INSERT INTO employees`).WillReturnError(mysqlError{1062, "23000", "Duplicate entry 'e1' for key 'PRIMARY'"})
+	err := drivers.SQLEmployeeRepo{DB: db, Dialect: drivers.MySQLDialect{}}.Create(&domain.Employee{ID: "e1"})
+	if !errors.Is(err, usecase.ErrEmployeeExists) {
+		t.Errorf("SQLEmployeeRepo.Create = %v, want ErrEmployeeExists", err)
+	}
+}
+
+func TestMySQLStatements(t *testing.T) {
+	script := `-- 先頭のコメント; ここでは分けない
+CREATE TABLE a (
+    id   INT PRIMARY KEY,
+    note VARCHAR(16) DEFAULT 'x;y'
+);
+INSERT INTO a VALUES (1, 'it''s; fine
+across lines;');
+CREATE INDEX a_note_idx ON a (note); -- 行末のコメント
+-- 最後のコメントだけの塊は捨てる
+`
+	got := drivers.MySQLDialect{}.Statements(script)
+	if len(got) != 3 {
+		t.Fatalf("Statements returned %d statements, want 3:\n%s", len(got), strings.Join(got, "\n----\n"))
+	}
+	for i, prefix := range []string{"-- 先頭のコメント", "INSERT INTO a", "CREATE INDEX a_note_idx"} {
+		if !strings.HasPrefix(got[i], prefix) {
+			t.Errorf("statement %d = %q, want it to start with %q", i, got[i], prefix)
+		}
+	}
+	if !strings.Contains(got[1], "across lines;')") {
+		t.Errorf("statement 1 = %q, want the quoted ; kept inside the literal", got[1])
+	}
+
+	// 埋め込みのスキーマ変更は、どれも ; で終わる文に分かれる
+	for _, mg := range drivers.EmbeddedMigrations(drivers.MySQLDialect{}) {
+		for _, body := range []string{mg.Up, mg.Down} {
+			stmts := drivers.MySQLDialect{}.Statements(body)
+			if len(stmts) == 0 {
+				t.Errorf("%s: no statements", mg.FileName())
+			}
+			for _, s := range stmts {
+				if !strings.HasSuffix(s, ";") {
+					t.Errorf("%s: statement does not end with ';': %q", mg.FileName(), s)
+				}
+			}
+		}
+	}
+}
+
+// Migrator はスキーマ変更を文ごとに Exec し、schema_migrations の行を FOR UPDATE でロックする
+func TestMySQLMigratorSplitsScripts(t *testing.T) {
+	db, fake := sqlfake.Open()
+	defer db.Close()
+	m := drivers.Migrator{DB: db, Dialect: drivers.MySQLDialect{}, Migrations: []drivers.Migration{{
+		Version: 1, Name: "create_a",
+		Up:   "CREATE TABLE a (id INT);\nCREATE INDEX a_idx ON a (id);\n",
+		Down: "DROP TABLE a;\n",
+	}}}
+	fake.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`)
+	fake.ExpectQuery(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).WillReturnRows([]string{"v"}, []any{0})
+	fake.ExpectQuery(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations FOR UPDATE`).WillReturnRows([]string{"v"}, []any{0})
+	fake.ExpectExec(`CREATE TABLE a (id INT);`)
+	fake.ExpectExec(`CREATE INDEX a_idx ON a (id);`)
+	fake.ExpectExec(`INSERT INTO schema_migrations(version, name) VALUES(?, ?)`).WithArgs(1, "create_a")
+
+	applied, err := m.Up(0)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("Up = %v, %v; want 1 migration applied", applied, err)
+	}
+	mustDone(t, fake)
+}
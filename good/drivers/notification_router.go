@@ -0,0 +1,135 @@
+package drivers
+
+// Framework & Drivers層（通知の振り分け）
+// --------------------------------------------------------
+// NotificationRouter は UseCase層の Mailer を満たし、通知をチャネルごとの Mailer（メール・チャットなど）へ振り分ける。
+// - どのチャネルで送るかは宛先の従業員の設定（domain.NotificationPrefs.ChannelsAt）に従う
+// - 選んだチャネルが用意されていない（宛先に届かない）ときはメールで送る
+// - 複数のチャネルへは並行して送る。遅いチャネルや失敗したチャネルがあってもほかのチャネルには届く
+// - 1つでも届けば成功とし、届かなかったチャネルはログに残す。すべて失敗したときだけエラーを返す
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// NotificationRoute：振り分け先のチャネル1つ
+type NotificationRoute struct {
+	Channel domain.Channel
+	Mailer  usecase.Mailer
+}
+
+// recipientChecker：宛先に届くかを事前に確かめられるチャネル（任意）
+// 例：部署ごとに投稿先を設定する ChatNotifier は、投稿先のない部署の従業員には届かない。
+type recipientChecker interface {
+	Reaches(e domain.Employee) bool
+}
+
+// NotificationRouter：通知をチャネルごとの Mailer へ振り分ける
+type NotificationRouter struct {
+	Routes   []NotificationRoute
+	Defaults []domain.Channel // チャネルを設定していない従業員に使うチャネル（空なら Routes のすべて）
+	Clock    usecase.Clock    // 静かな時間の判定に使う（nil なら現在時刻）
+	Logger   *slog.Logger     // 一部のチャネルだけが失敗したときの記録先（nil なら slog.Default）
+}
+
+func (r NotificationRouter) NotifyManagerNewRequest(n usecase.Notification) error {
+	return r.fanOut("NotifyManagerNewRequest", n, func(m usecase.Mailer) error { return m.NotifyManagerNewRequest(n) })
+}
+
+func (r NotificationRouter) NotifyApproverReminder(n usecase.Notification) error {
+	return r.fanOut("NotifyApproverReminder", n, func(m usecase.Mailer) error { return m.NotifyApproverReminder(n) })
+}
+
+func (r NotificationRouter) NotifyEscalation(n usecase.Notification) error {
+	return r.fanOut("NotifyEscalation", n, func(m usecase.Mailer) error { return m.NotifyEscalation(n) })
+}
+
+func (r NotificationRouter) NotifyRequesterDecision(n usecase.Notification) error {
+	return r.fanOut("NotifyRequesterDecision", n, func(m usecase.Mailer) error { return m.NotifyRequesterDecision(n) })
+}
+
+func (r NotificationRouter) NotifyTeamAbsence(n usecase.Notification) error {
+	return r.fanOut("NotifyTeamAbsence", n, func(m usecase.Mailer) error { return m.NotifyTeamAbsence(n) })
+}
+
+// routesFor は宛先と時刻から送り先のチャネルを選ぶ。
+func (r NotificationRouter) routesFor(e domain.Employee, now time.Time) []NotificationRoute {
+	defaults := r.Defaults
+	if len(defaults) == 0 {
+		for _, rt := range r.Routes {
+			defaults = append(defaults, rt.Channel)
+		}
+	}
+	want := e.Notify.ChannelsAt(now, defaults)
+	var routes []NotificationRoute
+	for _, rt := range r.Routes {
+		if !slices.Contains(want, rt.Channel) {
+			continue
+		}
+		if rc, ok := rt.Mailer.(recipientChecker); ok && !rc.Reaches(e) {
+			continue
+		}
+		routes = append(routes, rt)
+	}
+	if len(routes) > 0 {
+		return routes
+	}
+	// 希望したチャネルがこの環境にない・宛先に届かない
+	for _, rt := range r.Routes {
+		if rt.Channel == domain.ChannelEmail {
+			return []NotificationRoute{rt}
+		}
+	}
+	return nil
+}
+
+// fanOut は選んだチャネルへ並行して送る。
+func (r NotificationRouter) fanOut(method string, n usecase.Notification, send func(usecase.Mailer) error) error {
+	op := "NotificationRouter." + method
+	now := time.Now()
+	if r.Clock != nil {
+		now = r.Clock.Now()
+	}
+	routes := r.routesFor(n.Recipient, now)
+	if len(routes) == 0 {
+		return usecase.NewError(usecase.ErrInternal, op, errors.New("no notification channel is configured"))
+	}
+	errs := make([]error, len(routes))
+	var wg sync.WaitGroup
+	for i, rt := range routes {
+		wg.Add(1)
+		go func(i int, rt NotificationRoute) {
+			defer wg.Done()
+			errs[i] = send(rt.Mailer)
+		}(i, rt)
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+			r.logger().Warn("notification channel failed", "op", op, "channel", routes[i].Channel,
+				"request_id", n.Request.ID, "recipient_id", n.Recipient.ID, "err", err)
+		}
+	}
+	if len(failed) == len(routes) {
+		return errors.Join(failed...)
+	}
+	return nil
+}
+
+func (r NotificationRouter) logger() *slog.Logger {
+	if r.Logger != nil {
+		return r.Logger
+	}
+	return slog.Default()
+}
@@ -15,42 +15,307 @@ package drivers
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
 )
 
-// PostgresEmployeeRepo は従業員情報を PostgreSQL から取得するリポジトリ。
+// SQLEmployeeRepo は従業員情報を SQL データベース（PostgreSQL・MySQL）から取得するリポジトリ。
 // Domain層の EmployeeRepository インターフェースを満たす。
 // 「Domain層の EmployeeRepository インターフェース」というのは、ドメインやユースケースが外部に対して「こういうデータが欲しい」という依頼の窓口（契約）
-type PostgresEmployeeRepo struct{ DB *sql.DB }
+type SQLEmployeeRepo struct {
+	DB      *sql.DB
+	Dialect Dialect // nil なら PostgreSQL
+}
+
+func (r SQLEmployeeRepo) conn() sqlConn { return newSQLConn(r.DB, r.Dialect) }
 
 // FindByID は従業員IDで Employee を検索する。
 // 純粋にDBからデータを取得するのみで、業務ルールは扱わない。
-func (r PostgresEmployeeRepo) FindByID(id string) (domain.Employee, error) {
+// DBのエラーは usecase のエラー種類（見つからない場合は ErrEmployeeNotFound）へ翻訳して返す。
+func (r SQLEmployeeRepo) FindByID(id string) (domain.Employee, error) {
+	e, err := scanEmployee(r.conn().QueryRow(`SELECT `+employeeColumns+` FROM employees WHERE id=$1`, id))
+	return e, translateLookupError("SQLEmployeeRepo.FindByID", err, usecase.ErrEmployeeNotFound)
+}
+
+// List は全従業員をID順に取得する。
+func (r SQLEmployeeRepo) List() ([]domain.Employee, error) {
+	rows, err := r.conn().Query(`SELECT ` + employeeColumns + ` FROM employees ORDER BY id`)
+	if err != nil {
+		return nil, translateSQLError("SQLEmployeeRepo.List", err)
+	}
+	defer rows.Close()
+	var emps []domain.Employee
+	for rows.Next() {
+		e, err := scanEmployee(rows)
+		if err != nil {
+			return nil, translateSQLError("SQLEmployeeRepo.List", err)
+		}
+		emps = append(emps, e)
+	}
+	return emps, translateSQLError("SQLEmployeeRepo.List", rows.Err())
+}
+
+// Create は従業員を登録する。IDの重複は ErrEmployeeExists（ErrConflict の一種）として返る。
+func (r SQLEmployeeRepo) Create(e *domain.Employee) error {
+	_, err := r.conn().Exec(
+		`INSERT INTO employees(id,name,email,hire_date,manager_id,department,role,left_on,frozen_quota,locale,notify_channels,quiet_hours,quiet_time_zone)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		e.ID, e.Name, nullString(e.Email), e.HireDate, nullString(e.ManagerID), nullString(e.Department), e.Role,
+		nullTime(e.LeftOn), e.FrozenQuota, nullString(string(e.Locale)),
+		nullString(joinChannels(e.Notify.Channels)), nullString(e.Notify.Quiet.Range()), nullString(e.Notify.Quiet.TimeZone))
+	if sqlState(err) == "23505" { // unique_violation（主キー以外に一意制約はない。MySQL の 1062 も同じ扱い）
+		return usecase.NewError(usecase.ErrEmployeeExists, "SQLEmployeeRepo.Create", err)
+	}
+	return translateSQLError("SQLEmployeeRepo.Create", err)
+}
+
+// Update は従業員情報を更新する。
+func (r SQLEmployeeRepo) Update(e *domain.Employee) error {
+	res, err := r.conn().Exec(
+		`UPDATE employees SET name=$2, email=$3, hire_date=$4, manager_id=$5, department=$6, role=$7, left_on=$8, frozen_quota=$9, locale=$10,
+		     notify_channels=$11, quiet_hours=$12, quiet_time_zone=$13
+		 WHERE id=$1`,
+		e.ID, e.Name, nullString(e.Email), e.HireDate, nullString(e.ManagerID), nullString(e.Department), e.Role,
+		nullTime(e.LeftOn), e.FrozenQuota, nullString(string(e.Locale)),
+		nullString(joinChannels(e.Notify.Channels)), nullString(e.Notify.Quiet.Range()), nullString(e.Notify.Quiet.TimeZone))
+	if err != nil {
+		return translateSQLError("SQLEmployeeRepo.Update", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// MySQL は値が変わらなかった行を数えないので、行があるかを確かめ直す
+		var one int
+		err := r.conn().QueryRow(`SELECT 1 FROM employees WHERE id=$1`, e.ID).Scan(&one)
+		return translateLookupError("SQLEmployeeRepo.Update", err, usecase.ErrEmployeeNotFound)
+	}
+	return nil
+}
+
+// employeeColumns：employees から読み出す列（scanEmployee と順番を合わせる）
+const employeeColumns = `id, name, email, hire_date, manager_id, department, role, left_on, frozen_quota, locale,
+	notify_channels, quiet_hours, quiet_time_zone`
+
+// scanEmployee は employeeColumns の順に読み出した1行を Employee に変換する。
+func scanEmployee(s rowScanner) (domain.Employee, error) {
 	var e domain.Employee
-	return e, r.DB.QueryRow(`SELECT id, hire_date FROM employees WHERE id=$1`, id).Scan(&e.ID, &e.HireDate)
+	var email, managerID, department, locale, channels, quiet, quietTZ sql.NullString
+	err := s.Scan(&e.ID, &e.Name, &email, dbTime{&e.HireDate}, &managerID, &department, &e.Role, dbTime{&e.LeftOn}, &e.FrozenQuota, &locale,
+		&channels, &quiet, &quietTZ)
+	e.Email = email.String
+	e.ManagerID = managerID.String
+	e.Department = department.String
+	e.Locale = domain.Locale(locale.String)
+	e.Notify.Channels = splitChannels(channels.String)
+	if err == nil {
+		e.Notify.Quiet, err = domain.ParseQuietHours(quiet.String, quietTZ.String)
+	}
+	return e, err
 }
 
-// PostgresLeaveRepo は休暇申請データを PostgreSQL に保存・取得するリポジトリ。
+// SQLLeaveRepo は休暇申請データを SQL データベース（PostgreSQL・MySQL）に保存・取得するリポジトリ。
 // Domain層の LeaveRepository インターフェースを満たす。
-type PostgresLeaveRepo struct{ DB *sql.DB }
+// 年度内の申請回数は集計表 leave_counters から読む（leave_counters.go）。
+type SQLLeaveRepo struct {
+	DB      *sql.DB
+	Dialect Dialect // nil なら PostgreSQL
+	// YearStart：申請を集計表のどの年度に数えるか（nil なら domain.FiscalYearStart）。
+	// UseCase に渡す年度開始日の計算と同じものを渡すこと。
+	YearStart func(t time.Time) time.Time
+}
+
+func (r SQLLeaveRepo) conn() sqlConn { return newSQLConn(r.DB, r.Dialect) }
 
-// CountThisFiscalYear は年度内の申請回数をDBからカウントする。
-// ビジネス条件（年度開始日など）はUseCaseから与えられる。
-func (r PostgresLeaveRepo) CountThisFiscalYear(empID string, start time.Time) (int, error) {
+// CountThisFiscalYear は年度内の申請回数を集計表から読む（COUNT(*) はしない）。
+// start は YearStart で求めた年度の開始日であること。
+func (r SQLLeaveRepo) CountThisFiscalYear(empID string, start time.Time) (int, error) {
 	var c int
-	return c, r.DB.QueryRow(
-		`SELECT COUNT(*) FROM leave_requests WHERE employee_id=$1 AND created_at >= $2`,
-		empID, start).Scan(&c)
+	err := r.conn().QueryRow(
+		`SELECT requests FROM leave_counters WHERE employee_id=$1 AND fiscal_year_start=$2`,
+		empID, counterDate(start)).Scan(&c)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil // まだ1件も数えていない年度
+	}
+	return c, translateSQLError("SQLLeaveRepo.CountThisFiscalYear", err)
 }
 
-// Create は新しい休暇申請をDBに登録する。
+// Create は新しい休暇申請をDBに登録し、同じトランザクションで集計表を更新する。
 // 登録時の業務ルール（件数制限・勤務期間チェック等）はUseCase/Domain側で担保される。
-func (r PostgresLeaveRepo) Create(req *domain.LeaveRequest) error {
-	return r.DB.QueryRow(
-		`INSERT INTO leave_requests(employee_id,reason,from_date,to_date,status,created_at)
-		 VALUES($1,$2,$3,$4,$5,$6) RETURNING id`,
-		req.EmployeeID, req.Reason, req.From, req.To, req.Status, req.CreatedAt,
-	).Scan(&req.ID)
+func (r SQLLeaveRepo) Create(req *domain.LeaveRequest) error {
+	return r.CreateBatch([]*domain.LeaveRequest{req})
+}
+
+// CreateBatch は複数の休暇申請を1つのトランザクションで登録する。
+// 1件でも失敗した場合はロールバックし、どの申請も登録しない。
+func (r SQLLeaveRepo) CreateBatch(reqs []*domain.LeaveRequest) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return translateSQLError("SQLLeaveRepo.CreateBatch", err)
+	}
+	defer tx.Rollback()
+	c := newSQLConn(tx, r.Dialect)
+	for _, req := range reqs {
+		if err := insertLeave(c, req); err != nil {
+			return translateSQLError("SQLLeaveRepo.CreateBatch", err)
+		}
+		if err := r.addToCounter(c, req.EmployeeID, req.CreatedAt, domain.QuotaDelta("", req.Status)); err != nil {
+			return translateSQLError("SQLLeaveRepo.CreateBatch", err)
+		}
+	}
+	return translateSQLError("SQLLeaveRepo.CreateBatch", tx.Commit())
+}
+
+// FindByID は申請IDで休暇申請を取得する。
+func (r SQLLeaveRepo) FindByID(id string) (domain.LeaveRequest, error) {
+	req, err := scanLeave(r.conn().QueryRow(`SELECT `+leaveColumns+` FROM leave_requests WHERE id=$1`, id))
+	return req, translateLookupError("SQLLeaveRepo.FindByID", err, usecase.ErrLeaveRequestNotFound)
+}
+
+// ListPending は承認待ちの申請を古い順に取得する。
+func (r SQLLeaveRepo) ListPending() ([]domain.LeaveRequest, error) {
+	return r.queryLeaves("SQLLeaveRepo.ListPending",
+		`SELECT `+leaveColumns+` FROM leave_requests WHERE status=$1 ORDER BY created_at, id`,
+		domain.StatusPending)
+}
+
+// ListPendingByEmployee は指定した従業員の承認待ちの申請を古い順に取得する（退職処理用）。
+func (r SQLLeaveRepo) ListPendingByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.queryLeaves("SQLLeaveRepo.ListPendingByEmployee",
+		`SELECT `+leaveColumns+` FROM leave_requests WHERE employee_id=$1 AND status=$2 ORDER BY created_at, id`,
+		employeeID, domain.StatusPending)
 }
+
+// ListByEmployee は指定した従業員の申請をステータスを問わず古い順に取得する。
+func (r SQLLeaveRepo) ListByEmployee(employeeID string) ([]domain.LeaveRequest, error) {
+	return r.queryLeaves("SQLLeaveRepo.ListByEmployee",
+		`SELECT `+leaveColumns+` FROM leave_requests WHERE employee_id=$1 ORDER BY created_at, id`,
+		employeeID)
+}
+
+// ListOverlapping は期間 [from, to] に一部でも重なる休暇申請を取得する（集計用）。
+func (r SQLLeaveRepo) ListOverlapping(from, to time.Time) ([]domain.LeaveRequest, error) {
+	return r.queryLeaves("SQLLeaveRepo.ListOverlapping",
+		`SELECT `+leaveColumns+` FROM leave_requests WHERE from_date <= $2 AND to_date >= $1 ORDER BY from_date, id`,
+		from, to)
+}
+
+// queryLeaves は leaveColumns を選ぶ query を実行し、全行を LeaveRequest にして返す。
+// エラーは op の名前で usecase のエラーに翻訳する。
+func (r SQLLeaveRepo) queryLeaves(op, query string, args ...any) ([]domain.LeaveRequest, error) {
+	rows, err := r.conn().Query(query, args...)
+	if err != nil {
+		return nil, translateSQLError(op, err)
+	}
+	defer rows.Close()
+	var reqs []domain.LeaveRequest
+	for rows.Next() {
+		req, err := scanLeave(rows)
+		if err != nil {
+			return nil, translateSQLError(op, err)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, translateSQLError(op, rows.Err())
+}
+
+// Update は保存されているステータスが expected のときだけ、申請の状態（ステータス・承認者・催促日時・決定日時・
+// 判断コメント）を更新する。
+// ステータスが変わって申請回数に数えるかどうかが変われば、同じトランザクションで集計表も更新する。
+func (r SQLLeaveRepo) Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error {
+	const op = "SQLLeaveRepo.Update"
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return translateSQLError(op, err)
+	}
+	defer tx.Rollback()
+	c := newSQLConn(tx, r.Dialect)
+	// 更新前のステータス（同じ申請を同時に更新されても増減がずれないよう行をロックする）
+	var empID string
+	var before domain.LeaveStatus
+	var createdAt time.Time
+	err = c.QueryRow(`SELECT employee_id, status, created_at FROM leave_requests WHERE id=$1`+c.d.ForUpdate(), req.ID).
+		Scan(&empID, &before, dbTime{&createdAt})
+	if err != nil {
+		return translateLookupError(op, err, usecase.ErrLeaveRequestNotFound)
+	}
+	// 読んでから更新するまでに判断・取消などでステータスが変わっていれば上書きしない
+	// （行はロック済みなので、ここで確かめたステータスは UPDATE まで変わらない。WHERE の条件は念のため）
+	if before != expected {
+		return usecase.NewError(usecase.ErrLeaveRequestChanged, op, fmt.Errorf("id %q is %s, not %s", req.ID, before, expected))
+	}
+	_, err = c.Exec(
+		`UPDATE leave_requests SET status=$2, approver_id=$3, assigned_at=$4, reminded_at=$5, decided_at=$6, decision_comment=$7 WHERE id=$1 AND status=$8`,
+		req.ID, req.Status, nullString(req.ApproverID), nullTime(req.AssignedAt), nullTime(req.RemindedAt), nullTime(req.DecidedAt),
+		nullString(req.DecisionComment), expected)
+	if err != nil {
+		return translateSQLError(op, err)
+	}
+	if err := r.addToCounter(c, empID, createdAt, domain.QuotaDelta(before, req.Status)); err != nil {
+		return translateSQLError(op, err)
+	}
+	return translateSQLError(op, tx.Commit())
+}
+
+// leaveColumns：leave_requests から読み出す列（scanLeave と順番を合わせる）
+const leaveColumns = `id, employee_id, leave_type, reason, from_date, to_date, status, created_at, approver_id, assigned_at, reminded_at, decided_at, decision_comment, day_part`
+
+// rowScanner：*sql.Row と *sql.Rows の共通部分
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// insertLeave は休暇申請を1件登録し、採番されたIDを req.ID に設定する。
+func insertLeave(c sqlConn, req *domain.LeaveRequest) error {
+	id, err := c.InsertID(
+		`INSERT INTO leave_requests(employee_id,leave_type,reason,from_date,to_date,status,created_at,approver_id,assigned_at,reminded_at,decided_at,decision_comment,day_part)
+		 VALUES($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		req.EmployeeID, req.Type, req.Reason, req.From, req.To, req.Status, req.CreatedAt,
+		nullString(req.ApproverID), nullTime(req.AssignedAt), nullTime(req.RemindedAt), nullTime(req.DecidedAt),
+		nullString(req.DecisionComment), nullString(string(req.DayPart)))
+	if err == nil {
+		req.ID = id
+	}
+	return err
+}
+
+// scanLeave は leaveColumns の順に読み出した1行を LeaveRequest に変換する。
+func scanLeave(s rowScanner) (domain.LeaveRequest, error) {
+	var req domain.LeaveRequest
+	var approverID, comment, dayPart sql.NullString
+	err := s.Scan(&req.ID, &req.EmployeeID, &req.Type, &req.Reason, dbTime{&req.From}, dbTime{&req.To}, &req.Status, dbTime{&req.CreatedAt},
+		&approverID, dbTime{&req.AssignedAt}, dbTime{&req.RemindedAt}, dbTime{&req.DecidedAt}, &comment, &dayPart)
+	req.ApproverID = approverID.String
+	req.DecisionComment = comment.String
+	req.DayPart = domain.DayPart(dayPart.String)
+	return req, err
+}
+
+// joinChannels は通知のチャネルを "email,chat" の形で保存するための変換。
+func joinChannels(cs []domain.Channel) string {
+	ss := make([]string, len(cs))
+	for i, c := range cs {
+		ss[i] = string(c)
+	}
+	return strings.Join(ss, ",")
+}
+
+// splitChannels は joinChannels の逆変換。
+func splitChannels(s string) []domain.Channel {
+	if s == "" {
+		return nil
+	}
+	var cs []domain.Channel
+	for _, c := range strings.Split(s, ",") {
+		cs = append(cs, domain.Channel(c))
+	}
+	return cs
+}
+
+// nullString は空文字を NULL として保存するための変換。
+func nullString(s string) sql.NullString { return sql.NullString{String: s, Valid: s != ""} }
+
+// nullTime はゼロ値の時刻を NULL として保存するための変換。
+func nullTime(t time.Time) sql.NullTime { return sql.NullTime{Time: t, Valid: !t.IsZero()} }
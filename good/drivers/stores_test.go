@@ -0,0 +1,43 @@
+package drivers_test
+
+// 保存先ごとのリポジトリの契約テスト（internal/repotest）
+// --------------------------------------------------------
+// メモリ・ファイルはいつでも実行する。
+// PostgreSQL・MySQL は database/sql のドライバが必要（このモジュールは標準ライブラリだけで作るので同梱しない）。
+// 実行するときは、ドライバを登録するテストファイルを drivers/ に一時的に置いて go get する。
+//
+//	// drivers/sqldriver_local_test.go（コミットしない）
+//	package drivers_test
+//
+//	import (
+//		_ "github.com/go-sql-driver/mysql"
+//		_ "github.com/lib/pq"
+//	)
+//
+//	go get github.com/lib/pq github.com/go-sql-driver/mysql
+//	REPOTEST_DATABASE_URL=postgres://... REPOTEST_MYSQL_URL='user:pass@tcp(127.0.0.1:3306)/repotest' \
+//		go test ./drivers -run 'TestPostgresStore|TestMySQLStore' -v
+//
+// REPOTEST_DATABASE_URL を省略すると、PATH にある initdb / pg_ctl で一時的な PostgreSQL を起動する。
+// ドライバや接続先がなければ、理由を示して Skip する（-v で確認できる）。
+// --------------------------------------------------------
+
+import (
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/internal/repotest"
+)
+
+func TestMemoryStore(t *testing.T) { repotest.Run(t, repotest.MemoryFactory) }
+
+func TestFileStore(t *testing.T) { repotest.Run(t, repotest.FileFactory) }
+
+func TestPostgresStore(t *testing.T) {
+	db := repotest.StartPostgres(t)
+	repotest.Run(t, repotest.PostgresFactory(db))
+}
+
+func TestMySQLStore(t *testing.T) {
+	db := repotest.StartMySQL(t)
+	repotest.Run(t, repotest.MySQLFactory(db))
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"expvar"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// newEmployeeCache は従業員情報の読み取りキャッシュを作り、利用状況を /debug/vars の leave_employee_cache で公開する。
+// - EMPLOYEE_CACHE_TTL ：保持する時間（"30s" など。既定は1分）
+// - EMPLOYEE_CACHE_SIZE：保持する従業員の数の上限（既定は1000）
+func newEmployeeCache(next usecase.EmployeeRepo) *drivers.CachedEmployeeRepo {
+	var cfg drivers.EmployeeCacheConfig
+	if v := os.Getenv("EMPLOYEE_CACHE_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			slog.Warn("invalid EMPLOYEE_CACHE_TTL; using the default", "value", v, "err", err)
+		}
+		cfg.TTL = d
+	}
+	if v := os.Getenv("EMPLOYEE_CACHE_SIZE"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			slog.Warn("invalid EMPLOYEE_CACHE_SIZE; using the default", "value", v, "err", err)
+		}
+		cfg.MaxEntries = n
+	}
+	cache := drivers.NewCachedEmployeeRepo(next, cfg)
+	expvar.Publish("leave_employee_cache", cache)
+	return cache
+}
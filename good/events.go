@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+)
+
+// openEventBus は休暇申請の変更を記録・配信するイベントバスを作る。
+// ログは EVENTS_DIR（既定は data/events）に追記する。
+func openEventBus() (*drivers.EventBus, error) {
+	dir := os.Getenv("EVENTS_DIR")
+	if dir == "" {
+		dir = "data/events"
+	}
+	log, err := drivers.OpenEventLog(dir)
+	if err != nil {
+		return nil, err
+	}
+	return drivers.NewEventBus(log, slog.Default()), nil
+}
+
+// newEventsHandler は連携先がイベントログを読む入口を作る。EVENTS_TOKEN が未設定なら nil。
+func newEventsHandler(bus *drivers.EventBus) http.Handler {
+	token := os.Getenv("EVENTS_TOKEN")
+	if token == "" {
+		return nil
+	}
+	return adapters.EventsHandler{Feed: bus.Log, Token: token}
+}
@@ -1,3 +1,3 @@
 module github.com/ohagi/clean-architecture-examples/good
 
-go 1.21
+go 1.22
@@ -0,0 +1,91 @@
+package japanese
+
+// ISO-2022-JP（JIS コード）への変換
+// --------------------------------------------------------
+// 日本語メールの件名などで使う。ASCII はそのまま、それ以外は JIS X 0208 の2バイト文字にして
+// ESC $ B … ESC ( B で囲む。JIS X 0208 の範囲は CP932 の先行バイト 0x81-0xEA に当たるので、
+// cp932Table を逆引きして求める（NEC特殊文字は CP50220 と同じく含める）。
+// 半角カナ・IBM拡張文字・絵文字などは ISO-2022-JP では表せない。
+// --------------------------------------------------------
+
+import (
+	"sync"
+)
+
+const (
+	escJISX0208 = "\x1b$B"
+	escASCII    = "\x1b(B"
+	jisMaxLead  = 41 // cp932Index の先行バイト番号の上限（先行バイト 0xEA ＝ JIS X 0208 の84区）
+)
+
+var (
+	jisOnce  sync.Once
+	jisTable map[rune][2]byte // Unicode → JIS X 0208 の2バイト
+)
+
+// jisAliases：CP932 と JIS X 0208 で対応する Unicode が異なる文字（どちらからも同じ JIS コードにする）
+var jisAliases = map[rune][2]byte{
+	'〜': {0x21, 0x41}, // WAVE DASH（CP932 では FULLWIDTH TILDE）
+	'‖': {0x21, 0x42}, // DOUBLE VERTICAL LINE（CP932 では PARALLEL TO）
+	'−': {0x21, 0x5D}, // MINUS SIGN（CP932 では FULLWIDTH HYPHEN-MINUS）
+	'¢': {0x21, 0x71}, // CENT SIGN
+	'£': {0x21, 0x72}, // POUND SIGN
+	'¬': {0x22, 0x4C}, // NOT SIGN
+}
+
+// buildJISTable は cp932Table を逆引きして Unicode → JIS X 0208 の表を作る。
+// 同じ文字が複数の位置にあるときは、若い（JIS X 0208 本来の）位置を使う。
+func buildJISTable() {
+	jisTable = make(map[rune][2]byte, 7000)
+	for idx, u := range cp932Table {
+		li, ti := idx/188, idx%188
+		if u == 0 || li > jisMaxLead {
+			continue
+		}
+		var c [2]byte
+		if ti < 94 {
+			c = [2]byte{byte(2*li + 0x21), byte(ti + 0x21)}
+		} else {
+			c = [2]byte{byte(2*li + 0x22), byte(ti - 94 + 0x21)}
+		}
+		if _, dup := jisTable[rune(u)]; !dup {
+			jisTable[rune(u)] = c
+		}
+	}
+	for r, c := range jisAliases {
+		jisTable[r] = c
+	}
+}
+
+// EncodeISO2022JP は s を ISO-2022-JP に変換する。表せない文字があれば ok が false になる。
+// 結果は必ず ASCII の状態で終わるので、複数に分けて変換したものを並べてもよい。
+func EncodeISO2022JP(s string) (b []byte, ok bool) {
+	jisOnce.Do(buildJISTable)
+	inJIS := false
+	for _, r := range s {
+		if r < 0x80 {
+			if r == 0x1b || r == 0x0e || r == 0x0f {
+				return nil, false // エスケープ・シフト文字はそのまま通すと解釈が狂う
+			}
+			if inJIS {
+				b = append(b, escASCII...)
+				inJIS = false
+			}
+			b = append(b, byte(r))
+			continue
+		}
+		c, found := jisTable[r]
+		if !found {
+			return nil, false
+		}
+		if !inJIS {
+			b = append(b, escJISX0208...)
+			inJIS = true
+		}
+		b = append(b, c[0], c[1])
+	}
+	if inJIS {
+		b = append(b, escASCII...)
+	}
+	return b, true
+}
@@ -0,0 +1,106 @@
+// Package japanese は日本語の文字コード変換を標準ライブラリだけで行うための小さなパッケージ。
+//
+// Excel などが出力する Shift_JIS（実質的には Windows-31J / CP932）の読み込みに使う。
+// 業務ルールは持たず、Adapter層・Drivers層の両方から技術的な部品として利用する。
+package japanese
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding：入力の文字コード
+type Encoding string
+
+const (
+	EncodingAuto     Encoding = "" // UTF-8 として正しければ UTF-8、そうでなければ Shift_JIS とみなす
+	EncodingUTF8     Encoding = "utf-8"
+	EncodingShiftJIS Encoding = "shift_jis" // CP932 の拡張文字（NEC特殊文字・IBM拡張文字）も含む
+)
+
+// ParseEncoding は "utf8" "sjis" "cp932" などの表記ゆれを吸収して Encoding を返す。
+func ParseEncoding(s string) (Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(s, "-", "_")) {
+	case "", "auto":
+		return EncodingAuto, nil
+	case "utf_8", "utf8":
+		return EncodingUTF8, nil
+	case "shift_jis", "shiftjis", "sjis", "cp932", "windows_31j":
+		return EncodingShiftJIS, nil
+	}
+	return "", fmt.Errorf("unsupported encoding %q", s)
+}
+
+// utf8BOM は UTF-8 の BOM（Excel の「CSV UTF-8」形式が付与する）
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// Decode は指定の文字コードで b を UTF-8 文字列へ変換する。BOM は取り除く。
+func Decode(b []byte, enc Encoding) (string, error) {
+	switch enc {
+	case EncodingAuto:
+		if utf8.Valid(b) {
+			return Decode(b, EncodingUTF8)
+		}
+		return DecodeShiftJIS(b)
+	case EncodingUTF8:
+		if !utf8.Valid(b) {
+			return "", fmt.Errorf("invalid UTF-8 input")
+		}
+		return string(bytes.TrimPrefix(b, utf8BOM)), nil
+	case EncodingShiftJIS:
+		return DecodeShiftJIS(b)
+	}
+	return "", fmt.Errorf("unsupported encoding %q", enc)
+}
+
+// DecodeShiftJIS は Shift_JIS（CP932）のバイト列を UTF-8 文字列へ変換する。
+// 変換できないバイト列があればその位置を含むエラーを返す。
+func DecodeShiftJIS(b []byte) (string, error) {
+	var sb strings.Builder
+	sb.Grow(len(b) * 3 / 2)
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch {
+		case c < 0x80: // ASCII
+			sb.WriteByte(c)
+		case c >= 0xA1 && c <= 0xDF: // 半角カナ
+			sb.WriteRune(rune(0xFF61 + int(c) - 0xA1))
+		default: // 2バイト文字
+			if i+1 >= len(b) {
+				return "", fmt.Errorf("truncated Shift_JIS sequence at byte %d", i)
+			}
+			idx, ok := cp932Index(c, b[i+1])
+			if !ok || cp932Table[idx] == 0 {
+				return "", fmt.Errorf("invalid Shift_JIS sequence 0x%02X%02X at byte %d", c, b[i+1], i)
+			}
+			sb.WriteRune(rune(cp932Table[idx]))
+			i++
+		}
+	}
+	return sb.String(), nil
+}
+
+// cp932Index は2バイト文字から cp932Table のインデックスを求める。
+func cp932Index(lead, trail byte) (int, bool) {
+	var li int
+	switch {
+	case lead >= 0x81 && lead <= 0x9F:
+		li = int(lead) - 0x81
+	case lead >= 0xE0 && lead <= 0xFC:
+		li = int(lead) - 0xE0 + 31
+	default:
+		return 0, false
+	}
+	var ti int
+	switch {
+	case trail >= 0x40 && trail <= 0x7E:
+		ti = int(trail) - 0x40
+	case trail >= 0x80 && trail <= 0xFC:
+		ti = int(trail) - 0x80 + 63
+	default:
+		return 0, false
+	}
+	return li*188 + ti, true
+}
@@ -0,0 +1,211 @@
+package repotest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// RunEmployeeRepo は従業員リポジトリ（EmployeeRepo・EmployeeLister・EmployeeWriter）の契約テストを実行する。
+func RunEmployeeRepo(t *testing.T, newStores Factory) {
+	t.Helper()
+	t.Run("CreateThenFind", func(t *testing.T) { employeeCreateThenFind(t, newStores(t)) })
+	t.Run("FindUnknown", func(t *testing.T) { employeeFindUnknown(t, newStores(t)) })
+	t.Run("CreateDuplicate", func(t *testing.T) { employeeCreateDuplicate(t, newStores(t)) })
+	t.Run("UnknownManager", func(t *testing.T) { employeeUnknownManager(t, newStores(t)) })
+	t.Run("Update", func(t *testing.T) { employeeUpdate(t, newStores(t)) })
+	t.Run("UpdateUnknown", func(t *testing.T) { employeeUpdateUnknown(t, newStores(t)) })
+	t.Run("ListOrderedByID", func(t *testing.T) { employeeListOrdered(t, newStores(t)) })
+	t.Run("ConcurrentCreate", func(t *testing.T) { employeeConcurrentCreate(t, newStores(t)) })
+	t.Run("ChannelsNotShared", func(t *testing.T) { employeeChannelsNotShared(t, newStores(t)) })
+}
+
+// 登録したすべての項目がそのまま読めること
+func employeeCreateThenFind(t *testing.T, st Stores) {
+	boss := newEmployee("boss", "")
+	boss.Role = domain.RoleHR
+	e := newEmployee("e1", "boss")
+	e.Locale = domain.LocaleEnglish
+	e.FrozenQuota = 3
+	e.LeftOn = day(30)
+	quiet, err := domain.ParseQuietHours("22:00-07:00", "Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Notify = domain.NotificationPrefs{Channels: []domain.Channel{domain.ChannelChat, domain.ChannelEmail}, Quiet: quiet}
+	mustCreateEmployees(t, st, boss, e)
+
+	got, err := st.Employees.FindByID("e1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if d := diffEmployee(got, e); len(d) > 0 {
+		t.Fatalf("FindByID returned a different employee:\n%v", d)
+	}
+}
+
+// 見つからなければ ErrEmployeeNotFound
+func employeeFindUnknown(t *testing.T, st Stores) {
+	_, err := st.Employees.FindByID("nobody")
+	wantKind(t, "FindByID(unknown)", err, usecase.ErrEmployeeNotFound)
+}
+
+// 同じIDの登録は ErrEmployeeExists で、先に登録したものは変わらない
+func employeeCreateDuplicate(t *testing.T, st Stores) {
+	e := newEmployee("dup", "")
+	mustCreateEmployees(t, st, e)
+	again := newEmployee("dup", "")
+	again.Name = "someone else"
+	wantKind(t, "Create(duplicate)", st.Employees.Create(&again), usecase.ErrEmployeeExists)
+	got, err := st.Employees.FindByID("dup")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if got.Name != e.Name {
+		t.Fatalf("duplicate Create overwrote the employee: Name = %q", got.Name)
+	}
+}
+
+// 存在しない上長は ErrValidation（外部キー制約）
+func employeeUnknownManager(t *testing.T, st Stores) {
+	e := newEmployee("orphan", "ghost")
+	wantKind(t, "Create(unknown manager)", st.Employees.Create(&e), usecase.ErrValidation)
+	if _, err := st.Employees.FindByID("orphan"); err == nil {
+		t.Fatal("employee with an unknown manager was stored")
+	}
+}
+
+// 更新したすべての項目が読めること
+func employeeUpdate(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("m1", ""), newEmployee("m2", ""), newEmployee("e1", "m1"))
+	e, err := st.Employees.FindByID("e1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	e.Name, e.Email, e.ManagerID, e.Department = "Renamed", "", "m2", "Sales"
+	e.LeftOn, e.FrozenQuota, e.Locale = day(10), 2, domain.LocaleJapanese
+	e.Notify = domain.NotificationPrefs{Channels: []domain.Channel{domain.ChannelEmail}}
+	if err := st.Employees.Update(&e); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	got, err := st.Employees.FindByID("e1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if d := diffEmployee(got, e); len(d) > 0 {
+		t.Fatalf("Update was not stored as given:\n%v", d)
+	}
+}
+
+// 渡した・受け取った通知チャネルを呼び出し側が書き換えても、保存内容は変わらないこと
+func employeeChannelsNotShared(t *testing.T, st Stores) {
+	e := newEmployee("e1", "")
+	e.Notify.Channels = []domain.Channel{domain.ChannelEmail}
+	mustCreateEmployees(t, st, e)
+	e.Notify.Channels[0] = domain.ChannelChat
+	wantChannel := func(when string) {
+		t.Helper()
+		got, err := st.Employees.FindByID("e1")
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		if len(got.Notify.Channels) != 1 || got.Notify.Channels[0] != domain.ChannelEmail {
+			t.Errorf("after %s: Channels = %v, want [%s]", when, got.Notify.Channels, domain.ChannelEmail)
+		}
+	}
+	wantChannel("changing the created value")
+
+	got, err := st.Employees.FindByID("e1")
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	got.Notify.Channels[0] = domain.ChannelChat
+	wantChannel("changing a found value")
+
+	emps, err := st.Employees.List()
+	if err != nil || len(emps) != 1 {
+		t.Fatalf("List = %v, %v", emps, err)
+	}
+	emps[0].Notify.Channels[0] = domain.ChannelChat
+	wantChannel("changing a listed value")
+
+	upd := newEmployee("e1", "")
+	upd.Notify.Channels = []domain.Channel{domain.ChannelEmail}
+	if err := st.Employees.Update(&upd); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	upd.Notify.Channels[0] = domain.ChannelChat
+	wantChannel("changing the updated value")
+}
+
+// 存在しない従業員の更新は ErrEmployeeNotFound で、登録もされない
+func employeeUpdateUnknown(t *testing.T, st Stores) {
+	e := newEmployee("ghost", "")
+	wantKind(t, "Update(unknown)", st.Employees.Update(&e), usecase.ErrEmployeeNotFound)
+	if _, err := st.Employees.FindByID("ghost"); err == nil {
+		t.Fatal("Update of an unknown employee created it")
+	}
+}
+
+// List は登録順によらずID順
+func employeeListOrdered(t *testing.T, st Stores) {
+	if emps, err := st.Employees.List(); err != nil || len(emps) != 0 {
+		t.Fatalf("List on an empty store = %d employees, %v; want none", len(emps), err)
+	}
+	mustCreateEmployees(t, st, newEmployee("c", ""), newEmployee("a", ""), newEmployee("b", "a"))
+	emps, err := st.Employees.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	var got []string
+	for _, e := range emps {
+		got = append(got, e.ID)
+	}
+	if fmt.Sprint(got) != "[a b c]" {
+		t.Fatalf("List order = %v, want [a b c]", got)
+	}
+}
+
+// 同時に登録しても取りこぼさず、同じIDはちょうど1件だけ成功する
+func employeeConcurrentCreate(t *testing.T, st Stores) {
+	const n = 16
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*n)
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			e := newEmployee(fmt.Sprintf("p%02d", i), "")
+			errs <- st.Employees.Create(&e)
+		}(i)
+		go func() {
+			defer wg.Done()
+			e := newEmployee("same", "")
+			if err := st.Employees.Create(&e); err != nil && usecase.KindOf(err) != usecase.ErrConflict {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("concurrent Create: %v", err)
+		}
+	}
+	emps, err := st.Employees.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(emps) != n+1 {
+		t.Fatalf("List after concurrent Create = %d employees, want %d", len(emps), n+1)
+	}
+	for _, e := range emps {
+		if _, err := st.Employees.FindByID(e.ID); err != nil {
+			t.Fatalf("FindByID(%q) after concurrent Create: %v", e.ID, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package repotest
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+)
+
+// StartPostgres は契約テストに使う PostgreSQL に接続し、スキーマを最新にして返す。
+// - REPOTEST_DATABASE_URL があればその DB を使う（中身は PostgresFactory が消すので、使い捨ての DB にすること）
+// - なければ PATH にある initdb / pg_ctl で一時ディレクトリに DB を作って起動し、テストの終わりに止める
+// どちらもできないとき、または "postgres" のドライバが登録されていないときは Skip する
+// （ドライバは呼び出す側のテストで import _ "github.com/lib/pq" などとして登録する）。
+func StartPostgres(t *testing.T) *sql.DB {
+	t.Helper()
+	if !slices.Contains(sql.Drivers(), "postgres") {
+		t.Skip(`no database/sql driver registered as "postgres" (see drivers/stores_test.go)`)
+	}
+	dsn := os.Getenv("REPOTEST_DATABASE_URL")
+	if dsn == "" {
+		dsn = startLocalPostgres(t)
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := waitDB(db, 10*time.Second); err != nil {
+		t.Fatalf("connect postgres: %v", err)
+	}
+	if _, err := drivers.NewMigrator(db, drivers.PostgresDialect{}).Up(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// startLocalPostgres は一時ディレクトリに DB を作って起動し、接続文字列を返す。
+// TCP では待ち受けず、一時ディレクトリの Unix ソケットだけで接続する（他のプロセスと衝突しない）。
+func startLocalPostgres(t *testing.T) string {
+	t.Helper()
+	initdb, err := exec.LookPath("initdb")
+	if err != nil {
+		t.Skip("REPOTEST_DATABASE_URL is not set and initdb is not in PATH")
+	}
+	pgctl, err := exec.LookPath("pg_ctl")
+	if err != nil {
+		t.Skip("REPOTEST_DATABASE_URL is not set and pg_ctl is not in PATH")
+	}
+	dir := t.TempDir()
+	data, sock := filepath.Join(dir, "data"), filepath.Join(dir, "sock")
+	if err := os.Mkdir(sock, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command(initdb, "-D", data, "-U", "repotest", "-A", "trust", "-E", "UTF8", "--no-locale").CombinedOutput(); err != nil {
+		t.Fatalf("initdb: %v\n%s", err, out)
+	}
+	port, err := freePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := fmt.Sprintf("-c listen_addresses='' -k %s -p %d -F", sock, port)
+	if out, err := exec.Command(pgctl, "-D", data, "-o", opts, "-l", filepath.Join(dir, "postgres.log"), "-w", "start").CombinedOutput(); err != nil {
+		t.Fatalf("pg_ctl start: %v\n%s", err, out)
+	}
+	t.Cleanup(func() {
+		_ = exec.Command(pgctl, "-D", data, "-m", "immediate", "-w", "stop").Run()
+	})
+	return fmt.Sprintf("host=%s port=%d user=repotest dbname=postgres sslmode=disable", sock, port)
+}
+
+// freePort は使われていないポート番号を返す（Unix ソケットのファイル名に使うだけなので衝突しにくければよい）。
+func freePort() (int, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitDB は DB が接続を受け付けるまで待つ。
+func waitDB(db *sql.DB, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		err := db.Ping()
+		if err == nil || time.Now().After(deadline) {
+			return err
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// PostgresFactory は db の中身を空にしてから PostgreSQL のリポジトリ一式を返す Factory を作る。
+// 同じ DB を共有するので、サブテストを t.Parallel で並べないこと。
+func PostgresFactory(db *sql.DB) Factory {
+	return func(t *testing.T) Stores {
+		t.Helper()
+		if _, err := db.Exec(`TRUNCATE attachments, leave_counters, leave_requests, employees RESTART IDENTITY CASCADE`); err != nil {
+			t.Fatalf("truncate: %v", err)
+		}
+		return Stores{Employees: drivers.SQLEmployeeRepo{DB: db}, Leaves: drivers.SQLLeaveRepo{DB: db}}
+	}
+}
+
+// StartMySQL は契約テストに使う MySQL に接続し、スキーマを最新にして返す。
+// 接続先は REPOTEST_MYSQL_URL（go-sql-driver/mysql の形式。中身は MySQLFactory が消すので、使い捨ての DB にすること）。
+// 設定されていないとき、または "mysql" のドライバが登録されていないときは Skip する。
+func StartMySQL(t *testing.T) *sql.DB {
+	t.Helper()
+	if !slices.Contains(sql.Drivers(), "mysql") {
+		t.Skip(`no database/sql driver registered as "mysql" (see drivers/stores_test.go)`)
+	}
+	dsn := os.Getenv("REPOTEST_MYSQL_URL")
+	if dsn == "" {
+		t.Skip("REPOTEST_MYSQL_URL is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("open mysql: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := waitDB(db, 10*time.Second); err != nil {
+		t.Fatalf("connect mysql: %v", err)
+	}
+	if _, err := drivers.NewMigrator(db, drivers.MySQLDialect{}).Up(0); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// MySQLFactory は db の中身を空にしてから MySQL のリポジトリ一式を返す Factory を作る。
+// 外部キーのある表は TRUNCATE できないので、参照する側から DELETE して採番を戻す。
+func MySQLFactory(db *sql.DB) Factory {
+	return func(t *testing.T) Stores {
+		t.Helper()
+		for _, q := range []string{
+			`DELETE FROM attachments`, `DELETE FROM leave_counters`, `DELETE FROM leave_requests`,
+			`UPDATE employees SET manager_id = NULL`, `DELETE FROM employees`,
+			`ALTER TABLE leave_requests AUTO_INCREMENT = 1`, `ALTER TABLE attachments AUTO_INCREMENT = 1`,
+		} {
+			if _, err := db.Exec(q); err != nil {
+				t.Fatalf("%s: %v", q, err)
+			}
+		}
+		d := drivers.MySQLDialect{}
+		return Stores{Employees: drivers.SQLEmployeeRepo{DB: db, Dialect: d}, Leaves: drivers.SQLLeaveRepo{DB: db, Dialect: d}}
+	}
+}
+
+// MemoryFactory はメモリ上のリポジトリ一式を返す Factory。
+func MemoryFactory(t *testing.T) Stores {
+	m := drivers.NewMemoryStore()
+	return Stores{Employees: m.Employees(), Leaves: m.Leaves()}
+}
+
+// FileFactory は一時ディレクトリのジャーナルに保存するリポジトリ一式を返す Factory。
+func FileFactory(t *testing.T) Stores {
+	t.Helper()
+	f, err := drivers.OpenFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenFileStore: %v", err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return Stores{Employees: f.Employees(), Leaves: f.Leaves()}
+}
@@ -0,0 +1,317 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// RunLeaveRepo は休暇申請リポジトリ（LeaveRepo・LeaveFinder・LeaveLister・PendingLeaveRepo・
+// LeavePeriodRepo・EmployeeLeavesRepo）の契約テストを実行する。
+func RunLeaveRepo(t *testing.T, newStores Factory) {
+	t.Helper()
+	t.Run("CreateThenFind", func(t *testing.T) { leaveCreateThenFind(t, newStores(t)) })
+	t.Run("FindUnknown", func(t *testing.T) { leaveFindUnknown(t, newStores(t)) })
+	t.Run("UnknownEmployee", func(t *testing.T) { leaveUnknownEmployee(t, newStores(t)) })
+	t.Run("CountFiscalYearBoundary", func(t *testing.T) { leaveCountBoundary(t, newStores(t)) })
+	t.Run("CountByStatus", func(t *testing.T) { leaveCountByStatus(t, newStores(t)) })
+	t.Run("Update", func(t *testing.T) { leaveUpdate(t, newStores(t)) })
+	t.Run("UpdateUnknown", func(t *testing.T) { leaveUpdateUnknown(t, newStores(t)) })
+	t.Run("UpdateStatusChanged", func(t *testing.T) { leaveUpdateStatusChanged(t, newStores(t)) })
+	t.Run("ListPendingOrder", func(t *testing.T) { leaveListPending(t, newStores(t)) })
+	t.Run("ListByEmployee", func(t *testing.T) { leaveListByEmployee(t, newStores(t)) })
+	t.Run("ListOverlapping", func(t *testing.T) { leaveListOverlapping(t, newStores(t)) })
+	t.Run("ConcurrentCreate", func(t *testing.T) { leaveConcurrentCreate(t, newStores(t)) })
+}
+
+// 採番したIDが設定され、登録したすべての項目がそのまま読めること
+func leaveCreateThenFind(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""))
+	req := newLeave("e1", day(7), day(7), baseDate.Add(9*time.Hour))
+	req.Type, req.Reason, req.DayPart = domain.LeaveSick, "通院; 午前のみ, \"半休\"", domain.DayAM
+	req = mustCreateLeave(t, st, req)
+	other := mustCreateLeave(t, st, newLeave("e1", day(8), day(9), baseDate.Add(10*time.Hour)))
+	if other.ID == req.ID {
+		t.Fatalf("Create assigned the same ID %q twice", req.ID)
+	}
+
+	got, err := st.Leaves.FindByID(req.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if d := diffLeave(got, req); len(d) > 0 {
+		t.Fatalf("FindByID returned a different leave request:\n%v", d)
+	}
+}
+
+// 見つからなければ ErrLeaveRequestNotFound（ID の形式は実装ごとに違うので、採番されそうにない数字で試す）
+// 採番の形式に合わないIDも、エラーの種類は同じ（数値の列を持つDBでも内部エラーにしない）
+func leaveFindUnknown(t *testing.T, st Stores) {
+	_, err := st.Leaves.FindByID("987654321")
+	wantKind(t, "FindByID(unknown)", err, usecase.ErrLeaveRequestNotFound)
+	_, err = st.Leaves.FindByID("not-a-number")
+	wantKind(t, "FindByID(malformed)", err, usecase.ErrLeaveRequestNotFound)
+}
+
+// 存在しない従業員の申請は ErrValidation（外部キー制約）
+func leaveUnknownEmployee(t *testing.T, st Stores) {
+	req := newLeave("ghost", day(1), day(1), baseDate)
+	wantKind(t, "Create(unknown employee)", st.Leaves.Create(&req), usecase.ErrValidation)
+	if n, err := st.Leaves.CountThisFiscalYear("ghost", baseDate); err != nil || n != 0 {
+		t.Fatalf("CountThisFiscalYear after rejected Create = %d, %v; want 0", n, err)
+	}
+}
+
+// 年度の開始日時ちょうどに作成した申請はその年度に数え、直前のものは前の年度に数える。他の従業員の申請は数えない
+func leaveCountBoundary(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""), newEmployee("e2", ""))
+	if n, err := st.Leaves.CountThisFiscalYear("e1", baseDate); err != nil || n != 0 {
+		t.Fatalf("CountThisFiscalYear with no requests = %d, %v; want 0", n, err)
+	}
+	prevYear, nextYear := baseDate.AddDate(-1, 0, 0), baseDate.AddDate(1, 0, 0)
+	mustCreateLeave(t, st, newLeave("e1", day(-30), day(-30), baseDate.Add(-time.Second))) // 前年度の最後
+	mustCreateLeave(t, st, newLeave("e1", day(10), day(10), baseDate))                     // 年度の最初
+	mustCreateLeave(t, st, newLeave("e1", day(20), day(21), baseDate.AddDate(0, 6, 0)))
+	mustCreateLeave(t, st, newLeave("e1", day(370), day(370), nextYear)) // 翌年度の最初
+	mustCreateLeave(t, st, newLeave("e2", day(10), day(10), baseDate.Add(time.Hour)))
+
+	wantCounts(t, st, map[string]map[time.Time]int{
+		"e1":     {prevYear: 1, baseDate: 2, nextYear: 1},
+		"e2":     {prevYear: 0, baseDate: 1},
+		"nobody": {baseDate: 0},
+	})
+}
+
+// 承認待ち・承認済みは数え、却下・差し戻し・取消は数えない（ステータスが変われば数え直す）
+func leaveCountByStatus(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("boss", ""), newEmployee("e1", "boss"))
+	var reqs []domain.LeaveRequest
+	for i := 0; i < 5; i++ {
+		reqs = append(reqs, mustCreateLeave(t, st, newLeave("e1", day(10+i), day(10+i), baseDate.Add(time.Duration(i)*time.Hour))))
+	}
+	wantCounts(t, st, map[string]map[time.Time]int{"e1": {baseDate: 5}})
+
+	for i, status := range []domain.LeaveStatus{domain.StatusApproved, domain.StatusRejected, domain.StatusReturned, domain.StatusCancelled} {
+		req := reqs[i]
+		req.Status, req.DecidedAt = status, baseDate.Add(24*time.Hour)
+		if err := st.Leaves.Update(&req, domain.StatusPending); err != nil {
+			t.Fatalf("Update(%s): %v", status, err)
+		}
+	}
+	wantCounts(t, st, map[string]map[time.Time]int{"e1": {baseDate: 2}}) // 承認済み1件 + 承認待ち1件
+
+	// 数えない状態のまま更新しても（催促日時だけ変えるなど）件数は変わらない
+	rejected := reqs[1]
+	rejected.Status, rejected.RemindedAt = domain.StatusRejected, baseDate.Add(48*time.Hour)
+	if err := st.Leaves.Update(&rejected, domain.StatusRejected); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	// 取り込みなどで、最初から却下済みの申請を登録しても数えない
+	done := newLeave("e1", day(40), day(40), baseDate.Add(time.Hour))
+	done.Status = domain.StatusRejected
+	mustCreateLeave(t, st, done)
+	wantCounts(t, st, map[string]map[time.Time]int{"e1": {baseDate: 2}})
+}
+
+// wantCounts は従業員×年度開始日ごとの CountThisFiscalYear を確かめる。
+func wantCounts(t *testing.T, st Stores, want map[string]map[time.Time]int) {
+	t.Helper()
+	for emp, years := range want {
+		for start, n := range years {
+			got, err := st.Leaves.CountThisFiscalYear(emp, start)
+			if err != nil {
+				t.Fatalf("CountThisFiscalYear(%q, %s): %v", emp, start.Format("2006-01-02"), err)
+			}
+			if got != n {
+				t.Errorf("CountThisFiscalYear(%q, %s) = %d, want %d", emp, start.Format("2006-01-02"), got, n)
+			}
+		}
+	}
+}
+
+// Update は状態（ステータス・承認者・各日時・判断コメント）だけを変え、それ以外は変えない
+func leaveUpdate(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("boss", ""), newEmployee("e1", "boss"))
+	req := mustCreateLeave(t, st, newLeave("e1", day(3), day(4), baseDate))
+	want := req
+
+	changed := req
+	changed.Reason, changed.From, changed.Type = "changed", day(100), domain.LeaveSpecial // 変わらないはずの項目
+	changed.Status = domain.StatusApproved
+	changed.ApproverID = "boss"
+	changed.AssignedAt = baseDate.Add(time.Hour)
+	changed.RemindedAt = baseDate.Add(2 * time.Hour)
+	changed.DecidedAt = baseDate.Add(3 * time.Hour)
+	changed.DecisionComment = "enjoy"
+	if err := st.Leaves.Update(&changed, domain.StatusPending); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	want.Status, want.ApproverID, want.DecisionComment = changed.Status, changed.ApproverID, changed.DecisionComment
+	want.AssignedAt, want.RemindedAt, want.DecidedAt = changed.AssignedAt, changed.RemindedAt, changed.DecidedAt
+
+	got, err := st.Leaves.FindByID(req.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if d := diffLeave(got, want); len(d) > 0 {
+		t.Fatalf("Update stored something other than the status fields:\n%v", d)
+	}
+}
+
+// 存在しない申請の更新は ErrLeaveRequestNotFound
+func leaveUpdateUnknown(t *testing.T, st Stores) {
+	req := newLeave("e1", day(1), day(1), baseDate)
+	req.ID = "987654321"
+	wantKind(t, "Update(unknown)", st.Leaves.Update(&req, domain.StatusPending), usecase.ErrLeaveRequestNotFound)
+}
+
+// 保存されているステータスが expected と違えば ErrLeaveRequestChanged で、何も変えない（集計も変えない）
+func leaveUpdateStatusChanged(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("boss", ""), newEmployee("e1", "boss"))
+	req := mustCreateLeave(t, st, newLeave("e1", day(3), day(4), baseDate))
+	cancelled := req
+	cancelled.Status, cancelled.DecidedAt = domain.StatusCancelled, baseDate.Add(time.Hour)
+	if err := st.Leaves.Update(&cancelled, domain.StatusPending); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	// 取消される前に読んだ申請を承認しようとする（取消との競合）
+	stale := req
+	stale.Status, stale.ApproverID, stale.DecidedAt = domain.StatusApproved, "boss", baseDate.Add(2*time.Hour)
+	err := st.Leaves.Update(&stale, domain.StatusPending)
+	wantKind(t, "Update(status changed)", err, usecase.ErrLeaveRequestChanged)
+	if !errors.Is(err, usecase.ErrConflict) {
+		t.Errorf("Update(status changed) = %v, want an ErrConflict", err)
+	}
+
+	got, err := st.Leaves.FindByID(req.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if d := diffLeave(got, cancelled); len(d) > 0 {
+		t.Fatalf("rejected Update changed the stored request:\n%v", d)
+	}
+	wantCounts(t, st, map[string]map[time.Time]int{"e1": {baseDate: 0}})
+}
+
+// 承認待ちの一覧は作成日時の古い順（同じ日時は登録順）で、承認待ち以外は含まない
+func leaveListPending(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""), newEmployee("e2", ""))
+	late := mustCreateLeave(t, st, newLeave("e1", day(1), day(1), baseDate.Add(3*time.Hour)))
+	early := mustCreateLeave(t, st, newLeave("e2", day(2), day(2), baseDate.Add(1*time.Hour)))
+	tie1 := mustCreateLeave(t, st, newLeave("e1", day(3), day(3), baseDate.Add(2*time.Hour)))
+	tie2 := mustCreateLeave(t, st, newLeave("e1", day(4), day(4), baseDate.Add(2*time.Hour)))
+	done := mustCreateLeave(t, st, newLeave("e1", day(5), day(5), baseDate))
+	done.Status, done.DecidedAt = domain.StatusRejected, baseDate.Add(time.Hour)
+	if err := st.Leaves.Update(&done, domain.StatusPending); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	all, err := st.Leaves.ListPending()
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if got, want := fmt.Sprint(ids(all)), fmt.Sprint([]string{early.ID, tie1.ID, tie2.ID, late.ID}); got != want {
+		t.Errorf("ListPending = %s, want %s", got, want)
+	}
+	mine, err := st.Leaves.ListPendingByEmployee("e1")
+	if err != nil {
+		t.Fatalf("ListPendingByEmployee: %v", err)
+	}
+	if got, want := fmt.Sprint(ids(mine)), fmt.Sprint([]string{tie1.ID, tie2.ID, late.ID}); got != want {
+		t.Errorf("ListPendingByEmployee(e1) = %s, want %s", got, want)
+	}
+}
+
+// 従業員ごとの一覧はステータスを問わず作成日時の古い順（同じ日時は登録順）で、他の従業員の申請は含まない
+func leaveListByEmployee(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""), newEmployee("e2", ""))
+	late := mustCreateLeave(t, st, newLeave("e1", day(1), day(1), baseDate.Add(3*time.Hour)))
+	mustCreateLeave(t, st, newLeave("e2", day(2), day(2), baseDate.Add(1*time.Hour)))
+	tie1 := mustCreateLeave(t, st, newLeave("e1", day(3), day(3), baseDate.Add(2*time.Hour)))
+	tie2 := mustCreateLeave(t, st, newLeave("e1", day(4), day(4), baseDate.Add(2*time.Hour)))
+	done := mustCreateLeave(t, st, newLeave("e1", day(5), day(5), baseDate))
+	done.Status, done.DecidedAt = domain.StatusRejected, baseDate.Add(time.Hour)
+	if err := st.Leaves.Update(&done, domain.StatusPending); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	got, err := st.Leaves.ListByEmployee("e1")
+	if err != nil {
+		t.Fatalf("ListByEmployee: %v", err)
+	}
+	if got, want := fmt.Sprint(ids(got)), fmt.Sprint([]string{done.ID, tie1.ID, tie2.ID, late.ID}); got != want {
+		t.Errorf("ListByEmployee(e1) = %s, want %s", got, want)
+	}
+	none, err := st.Leaves.ListByEmployee("nobody")
+	if err != nil || len(none) != 0 {
+		t.Errorf("ListByEmployee(nobody) = %v, %v; want empty", ids(none), err)
+	}
+}
+
+// 期間 [from, to] に1日でも重なる申請を、開始日の早い順（同じ日は登録順）に返す
+func leaveListOverlapping(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""))
+	mustCreateLeave(t, st, newLeave("e1", day(0), day(9), baseDate))               // 前日に終わる
+	touchFrom := mustCreateLeave(t, st, newLeave("e1", day(5), day(10), baseDate)) // 初日に重なる
+	inside := mustCreateLeave(t, st, newLeave("e1", day(12), day(12), baseDate))   // 期間内
+	touchTo := mustCreateLeave(t, st, newLeave("e1", day(20), day(25), baseDate))  // 最終日に重なる
+	covering := mustCreateLeave(t, st, newLeave("e1", day(5), day(30), baseDate))  // 期間を覆う
+	mustCreateLeave(t, st, newLeave("e1", day(21), day(22), baseDate))             // 翌日から
+
+	got, err := st.Leaves.ListOverlapping(day(10), day(20))
+	if err != nil {
+		t.Fatalf("ListOverlapping: %v", err)
+	}
+	want := []string{touchFrom.ID, covering.ID, inside.ID, touchTo.ID}
+	if fmt.Sprint(ids(got)) != fmt.Sprint(want) {
+		t.Fatalf("ListOverlapping(day 10, day 20) = %v, want %v", ids(got), want)
+	}
+}
+
+// 同時に登録しても、すべてに別々のIDが振られて取りこぼさない
+func leaveConcurrentCreate(t *testing.T, st Stores) {
+	mustCreateEmployees(t, st, newEmployee("e1", ""))
+	const n = 32
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	seen := map[string]bool{}
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := newLeave("e1", day(i), day(i), baseDate.Add(time.Duration(i)*time.Minute))
+			if err := st.Leaves.Create(&req); err != nil {
+				errs <- err
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if seen[req.ID] {
+				errs <- fmt.Errorf("ID %q was assigned twice", req.ID)
+			}
+			seen[req.ID] = true
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent Create: %v", err)
+	}
+	if c, err := st.Leaves.CountThisFiscalYear("e1", baseDate); err != nil || c != n {
+		t.Fatalf("CountThisFiscalYear after concurrent Create = %d, %v; want %d", c, err, n)
+	}
+	pending, err := st.Leaves.ListPending()
+	if err != nil {
+		t.Fatalf("ListPending: %v", err)
+	}
+	if len(pending) != n {
+		t.Fatalf("ListPending after concurrent Create = %d requests, want %d", len(pending), n)
+	}
+}
@@ -0,0 +1,200 @@
+// Package repotest は UseCase層のリポジトリ（EmployeeRepo・LeaveRepo など）の契約テストを提供する。
+//
+// ports.go のインターフェースが約束していること（登録したものがそのまま読めるか、見つからないときの
+// エラーの種類、年度の境界での数え方、一覧の並び順、同時に呼ばれたときの振る舞い）を1か所に書き、
+// どの実装（メモリ・ファイル・PostgreSQL・MySQL …）も同じテストで確かめられるようにする。
+// 新しい保存先を作ったら、空のリポジトリ一式を作る Factory を渡して Run を呼ぶ。
+//
+//	package drivers_test
+//
+//	func TestMemoryStore(t *testing.T) { repotest.Run(t, repotest.MemoryFactory) }
+//	func TestFileStore(t *testing.T)   { repotest.Run(t, repotest.FileFactory) }
+//
+//	func TestPostgresStore(t *testing.T) {
+//		db := repotest.StartPostgres(t) // DB がなければ Skip する
+//		repotest.Run(t, repotest.PostgresFactory(db))
+//	}
+//
+//	func TestMySQLStore(t *testing.T) {
+//		db := repotest.StartMySQL(t) // REPOTEST_MYSQL_URL がなければ Skip する
+//		repotest.Run(t, repotest.MySQLFactory(db))
+//	}
+//
+// repotest は drivers を import するので、呼び出すテストは外部テストパッケージ（drivers_test）に置く。
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// EmployeeStore：従業員リポジトリが満たすポートの全部
+type EmployeeStore interface {
+	usecase.EmployeeRepo
+	usecase.EmployeeLister
+	usecase.EmployeeWriter
+}
+
+// LeaveStore：休暇申請リポジトリが満たすポートの全部
+type LeaveStore interface {
+	usecase.LeaveRepo
+	usecase.LeaveFinder
+	usecase.LeaveLister
+	usecase.PendingLeaveRepo
+	usecase.LeavePeriodRepo
+	usecase.EmployeeLeavesRepo
+}
+
+// Stores：契約テストの対象になるリポジトリ一式（同じ保存先を共有していること）
+type Stores struct {
+	Employees EmployeeStore
+	Leaves    LeaveStore
+}
+
+// Factory は空の保存先に対するリポジトリ一式を作る。サブテストごとに呼ばれる。
+// 後片付けが必要なら t.Cleanup で登録する。
+type Factory func(t *testing.T) Stores
+
+// Run は従業員・休暇申請の両方の契約テストを実行する。
+func Run(t *testing.T, newStores Factory) {
+	t.Helper()
+	t.Run("EmployeeRepo", func(t *testing.T) { RunEmployeeRepo(t, newStores) })
+	t.Run("LeaveRepo", func(t *testing.T) { RunLeaveRepo(t, newStores) })
+}
+
+// --------------------------------------------------------
+// テスト用のデータと比較
+// --------------------------------------------------------
+
+// baseDate：テストデータの日付の基準（domain.FiscalYearStart の年度の開始日でもある）
+var baseDate = time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+func day(offset int) time.Time { return baseDate.AddDate(0, 0, offset) }
+
+// newEmployee はテスト用の従業員を作る。
+func newEmployee(id, managerID string) domain.Employee {
+	return domain.Employee{
+		ID: id, Name: "Employee " + id, Email: id + "@example.com",
+		HireDate: time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC), ManagerID: managerID,
+		Department: "Dev", Role: domain.RoleEmployee,
+	}
+}
+
+// newLeave はテスト用の休暇申請を作る（CreatedAt は秒単位。DB の精度に左右されないようにする）。
+func newLeave(empID string, from, to time.Time, createdAt time.Time) domain.LeaveRequest {
+	return domain.LeaveRequest{
+		EmployeeID: empID, Type: domain.LeavePaid, Reason: "contract test",
+		From: from, To: to, Status: domain.StatusPending, CreatedAt: createdAt.Truncate(time.Second),
+	}
+}
+
+// mustCreateEmployees は従業員を順に登録する（上長を先に渡すこと）。
+func mustCreateEmployees(t *testing.T, st Stores, emps ...domain.Employee) {
+	t.Helper()
+	for _, e := range emps {
+		if err := st.Employees.Create(&e); err != nil {
+			t.Fatalf("Create employee %q: %v", e.ID, err)
+		}
+	}
+}
+
+// mustCreateLeave は休暇申請を登録し、採番された申請を返す。
+func mustCreateLeave(t *testing.T, st Stores, req domain.LeaveRequest) domain.LeaveRequest {
+	t.Helper()
+	if err := st.Leaves.Create(&req); err != nil {
+		t.Fatalf("Create leave for %q: %v", req.EmployeeID, err)
+	}
+	if req.ID == "" {
+		t.Fatalf("Create leave for %q: ID was not assigned", req.EmployeeID)
+	}
+	return req
+}
+
+// wantKind は err が usecase のエラー種類 kind であることを確かめる。
+// kind には usecase.ErrEmployeeNotFound のような、種類を細かくしたエラーも渡せる。
+func wantKind(t *testing.T, what string, err, kind error) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("%s: got nil error, want %v", what, kind)
+	}
+	if got := usecase.KindOf(err); !errors.Is(err, kind) || !errors.Is(kind, got) {
+		t.Fatalf("%s: got error kind %v (%v), want %v", what, got, err, kind)
+	}
+}
+
+// sameTime は時刻が同じか（ゼロ値どうしも同じとみなす）を返す。
+func sameTime(a, b time.Time) bool {
+	return a.IsZero() && b.IsZero() || a.Equal(b)
+}
+
+// sameDate は日付（年月日）が同じかを返す。DATE 型の列はタイムゾーンを持たないので年月日だけを比べる。
+func sameDate(a, b time.Time) bool {
+	if a.IsZero() || b.IsZero() {
+		return a.IsZero() && b.IsZero()
+	}
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// diffEmployee は2人の従業員の違う項目を返す（同じなら空）。
+func diffEmployee(got, want domain.Employee) []string {
+	var diffs []string
+	check := func(field string, ok bool, g, w any) {
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, want %v", field, g, w))
+		}
+	}
+	check("ID", got.ID == want.ID, got.ID, want.ID)
+	check("Name", got.Name == want.Name, got.Name, want.Name)
+	check("Email", got.Email == want.Email, got.Email, want.Email)
+	check("HireDate", sameDate(got.HireDate, want.HireDate), got.HireDate, want.HireDate)
+	check("ManagerID", got.ManagerID == want.ManagerID, got.ManagerID, want.ManagerID)
+	check("Department", got.Department == want.Department, got.Department, want.Department)
+	check("Role", got.Role == want.Role, got.Role, want.Role)
+	check("LeftOn", sameDate(got.LeftOn, want.LeftOn), got.LeftOn, want.LeftOn)
+	check("FrozenQuota", got.FrozenQuota == want.FrozenQuota, got.FrozenQuota, want.FrozenQuota)
+	check("Locale", got.Locale == want.Locale, got.Locale, want.Locale)
+	check("Notify.Channels", fmt.Sprint(got.Notify.Channels) == fmt.Sprint(want.Notify.Channels), got.Notify.Channels, want.Notify.Channels)
+	check("Notify.Quiet", got.Notify.Quiet == want.Notify.Quiet, got.Notify.Quiet, want.Notify.Quiet)
+	return diffs
+}
+
+// diffLeave は2件の休暇申請の違う項目を返す（同じなら空）。
+func diffLeave(got, want domain.LeaveRequest) []string {
+	var diffs []string
+	check := func(field string, ok bool, g, w any) {
+		if !ok {
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, want %v", field, g, w))
+		}
+	}
+	check("ID", got.ID == want.ID, got.ID, want.ID)
+	check("EmployeeID", got.EmployeeID == want.EmployeeID, got.EmployeeID, want.EmployeeID)
+	check("Type", got.Type == want.Type, got.Type, want.Type)
+	check("Reason", got.Reason == want.Reason, got.Reason, want.Reason)
+	check("From", sameDate(got.From, want.From), got.From, want.From)
+	check("To", sameDate(got.To, want.To), got.To, want.To)
+	check("DayPart", got.DayPart == want.DayPart, got.DayPart, want.DayPart)
+	check("Status", got.Status == want.Status, got.Status, want.Status)
+	check("CreatedAt", sameTime(got.CreatedAt, want.CreatedAt), got.CreatedAt, want.CreatedAt)
+	check("ApproverID", got.ApproverID == want.ApproverID, got.ApproverID, want.ApproverID)
+	check("AssignedAt", sameTime(got.AssignedAt, want.AssignedAt), got.AssignedAt, want.AssignedAt)
+	check("RemindedAt", sameTime(got.RemindedAt, want.RemindedAt), got.RemindedAt, want.RemindedAt)
+	check("DecidedAt", sameTime(got.DecidedAt, want.DecidedAt), got.DecidedAt, want.DecidedAt)
+	check("DecisionComment", got.DecisionComment == want.DecisionComment, got.DecisionComment, want.DecisionComment)
+	return diffs
+}
+
+// ids は申請IDの一覧を返す（並び順の比較用）。
+func ids(reqs []domain.LeaveRequest) []string {
+	out := make([]string, 0, len(reqs))
+	for _, r := range reqs {
+		out = append(out, r.ID)
+	}
+	return out
+}
@@ -0,0 +1,194 @@
+// Package scimtest は IdP の代わりに SCIM 2.0 のリクエストを送るテスト用のクライアントを提供する。
+//
+// smtptest・webhooktest と同じく、外部のサービスなしで手元で確かめるためのもの。
+// リソースは型を決めずに JSON のまま（Resource）扱うので、サーバがどの属性を返すかもそのまま確認できる。
+// サーバが SCIM のエラーを返したら *Error になる。
+//
+//	c := scimtest.NewClient(srv.URL+"/scim/v2", "token")
+//	u, err := c.Create("/Users", scimtest.Resource{
+//		"schemas":     []string{scimtest.UserSchema},
+//		"userName":    "e1",
+//		"displayName": "Employee 1",
+//	})
+//	_, err = c.Patch("/Users/e1", scimtest.Replace("active", false))
+//	users, err := c.ListAll("/Users", `userName eq "e1"`, 10) // IdP と同じくページを送りながら全件取る
+package scimtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SCIM のスキーマ
+const (
+	UserSchema       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	GroupSchema      = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	EnterpriseSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+	PatchOpSchema    = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+)
+
+// Resource：User・Group などのリソース（JSON のまま）
+type Resource map[string]any
+
+// String は属性の文字列の値を返す（なければ空）。
+func (r Resource) String(attr string) string {
+	s, _ := r[attr].(string)
+	return s
+}
+
+// ListResponse：一覧の1ページ
+type ListResponse struct {
+	TotalResults int        `json:"totalResults"`
+	StartIndex   int        `json:"startIndex"`
+	ItemsPerPage int        `json:"itemsPerPage"`
+	Resources    []Resource `json:"Resources"`
+}
+
+// Op：PATCH の操作1つ
+type Op struct {
+	Op    string `json:"op"`
+	Path  string `json:"path,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// Add・Replace・Remove は PATCH の操作を作る。
+func Add(path string, value any) Op     { return Op{Op: "add", Path: path, Value: value} }
+func Replace(path string, value any) Op { return Op{Op: "replace", Path: path, Value: value} }
+func Remove(path string) Op             { return Op{Op: "remove", Path: path} }
+
+// Error：サーバが返した SCIM のエラー
+type Error struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *Error) Error() string {
+	if e.ScimType != "" {
+		return fmt.Sprintf("scim: %d %s: %s", e.Status, e.ScimType, e.Detail)
+	}
+	return fmt.Sprintf("scim: %d: %s", e.Status, e.Detail)
+}
+
+// Client：SCIM クライアント
+type Client struct {
+	BaseURL    string       // 例 "http://localhost:8080/scim/v2"
+	Token      string       // Authorization: Bearer で送るトークン
+	HTTPClient *http.Client // nil なら http.DefaultClient
+}
+
+// NewClient はクライアントを作る。
+func NewClient(baseURL, token string) *Client {
+	return &Client{BaseURL: strings.TrimSuffix(baseURL, "/"), Token: token}
+}
+
+// Get はリソースを取得する（path は "/Users/e1" など）。
+func (c *Client) Get(path string) (Resource, error) {
+	var res Resource
+	return res, c.do(http.MethodGet, path, nil, &res)
+}
+
+// Create はリソースを登録する（path は "/Users" など）。
+func (c *Client) Create(path string, r Resource) (Resource, error) {
+	var res Resource
+	return res, c.do(http.MethodPost, path, r, &res)
+}
+
+// Replace はリソースを置き換える（PUT）。
+func (c *Client) Replace(path string, r Resource) (Resource, error) {
+	var res Resource
+	return res, c.do(http.MethodPut, path, r, &res)
+}
+
+// Patch はリソースに操作を反映する。
+func (c *Client) Patch(path string, ops ...Op) (Resource, error) {
+	body := map[string]any{"schemas": []string{PatchOpSchema}, "Operations": ops}
+	var res Resource
+	return res, c.do(http.MethodPatch, path, body, &res)
+}
+
+// Delete はリソースを削除する。
+func (c *Client) Delete(path string) error {
+	return c.do(http.MethodDelete, path, nil, nil)
+}
+
+// List は一覧の1ページを取得する（startIndex は 1 始まり。filter が空なら全件）。
+func (c *Client) List(path, filter string, startIndex, count int) (ListResponse, error) {
+	q := url.Values{}
+	if filter != "" {
+		q.Set("filter", filter)
+	}
+	q.Set("startIndex", strconv.Itoa(startIndex))
+	q.Set("count", strconv.Itoa(count))
+	var res ListResponse
+	return res, c.do(http.MethodGet, path+"?"+q.Encode(), nil, &res)
+}
+
+// ListAll は pageSize 件ずつページを送り、filter に合うリソースをすべて取得する。
+func (c *Client) ListAll(path, filter string, pageSize int) ([]Resource, error) {
+	var all []Resource
+	for start := 1; ; {
+		page, err := c.List(path, filter, start, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page.Resources...)
+		if len(page.Resources) == 0 || len(all) >= page.TotalResults {
+			return all, nil
+		}
+		start += len(page.Resources)
+	}
+}
+
+func (c *Client) do(method, path string, body, out any) error {
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.BaseURL+path, rd)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	req.Header.Set("Accept", "application/scim+json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/scim+json")
+	}
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			ScimType string `json:"scimType"`
+			Detail   string `json:"detail"`
+		}
+		if json.Unmarshal(b, &e) != nil || e.Detail == "" {
+			e.Detail = strings.TrimSpace(string(b))
+		}
+		return &Error{Status: resp.StatusCode, ScimType: e.ScimType, Detail: e.Detail}
+	}
+	if out == nil || len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, out)
+}
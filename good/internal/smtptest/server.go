@@ -0,0 +1,322 @@
+// Package smtptest はテスト用にプロセス内で動く SMTP サーバを提供する。
+//
+// net/http/httptest と同じ感覚で使う。受け取ったメールは送らずに保持し、
+// テストから宛先や本文を確認できる。対応するのは送信に必要な最小限のコマンド
+// （EHLO/HELO, STARTTLS, AUTH PLAIN, MAIL, RCPT, DATA, RSET, NOOP, QUIT）だけ。
+//
+//	srv := smtptest.NewServer()
+//	defer srv.Close()
+//	// srv.Host, srv.Port に向けて送信する
+//	msgs := srv.Messages()
+package smtptest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Message：受け取ったメール1通
+type Message struct {
+	From string   // MAIL FROM の送信者
+	To   []string // RCPT TO の宛先（ヘッダの To ではない）
+	Data []byte   // DATA の中身（ヘッダと本文。ドット・スタッフィングは解除済み）
+	TLS  bool     // STARTTLS 後に受け取ったか
+	Auth string   // AUTH で認証したユーザー名（認証していなければ空）
+}
+
+// Server：テスト用 SMTP サーバ
+type Server struct {
+	Addr string // "127.0.0.1:ポート"
+	Host string
+	Port int
+
+	// 以下はサーバ起動後、クライアントが接続する前に設定する。
+	// Username / Password を設定すると AUTH PLAIN でその組だけを受け付ける（空なら AUTH を広告しない）
+	Username string
+	Password string
+	// RejectRcpt を設定すると、その関数が true を返す宛先を 550 で拒否する
+	RejectRcpt func(addr string) bool
+
+	ln       net.Listener
+	tlsConf  *tls.Config // nil なら STARTTLS を広告しない
+	certPool *x509.CertPool
+
+	mu   sync.Mutex
+	msgs []Message
+	wg   sync.WaitGroup
+}
+
+// NewServer は STARTTLS なしのサーバを起動する。
+func NewServer() *Server {
+	s := &Server{}
+	s.start()
+	return s
+}
+
+// NewStartTLSServer は自己署名証明書で STARTTLS に対応したサーバを起動する。
+// クライアントは ClientTLSConfig の設定で接続すれば証明書を検証できる。
+func NewStartTLSServer() *Server {
+	s := &Server{}
+	cert, pool, err := selfSignedCert()
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: %v", err))
+	}
+	s.tlsConf = &tls.Config{Certificates: []tls.Certificate{cert}}
+	s.certPool = pool
+	s.start()
+	return s
+}
+
+// ClientTLSConfig はこのサーバの証明書を信頼する TLS 設定を返す（STARTTLS なしのサーバでは nil）。
+func (s *Server) ClientTLSConfig() *tls.Config {
+	if s.certPool == nil {
+		return nil
+	}
+	return &tls.Config{RootCAs: s.certPool, ServerName: s.Host}
+}
+
+// Messages は受け取ったメールのコピーを受信順に返す。
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.msgs...)
+}
+
+// Reset は受け取ったメールを捨てる。
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.msgs = nil
+}
+
+// Close はサーバを止め、処理中の接続が終わるまで待つ。
+func (s *Server) Close() {
+	s.ln.Close()
+	s.wg.Wait()
+}
+
+func (s *Server) start() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: failed to listen: %v", err))
+	}
+	s.ln = ln
+	s.Addr = ln.Addr().String()
+	host, port, _ := net.SplitHostPort(s.Addr)
+	s.Host = host
+	s.Port, _ = strconv.Atoi(port)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				s.serve(conn)
+			}()
+		}
+	}()
+}
+
+// session：1接続分の状態
+type session struct {
+	conn net.Conn
+	tp   *textproto.Conn
+	tls  bool
+	auth string
+	from string
+	to   []string
+}
+
+func (s *Server) serve(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(time.Minute))
+	ss := &session{conn: conn, tp: textproto.NewConn(conn)}
+	ss.reply(220, "smtptest ESMTP ready")
+	for {
+		line, err := ss.tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO":
+			ext := []string{"smtptest", "8BITMIME", "PIPELINING"}
+			if s.tlsConf != nil && !ss.tls {
+				ext = append(ext, "STARTTLS")
+			}
+			if s.Username != "" {
+				ext = append(ext, "AUTH PLAIN")
+			}
+			ss.replyLines(250, ext)
+		case "HELO":
+			ss.reply(250, "smtptest")
+		case "STARTTLS":
+			if s.tlsConf == nil || ss.tls {
+				ss.reply(502, "STARTTLS not available")
+				continue
+			}
+			ss.reply(220, "ready to start TLS")
+			tc := tls.Server(conn, s.tlsConf)
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			ss = &session{conn: tc, tp: textproto.NewConn(tc), tls: true}
+		case "AUTH":
+			ss.authPlain(s, arg)
+		case "MAIL":
+			addr, ok := parsePath(arg, "FROM:")
+			if !ok {
+				ss.reply(501, "syntax: MAIL FROM:<address>")
+				continue
+			}
+			if s.Username != "" && ss.auth == "" {
+				ss.reply(530, "authentication required")
+				continue
+			}
+			ss.from, ss.to = addr, nil
+			ss.reply(250, "ok")
+		case "RCPT":
+			addr, ok := parsePath(arg, "TO:")
+			switch {
+			case !ok:
+				ss.reply(501, "syntax: RCPT TO:<address>")
+			case ss.from == "":
+				ss.reply(503, "need MAIL first")
+			case s.RejectRcpt != nil && s.RejectRcpt(addr):
+				ss.reply(550, "no such user")
+			default:
+				ss.to = append(ss.to, addr)
+				ss.reply(250, "ok")
+			}
+		case "DATA":
+			if len(ss.to) == 0 {
+				ss.reply(503, "need RCPT first")
+				continue
+			}
+			ss.reply(354, "end data with <CR><LF>.<CR><LF>")
+			data, err := ss.tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.msgs = append(s.msgs, Message{From: ss.from, To: ss.to, Data: data, TLS: ss.tls, Auth: ss.auth})
+			s.mu.Unlock()
+			ss.from, ss.to = "", nil
+			ss.reply(250, "ok: queued")
+		case "RSET":
+			ss.from, ss.to = "", nil
+			ss.reply(250, "ok")
+		case "NOOP":
+			ss.reply(250, "ok")
+		case "QUIT":
+			ss.reply(221, "bye")
+			return
+		default:
+			ss.reply(502, "command not implemented")
+		}
+	}
+}
+
+// authPlain は AUTH PLAIN（初期応答つき・なしの両方）を処理する。
+func (ss *session) authPlain(s *Server, arg string) {
+	mech, resp, _ := strings.Cut(arg, " ")
+	if s.Username == "" || !strings.EqualFold(mech, "PLAIN") {
+		ss.reply(504, "unrecognized authentication type")
+		return
+	}
+	if resp == "" {
+		ss.reply(334, "")
+		line, err := ss.tp.ReadLine()
+		if err != nil {
+			return
+		}
+		resp = line
+	}
+	raw, err := base64.StdEncoding.DecodeString(resp)
+	parts := strings.Split(string(raw), "\x00")
+	if err != nil || len(parts) != 3 || parts[1] != s.Username || parts[2] != s.Password {
+		ss.reply(535, "authentication failed")
+		return
+	}
+	ss.auth = parts[1]
+	ss.reply(235, "authenticated")
+}
+
+func (ss *session) reply(code int, msg string) {
+	_ = ss.tp.PrintfLine("%d %s", code, msg)
+}
+
+func (ss *session) replyLines(code int, lines []string) {
+	for i, l := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		_ = ss.tp.PrintfLine("%d%s%s", code, sep, l)
+	}
+}
+
+// parsePath は "FROM:<addr> SIZE=..." のような引数からアドレスを取り出す。
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(rest, "<") {
+		return "", false
+	}
+	end := strings.IndexByte(rest, '>')
+	if end < 0 {
+		return "", false
+	}
+	return rest[1:end], true
+}
+
+// selfSignedCert は 127.0.0.1 / localhost 用の自己署名証明書を作る。
+func selfSignedCert() (tls.Certificate, *x509.CertPool, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "smtptest"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool, nil
+}
@@ -0,0 +1,298 @@
+// Package sqlfake はテスト用に、決めたとおりに応答する database/sql のドライバを提供する。
+//
+// smtptest・webhooktest と同じく、外部のサービス（ここでは DB）なしで手元で確かめるためのもの。
+// 方言ごとに違う SQL（プレースホルダ・採番した ID の受け取り方・時刻の値）を、本物の DB なしで確かめる。
+// 実行されるはずのクエリを順に登録しておき、クエリ（空白の違いは無視した部分一致）と引数が合えば、
+// 登録した結果を返す。合わなければエラーを返し、Done で報告する。
+// トランザクションの BEGIN・COMMIT・ROLLBACK は登録しなくても受け付け、Log に残す。
+//
+//	db, fake := sqlfake.Open()
+//	fake.ExpectExec("INSERT INTO leave_requests").WithArgs("e1", ...).WillReturnResult(42, 1)
+//	fake.ExpectQuery("SELECT requests FROM leave_counters WHERE employee_id=?").
+//		WillReturnRows([]string{"requests"}, []any{3})
+//	repo := drivers.SQLLeaveRepo{DB: db, Dialect: drivers.MySQLDialect{}}
+//	// repo を呼ぶ
+//	if err := fake.Done(); err != nil {
+//		t.Fatal(err)
+//	}
+package sqlfake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Expectation：実行されるはずのクエリ1つと、その結果
+type Expectation struct {
+	kind      string // "exec" か "query"
+	query     string
+	args      []driver.Value
+	checkArgs bool
+	result    driver.Result
+	columns   []string
+	rows      [][]driver.Value
+	err       error
+}
+
+// WithArgs は引数も確かめるようにする（database/sql が変換した後の値で比べる。時刻はタイムゾーンまで比べる）。
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.checkArgs = true
+	e.args = make([]driver.Value, len(args))
+	for i, a := range args {
+		v, err := driver.DefaultParameterConverter.ConvertValue(a)
+		if err != nil {
+			panic(fmt.Sprintf("sqlfake: arg %d: %v", i, err))
+		}
+		e.args[i] = v
+	}
+	return e
+}
+
+// WillReturnResult は Exec の結果（LastInsertId と RowsAffected）を決める。
+func (e *Expectation) WillReturnResult(lastInsertID, rowsAffected int64) *Expectation {
+	e.result = result{lastInsertID, rowsAffected}
+	return e
+}
+
+// WillReturnRows は Query の結果の列と行を決める（値は time.Time・[]byte・string・整数・nil など）。
+func (e *Expectation) WillReturnRows(columns []string, rows ...[]any) *Expectation {
+	e.columns = columns
+	e.rows = nil
+	for _, r := range rows {
+		vs := make([]driver.Value, len(r))
+		for i, a := range r {
+			v, err := driver.DefaultParameterConverter.ConvertValue(a)
+			if err != nil {
+				panic(fmt.Sprintf("sqlfake: row value %d: %v", i, err))
+			}
+			vs[i] = v
+		}
+		e.rows = append(e.rows, vs)
+	}
+	return e
+}
+
+// WillReturnError は Exec・Query がエラーを返すようにする。
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Fake：決めたとおりに応答する DB
+type Fake struct {
+	mu       sync.Mutex
+	expects  []*Expectation
+	next     int
+	log      []string
+	failures []string
+}
+
+// Open は Fake につながった *sql.DB を返す。
+func Open() (*sql.DB, *Fake) {
+	f := &Fake{}
+	return sql.OpenDB(connector{f}), f
+}
+
+// ExpectExec は次に Exec されるクエリを登録する（結果を決めなければ RowsAffected 1 を返す）。
+func (f *Fake) ExpectExec(query string) *Expectation {
+	return f.expect("exec", query)
+}
+
+// ExpectQuery は次に Query（QueryRow）されるクエリを登録する（結果を決めなければ0行を返す）。
+func (f *Fake) ExpectQuery(query string) *Expectation {
+	return f.expect("query", query)
+}
+
+func (f *Fake) expect(kind, query string) *Expectation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	e := &Expectation{kind: kind, query: query, result: result{0, 1}}
+	f.expects = append(f.expects, e)
+	return e
+}
+
+// Log は受け付けたクエリ（BEGIN・COMMIT・ROLLBACK を含む）のコピーを実行順に返す。
+func (f *Fake) Log() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.log...)
+}
+
+// Done は登録したクエリがすべて実行され、合わないクエリがなかったかを返す。
+func (f *Fake) Done() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	msgs := append([]string(nil), f.failures...)
+	for _, e := range f.expects[f.next:] {
+		msgs = append(msgs, fmt.Sprintf("%s %q was not executed", e.kind, e.query))
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New("sqlfake: " + strings.Join(msgs, "; "))
+}
+
+// take は次に登録されたクエリと照らし合わせ、合えばそれを返す。
+func (f *Fake) take(kind, query string, args []driver.NamedValue) (*Expectation, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, query)
+	fail := func(format string, a ...any) error {
+		msg := fmt.Sprintf(format, a...)
+		f.failures = append(f.failures, msg)
+		return errors.New("sqlfake: " + msg)
+	}
+	if f.next >= len(f.expects) {
+		return nil, fail("unexpected %s %q", kind, query)
+	}
+	e := f.expects[f.next]
+	if e.kind != kind || !strings.Contains(normalize(query), normalize(e.query)) {
+		return nil, fail("got %s %q, want %s %q", kind, query, e.kind, e.query)
+	}
+	if e.checkArgs {
+		got := make([]driver.Value, len(args))
+		for i, a := range args {
+			got[i] = a.Value
+		}
+		if !sameValues(got, e.args) {
+			return nil, fail("%s %q: got args %v, want %v", kind, query, got, e.args)
+		}
+	}
+	f.next++
+	return e, nil
+}
+
+func (f *Fake) record(stmt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.log = append(f.log, stmt)
+}
+
+// normalize は連続する空白を1つにする。
+func normalize(s string) string { return strings.Join(strings.Fields(s), " ") }
+
+// sameValues は引数が同じかを返す。時刻は同じ時点で、タイムゾーンの名前も同じなら同じとみなす。
+func sameValues(got, want []driver.Value) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		gt, ok1 := got[i].(time.Time)
+		wt, ok2 := want[i].(time.Time)
+		if ok1 && ok2 {
+			if !gt.Equal(wt) || gt.Location().String() != wt.Location().String() {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(got[i], want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// --------------------------------------------------------
+// database/sql/driver の実装
+// --------------------------------------------------------
+
+type connector struct{ f *Fake }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return conn(c), nil }
+func (c connector) Driver() driver.Driver                        { return fakeDriver{c.f} }
+
+type fakeDriver struct{ f *Fake }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return conn{d.f}, nil }
+
+type conn struct{ f *Fake }
+
+func (c conn) Prepare(query string) (driver.Stmt, error) { return stmt{c, query}, nil }
+func (c conn) Close() error                              { return nil }
+
+func (c conn) Begin() (driver.Tx, error) {
+	c.f.record("BEGIN")
+	return tx(c), nil
+}
+
+func (c conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, err := c.f.take("exec", query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.result, nil
+}
+
+func (c conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e, err := c.f.take("query", query, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &rows{columns: e.columns, values: e.rows}, nil
+}
+
+type stmt struct {
+	c     conn
+	query string
+}
+
+func (s stmt) Close() error  { return nil }
+func (s stmt) NumInput() int { return -1 }
+
+func (s stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.c.ExecContext(context.Background(), s.query, named(args))
+}
+
+func (s stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.c.QueryContext(context.Background(), s.query, named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	nvs := make([]driver.NamedValue, len(args))
+	for i, a := range args {
+		nvs[i] = driver.NamedValue{Ordinal: i + 1, Value: a}
+	}
+	return nvs
+}
+
+type tx struct{ f *Fake }
+
+func (t tx) Commit() error   { t.f.record("COMMIT"); return nil }
+func (t tx) Rollback() error { t.f.record("ROLLBACK"); return nil }
+
+type result struct{ lastInsertID, rowsAffected int64 }
+
+func (r result) LastInsertId() (int64, error) { return r.lastInsertID, nil }
+func (r result) RowsAffected() (int64, error) { return r.rowsAffected, nil }
+
+type rows struct {
+	columns []string
+	values  [][]driver.Value
+	i       int
+}
+
+func (r *rows) Columns() []string { return r.columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.values) {
+		return io.EOF
+	}
+	copy(dest, r.values[r.i])
+	r.i++
+	return nil
+}
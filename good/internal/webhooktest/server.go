@@ -0,0 +1,121 @@
+// Package webhooktest はテスト用にプロセス内で動くチャットの Incoming Webhook を提供する。
+//
+// net/http/httptest の上に作ってあり、smtptest と同じ感覚で使う。
+// 受け取った投稿はどこにも送らずに保持し、テストからパス（投稿先）や本文を確認できる。
+// 部署ごとの投稿先は srv.URL+"/dev" のようにパスを変えて作る。
+//
+//	srv := webhooktest.NewServer()
+//	defer srv.Close()
+//	srv.FailNext(http.StatusTooManyRequests, "1") // 次の1回は 429（Retry-After: 1）を返す
+//	// srv.URL+"/dev" に向けて投稿する
+//	posts := srv.Posts()
+package webhooktest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+)
+
+// Post：受け取った投稿1件
+type Post struct {
+	Path        string // 投稿先のパス（"/dev" など）
+	ContentType string
+	Body        []byte
+	Text        string // 本文が {"text": "..."} の JSON なら text の値
+}
+
+// Failure：わざと返す失敗の応答
+type Failure struct {
+	Status     int
+	RetryAfter string // 空なら Retry-After を付けない
+}
+
+// Server：テスト用 Webhook サーバ
+type Server struct {
+	URL string
+
+	srv *httptest.Server
+
+	mu       sync.Mutex
+	posts    []Post
+	failures []Failure // 先頭から順に、次の投稿への応答として使う
+	attempts int       // 失敗させたものも含めて受け付けた回数
+}
+
+// NewServer はサーバを起動する。
+func NewServer() *Server {
+	s := &Server{}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serve))
+	s.URL = s.srv.URL
+	return s
+}
+
+// FailNext は次の投稿に status で応答させる（呼んだ回数だけ順に失敗させる）。
+func (s *Server) FailNext(status int, retryAfter string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, Failure{Status: status, RetryAfter: retryAfter})
+}
+
+// Posts は受け付けた投稿（失敗させたものは含まない）のコピーを受信順に返す。
+func (s *Server) Posts() []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Post(nil), s.posts...)
+}
+
+// Attempts は失敗させたものも含めて受け付けた回数を返す。
+func (s *Server) Attempts() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// Reset は受け付けた投稿と予定していた失敗を捨てる。
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.posts, s.failures, s.attempts = nil, nil, 0
+}
+
+// Close はサーバを止める。
+func (s *Server) Close() { s.srv.Close() }
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "bad body", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	s.attempts++
+	if len(s.failures) > 0 {
+		f := s.failures[0]
+		s.failures = s.failures[1:]
+		s.mu.Unlock()
+		if f.RetryAfter != "" {
+			w.Header().Set("Retry-After", f.RetryAfter)
+		}
+		http.Error(w, strconv.Itoa(f.Status)+" from webhooktest", f.Status)
+		return
+	}
+	var payload struct {
+		Text string `json:"text"`
+	}
+	_ = json.Unmarshal(body, &payload)
+	s.posts = append(s.posts, Post{Path: r.URL.Path, ContentType: r.Header.Get("Content-Type"), Body: body, Text: payload.Text})
+	s.mu.Unlock()
+
+	// Slack と同じく、成功したら本文 "ok" を返す
+	_, _ = io.WriteString(w, "ok")
+}
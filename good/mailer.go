@@ -0,0 +1,126 @@
+package main
+
+// 通知（メール・チャット）の設定
+// --------------------------------------------------------
+// SMTP_HOST を設定すると SMTP でメールを送る。未設定ならログに書くだけにする。
+// - SMTP_PORT（既定 587）・SMTP_USERNAME・SMTP_PASSWORD
+// - SMTP_FROM（既定 noreply@example.com）
+// - SMTP_STARTTLS：空（使えれば使う）・required・off
+// - SMTP_SUBJECT_CHARSET：ISO-2022-JP（既定）・UTF-8（日本語のメールの件名に使う）
+// CHAT_WEBHOOKS を設定すると、部署ごとのチャットにも投稿できるようにする。
+// - "部署=URL" をカンマで区切って並べる。部署を "*" にするとほかの部署すべての投稿先になる
+// - URL の前に "teams:" を付けると Teams 向けの書式にする（"slack:" または省略で Slack 向け）
+//   例：CHAT_WEBHOOKS="Dev=https://hooks.slack.com/services/...,HR=teams:https://example.webhook.office.com/..."
+// どのチャネルで送るかは従業員ごとの設定（チャネル・静かな時間）に従う。
+// 設定していない従業員には NOTIFY_DEFAULT_CHANNELS（"email,chat" の形。既定は用意したチャネルすべて）で送る。
+// 通知に載せるリンクは APP_BASE_URL（既定 http://localhost:8080）から作る。
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// newMailer は環境変数に従ってチャネルごとの Mailer を用意し、従業員の設定に従って振り分ける Mailer にまとめる。
+func newMailer(clock usecase.Clock) (usecase.Mailer, error) {
+	mail, err := newSMTPMailer()
+	if err != nil {
+		return nil, err
+	}
+	routes := []drivers.NotificationRoute{{Channel: domain.ChannelEmail, Mailer: mail}}
+	if v := os.Getenv("CHAT_WEBHOOKS"); v != "" {
+		chat, err := parseChatWebhooks(v)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, drivers.NotificationRoute{Channel: domain.ChannelChat, Mailer: chat})
+	}
+	var defaults []domain.Channel
+	if v := os.Getenv("NOTIFY_DEFAULT_CHANNELS"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			c, err := domain.ParseChannel(name)
+			if err != nil {
+				return nil, fmt.Errorf("bad NOTIFY_DEFAULT_CHANNELS %q: %w", v, err)
+			}
+			defaults = append(defaults, c)
+		}
+	}
+	return drivers.NotificationRouter{Routes: routes, Defaults: defaults, Clock: clock, Logger: slog.Default()}, nil
+}
+
+// newSMTPMailer は SMTP_* の環境変数に従ってメールの Mailer を用意する。
+func newSMTPMailer() (usecase.Mailer, error) {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		return drivers.LogMailer{Logger: slog.Default()}, nil
+	}
+	cfg := drivers.SMTPConfig{
+		Host:     host,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("SMTP_FROM"),
+		StartTLS: drivers.StartTLSMode(os.Getenv("SMTP_STARTTLS")),
+		Charset:  os.Getenv("SMTP_SUBJECT_CHARSET"),
+	}
+	if cfg.From == "" {
+		cfg.From = "noreply@example.com"
+	}
+	if p := os.Getenv("SMTP_PORT"); p != "" {
+		port, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("bad SMTP_PORT %q", p)
+		}
+		cfg.Port = port
+	}
+	switch cfg.StartTLS {
+	case drivers.StartTLSOpportunistic, drivers.StartTLSRequired, drivers.StartTLSOff:
+	default:
+		return nil, fmt.Errorf("bad SMTP_STARTTLS %q (want required or off)", cfg.StartTLS)
+	}
+	return drivers.SMTPMailer{Config: cfg}, nil
+}
+
+// parseChatWebhooks は CHAT_WEBHOOKS の値から部署ごとの投稿先を読み取る。
+func parseChatWebhooks(v string) (drivers.ChatNotifier, error) {
+	c := drivers.ChatNotifier{Webhooks: map[string]drivers.ChatWebhook{}}
+	for _, item := range strings.Split(v, ",") {
+		dept, target, ok := strings.Cut(strings.TrimSpace(item), "=")
+		dept = strings.TrimSpace(dept)
+		if !ok || dept == "" {
+			return c, fmt.Errorf("bad CHAT_WEBHOOKS entry %q (want department=url)", item)
+		}
+		wh := drivers.ChatWebhook{URL: strings.TrimSpace(target), Format: drivers.ChatFormatSlack}
+		for _, f := range []drivers.ChatFormat{drivers.ChatFormatSlack, drivers.ChatFormatTeams} {
+			if rest, found := strings.CutPrefix(wh.URL, string(f)+":"); found {
+				wh.URL, wh.Format = rest, f
+			}
+		}
+		if u, err := url.Parse(wh.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return c, fmt.Errorf("bad CHAT_WEBHOOKS url for department %q", dept) // URL は秘密の値なので表示しない
+		}
+		if dept == "*" {
+			c.Default = wh
+		} else {
+			c.Webhooks[dept] = wh
+		}
+	}
+	return c, nil
+}
+
+// newDeepLinks は APP_BASE_URL から通知に載せるリンクの作り方を決める。
+func newDeepLinks() adapters.DeepLinks {
+	base := os.Getenv("APP_BASE_URL")
+	if base == "" {
+		base = "http://localhost:8080"
+	}
+	return adapters.DeepLinks{BaseURL: base}
+}
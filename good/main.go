@@ -1,11 +1,13 @@
 package main
 
 import (
-	"database/sql"
+	"log/slog"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/domain"
 	"github.com/ohagi/clean-architecture-examples/good/drivers"
 	"github.com/ohagi/clean-architecture-examples/good/usecase"
 )
@@ -13,30 +15,213 @@ import (
 type sysClock struct{}
 
 func (sysClock) Now() time.Time { return time.Now().UTC() }
-func fiscalYearStart(now time.Time) time.Time {
-	year := now.Year()
-	start := time.Date(year, 4, 1, 0, 0, 0, 0, time.UTC)
-	if now.Before(start) {
-		start = time.Date(year-1, 4, 1, 0, 0, 0, 0, time.UTC)
-	}
-	return start
-}
+
+// fiscalYearStart：会計年度の開始日（UseCase とリポジトリに同じものを渡す）
+var fiscalYearStart = domain.FiscalYearStart
 
 func main() {
-	// DB接続の初期化
-	db, _ := sql.Open("postgres", "postgres://...")
+	// スキーマ移行は保存先の初期化（スキーマのバージョン確認）より先に行う
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-counters" {
+		os.Exit(runRebuildCounters(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// 保存先の初期化（LEAVE_STORE / DATABASE_URL で切り替える）
+	st, err := openStores()
+	if err != nil {
+		slog.Error("failed to open stores", "err", err)
+		os.Exit(1)
+	}
+	// ログ・計測・リトライ（各ポートとUseCaseをデコレータで包む）
+	// 計測結果は /debug/vars（expvar）で参照できる
+	obs := &drivers.Observer{
+		Logger:  slog.Default(),
+		Metrics: drivers.NewMetrics("leave"),
+		Retry:   drivers.DefaultRetryPolicy,
+	}
+	// 従業員情報は読み取りキャッシュを通す（変更は従業員管理UseCaseが InvalidateEmployee で知らせる）
+	observedEmployees := drivers.EmployeeRepoDecorator{Next: st.Employees, Obs: obs}
+	employees := newEmployeeCache(observedEmployees)
+	leaves := drivers.DecorateLeaveRepo(st.Leaves, obs)
+	pending := drivers.PendingLeaveRepoDecorator{Next: st.Leaves, Obs: obs}
+	notifier, err := newMailer(sysClock{})
+	if err != nil {
+		slog.Error("failed to configure mailer", "err", err)
+		os.Exit(1)
+	}
+	mailer := drivers.MailerDecorator{Next: notifier, Obs: obs}
+	links := newDeepLinks()
+	// 休暇申請の変更の発行（ログへの記録と、プロセス内の購読者への配信）
+	bus, err := openEventBus()
+	if err != nil {
+		slog.Error("failed to open event log", "err", err)
+		os.Exit(1)
+	}
+	events := drivers.EventPublisherDecorator{Next: bus, Obs: obs}
 	// 依存性の注入
 	// UseCaseはインターフェイスに依存するので、ここで具体実装を差し込む
 	uc := usecase.SubmitLeave{
-		EmployeesRepo: drivers.PostgresEmployeeRepo{DB: db},
-		LeavesRepo:    drivers.PostgresLeaveRepo{DB: db},
-		Mailer:        drivers.SMTPMailer{},
+		EmployeesRepo: employees,
+		LeavesRepo:    leaves,
+		Mailer:        mailer,
+		Links:         links,
+		Events:        events,
+		Clock:         sysClock{},
+		YearStart:     fiscalYearStart,
+	}
+	// 一括取込UseCase
+	importer := usecase.ImportLeaves{
+		EmployeesRepo: employees,
+		LeavesRepo:    leaves,
+		Events:        events,
+		Clock:         sysClock{},
+		YearStart:     fiscalYearStart,
+	}
+	// 催促ジョブUseCase（ポリシーは環境変数で設定する。remind.go を参照）
+	reminder := usecase.RemindStaleRequests{
+		EmployeesRepo: employees,
+		LeavesRepo:    pending,
+		Mailer:        mailer,
+		Links:         links,
+		Events:        events,
+		Clock:         sysClock{},
+		Policy:        stalePolicy(),
+	}
+	// サブコマンド（CLIの入口）
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			os.Exit(adapters.RunImportCLI(os.Args[2:], drivers.ImporterDecorator{Next: importer, Obs: obs}, os.Stdin, os.Stdout, os.Stderr))
+		case "remind":
+			os.Exit(adapters.RunRemindCLI(os.Args[2:], reminder.Policy, func(p domain.StalePolicy) usecase.StaleReminder {
+				reminder.Policy = p
+				return drivers.ReminderDecorator{Next: reminder, Obs: obs}
+			}, os.Stdout, os.Stderr))
+		}
+	}
+	// 前回までに記録できなかった変更をイベントログに補う（申請を受け付け始める前に行う。失敗しても次の起動でやり直す）
+	recovered, err := usecase.RecoverEvents{
+		EmployeeList: st.Employees,
+		LeavesRepo:   st.Leaves,
+		Log:          bus.Log,
+		Events:       events,
+		Clock:        sysClock{},
+	}.Run()
+	if err != nil {
+		slog.Error("failed to recover missing events", "err", err)
+	} else if len(recovered.Recovered) > 0 {
+		slog.Warn("recorded missing events", "count", len(recovered.Recovered), "requests", recovered.Recovered)
+	}
+	// 事前確認UseCaseは読み取り系のポートだけを注入する
+	preview := usecase.PreviewLeave{
+		EmployeesRepo: employees,
+		LeavesRepo:    leaves,
 		Clock:         sysClock{},
 		YearStart:     fiscalYearStart,
 	}
+	// 添付ファイルUseCase（中身はローカルディスクに保存する）
+	leaveFinder := st.Leaves
+	attachmentsRepo := st.Attachments
+	blobs := drivers.LocalBlobStore{Dir: "data/attachments"}
+	attachments := adapters.AttachmentHandler{
+		Upload: drivers.UploaderDecorator{Next: usecase.UploadAttachment{
+			EmployeesRepo: employees, LeavesRepo: leaveFinder, AttachmentsRepo: attachmentsRepo, Blobs: blobs, Clock: sysClock{},
+		}, Obs: obs},
+		List: drivers.AttachmentListerDecorator{Next: usecase.ListAttachments{
+			EmployeesRepo: employees, LeavesRepo: leaveFinder, AttachmentsRepo: attachmentsRepo,
+		}, Obs: obs},
+		Download: drivers.DownloaderDecorator{Next: usecase.DownloadAttachment{
+			EmployeesRepo: employees, LeavesRepo: leaveFinder, AttachmentsRepo: attachmentsRepo, Blobs: blobs,
+		}, Obs: obs},
+	}
+	// 集計レポートUseCase
+	reports := usecase.LeaveReports{
+		EmployeesRepo: employees,
+		EmployeeList:  st.Employees,
+		LeavesRepo:    st.Leaves,
+		YearStart:     fiscalYearStart,
+	}
+	// 従業員管理UseCase
+	// 変更前の情報はキャッシュを通さずに読む（古い情報に変更を重ねて保存しないように）
+	employeeAdmin := usecase.ManageEmployees{
+		EmployeesRepo:  observedEmployees,
+		EmployeeList:   st.Employees,
+		EmployeeWriter: st.Employees,
+		LeavesRepo:     leaves,
+		EmployeeLeaves: st.Leaves,
+		Cache:          employees,
+		Events:         events,
+		Mailer:         mailer,
+		Links:          links,
+		Clock:          sysClock{},
+		YearStart:      fiscalYearStart,
+	}
+	// 判断UseCase（NOTIFY_TEAM_ON_APPROVAL=1 なら承認した休暇をチームにも知らせる）
+	decide := usecase.DecideLeave{
+		EmployeesRepo: employees,
+		LeavesRepo:    drivers.DecisionLeaveRepoDecorator{Next: st.Leaves, Obs: obs},
+		Mailer:        mailer,
+		Links:         links,
+		Events:        events,
+		Clock:         sysClock{},
+	}
+	if os.Getenv("NOTIFY_TEAM_ON_APPROVAL") == "1" {
+		decide.Team = st.Employees
+	}
+	// 参照・取り下げUseCase
+	view := usecase.ViewLeaves{
+		EmployeesRepo: employees,
+		LeavesRepo:    st.Leaves,
+		Lister:        st.Leaves,
+	}
+	cancel := usecase.CancelLeave{
+		EmployeesRepo: employees,
+		LeavesRepo:    drivers.DecisionLeaveRepoDecorator{Next: st.Leaves, Obs: obs},
+		Events:        events,
+		Clock:         sysClock{},
+	}
+	// カレンダー配信UseCase（購読 URL のトークンは CALENDAR_SECRET から計算する）
+	calendars := usecase.CalendarFeeds{
+		EmployeesRepo: employees,
+		EmployeeList:  st.Employees,
+		LeavesRepo:    st.Leaves,
+		Tokens:        newFeedTokens(),
+		Clock:         sysClock{},
+	}
 	// HTTPハンドラの登録
-	// HandlerにはUseCaseを注入して利用する
-	http.Handle("/leave-requests", adapters.SubmitHandler{UC: uc})
+	// HandlerにはUseCaseを注入して利用する（UseCaseもデコレータで包む）
+	// 休暇申請（/leave-requests 以下）はメソッドごとに振り分けるルータに任せる
+	adapters.NewLeaveRouter(adapters.LeaveRoutes{
+		Submit:      drivers.SubmitterDecorator{Next: uc, Obs: obs},
+		Preview:     drivers.PreviewerDecorator{Next: preview, Obs: obs},
+		Import:      drivers.ImporterDecorator{Next: importer, Obs: obs},
+		Decide:      drivers.DeciderDecorator{Next: decide, Obs: obs},
+		View:        drivers.ViewerDecorator{Next: view, Obs: obs},
+		Cancel:      drivers.CancellerDecorator{Next: cancel, Obs: obs},
+		Attachments: attachments,
+	}).Register(http.DefaultServeMux)
+	http.Handle("/attachments/", attachments)
+	http.Handle("/reports/leave", adapters.ReportHandler{UC: drivers.ReporterDecorator{Next: reports, Obs: obs}})
+	observedAdmin := drivers.EmployeeManagerDecorator{Next: employeeAdmin, Obs: obs}
+	http.Handle("/employees", adapters.EmployeeHandler{UC: observedAdmin})
+	http.Handle("/employees/", adapters.EmployeeHandler{UC: observedAdmin})
+	http.Handle("/calendars/", adapters.CalendarHandler{
+		UC: drivers.CalendarFeederDecorator{Next: calendars, Obs: obs}, BaseURL: links.BaseURL, Location: calendarLocation(),
+	})
+	// IdP からの従業員のプロビジョニング（SCIM_TOKEN を設定したときだけ受け付ける）
+	if scim := newSCIMHandler(observedAdmin, links.BaseURL); scim != nil {
+		http.Handle("/scim/v2/", scim)
+	}
+	// 連携先が offset を指定して変更を読む入口（EVENTS_TOKEN を設定したときだけ受け付ける）
+	if feed := newEventsHandler(bus); feed != nil {
+		http.Handle("/events", feed)
+	}
+	// 催促ジョブを1時間ごとに実行（cron で `remind` を呼ぶ場合は不要）
+	go adapters.RemindJob{UC: drivers.ReminderDecorator{Next: reminder, Obs: obs}, Interval: time.Hour}.Start(nil)
 	// HTTPサーバ起動
-	http.ListenAndServe(":8080", nil)
+	if err := http.ListenAndServe(":8080", nil); err != nil {
+		slog.Error("server stopped", "err", err)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,80 @@
+package main
+
+// migrate サブコマンド（SQL データベースのスキーマ移行）
+// --------------------------------------------------------
+// - migrate up [VERSION]   … VERSION まで（省略時は最新まで）適用する
+// - migrate down [VERSION] … VERSION まで（省略時は1つ前まで）取り消す
+// - migrate status         … 適用済みのバージョンと未適用のスキーマ変更を表示する
+// 接続先は DATABASE_URL。方言は LEAVE_STORE（postgres・mysql。省略時は postgres）。
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+)
+
+// runMigrate は migrate サブコマンドを実行し、終了コードを返す。
+func runMigrate(args []string, stdout, stderr io.Writer) int {
+	usage := func() int {
+		fmt.Fprintln(stderr, "usage: migrate up [VERSION] | migrate down [VERSION] | migrate status")
+		return 64 // EX_USAGE
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return usage()
+	}
+	target := -1
+	if len(args) == 2 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			return usage()
+		}
+		target = v
+	}
+
+	db, d, err := openDB()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 69 // EX_UNAVAILABLE
+	}
+	defer db.Close()
+	m := drivers.NewMigrator(db, d)
+	cur, err := m.Current()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 69
+	}
+
+	var done []drivers.Migration
+	switch args[0] {
+	case "status":
+		fmt.Fprintf(stdout, "current version: %d (expected: %d)\n", cur, m.Latest())
+		for _, mg := range m.Migrations {
+			state := "pending"
+			if mg.Version <= cur {
+				state = "applied"
+			}
+			fmt.Fprintf(stdout, "  %s  %s\n", state, mg.FileName())
+		}
+		return 0
+	case "up":
+		done, err = m.Up(max(target, 0))
+	case "down":
+		if target < 0 {
+			target = cur - 1
+		}
+		done, err = m.Down(target)
+	default:
+		return usage()
+	}
+	for _, mg := range done {
+		fmt.Fprintf(stdout, "%s %s\n", args[0], mg.FileName())
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 70 // EX_SOFTWARE
+	}
+	return 0
+}
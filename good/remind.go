@@ -0,0 +1,47 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// stalePolicy は滞留している申請の扱い方を環境変数から作る（未設定の項目は domain.DefaultStalePolicy のまま）。
+// サーバ内の1時間ごとの催促ジョブと remind サブコマンドの既定値は、どちらもここで決める（サブコマンドはフラグで上書きできる）。
+// - STALE_REMIND_AFTER_DAYS        ：何営業日で催促するか（0 なら催促しない）
+// - STALE_ESCALATE_AFTER_DAYS      ：何営業日で次の承認者へ回すか（0 ならエスカレーションしない）
+// - STALE_AUTO_APPROVE             ："1" なら開始日の前日に自動承認する
+// - STALE_AUTO_APPROVE_MIN_PENDING ：申請から自動承認までに最低限置く時間（"48h" など。既定は24時間）
+// - STALE_EXPIRE_AFTER_START       ："1" なら開始日になっても承認待ちの申請を期限切れにする
+func stalePolicy() domain.StalePolicy {
+	p := domain.DefaultStalePolicy
+	envInt("STALE_REMIND_AFTER_DAYS", &p.RemindAfterDays)
+	envInt("STALE_ESCALATE_AFTER_DAYS", &p.EscalateAfterDays)
+	if v := os.Getenv("STALE_AUTO_APPROVE_MIN_PENDING"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			slog.Warn("invalid STALE_AUTO_APPROVE_MIN_PENDING; using the default", "value", v, "err", err)
+		}
+		p.AutoApproveMinPending = d
+	}
+	p.AutoApproveBeforeStart = os.Getenv("STALE_AUTO_APPROVE") == "1"
+	p.ExpireAfterStart = os.Getenv("STALE_EXPIRE_AFTER_START") == "1"
+	return p
+}
+
+// envInt は環境変数 name が設定されていれば整数として dst に読み込む（読めなければ dst はそのまま）。
+func envInt(name string, dst *int) {
+	v := os.Getenv(name)
+	if v == "" {
+		return
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		slog.Warn("invalid "+name+"; using the default", "value", v, "err", err)
+		return
+	}
+	*dst = n
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+
+	"github.com/ohagi/clean-architecture-examples/good/adapters"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// newSCIMHandler は IdP からのプロビジョニング（SCIM 2.0）の入口を作る。SCIM_TOKEN が未設定なら nil。
+// - SCIM_TOKEN   ：IdP が Authorization: Bearer で送るトークン
+// - SCIM_ACTOR_ID：IdP の操作を行う人事アカウントの従業員ID
+func newSCIMHandler(uc usecase.EmployeeManager, baseURL string) http.Handler {
+	token := os.Getenv("SCIM_TOKEN")
+	if token == "" {
+		return nil
+	}
+	actor := os.Getenv("SCIM_ACTOR_ID")
+	if actor == "" {
+		slog.Warn("SCIM_ACTOR_ID is not set; SCIM requests will be rejected")
+	}
+	return adapters.SCIMHandler{UC: uc, Token: token, ActorID: actor, BaseURL: baseURL, Clock: sysClock{}}
+}
@@ -0,0 +1,125 @@
+package main
+
+// 保存先の切り替え
+// --------------------------------------------------------
+// 環境変数 LEAVE_STORE で保存先を選ぶ。
+// - memory  ：メモリ上（再起動で消える。ローカルでのデモ用）
+// - file    ：ローカルディレクトリのジャーナル（保存先は LEAVE_DATA_DIR。省略時は data/store）
+// - postgres：PostgreSQL（接続先は DATABASE_URL。ドライバは別途 import すること）
+// - mysql   ：MySQL 8.0（接続先は DATABASE_URL。go-sql-driver/mysql の形式。ドライバは別途 import すること）
+// 省略時は DATABASE_URL があれば postgres、なければ memory。
+// memory と file では、従業員が1人もいなければデモ用の従業員を登録しておく。
+// --------------------------------------------------------
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// leaveStore：休暇申請リポジトリが満たすポートの全部
+type leaveStore interface {
+	usecase.LeaveRepo
+	usecase.LeaveFinder
+	usecase.LeaveLister
+	usecase.PendingLeaveRepo
+	usecase.LeavePeriodRepo
+	usecase.EmployeeLeavesRepo
+}
+
+// employeeStore：従業員リポジトリが満たすポートの全部
+type employeeStore interface {
+	usecase.EmployeeRepo
+	usecase.EmployeeLister
+	usecase.EmployeeWriter
+}
+
+// stores：保存先ごとのリポジトリ一式
+type stores struct {
+	Employees   employeeStore
+	Leaves      leaveStore
+	Attachments usecase.AttachmentRepo
+}
+
+// openStores は環境変数に従ってリポジトリ一式を用意する。
+func openStores() (stores, error) {
+	kind, url := os.Getenv("LEAVE_STORE"), os.Getenv("DATABASE_URL")
+	if kind == "" {
+		kind = "memory"
+		if url != "" {
+			kind = "postgres"
+		}
+	}
+	switch kind {
+	case "memory":
+		m := drivers.NewMemoryStore()
+		m.Seed(demoEmployees()...)
+		return stores{Employees: m.Employees(), Leaves: m.Leaves(), Attachments: m.Attachments()}, nil
+	case "file":
+		dir := os.Getenv("LEAVE_DATA_DIR")
+		if dir == "" {
+			dir = "data/store"
+		}
+		f, err := drivers.OpenFileStore(dir)
+		if err != nil {
+			return stores{}, err
+		}
+		st := stores{Employees: f.Employees(), Leaves: f.Leaves(), Attachments: f.Attachments()}
+		if emps, err := st.Employees.List(); err == nil && len(emps) == 0 {
+			for _, e := range demoEmployees() {
+				if err := st.Employees.Create(&e); err != nil {
+					return stores{}, fmt.Errorf("seed demo employees: %w", err)
+				}
+			}
+		}
+		return st, nil
+	case "postgres", "mysql":
+		db, d, err := openDB()
+		if err != nil {
+			return stores{}, err
+		}
+		// スキーマが古い（または新しい）まま動かすと、実行時に分かりにくいSQLエラーになる
+		if err := drivers.NewMigrator(db, d).CheckVersion(); err != nil {
+			return stores{}, err
+		}
+		return stores{
+			Employees:   drivers.SQLEmployeeRepo{DB: db, Dialect: d},
+			Leaves:      drivers.SQLLeaveRepo{DB: db, Dialect: d, YearStart: fiscalYearStart},
+			Attachments: drivers.SQLAttachmentRepo{DB: db, Dialect: d},
+		}, nil
+	}
+	return stores{}, fmt.Errorf("unknown LEAVE_STORE %q (want memory, file, postgres or mysql)", kind)
+}
+
+// openDB は DATABASE_URL のデータベースに接続し、その方言を返す。
+// 方言は LEAVE_STORE（postgres・mysql。それ以外・省略時は postgres）で選び、同じ名前の database/sql ドライバを使う。
+func openDB() (*sql.DB, drivers.Dialect, error) {
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		return nil, nil, fmt.Errorf("DATABASE_URL is not set")
+	}
+	d, ok := drivers.LookupDialect(os.Getenv("LEAVE_STORE"))
+	if !ok {
+		d = drivers.PostgresDialect{}
+	}
+	db, err := sql.Open(d.Name(), url)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open database: %w", err)
+	}
+	return db, d, nil
+}
+
+// demoEmployees：メモリ保存のときに最初から登録しておく従業員（人事1名・上長1名・部下1名）
+func demoEmployees() []domain.Employee {
+	hired := time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC)
+	return []domain.Employee{
+		{ID: "hr", Name: "人事 太郎", Email: "hr@example.com", HireDate: hired, Department: "HR", Role: domain.RoleHR},
+		{ID: "manager", Name: "上長 花子", Email: "manager@example.com", HireDate: hired, Department: "Dev", Role: domain.RoleEmployee},
+		{ID: "alice", Name: "Alice", Email: "alice@example.com", HireDate: hired, ManagerID: "manager", Department: "Dev", Role: domain.RoleEmployee, Locale: domain.LocaleEnglish},
+	}
+}
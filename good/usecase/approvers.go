@@ -0,0 +1,26 @@
+package usecase
+
+// 承認者の決定（申請・一括取込・エスカレーション・退職処理で共通）
+// --------------------------------------------------------
+// 申請は申請者の上長に回す。上長が退職していれば、その上長の上長へとたどり、最初に見つかった在籍者に回す。
+// 退職者に回すと誰も判断できない申請が残るため、承認者を決めるところはすべてここを通す。
+// --------------------------------------------------------
+
+// errNoActiveApprover：上長をたどっても在籍中の承認者がいない
+var errNoActiveApprover = &Error{Kind: ErrConflict, Msg: "no active manager to approve the request"}
+
+// activeApprover は managerID から上長をたどり、最初に見つかった在籍中の従業員のIDを返す。
+// managerID が空、または最上位まで全員退職していれば空を返す。
+func activeApprover(emps EmployeeRepo, managerID string) (string, error) {
+	for id, depth := managerID, 0; id != "" && depth < maxManagerDepth; depth++ {
+		m, err := emps.FindByID(id)
+		if err != nil {
+			return "", err
+		}
+		if m.IsActive() {
+			return m.ID, nil
+		}
+		id = m.ManagerID
+	}
+	return "", nil
+}
@@ -0,0 +1,181 @@
+package usecase
+
+// 添付ファイル（診断書・証明書など）のユースケース
+// --------------------------------------------------------
+// - UploadAttachment  ：休暇申請にファイルを添付する
+// - ListAttachments   ：休暇申請の添付ファイル一覧を取得する
+// - DownloadAttachment：添付ファイルの中身を取得する
+// --------------------------------------------------------
+// 誰が添付・閲覧できるか、どんなファイルを添付できるかは Domain層のルールに従う。
+// ファイルの中身の保存先は BlobStore ポートの実装（Drivers層）に任せる。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"io"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// errAttachmentDenied：添付・閲覧の権限がない
+var errAttachmentDenied = &Error{Kind: ErrForbidden, Msg: "not allowed to access attachments of this request"}
+
+// UploadAttachment：添付ユースケースの実行構造体
+type UploadAttachment struct {
+	EmployeesRepo   EmployeeRepo
+	LeavesRepo      LeaveFinder
+	AttachmentsRepo AttachmentRepo
+	Blobs           BlobStore
+	Clock           Clock
+}
+
+// UploadInput：添付の入力
+type UploadInput struct {
+	ActorID     string // 操作している従業員
+	RequestID   string
+	FileName    string
+	ContentType string    // Adapter層で中身から判定した形式
+	Body        io.Reader // ファイルの中身
+}
+
+// Upload：添付の実行
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 休暇申請と操作者の取得
+// 2. ドメインルールによる権限チェック
+// 3. ファイル形式のチェック
+// 4. 中身の保存（上限サイズを超えたら中断）
+// 5. 添付ファイル情報の保存（失敗したら保存した中身を削除）
+// --------------------------------------------------------
+func (uc UploadAttachment) Upload(in UploadInput) (domain.Attachment, error) {
+	// 1. 休暇申請と操作者の取得
+	req, actor, err := findRequestAndActor(uc.LeavesRepo, uc.EmployeesRepo, in.RequestID, in.ActorID)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+
+	// 2. ドメインルールによる権限チェック
+	if !domain.CanUploadAttachment(actor, req) {
+		return domain.Attachment{}, errAttachmentDenied
+	}
+
+	// 3. ファイル形式のチェック（サイズは保存しながら数える）
+	if err := domain.ValidateAttachment(in.ContentType, 1); err != nil {
+		return domain.Attachment{}, attachmentError(err)
+	}
+
+	// 4. 中身の保存（上限サイズを超えたら中断）
+	body := &sizeLimitReader{r: in.Body, limit: domain.MaxAttachmentSize}
+	key, err := uc.Blobs.Put(body)
+	if err != nil {
+		if errors.Is(err, domain.ErrAttachmentSize) {
+			return domain.Attachment{}, attachmentError(domain.ErrAttachmentSize)
+		}
+		return domain.Attachment{}, err
+	}
+	if err := domain.ValidateAttachment(in.ContentType, body.n); err != nil {
+		_ = uc.Blobs.Delete(key)
+		return domain.Attachment{}, attachmentError(err)
+	}
+
+	// 5. 添付ファイル情報の保存（失敗したら保存した中身を削除）
+	a := &domain.Attachment{
+		RequestID:   req.ID,
+		FileName:    in.FileName,
+		ContentType: in.ContentType,
+		Size:        body.n,
+		BlobKey:     key,
+		UploadedBy:  actor.ID,
+		UploadedAt:  uc.Clock.Now(),
+	}
+	if err := uc.AttachmentsRepo.Create(a); err != nil {
+		_ = uc.Blobs.Delete(key)
+		return domain.Attachment{}, err
+	}
+	return *a, nil
+}
+
+// ListAttachments：添付ファイル一覧ユースケースの実行構造体
+type ListAttachments struct {
+	EmployeesRepo   EmployeeRepo
+	LeavesRepo      LeaveFinder
+	AttachmentsRepo AttachmentRepo
+}
+
+// List は休暇申請の添付ファイル一覧を返す（閲覧権限がある場合のみ）。
+func (uc ListAttachments) List(actorID, requestID string) ([]domain.Attachment, error) {
+	req, actor, err := findRequestAndActor(uc.LeavesRepo, uc.EmployeesRepo, requestID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.CanViewAttachment(actor, req) {
+		return nil, errAttachmentDenied
+	}
+	return uc.AttachmentsRepo.ListByRequest(req.ID)
+}
+
+// DownloadAttachment：添付ファイル取得ユースケースの実行構造体
+type DownloadAttachment struct {
+	EmployeesRepo   EmployeeRepo
+	LeavesRepo      LeaveFinder
+	AttachmentsRepo AttachmentRepo
+	Blobs           BlobStore
+}
+
+// Download は添付ファイルの情報と中身を返す（閲覧権限がある場合のみ）。
+// 中身の io.ReadCloser は呼び出し側で Close すること。
+func (uc DownloadAttachment) Download(actorID, attachmentID string) (domain.Attachment, io.ReadCloser, error) {
+	a, err := uc.AttachmentsRepo.FindByID(attachmentID)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	req, actor, err := findRequestAndActor(uc.LeavesRepo, uc.EmployeesRepo, a.RequestID, actorID)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	if !domain.CanViewAttachment(actor, req) {
+		return domain.Attachment{}, nil, errAttachmentDenied
+	}
+	body, err := uc.Blobs.Get(a.BlobKey)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	return a, body, nil
+}
+
+// findRequestAndActor は休暇申請と操作している従業員を取得する。
+func findRequestAndActor(leaves LeaveFinder, emps EmployeeRepo, requestID, actorID string) (domain.LeaveRequest, domain.Employee, error) {
+	if actorID == "" {
+		return domain.LeaveRequest{}, domain.Employee{}, &Error{Kind: ErrUnauthenticated, Msg: "authentication required"}
+	}
+	req, err := leaves.FindByID(requestID)
+	if err != nil {
+		return domain.LeaveRequest{}, domain.Employee{}, err
+	}
+	actor, err := findActor(emps, actorID)
+	if err != nil {
+		return domain.LeaveRequest{}, domain.Employee{}, err
+	}
+	return req, actor, nil
+}
+
+// attachmentError はドメインの検証エラーを入力不正エラーへ変換する。
+func attachmentError(err error) error {
+	return &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+}
+
+// sizeLimitReader：読んだバイト数を数え、上限を超えたら domain.ErrAttachmentSize を返す Reader
+type sizeLimitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+}
+
+func (l *sizeLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		return n, domain.ErrAttachmentSize
+	}
+	return n, err
+}
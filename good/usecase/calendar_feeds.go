@@ -0,0 +1,169 @@
+package usecase
+
+// 承認済みの休暇をカレンダーとして配信するユースケース
+// --------------------------------------------------------
+// Outlook・Google カレンダーなどから購読できるよう、承認済みの休暇を2種類のフィードにまとめる。
+// - 従業員ごとのフィード … 本人の承認済みの休暇
+// - チームごとのフィード … 上長本人と直属の部下（在籍中）の承認済みの休暇
+// --------------------------------------------------------
+// カレンダーアプリは認証ヘッダを送れないので、フィードの URL に秘密のトークンを載せて本人確認の代わりにする。
+// トークンが合わないときは、フィードの有無を知られないよう NotFound を返す。
+// iCalendar 形式への変換は Adapter層の責務。ここでは休暇の一覧（DTO）だけを返す。
+// --------------------------------------------------------
+
+import (
+	"sort"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// CalendarFeeds：カレンダー配信ユースケースの実行構造体
+type CalendarFeeds struct {
+	EmployeesRepo EmployeeRepo
+	EmployeeList  EmployeeLister
+	LeavesRepo    LeavePeriodRepo
+	Tokens        FeedTokens
+	Clock         Clock
+	Past          time.Duration // 今日からどれだけ前までの休暇を載せるか（0 なら1年）
+	Future        time.Duration // 今日からどれだけ先までの休暇を載せるか（0 なら2年）
+}
+
+// CalendarEntry：フィードに載せる休暇1件
+type CalendarEntry struct {
+	Request  domain.LeaveRequest
+	Employee domain.Employee // 休暇を取る従業員
+}
+
+// CalendarFeed：フィードの内容
+type CalendarFeed struct {
+	Owner   domain.Employee // フィードの持ち主（チームのフィードなら上長）
+	Team    bool            // チームのフィードか（休暇の種類などは載せない）
+	Entries []CalendarEntry
+}
+
+// FeedTokensOutput：操作者が購読できるフィードのトークン
+type FeedTokensOutput struct {
+	EmployeeID    string
+	EmployeeToken string
+	TeamToken     string // 部下がいなければ空
+}
+
+// フィードを区別するためのキー（トークンはこのキーごとに発行する）
+func employeeFeedKey(id string) string { return "employee:" + id }
+func teamFeedKey(id string) string     { return "team:" + id }
+
+// EmployeeFeed：従業員ごとのフィード
+// --------------------------------------------------------
+// 処理フロー：
+// 1. トークンの検証
+// 2. 従業員の取得
+// 3. 期間内の承認済みの休暇の抽出
+// --------------------------------------------------------
+func (uc CalendarFeeds) EmployeeFeed(employeeID, token string) (CalendarFeed, error) {
+	// 1. トークンの検証
+	if !uc.Tokens.Verify(employeeFeedKey(employeeID), token) {
+		return CalendarFeed{}, &Error{Kind: ErrNotFound, Msg: "calendar feed not found"}
+	}
+	// 2. 従業員の取得
+	emp, err := uc.EmployeesRepo.FindByID(employeeID)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	// 3. 期間内の承認済みの休暇の抽出
+	entries, err := uc.approvedLeaves(map[string]domain.Employee{emp.ID: emp})
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	return CalendarFeed{Owner: emp, Entries: entries}, nil
+}
+
+// TeamFeed：チームごとのフィード
+// --------------------------------------------------------
+// 処理フロー：
+// 1. トークンの検証
+// 2. 上長と直属の部下の取得
+// 3. 期間内の承認済みの休暇の抽出
+// --------------------------------------------------------
+func (uc CalendarFeeds) TeamFeed(managerID, token string) (CalendarFeed, error) {
+	// 1. トークンの検証
+	if !uc.Tokens.Verify(teamFeedKey(managerID), token) {
+		return CalendarFeed{}, &Error{Kind: ErrNotFound, Msg: "calendar feed not found"}
+	}
+	// 2. 上長と直属の部下の取得
+	manager, err := uc.EmployeesRepo.FindByID(managerID)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	all, err := uc.EmployeeList.List()
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	members := map[string]domain.Employee{manager.ID: manager}
+	for _, e := range all {
+		if e.ManagerID == manager.ID && e.IsActive() {
+			members[e.ID] = e
+		}
+	}
+	// 3. 期間内の承認済みの休暇の抽出
+	entries, err := uc.approvedLeaves(members)
+	if err != nil {
+		return CalendarFeed{}, err
+	}
+	return CalendarFeed{Owner: manager, Team: true, Entries: entries}, nil
+}
+
+// FeedTokens：操作者が購読できるフィードのトークンを返す
+// チームのフィードは直属の部下がいる従業員だけに発行する。
+func (uc CalendarFeeds) FeedTokens(actorID string) (FeedTokensOutput, error) {
+	if actorID == "" {
+		return FeedTokensOutput{}, &Error{Kind: ErrUnauthenticated, Msg: "authentication required"}
+	}
+	actor, err := uc.EmployeesRepo.FindByID(actorID)
+	if err != nil {
+		return FeedTokensOutput{}, err
+	}
+	out := FeedTokensOutput{EmployeeID: actor.ID, EmployeeToken: uc.Tokens.Token(employeeFeedKey(actor.ID))}
+	all, err := uc.EmployeeList.List()
+	if err != nil {
+		return FeedTokensOutput{}, err
+	}
+	for _, e := range all {
+		if e.ManagerID == actor.ID && e.IsActive() {
+			out.TeamToken = uc.Tokens.Token(teamFeedKey(actor.ID))
+			break
+		}
+	}
+	return out, nil
+}
+
+// approvedLeaves は members の承認済みの休暇のうち、配信する期間に重なるものを開始日順に返す。
+func (uc CalendarFeeds) approvedLeaves(members map[string]domain.Employee) ([]CalendarEntry, error) {
+	past, future := uc.Past, uc.Future
+	if past <= 0 {
+		past = 365 * 24 * time.Hour
+	}
+	if future <= 0 {
+		future = 2 * 365 * 24 * time.Hour
+	}
+	now := uc.Clock.Now()
+	reqs, err := uc.LeavesRepo.ListOverlapping(now.Add(-past), now.Add(future))
+	if err != nil {
+		return nil, err
+	}
+	var entries []CalendarEntry
+	for _, r := range reqs {
+		emp, ok := members[r.EmployeeID]
+		if !ok || r.Status != domain.StatusApproved {
+			continue
+		}
+		entries = append(entries, CalendarEntry{Request: r, Employee: emp})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].Request.From.Equal(entries[j].Request.From) {
+			return entries[i].Request.From.Before(entries[j].Request.From)
+		}
+		return entries[i].Request.ID < entries[j].Request.ID
+	})
+	return entries, nil
+}
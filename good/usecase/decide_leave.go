@@ -0,0 +1,134 @@
+package usecase
+
+// 承認者による判断（承認・却下・差し戻し）ユースケース
+// --------------------------------------------------------
+// 判断を記録し、申請者に結果と承認者のコメントを知らせる。
+// Team を設定すると、承認された休暇をチームのメンバー（同じ上長の下の従業員）にも知らせる。
+// 通知先はすべて従業員情報（上長の関係）からたどる。
+// --------------------------------------------------------
+// 判断できるかどうかのルールは domain.LeaveRequest.Decide に任せる。
+// 判断を保存した後の通知の失敗はエラーとして返さず、DecideOutput.NotifyErr で知らせる
+// （判断は取り消されないので、呼び出し側が「判断に失敗した」と誤解しないようにする）。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// DecideLeave：判断ユースケースの実行構造体
+type DecideLeave struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    DecisionLeaveRepo
+	Team          EmployeeLister // nil ならチームには知らせない
+	Mailer        Mailer
+	Links         LinkBuilder    // 通知に載せるリンク（nil ならリンクなし）
+	Events        EventPublisher // 変更の発行先（nil なら発行しない）
+	Clock         Clock
+}
+
+// DecideInput：判断の入力
+type DecideInput struct {
+	ActorID   string // 判断する承認者
+	RequestID string
+	Decision  domain.Decision
+	Comment   string // 却下・差し戻しでは必須
+}
+
+// DecideOutput：判断の結果
+type DecideOutput struct {
+	ID        string
+	Status    domain.LeaveStatus
+	DecidedAt time.Time
+	Notified  []string // 通知した従業員のID（申請者が先頭）
+	NotifyErr error    // 保存後の通知の失敗（nil ならすべて通知した）。判断自体は保存済み
+}
+
+// Decide：判断ユースケースの実行
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者と申請の取得
+// 2. ドメインルールによる判断の反映（承認者本人か・承認待ちか・コメントの有無）
+// 3. 申請の保存と変更の発行（leave.decided。取得後に他の判断・取消が保存されていれば ErrLeaveRequestChanged）
+// 4. 申請者への通知
+// 5. 承認した場合はチームのメンバーへの通知（1人に送れなくても残りには送る）
+// --------------------------------------------------------
+func (uc DecideLeave) Decide(in DecideInput) (DecideOutput, error) {
+	// 1. 操作者と申請の取得
+	if in.ActorID == "" {
+		return DecideOutput{}, &Error{Kind: ErrUnauthenticated, Msg: "authentication required"}
+	}
+	approver, err := uc.EmployeesRepo.FindByID(in.ActorID)
+	if err != nil {
+		return DecideOutput{}, err
+	}
+	req, err := uc.LeavesRepo.FindByID(in.RequestID)
+	if err != nil {
+		return DecideOutput{}, err
+	}
+
+	// 2. 判断の反映
+	now := uc.Clock.Now()
+	if err := req.Decide(approver.ID, in.Decision, in.Comment, now); err != nil {
+		return DecideOutput{}, decisionError(err)
+	}
+
+	// 3. 申請の保存と変更の発行
+	if err := uc.LeavesRepo.Update(&req, domain.StatusPending); err != nil {
+		return DecideOutput{}, err
+	}
+	out := DecideOutput{ID: req.ID, Status: req.Status, DecidedAt: req.DecidedAt}
+	publish(uc.Events, leaveEvent(EventLeaveDecided, approver.ID, req, now))
+
+	// 4〜5. 申請者・チームのメンバーへの通知
+	out.NotifyErr = uc.notify(&out, approver, req)
+	return out, nil
+}
+
+// notify は申請者へ判断を、承認した場合はチームのメンバーへ不在を通知し、通知した従業員を out に記録する。
+func (uc DecideLeave) notify(out *DecideOutput, approver domain.Employee, req domain.LeaveRequest) error {
+	// 4. 申請者への通知
+	n, err := newNotification(uc.EmployeesRepo, uc.Links, req, req.EmployeeID)
+	if err != nil {
+		return err
+	}
+	n.Approver = approver
+	if err := uc.Mailer.NotifyRequesterDecision(n); err != nil {
+		return err
+	}
+	out.Notified = append(out.Notified, n.Recipient.ID)
+
+	// 5. チームのメンバーへの通知
+	if req.Status != domain.StatusApproved || uc.Team == nil {
+		return nil
+	}
+	all, err := uc.Team.List()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, m := range domain.Teammates(all, n.Requester) {
+		tn := n
+		tn.Recipient = m
+		if err := uc.Mailer.NotifyTeamAbsence(tn); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		out.Notified = append(out.Notified, m.ID)
+	}
+	return errors.Join(errs...)
+}
+
+// decisionError はドメインルールの違反をエラーの種類へ対応づける。
+func decisionError(err error) error {
+	kind := ErrValidation
+	switch {
+	case errors.Is(err, domain.ErrNotApprover):
+		kind = ErrForbidden
+	case errors.Is(err, domain.ErrNotPending), errors.Is(err, domain.ErrNotAutoApprovable):
+		kind = ErrConflict
+	}
+	return &Error{Kind: kind, Msg: err.Error(), Err: err}
+}
@@ -0,0 +1,55 @@
+package usecase
+
+// 申請可否の判定（SubmitLeave / PreviewLeave 共通）
+// --------------------------------------------------------
+// 申請と事前確認で「同じデータを読み、同じルールで判定する」ことを保証するため、
+// 判定に必要な読み取りとドメインルールの適用をここにまとめる。
+// この処理は読み取りのみで、保存や通知などの副作用を持たない。
+// --------------------------------------------------------
+
+import (
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// assessment：申請内容の判定結果
+type assessment struct {
+	Employee       domain.Employee
+	SubmittedCount int                       // 年度内の申請回数
+	Reasons        []domain.IneligibleReason // 申請できない理由（空なら申請可能）
+}
+
+// assessLeave は申請内容をドメインルールで判定する。
+// 1. 期間の妥当性チェック
+// 2. 従業員情報の取得
+// 3. 年度内の申請回数の取得
+// 4. ドメインルールによる申請可否判定
+func assessLeave(emps EmployeeRepo, leaves LeaveCounter, yearStart func(time.Time) time.Time, in SubmitInput, now time.Time) (assessment, error) {
+	// 1. 期間の妥当性チェック
+	if err := domain.ValidatePeriod(in.From, in.To); err != nil {
+		return assessment{}, &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+	if err := domain.ValidateDayPart(in.DayPart, in.From, in.To); err != nil {
+		return assessment{}, &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+
+	// 2. 従業員情報の取得
+	emp, err := emps.FindByID(in.EmployeeID)
+	if err != nil {
+		return assessment{}, err
+	}
+
+	// 3. 年度内の申請回数の取得
+	count, err := leaves.CountThisFiscalYear(in.EmployeeID, yearStart(now))
+	if err != nil {
+		return assessment{}, err
+	}
+
+	// 4. ドメインルールによる申請可否判定
+	return assessment{
+		Employee:       emp,
+		SubmittedCount: count,
+		Reasons:        domain.CheckSubmit(emp, count, now),
+	}, nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+// アプリケーションエラーの分類
+// --------------------------------------------------------
+// UseCase層が外側（Adapter層）へ返すエラーの「種類」を定義する。
+// - Drivers層は DB やメールなど技術固有のエラーを、ここで定義した種類へ翻訳して返す
+// - Adapter層はエラーの種類だけを見て、HTTPステータスや終了コードを決める
+// --------------------------------------------------------
+// sql.ErrNoRows のような技術固有のエラーを内側にも外側にも漏らさないための「共通言語」。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"strings"
+)
+
+// エラーの種類
+// errors.Is(err, ErrNotFound) のように判定して使う
+var (
+	ErrNotFound        = errors.New("not found")           // 対象が存在しない
+	ErrValidation      = errors.New("validation failed")   // 入力が不正
+	ErrConflict        = errors.New("conflict")            // 既存データと競合している
+	ErrForbidden       = errors.New("forbidden")           // 業務ルール・権限上許可されない
+	ErrUnauthenticated = errors.New("unauthenticated")     // 利用者を特定できない
+	ErrUnavailable     = errors.New("service unavailable") // 外部リソースが一時的に利用できない
+	ErrInternal        = errors.New("internal error")      // 上記以外の想定外エラー
+)
+
+// 対象を特定したエラー
+// Drivers層が何が見つからない・重複しているのかを伝えるのに使う。
+// errors.Is(err, ErrEmployeeNotFound) でも errors.Is(err, ErrNotFound) でも判定できる。
+var (
+	ErrEmployeeNotFound     = &subKind{ErrNotFound, "employee not found"}
+	ErrLeaveRequestNotFound = &subKind{ErrNotFound, "leave request not found"}
+	ErrAttachmentNotFound   = &subKind{ErrNotFound, "attachment not found"}
+	ErrEmployeeExists       = &subKind{ErrConflict, "employee already exists"}
+	ErrLeaveRequestChanged  = &subKind{ErrConflict, "leave request was changed by someone else"}
+)
+
+// subKind：エラー種類を細かくしたもの。利用者に見せてよい文言を持つ
+type subKind struct {
+	kind error
+	msg  string
+}
+
+func (k *subKind) Error() string { return k.msg }
+func (k *subKind) Unwrap() error { return k.kind }
+
+// kinds は KindOf で判定する順番
+var kinds = []error{ErrNotFound, ErrValidation, ErrConflict, ErrForbidden, ErrUnauthenticated, ErrUnavailable, ErrInternal}
+
+// Error：種類と発生箇所を持つアプリケーションエラー
+// --------------------------------------------------------
+// - Kind：上記のエラー種類のどれか
+// - Op  ：発生箇所（例："SQLEmployeeRepo.FindByID"）。ログ調査用
+// - Msg ：利用者に見せてよいメッセージ。空なら Kind の文言を使う
+// - Err ：元になったエラー。ログには出すが利用者には見せない
+// --------------------------------------------------------
+type Error struct {
+	Kind error
+	Op   string
+	Msg  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	parts := make([]string, 0, 3)
+	if e.Op != "" {
+		parts = append(parts, e.Op)
+	}
+	if e.Msg != "" {
+		parts = append(parts, e.Msg)
+	} else if e.Kind != nil {
+		parts = append(parts, e.Kind.Error())
+	}
+	if e.Err != nil {
+		parts = append(parts, e.Err.Error())
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Unwrap は種類と元エラーの両方を返す。
+// これにより errors.Is(err, ErrNotFound) と errors.Is(err, sql.ErrNoRows) のどちらも成り立つ。
+func (e *Error) Unwrap() []error {
+	errs := make([]error, 0, 2)
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// NewError は種類・発生箇所・元エラーからアプリケーションエラーを作る。
+func NewError(kind error, op string, err error) error {
+	return &Error{Kind: kind, Op: op, Err: err}
+}
+
+// KindOf はエラーの種類を返す。どの種類にも当てはまらなければ ErrInternal。
+// Error が Error を包んでいるときは外側の種類を優先する
+// （見つからない上長を「入力不正」として返す場合など、元エラーの種類とは違う種類で返すことがあるため）。
+func KindOf(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) && e.Kind != nil {
+		err = e.Kind
+	}
+	for _, k := range kinds {
+		if errors.Is(err, k) {
+			return k
+		}
+	}
+	return ErrInternal
+}
+
+// PublicMessage は利用者に返してよいメッセージを返す。
+// 元エラー（DBドライバのメッセージなど）は含めない。
+func PublicMessage(err error) string {
+	var e *Error
+	if errors.As(err, &e) {
+		if e.Msg != "" {
+			return e.Msg
+		}
+		var k *subKind
+		if errors.As(e.Kind, &k) {
+			return k.msg
+		}
+	}
+	return KindOf(err).Error()
+}
@@ -0,0 +1,68 @@
+package usecase
+
+// 休暇申請の変更の発行（イベント）
+// --------------------------------------------------------
+// 連携先（勤怠・給与システムなど）がポーリングせずに休暇申請の変更に反応できるよう、
+// 各ユースケースは申請を保存し終えた後に、何が起きたかを EventPublisher に渡す。
+// - 保存に失敗したときは発行しない（保存していない変更は知らせない）
+// - 発行に失敗しても保存は取り消さず、ユースケースのエラーにもしない（利用者には保存できたとおりに返す）
+// - 届け方（プロセス内の購読者・ログへの記録など）と、発行できなかったイベントの記録は実装に任せる
+// --------------------------------------------------------
+// 保存と発行は別の操作なので、保存した直後にプロセスが落ちた・発行に失敗した変更は、その場では記録されない。
+// 保存されている申請を正本として、次の起動時に RecoverEvents が leave.recovered として記録し直す
+// （recover_events.go を参照。記録は at-least-once になる）。
+// --------------------------------------------------------
+
+import (
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// EventType：変更の種類
+type EventType string
+
+const (
+	EventLeaveSubmitted EventType = "leave.submitted" // 申請された（SubmitLeave）
+	EventLeaveImported  EventType = "leave.imported"  // 一括取込で登録された（ImportLeaves）
+	EventLeaveDecided   EventType = "leave.decided"   // 承認・却下・差し戻しされた（DecideLeave・自動承認）
+	EventLeaveReminded  EventType = "leave.reminded"  // 承認者に催促した
+	EventLeaveEscalated EventType = "leave.escalated" // 次の承認者へ回した
+	EventLeaveCancelled EventType = "leave.cancelled" // 取り消された（CancelLeave・退職処理）
+	EventLeaveExpired   EventType = "leave.expired"   // 開始日までに承認されず、期限切れとして取り消された
+	EventLeaveRecovered EventType = "leave.recovered" // 記録できなかった変更を、保存されている申請から補った（RecoverEvents）
+)
+
+// Event：保存し終えた休暇申請の変更
+type Event struct {
+	Type       EventType
+	OccurredAt time.Time
+	ActorID    string              // 操作した従業員（催促ジョブ・一括取込なら空）
+	Request    domain.LeaveRequest // 変更後の申請
+}
+
+// LoggedEvent：イベントログに記録されたイベント（Offset は記録した順に 1 から振る番号）
+type LoggedEvent struct {
+	Offset int64
+	Event
+}
+
+// EventPublisher：変更を発行する
+// ユースケースは Publish のエラーを呼び出し元に返さないので、失敗したイベントは実装の側で記録すること。
+type EventPublisher interface {
+	Publish(events ...Event) error
+}
+
+// publish は保存し終えた変更の events を発行する（p が nil なら何もしない）。
+// 失敗しても保存は済んでいるので、エラーは返さない（記録は EventPublisher の実装が行う）。
+func publish(p EventPublisher, events ...Event) {
+	if p == nil || len(events) == 0 {
+		return
+	}
+	_ = p.Publish(events...)
+}
+
+// leaveEvent は申請 req の変更を表すイベントを作る。
+func leaveEvent(t EventType, actorID string, req domain.LeaveRequest, at time.Time) Event {
+	return Event{Type: t, OccurredAt: at, ActorID: actorID, Request: req}
+}
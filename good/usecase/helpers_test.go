@@ -0,0 +1,66 @@
+package usecase_test
+
+// ユースケースのテストで共有する時計・メール送信の代わり
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// fixedClock：いつも同じ時刻を返す時計
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+// errMailDown：recordingMailer.Fail を設定したときに返すエラー
+var errMailDown = &usecase.Error{Kind: usecase.ErrUnavailable, Msg: "mail server down", Err: errors.New("connection refused")}
+
+// sentMail：送った通知1件（種類と宛先・申請）
+type sentMail struct {
+	Kind      string
+	Recipient string
+	RequestID string
+}
+
+// recordingMailer：送った通知を記録するだけの usecase.Mailer
+type recordingMailer struct {
+	mu   sync.Mutex
+	Fail bool // true ならすべての送信に失敗する
+	Sent []sentMail
+}
+
+func (m *recordingMailer) record(kind string, n usecase.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Fail {
+		return errMailDown
+	}
+	m.Sent = append(m.Sent, sentMail{Kind: kind, Recipient: n.Recipient.ID, RequestID: n.Request.ID})
+	return nil
+}
+
+func (m *recordingMailer) NotifyManagerNewRequest(n usecase.Notification) error {
+	return m.record("new", n)
+}
+
+func (m *recordingMailer) NotifyApproverReminder(n usecase.Notification) error {
+	return m.record("reminder", n)
+}
+
+func (m *recordingMailer) NotifyEscalation(n usecase.Notification) error {
+	return m.record("escalation", n)
+}
+
+func (m *recordingMailer) NotifyRequesterDecision(n usecase.Notification) error {
+	return m.record("decision", n)
+}
+
+func (m *recordingMailer) NotifyTeamAbsence(n usecase.Notification) error {
+	return m.record("team", n)
+}
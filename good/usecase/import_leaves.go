@@ -0,0 +1,306 @@
+package usecase
+
+// 過去の休暇申請の一括取込ユースケース
+// --------------------------------------------------------
+// スプレッドシートで管理していた過去の休暇申請をまとめて登録する。
+// - 1行ずつドメインルールで検証し、不正な行は理由を記録して取込対象から外す
+// - 1行のエラーでファイル全体を中断しない
+// - DryRun のときは検証だけ行い、保存しない
+// - 保存は BatchSize 件ずつまとめて行う
+// - 取り込めるのは人事だけ（他人の申請を承認済みで登録できるため）
+// - 承認待ちの申請は在籍中の上長に取込日時で回す（承認者のいない・退職した従業員の承認待ちは取り込まない）
+// --------------------------------------------------------
+// CSVの読み込みや文字コードの扱いは Adapter層の責務。ここでは変換済みの行だけを扱う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// DefaultImportBatchSize：BatchSize 未指定時の1回の書き込み件数
+const DefaultImportBatchSize = 100
+
+// ImportLeaves：一括取込ユースケースの実行構造体
+type ImportLeaves struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    LeaveRepo
+	YearStart     func(now time.Time) time.Time // 会計年度開始日の計算
+	BatchSize     int                           // 1回の書き込み件数（0なら DefaultImportBatchSize）
+	Events        EventPublisher                // 登録した申請の発行先（nil なら発行しない）
+	Clock         Clock                         // 承認待ちの申請が承認者に回った日時・発行するイベントの日時
+}
+
+// ImportRow：取込対象の1行（Adapter層で変換済み）
+type ImportRow struct {
+	Line       int // 元ファイルの行番号（エラーレポート用）
+	EmployeeID string
+	Type       domain.LeaveType
+	Reason     string
+	From       time.Time
+	To         time.Time
+	Status     domain.LeaveStatus
+	CreatedAt  time.Time
+	DecidedAt  time.Time // 承認・却下された日時（不明ならゼロ値）
+}
+
+// RowError：1行分のエラー
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// ImportInput：一括取込の入力
+type ImportInput struct {
+	ActorID   string // 取り込む操作者（人事のみ）
+	Rows      []ImportRow
+	Rejected  []RowError // Adapter層で変換できなかった行（レポートにそのまま含める）
+	DryRun    bool
+	BatchSize int // 1回の書き込み件数（0なら ImportLeaves.BatchSize）
+}
+
+// ImportOutput：一括取込の結果
+type ImportOutput struct {
+	Total    int        // 対象行数
+	Valid    int        // 検証を通過した行数
+	Imported int        // 実際に保存した行数（DryRun のときは 0）
+	Errors   []RowError // 行ごとのエラー（行番号順）
+	DryRun   bool
+}
+
+// Import：一括取込の実行
+// --------------------------------------------------------
+// 処理フロー：
+// 0. 操作者が人事であることの確認（DryRun でも行う）
+// 1. 作成日時の順に並べ、行ごとにドメインルールで検証
+// 2. DryRun でなければ、検証を通過した行を BatchSize 件ずつ保存し、保存した申請を発行（leave.imported）
+// --------------------------------------------------------
+// 行単位の問題（従業員が存在しない・期間が不正など）は Errors に記録して続行する。
+// DB停止など行と無関係の問題はその時点で中断し、途中までの結果とエラーを返す。
+// --------------------------------------------------------
+func (uc ImportLeaves) Import(in ImportInput) (ImportOutput, error) {
+	// 0. 操作者が人事であることの確認
+	if err := requireHR(uc.EmployeesRepo, in.ActorID); err != nil {
+		return ImportOutput{}, err
+	}
+	out := ImportOutput{
+		Total:  len(in.Rows) + len(in.Rejected),
+		Errors: append([]RowError{}, in.Rejected...),
+		DryRun: in.DryRun,
+	}
+	err := uc.importRows(in, &out)
+	sort.SliceStable(out.Errors, func(i, j int) bool { return out.Errors[i].Line < out.Errors[j].Line })
+	return out, err
+}
+
+func (uc ImportLeaves) importRows(in ImportInput, out *ImportOutput) error {
+	// 1. 作成日時の順に並べ、行ごとにドメインルールで検証
+	rows := append([]ImportRow{}, in.Rows...)
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].CreatedAt.Before(rows[j].CreatedAt) })
+
+	v := importValidator{uc: uc, now: uc.Clock.Now(), employees: map[string]domain.Employee{}, approvers: map[string]string{}, counts: map[fiscalKey]int{}}
+	var valid []importItem
+	for _, row := range rows {
+		req, err := v.validate(row)
+		if err != nil {
+			if !isRowError(err) {
+				return err
+			}
+			out.Errors = append(out.Errors, RowError{Line: row.Line, Err: err})
+			continue
+		}
+		valid = append(valid, importItem{line: row.Line, req: req})
+	}
+	out.Valid = len(valid)
+	if in.DryRun {
+		return nil
+	}
+
+	// 2. 検証を通過した行を BatchSize 件ずつ保存し、保存した申請を発行
+	size := in.BatchSize
+	if size <= 0 {
+		size = uc.BatchSize
+	}
+	if size <= 0 {
+		size = DefaultImportBatchSize
+	}
+	for start := 0; start < len(valid); start += size {
+		batch := valid[start:min(start+size, len(valid))]
+		n, rowErrs, err := uc.writeBatch(in.ActorID, batch)
+		out.Imported += n
+		out.Errors = append(out.Errors, rowErrs...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importItem：保存待ちの申請と元の行番号
+type importItem struct {
+	line int
+	req  *domain.LeaveRequest
+}
+
+// writeBatch は1バッチ分を保存する。
+// 一括保存に失敗した場合は1件ずつ保存し直し、失敗した行だけをエラーとして記録する。
+func (uc ImportLeaves) writeBatch(actorID string, batch []importItem) (int, []RowError, error) {
+	if bc, ok := uc.LeavesRepo.(LeaveBatchCreator); ok {
+		reqs := make([]*domain.LeaveRequest, len(batch))
+		for i, it := range batch {
+			reqs[i] = it.req
+		}
+		err := bc.CreateBatch(reqs)
+		if err == nil {
+			uc.publish(actorID, reqs...)
+			return len(batch), nil, nil
+		}
+		if !isRowError(err) {
+			return 0, nil, err
+		}
+	}
+	imported := 0
+	var rowErrs []RowError
+	for _, it := range batch {
+		if err := uc.LeavesRepo.Create(it.req); err != nil {
+			if !isRowError(err) {
+				return imported, rowErrs, err
+			}
+			rowErrs = append(rowErrs, RowError{Line: it.line, Err: err})
+			continue
+		}
+		imported++
+		uc.publish(actorID, it.req)
+	}
+	return imported, rowErrs, nil
+}
+
+// publish は保存した申請を、取り込んだ人事を操作者とする leave.imported として発行する。
+func (uc ImportLeaves) publish(actorID string, reqs ...*domain.LeaveRequest) {
+	if uc.Events == nil {
+		return
+	}
+	now := uc.Clock.Now()
+	events := make([]Event, len(reqs))
+	for i, req := range reqs {
+		events[i] = leaveEvent(EventLeaveImported, actorID, *req, now)
+	}
+	publish(uc.Events, events...)
+}
+
+// fiscalKey：従業員×会計年度
+type fiscalKey struct {
+	employeeID string
+	yearStart  time.Time
+}
+
+// errPendingWithoutApprover：承認待ちの行だが、回す承認者がいない
+var errPendingWithoutApprover = &Error{Kind: ErrValidation, Msg: "pending request needs an active employee with a manager"}
+
+// importValidator：行の検証に使う従業員情報と年度内件数を覚えておく
+type importValidator struct {
+	uc        ImportLeaves
+	now       time.Time // 承認待ちの申請が承認者に回った日時
+	employees map[string]domain.Employee
+	approvers map[string]string // 従業員ID → 承認待ちの申請を回す在籍中の上長
+	counts    map[fiscalKey]int // 既存の件数 + ここまでに検証を通過した行の件数
+}
+
+// validate は1行をドメインルールで検証し、保存する申請を返す。
+func (v importValidator) validate(row ImportRow) (*domain.LeaveRequest, error) {
+	if row.EmployeeID == "" {
+		return nil, &Error{Kind: ErrValidation, Msg: "employee_id is required"}
+	}
+	if err := domain.ValidatePeriod(row.From, row.To); err != nil {
+		return nil, &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+	emp, err := v.employee(row.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+	key := fiscalKey{row.EmployeeID, v.uc.YearStart(row.CreatedAt)}
+	count, err := v.count(key)
+	if err != nil {
+		return nil, err
+	}
+	// 在籍しているかは、今ではなく申請した時点で判定する（退職者の過去の申請も取り込めるように）
+	for _, reason := range domain.CheckSubmit(emp, count, row.CreatedAt) {
+		if reason != domain.ReasonInactive || !emp.WasActiveAt(row.CreatedAt) {
+			return nil, ErrNotEligible
+		}
+	}
+	req := &domain.LeaveRequest{
+		EmployeeID: row.EmployeeID,
+		Type:       row.Type,
+		Reason:     row.Reason,
+		From:       row.From,
+		To:         row.To,
+		Status:     row.Status,
+		CreatedAt:  row.CreatedAt,
+		DecidedAt:  row.DecidedAt,
+	}
+	// 承認待ちの申請は、承認・却下できるよう在籍中の上長に回す
+	if row.Status == domain.StatusPending {
+		approverID, err := v.approver(emp)
+		if err != nil {
+			return nil, err
+		}
+		if !emp.IsActive() || approverID == "" {
+			return nil, errPendingWithoutApprover
+		}
+		req.ApproverID = approverID
+		req.AssignedAt = v.now
+		req.DecidedAt = time.Time{}
+	}
+	// 却下・取消などで終わった申請は、取り込んでも申請回数に数えない
+	v.counts[key] = count + domain.QuotaDelta("", row.Status)
+	return req, nil
+}
+
+// employee は従業員情報を取得する（同じ従業員は1回だけ問い合わせる）。
+func (v importValidator) employee(id string) (domain.Employee, error) {
+	if e, ok := v.employees[id]; ok {
+		return e, nil
+	}
+	e, err := v.uc.EmployeesRepo.FindByID(id)
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	v.employees[id] = e
+	return e, nil
+}
+
+// approver は承認待ちの申請を回す在籍中の上長を返す（同じ従業員は1回だけたどる）。
+func (v importValidator) approver(emp domain.Employee) (string, error) {
+	if id, ok := v.approvers[emp.ID]; ok {
+		return id, nil
+	}
+	id, err := activeApprover(v.uc.EmployeesRepo, emp.ManagerID)
+	if err != nil {
+		return "", err
+	}
+	v.approvers[emp.ID] = id
+	return id, nil
+}
+
+// count はその会計年度に登録済みの件数を返す。
+func (v importValidator) count(key fiscalKey) (int, error) {
+	if c, ok := v.counts[key]; ok {
+		return c, nil
+	}
+	c, err := v.uc.LeavesRepo.CountThisFiscalYear(key.employeeID, key.yearStart)
+	if err != nil {
+		return 0, err
+	}
+	v.counts[key] = c
+	return c, nil
+}
+
+// isRowError は、そのエラーが行の内容に起因するもの（続行してよいもの）かを返す。
+func isRowError(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrValidation) ||
+		errors.Is(err, ErrConflict) || errors.Is(err, ErrForbidden)
+}
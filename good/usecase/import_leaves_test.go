@@ -0,0 +1,120 @@
+package usecase_test
+
+// ImportLeaves のテスト（メモリの保存先を使う）
+// --------------------------------------------------------
+// - 退職者の在籍中の申請は取り込み、退職後の申請は申請条件を満たさないとして外す
+// - 承認待ちの行は上長を承認者として取り込み、承認・却下できる状態にする
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// importNow：取込を実行する日時
+var importNow = time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)
+
+func newImportStore(t *testing.T, emps ...domain.Employee) (*drivers.MemoryStore, usecase.ImportLeaves) {
+	t.Helper()
+	st := drivers.NewMemoryStore()
+	st.Seed(append([]domain.Employee{
+		{ID: "hr", Name: "HR", HireDate: day(2015, 4, 1), Role: domain.RoleHR},
+		{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1)},
+	}, emps...)...)
+	return st, usecase.ImportLeaves{
+		EmployeesRepo: st.Employees(),
+		LeavesRepo:    st.Leaves(),
+		YearStart:     domain.FiscalYearStart,
+		Clock:         fixedClock(importNow),
+	}
+}
+
+func TestImportLeavesHistoryOfLeaver(t *testing.T) {
+	leaver := domain.Employee{ID: "bob", Name: "Bob", HireDate: day(2018, 4, 1), ManagerID: "boss", LeftOn: day(2024, 3, 31)}
+	st, uc := newImportStore(t, leaver)
+
+	out, err := uc.Import(usecase.ImportInput{ActorID: "hr", Rows: []usecase.ImportRow{
+		{Line: 2, EmployeeID: "bob", Type: domain.LeavePaid, From: day(2022, 8, 1), To: day(2022, 8, 3),
+			Status: domain.StatusApproved, CreatedAt: day(2022, 7, 1), DecidedAt: day(2022, 7, 2)},
+		{Line: 3, EmployeeID: "bob", Type: domain.LeavePaid, From: day(2024, 3, 28), To: day(2024, 3, 29),
+			Status: domain.StatusApproved, CreatedAt: day(2024, 3, 30).Add(23 * time.Hour), DecidedAt: day(2024, 3, 31)},
+		// 退職日以降の申請はありえない
+		{Line: 4, EmployeeID: "bob", Type: domain.LeavePaid, From: day(2024, 4, 1), To: day(2024, 4, 1),
+			Status: domain.StatusApproved, CreatedAt: day(2024, 3, 31), DecidedAt: day(2024, 3, 31)},
+	}})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if out.Imported != 2 || out.Valid != 2 {
+		t.Errorf("Imported = %d, Valid = %d, want 2, 2 (errors %v)", out.Imported, out.Valid, out.Errors)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Line != 4 || !errors.Is(out.Errors[0].Err, usecase.ErrNotEligible) {
+		t.Errorf("Errors = %v, want line 4 not eligible", out.Errors)
+	}
+	reqs, err := st.Leaves().ListByEmployee("bob")
+	if err != nil || len(reqs) != 2 {
+		t.Fatalf("ListByEmployee = %v, %v, want 2 requests", reqs, err)
+	}
+}
+
+func TestImportLeavesOtherReasonsStillApplyToLeavers(t *testing.T) {
+	// 在籍中だった時点でも勤続半年に満たなければ取り込まない
+	leaver := domain.Employee{ID: "bob", Name: "Bob", HireDate: day(2023, 4, 1), ManagerID: "boss", LeftOn: day(2024, 3, 31)}
+	_, uc := newImportStore(t, leaver)
+
+	out, err := uc.Import(usecase.ImportInput{ActorID: "hr", Rows: []usecase.ImportRow{
+		{Line: 2, EmployeeID: "bob", Type: domain.LeavePaid, From: day(2023, 6, 1), To: day(2023, 6, 1),
+			Status: domain.StatusApproved, CreatedAt: day(2023, 5, 1)},
+	}})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if out.Imported != 0 || len(out.Errors) != 1 || !errors.Is(out.Errors[0].Err, usecase.ErrNotEligible) {
+		t.Errorf("Imported = %d, Errors = %v, want the row rejected as not eligible", out.Imported, out.Errors)
+	}
+}
+
+func TestImportLeavesPendingGoesToManager(t *testing.T) {
+	alice := domain.Employee{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"}
+	top := domain.Employee{ID: "top", Name: "Top", HireDate: day(2020, 4, 1)}
+	leaver := domain.Employee{ID: "bob", Name: "Bob", HireDate: day(2018, 4, 1), ManagerID: "boss", LeftOn: day(2025, 3, 31)}
+	st, uc := newImportStore(t, alice, top, leaver)
+
+	out, err := uc.Import(usecase.ImportInput{ActorID: "hr", Rows: []usecase.ImportRow{
+		{Line: 2, EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 3),
+			Status: domain.StatusPending, CreatedAt: day(2025, 5, 1)},
+		// 上長のいない・退職した従業員の承認待ちは、誰も判断できないので取り込まない
+		{Line: 3, EmployeeID: "top", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2),
+			Status: domain.StatusPending, CreatedAt: day(2025, 5, 1)},
+		{Line: 4, EmployeeID: "bob", Type: domain.LeavePaid, From: day(2025, 3, 3), To: day(2025, 3, 3),
+			Status: domain.StatusPending, CreatedAt: day(2025, 2, 1)},
+	}})
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if out.Imported != 1 || len(out.Errors) != 2 || out.Errors[0].Line != 3 || out.Errors[1].Line != 4 {
+		t.Fatalf("Imported = %d, Errors = %v, want line 2 imported and lines 3, 4 rejected", out.Imported, out.Errors)
+	}
+	for _, e := range out.Errors {
+		if !errors.Is(e.Err, usecase.ErrValidation) {
+			t.Errorf("line %d: err = %v, want ErrValidation", e.Line, e.Err)
+		}
+	}
+
+	reqs, err := st.Leaves().ListPendingByEmployee("alice")
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("ListPendingByEmployee = %v, %v, want 1 request", reqs, err)
+	}
+	req := reqs[0]
+	if req.ApproverID != "boss" || !req.AssignedAt.Equal(importNow) {
+		t.Errorf("ApproverID = %q, AssignedAt = %v, want boss at %v", req.ApproverID, req.AssignedAt, importNow)
+	}
+	if err := req.Decide("boss", domain.DecisionApprove, "", importNow); err != nil {
+		t.Errorf("manager cannot decide on the imported request: %v", err)
+	}
+}
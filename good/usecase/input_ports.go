@@ -0,0 +1,93 @@
+package usecase
+
+// UseCaseの入口を抽象化するインターフェース群（入力ポート）
+// --------------------------------------------------------
+// Adapter層（HTTPハンドラやCLI）は具体的なUseCase構造体ではなく、これらのインターフェースに依存する。
+// これにより、UseCaseを変更せずにログ・計測などの横断的な処理を外側から被せられる。
+// --------------------------------------------------------
+
+import (
+	"io"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// LeaveSubmitter：休暇申請（SubmitLeave）
+type LeaveSubmitter interface {
+	Submit(in SubmitInput) (SubmitOutput, error)
+}
+
+// LeavePreviewer：休暇申請の事前確認（PreviewLeave）
+type LeavePreviewer interface {
+	Preview(in SubmitInput) (PreviewOutput, error)
+}
+
+// LeaveImporter：過去の休暇申請の一括取込（ImportLeaves）
+type LeaveImporter interface {
+	Import(in ImportInput) (ImportOutput, error)
+}
+
+// StaleReminder：滞留している申請の催促（RemindStaleRequests）
+type StaleReminder interface {
+	Run() (RemindOutput, error)
+}
+
+// LeaveDecider：承認者による判断（DecideLeave）
+type LeaveDecider interface {
+	Decide(in DecideInput) (DecideOutput, error)
+}
+
+// LeaveViewer：休暇申請の参照（ViewLeaves）
+type LeaveViewer interface {
+	List(in ListLeavesInput) ([]domain.LeaveRequest, error)
+	Get(actorID, requestID string) (domain.LeaveRequest, error)
+}
+
+// LeaveCanceller：申請者による取り下げ（CancelLeave）
+type LeaveCanceller interface {
+	Cancel(actorID, requestID string) (domain.LeaveRequest, error)
+}
+
+// LeaveReporter：休暇の取得状況の集計（LeaveReports）
+type LeaveReporter interface {
+	Generate(in ReportInput) (LeaveReport, error)
+}
+
+// EmployeeManager：人事による従業員の管理（ManageEmployees）
+type EmployeeManager interface {
+	Get(actorID, id string) (domain.Employee, error)
+	List(actorID string) ([]domain.Employee, error)
+	Create(actorID string, in EmployeeInput) (domain.Employee, error)
+	Update(actorID, id string, p EmployeePatch) (domain.Employee, error)
+	SetNotificationPrefs(actorID, id string, p domain.NotificationPrefs) (domain.Employee, error)
+	Deactivate(actorID, id string, leftOn time.Time) (DeactivateOutput, error)
+}
+
+// AttachmentUploader：添付ファイルの追加（UploadAttachment）
+type AttachmentUploader interface {
+	Upload(in UploadInput) (domain.Attachment, error)
+}
+
+// AttachmentLister：申請の添付ファイルの一覧（ListAttachments）
+type AttachmentLister interface {
+	List(actorID, requestID string) ([]domain.Attachment, error)
+}
+
+// AttachmentDownloader：添付ファイルの取得（DownloadAttachment）。中身は呼び出し側で閉じる
+type AttachmentDownloader interface {
+	Download(actorID, attachmentID string) (domain.Attachment, io.ReadCloser, error)
+}
+
+// CalendarFeeder：承認済みの休暇のカレンダー配信（CalendarFeeds）
+type CalendarFeeder interface {
+	EmployeeFeed(employeeID, token string) (CalendarFeed, error)
+	TeamFeed(managerID, token string) (CalendarFeed, error)
+	FeedTokens(actorID string) (FeedTokensOutput, error)
+}
+
+// EventFeed：記録した休暇申請の変更の読み出し（連携先が offset を指定して読む）
+type EventFeed interface {
+	Read(from int64, limit int) ([]LoggedEvent, error)
+	LastOffset() int64
+}
@@ -0,0 +1,211 @@
+package usecase
+
+// 休暇取得状況の集計ユースケース（人事向けレポート）
+// --------------------------------------------------------
+// 月次・年度ごとに、次の観点で休暇申請を集計する。
+// - 取得日数（従業員別・部署別・休暇の種類別）
+// - 承認・却下・差し戻しの件数と却下率
+// - 申請から決定までの平均リードタイム
+// --------------------------------------------------------
+// CSV・XLSX などの出力形式は Adapter層の責務。ここでは集計結果（DTO）だけを返す。
+// --------------------------------------------------------
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// ReportPeriodKind：集計期間の種類
+type ReportPeriodKind string
+
+const (
+	PeriodMonth      ReportPeriodKind = "month"       // 月次（Year 年 Month 月）
+	PeriodFiscalYear ReportPeriodKind = "fiscal-year" // 年度（Year 年度。開始日は YearStart で決まる）
+)
+
+// LeaveReports：集計ユースケースの実行構造体
+type LeaveReports struct {
+	EmployeesRepo EmployeeRepo
+	EmployeeList  EmployeeLister
+	LeavesRepo    LeavePeriodRepo
+	YearStart     func(now time.Time) time.Time // 会計年度開始日の計算
+}
+
+// ReportInput：集計の入力
+type ReportInput struct {
+	ActorID string // 操作している従業員（人事のみ集計できる）
+	Kind    ReportPeriodKind
+	Year    int
+	Month   time.Month // Kind が PeriodMonth のときだけ使う
+}
+
+// ReportRow：集計結果の1行
+type ReportRow struct {
+	Key           string        // 従業員ID・部署名・休暇の種類（合計行は "TOTAL"）
+	DaysTaken     float64       // 期間内に取得した日数（承認済み・平日のみ。半日休は 0.5 日）
+	Approved      int           // 承認件数
+	Rejected      int           // 却下件数
+	Returned      int           // 差し戻し件数
+	Pending       int           // 承認待ち件数
+	RejectionRate float64       // 却下率 = 却下 / (承認 + 却下)
+	AvgLeadTime   time.Duration // 申請から決定までの平均（決定日時が分かるものだけ）
+}
+
+// LeaveReport：集計結果
+type LeaveReport struct {
+	From         time.Time // 集計期間の開始日
+	To           time.Time // 集計期間の終了日（この日を含む）
+	ByEmployee   []ReportRow
+	ByDepartment []ReportRow
+	ByType       []ReportRow
+	Total        ReportRow
+}
+
+// Generate：集計の実行
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者が人事であることの確認
+// 2. 集計期間の決定
+// 3. 期間に重なる申請と従業員一覧の取得
+// 4. 従業員別・部署別・種類別に集計
+// --------------------------------------------------------
+func (uc LeaveReports) Generate(in ReportInput) (LeaveReport, error) {
+	// 1. 操作者が人事であることの確認
+	if err := requireHR(uc.EmployeesRepo, in.ActorID); err != nil {
+		return LeaveReport{}, err
+	}
+
+	// 2. 集計期間の決定
+	from, to, err := uc.period(in)
+	if err != nil {
+		return LeaveReport{}, err
+	}
+
+	// 3. 期間に重なる申請と従業員一覧の取得
+	reqs, err := uc.LeavesRepo.ListOverlapping(from, to)
+	if err != nil {
+		return LeaveReport{}, err
+	}
+	emps, err := uc.EmployeeList.List()
+	if err != nil {
+		return LeaveReport{}, err
+	}
+	department := make(map[string]string, len(emps))
+	for _, e := range emps {
+		department[e.ID] = e.Department
+	}
+
+	// 4. 従業員別・部署別・種類別に集計
+	byEmp, byDept, byType := newReportGroup(), newReportGroup(), newReportGroup()
+	total := &reportAcc{}
+	for _, req := range reqs {
+		byEmp.get(req.EmployeeID).add(req, from, to)
+		byDept.get(department[req.EmployeeID]).add(req, from, to)
+		byType.get(string(req.Type)).add(req, from, to)
+		total.add(req, from, to)
+	}
+	return LeaveReport{
+		From:         from,
+		To:           to,
+		ByEmployee:   byEmp.rows(),
+		ByDepartment: byDept.rows(),
+		ByType:       byType.rows(),
+		Total:        total.row("TOTAL"),
+	}, nil
+}
+
+// period は集計期間（開始日と、終了日を含む最終日）を求める。
+func (uc LeaveReports) period(in ReportInput) (time.Time, time.Time, error) {
+	switch in.Kind {
+	case PeriodMonth:
+		if in.Month < time.January || in.Month > time.December {
+			return time.Time{}, time.Time{}, &Error{Kind: ErrValidation, Msg: fmt.Sprintf("bad month: %d", in.Month)}
+		}
+		from := time.Date(in.Year, in.Month, 1, 0, 0, 0, 0, time.UTC)
+		return from, from.AddDate(0, 1, -1), nil
+	case PeriodFiscalYear:
+		// 「Year 年度」は Year 年中に始まる年度とする
+		from := uc.YearStart(time.Date(in.Year, 12, 31, 0, 0, 0, 0, time.UTC))
+		return from, uc.YearStart(from.AddDate(1, 0, 0)).AddDate(0, 0, -1), nil
+	}
+	return time.Time{}, time.Time{}, &Error{Kind: ErrValidation, Msg: fmt.Sprintf("bad period: %q", in.Kind)}
+}
+
+// requireHR は操作者が人事であることを確認する。
+func requireHR(emps EmployeeRepo, actorID string) error {
+	if actorID == "" {
+		return &Error{Kind: ErrUnauthenticated, Msg: "authentication required"}
+	}
+	actor, err := emps.FindByID(actorID)
+	if err != nil {
+		return err
+	}
+	if actor.Role != domain.RoleHR {
+		return &Error{Kind: ErrForbidden, Msg: "only HR can perform this operation"}
+	}
+	return nil
+}
+
+// reportAcc：1行分の集計途中の値
+type reportAcc struct {
+	ReportRow
+	leadTimeSum   time.Duration
+	leadTimeCount int
+}
+
+func (a *reportAcc) add(req domain.LeaveRequest, from, to time.Time) {
+	a.DaysTaken += req.DaysTakenBetween(from, to)
+	switch req.Status {
+	case domain.StatusApproved:
+		a.Approved++
+	case domain.StatusRejected:
+		a.Rejected++
+	case domain.StatusReturned:
+		a.Returned++
+	case domain.StatusPending:
+		a.Pending++
+	}
+	if req.IsDecided() && !req.DecidedAt.IsZero() {
+		a.leadTimeSum += req.DecidedAt.Sub(req.CreatedAt)
+		a.leadTimeCount++
+	}
+}
+
+func (a *reportAcc) row(key string) ReportRow {
+	r := a.ReportRow
+	r.Key = key
+	if n := r.Approved + r.Rejected; n > 0 {
+		r.RejectionRate = float64(r.Rejected) / float64(n)
+	}
+	if a.leadTimeCount > 0 {
+		r.AvgLeadTime = a.leadTimeSum / time.Duration(a.leadTimeCount)
+	}
+	return r
+}
+
+// reportGroup：キーごとの集計
+type reportGroup map[string]*reportAcc
+
+func newReportGroup() reportGroup { return reportGroup{} }
+
+func (g reportGroup) get(key string) *reportAcc {
+	a, ok := g[key]
+	if !ok {
+		a = &reportAcc{}
+		g[key] = a
+	}
+	return a
+}
+
+// rows はキー順に並べた集計結果を返す。
+func (g reportGroup) rows() []ReportRow {
+	rows := make([]ReportRow, 0, len(g))
+	for k, a := range g {
+		rows = append(rows, a.row(k))
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	return rows
+}
@@ -0,0 +1,420 @@
+package usecase
+
+// 従業員管理ユースケース（人事向け）
+// --------------------------------------------------------
+// - Create    ：従業員の登録（入社日・上長の検証つき）
+// - Update    ：従業員情報の変更
+// - Deactivate：退職処理（承認待ちの申請を自動取消し、残り申請回数を凍結。部下と承認待ちの判断は退職者の上長へ回す）
+// - Get / List：従業員情報の参照
+// - SetNotificationPrefs：通知の受け取り方（チャネル・静かな時間）の変更
+// --------------------------------------------------------
+// 登録・変更・退職処理は人事のみが行える。参照と通知の受け取り方の変更は人事と本人のみ。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// maxManagerDepth：上長をたどる深さの上限（データ不整合による無限ループ防止）
+const maxManagerDepth = 100
+
+// ManageEmployees：従業員管理ユースケースの実行構造体
+type ManageEmployees struct {
+	EmployeesRepo  EmployeeRepo
+	EmployeeList   EmployeeLister
+	EmployeeWriter EmployeeWriter
+	LeavesRepo     LeaveRepo                // 退職時の残り申請回数の算出に使う
+	EmployeeLeaves EmployeeLeavesRepo       // 退職時の承認待ち申請の取消・承認者の付け替えに使う
+	Cache          EmployeeCacheInvalidator // 従業員情報のキャッシュ（なければ nil）
+	Events         EventPublisher           // 退職時に取り消した・回した申請の発行先（nil なら発行しない）
+	Mailer         Mailer                   // 退職時に回した申請の新しい承認者への通知（nil なら通知しない）
+	Links          LinkBuilder              // 通知に載せるリンク（nil ならリンクなし）
+	Clock          Clock
+	YearStart      func(now time.Time) time.Time // 会計年度開始日の計算
+}
+
+// EmployeeInput：従業員の登録内容
+type EmployeeInput struct {
+	ID         string
+	Name       string
+	Email      string
+	HireDate   time.Time
+	ManagerID  string
+	Department string
+	Role       domain.Role
+	Locale     domain.Locale // 通知の言語（空なら既定の言語）
+}
+
+// EmployeePatch：従業員情報の変更内容（nil の項目は変更しない）
+type EmployeePatch struct {
+	Name       *string
+	Email      *string
+	HireDate   *time.Time
+	ManagerID  *string
+	Department *string
+	Role       *domain.Role
+	Locale     *domain.Locale
+}
+
+// DeactivateOutput：退職処理の結果
+type DeactivateOutput struct {
+	Employee            domain.Employee
+	CancelledRequests   []string // 自動取消した申請ID
+	ReassignedEmployees []string // 上長を退職者の上長に付け替えた部下のID
+	ReassignedRequests  []string // 承認者を退職者の上長に付け替えた申請ID
+	UnassignedRequests  []string // 退職者が承認者で、回す先の上長がいない申請ID（人事が対応する）
+	NotifyErr           error    // 付け替えた申請の新しい承認者への通知の失敗（nil ならすべて通知した）
+}
+
+// Get は従業員情報を返す（人事と本人のみ）。
+func (uc ManageEmployees) Get(actorID, id string) (domain.Employee, error) {
+	if actorID != id || actorID == "" {
+		if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+			return domain.Employee{}, err
+		}
+	}
+	return uc.EmployeesRepo.FindByID(id)
+}
+
+// List は全従業員を返す（人事のみ）。
+func (uc ManageEmployees) List(actorID string) ([]domain.Employee, error) {
+	if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+		return nil, err
+	}
+	return uc.EmployeeList.List()
+}
+
+// Create：従業員の登録
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者が人事であることの確認
+// 2. ドメインルールによる検証（入社日・上長）
+// 3. 保存
+// --------------------------------------------------------
+func (uc ManageEmployees) Create(actorID string, in EmployeeInput) (domain.Employee, error) {
+	// 1. 操作者が人事であることの確認
+	if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+		return domain.Employee{}, err
+	}
+	e := domain.Employee{
+		ID:         in.ID,
+		Name:       in.Name,
+		Email:      in.Email,
+		HireDate:   in.HireDate,
+		ManagerID:  in.ManagerID,
+		Department: in.Department,
+		Role:       in.Role,
+		Locale:     in.Locale,
+	}
+	if e.Role == "" {
+		e.Role = domain.RoleEmployee
+	}
+
+	// 2. ドメインルールによる検証（入社日・上長）
+	if err := uc.validate(e); err != nil {
+		return domain.Employee{}, err
+	}
+
+	// 3. 保存
+	if err := uc.writeEmployee(&e, uc.EmployeeWriter.Create); err != nil {
+		return domain.Employee{}, err
+	}
+	return e, nil
+}
+
+// Update：従業員情報の変更
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者が人事であることの確認
+// 2. 現在の情報を取得し、変更内容を反映
+// 3. ドメインルールによる検証（入社日・上長）
+// 4. 保存
+// --------------------------------------------------------
+func (uc ManageEmployees) Update(actorID, id string, p EmployeePatch) (domain.Employee, error) {
+	// 1. 操作者が人事であることの確認
+	if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+		return domain.Employee{}, err
+	}
+
+	// 2. 現在の情報を取得し、変更内容を反映
+	e, err := uc.EmployeesRepo.FindByID(id)
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	if !e.IsActive() {
+		return domain.Employee{}, &Error{Kind: ErrConflict, Msg: domain.ErrAlreadyLeft.Error()}
+	}
+	applyEmployeePatch(&e, p)
+
+	// 3. ドメインルールによる検証（入社日・上長）
+	if err := uc.validate(e); err != nil {
+		return domain.Employee{}, err
+	}
+
+	// 4. 保存
+	if err := uc.writeEmployee(&e, uc.EmployeeWriter.Update); err != nil {
+		return domain.Employee{}, err
+	}
+	return e, nil
+}
+
+// SetNotificationPrefs は通知の受け取り方を変更する（人事と本人のみ）。
+func (uc ManageEmployees) SetNotificationPrefs(actorID, id string, p domain.NotificationPrefs) (domain.Employee, error) {
+	if actorID != id || actorID == "" {
+		if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+			return domain.Employee{}, err
+		}
+	}
+	if err := domain.ValidateNotificationPrefs(p); err != nil {
+		return domain.Employee{}, &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+	e, err := uc.EmployeesRepo.FindByID(id)
+	if err != nil {
+		return domain.Employee{}, err
+	}
+	if !e.IsActive() {
+		return domain.Employee{}, &Error{Kind: ErrConflict, Msg: domain.ErrAlreadyLeft.Error()}
+	}
+	e.Notify = p
+	if err := uc.writeEmployee(&e, uc.EmployeeWriter.Update); err != nil {
+		return domain.Employee{}, err
+	}
+	return e, nil
+}
+
+// Deactivate：退職処理
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者が人事であることの確認
+// 2. 残り申請回数を凍結して退職状態にし、保存（これ以降の申請は受け付けない）
+// 3. 保存した後の承認待ちの申請をすべて取消し、取消を発行（leave.cancelled）
+// 4. 部下の上長と、退職者が承認者の承認待ちの申請を、退職者の在籍中の上長へ付け替える（leave.escalated）
+// 5. 取消で申請回数が変わったら、凍結した残り申請回数を保存し直す
+// --------------------------------------------------------
+// 退職状態を先に保存するので、取消の一覧を作った後に申請が届くことはない
+// （先に取り消すと、取消と保存の間に届いた申請が退職者の承認待ちとして残る）。
+// 途中で失敗しても再実行すれば、承認待ちの申請・部下・退職者が承認者の申請が残っている間は続きから処理される。
+// --------------------------------------------------------
+func (uc ManageEmployees) Deactivate(actorID, id string, leftOn time.Time) (DeactivateOutput, error) {
+	// 1. 操作者が人事であることの確認
+	if err := requireHR(uc.EmployeesRepo, actorID); err != nil {
+		return DeactivateOutput{}, err
+	}
+	now := uc.Clock.Now()
+	if leftOn.IsZero() {
+		y, m, d := now.Date()
+		leftOn = time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	}
+	e, err := uc.EmployeesRepo.FindByID(id)
+	if err != nil {
+		return DeactivateOutput{}, err
+	}
+
+	// 2. 残り申請回数を凍結して退職状態にし、保存
+	resuming := !e.IsActive()
+	if !resuming {
+		count, err := uc.LeavesRepo.CountThisFiscalYear(id, uc.YearStart(leftOn))
+		if err != nil {
+			return DeactivateOutput{}, err
+		}
+		if err := e.Leave(leftOn, domain.RemainingQuota(count)); err != nil {
+			return DeactivateOutput{}, &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+		}
+		if err := uc.writeEmployee(&e, uc.EmployeeWriter.Update); err != nil {
+			return DeactivateOutput{}, err
+		}
+	}
+	out := DeactivateOutput{Employee: e}
+
+	// 3. 保存した後の承認待ちの申請をすべて取消
+	pending, err := uc.EmployeeLeaves.ListPendingByEmployee(id)
+	if err != nil {
+		return out, err
+	}
+	reassign, err := uc.leaverAssignments(id)
+	if err != nil {
+		return out, err
+	}
+	if resuming && len(pending) == 0 && reassign.empty() {
+		return DeactivateOutput{}, &Error{Kind: ErrConflict, Msg: domain.ErrAlreadyLeft.Error()}
+	}
+	for i := range pending {
+		req := &pending[i]
+		if err := req.Cancel(now); err != nil {
+			continue // 取得後に他の処理で決定済みになったもの
+		}
+		if err := uc.EmployeeLeaves.Update(req, domain.StatusPending); err != nil {
+			if errors.Is(err, ErrLeaveRequestChanged) {
+				continue // 取得後に他の処理で決定済みになったもの
+			}
+			return out, err
+		}
+		out.CancelledRequests = append(out.CancelledRequests, req.ID)
+		publish(uc.Events, leaveEvent(EventLeaveCancelled, actorID, *req, now))
+	}
+
+	// 4. 部下の上長と、退職者が承認者の申請を、退職者の在籍中の上長へ付け替える
+	if err := uc.reassign(&out, actorID, e, reassign, now); err != nil {
+		return out, err
+	}
+
+	// 5. 取消で申請回数が変わったら、凍結した残り申請回数を保存し直す
+	count, err := uc.LeavesRepo.CountThisFiscalYear(id, uc.YearStart(e.LeftOn))
+	if err != nil {
+		return out, err
+	}
+	if remaining := domain.RemainingQuota(count); remaining != e.FrozenQuota {
+		e.FrozenQuota = remaining
+		if err := uc.writeEmployee(&e, uc.EmployeeWriter.Update); err != nil {
+			return out, err
+		}
+		out.Employee = e
+	}
+	return out, nil
+}
+
+// leaverAssignments：退職者が上長・承認者になっているもの
+type leaverAssignments struct {
+	subordinates []domain.Employee     // 退職者が上長の在籍者
+	approvals    []domain.LeaveRequest // 退職者が承認者の承認待ちの申請（退職者自身の申請は除く）
+}
+
+func (a leaverAssignments) empty() bool {
+	return len(a.subordinates) == 0 && len(a.approvals) == 0
+}
+
+// leaverAssignments は退職者 id が上長の在籍者と、承認者の承認待ちの申請を返す。
+func (uc ManageEmployees) leaverAssignments(id string) (leaverAssignments, error) {
+	var a leaverAssignments
+	emps, err := uc.EmployeeList.List()
+	if err != nil {
+		return a, err
+	}
+	for _, sub := range emps {
+		if sub.ManagerID == id && sub.ID != id && sub.IsActive() {
+			a.subordinates = append(a.subordinates, sub)
+		}
+	}
+	pending, err := uc.EmployeeLeaves.ListPending()
+	if err != nil {
+		return a, err
+	}
+	for _, req := range pending {
+		if req.ApproverID == id && req.EmployeeID != id {
+			a.approvals = append(a.approvals, req)
+		}
+	}
+	return a, nil
+}
+
+// reassign は部下の上長と承認待ちの申請の承認者を、退職者 leaver の在籍中の上長へ付け替え、新しい承認者に知らせる。
+// 回す先がいなければ（退職者が最上位）、部下は上長なしとし、申請は UnassignedRequests として人事に任せる。
+func (uc ManageEmployees) reassign(out *DeactivateOutput, actorID string, leaver domain.Employee, a leaverAssignments, now time.Time) error {
+	successor, err := activeApprover(uc.EmployeesRepo, leaver.ManagerID)
+	if err != nil {
+		return err
+	}
+	for _, sub := range a.subordinates {
+		sub.ManagerID = successor
+		if err := uc.writeEmployee(&sub, uc.EmployeeWriter.Update); err != nil {
+			return err
+		}
+		out.ReassignedEmployees = append(out.ReassignedEmployees, sub.ID)
+	}
+	var notifyErrs []error
+	for i := range a.approvals {
+		req := &a.approvals[i]
+		if successor == "" {
+			out.UnassignedRequests = append(out.UnassignedRequests, req.ID)
+			continue
+		}
+		req.ApproverID = successor
+		req.AssignedAt = now
+		req.RemindedAt = time.Time{}
+		if err := uc.EmployeeLeaves.Update(req, domain.StatusPending); err != nil {
+			if errors.Is(err, ErrLeaveRequestChanged) {
+				continue // 取得後に他の処理で決定済みになったもの
+			}
+			return err
+		}
+		out.ReassignedRequests = append(out.ReassignedRequests, req.ID)
+		publish(uc.Events, leaveEvent(EventLeaveEscalated, actorID, *req, now))
+		if uc.Mailer == nil {
+			continue
+		}
+		n, err := newNotification(uc.EmployeesRepo, uc.Links, *req, successor)
+		if err == nil {
+			err = uc.Mailer.NotifyEscalation(n)
+		}
+		if err != nil {
+			notifyErrs = append(notifyErrs, err)
+		}
+	}
+	out.NotifyErr = errors.Join(notifyErrs...)
+	return nil
+}
+
+// writeEmployee は従業員を保存し、キャッシュに残っている古い情報を捨てる。
+// 保存できたか分からない失敗（タイムアウトなど）もあるので、失敗したときも捨てる。
+func (uc ManageEmployees) writeEmployee(e *domain.Employee, write func(*domain.Employee) error) error {
+	err := write(e)
+	if uc.Cache != nil {
+		uc.Cache.InvalidateEmployee(e.ID)
+	}
+	return err
+}
+
+// validate は従業員情報をドメインルールで検証する。
+// 上長が指定されていれば、最上位までたどって実在・在籍・循環がないことを確認する。
+func (uc ManageEmployees) validate(e domain.Employee) error {
+	if err := domain.ValidateEmployee(e, uc.Clock.Now()); err != nil {
+		return &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+	var chain []domain.Employee
+	for id := e.ManagerID; id != "" && id != e.ID && len(chain) < maxManagerDepth; {
+		m, err := uc.EmployeesRepo.FindByID(id)
+		if errors.Is(err, ErrNotFound) {
+			return &Error{Kind: ErrValidation, Msg: "manager not found: " + id, Err: err}
+		}
+		if err != nil {
+			return err
+		}
+		chain = append(chain, m)
+		id = m.ManagerID
+		if id == e.ID {
+			chain = append(chain, e)
+		}
+	}
+	if err := domain.ValidateManagerChain(e, chain); err != nil {
+		return &Error{Kind: ErrValidation, Msg: err.Error(), Err: err}
+	}
+	return nil
+}
+
+// applyEmployeePatch は変更内容を従業員情報に反映する。
+func applyEmployeePatch(e *domain.Employee, p EmployeePatch) {
+	if p.Name != nil {
+		e.Name = *p.Name
+	}
+	if p.Email != nil {
+		e.Email = *p.Email
+	}
+	if p.HireDate != nil {
+		e.HireDate = *p.HireDate
+	}
+	if p.ManagerID != nil {
+		e.ManagerID = *p.ManagerID
+	}
+	if p.Department != nil {
+		e.Department = *p.Department
+	}
+	if p.Role != nil {
+		e.Role = *p.Role
+	}
+	if p.Locale != nil {
+		e.Locale = *p.Locale
+	}
+}
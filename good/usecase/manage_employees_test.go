@@ -0,0 +1,200 @@
+package usecase_test
+
+// ManageEmployees.Deactivate のテスト（メモリの保存先を使う）
+// --------------------------------------------------------
+// - 退職状態を保存した後に承認待ちの申請を取り消すので、その間に届いた申請も残らない
+// - 部下と、退職者が承認者の承認待ちの申請は、退職者の在籍中の上長へ回す
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// hookedWriter：従業員を保存する直前に before を1回だけ呼ぶ EmployeeWriter
+type hookedWriter struct {
+	usecase.EmployeeWriter
+	before func()
+}
+
+func (w *hookedWriter) Update(e *domain.Employee) error {
+	if f := w.before; f != nil {
+		w.before = nil
+		f()
+	}
+	return w.EmployeeWriter.Update(e)
+}
+
+func newEmployeeAdmin(st *drivers.MemoryStore) usecase.ManageEmployees {
+	return usecase.ManageEmployees{
+		EmployeesRepo:  st.Employees(),
+		EmployeeList:   st.Employees(),
+		EmployeeWriter: st.Employees(),
+		LeavesRepo:     st.Leaves(),
+		EmployeeLeaves: st.Leaves(),
+		Clock:          fixedClock(importNow),
+		YearStart:      domain.FiscalYearStart,
+	}
+}
+
+func seedTeam(st *drivers.MemoryStore, emps ...domain.Employee) {
+	st.Seed(append([]domain.Employee{
+		{ID: "hr", Name: "HR", HireDate: day(2015, 4, 1), Role: domain.RoleHR},
+		{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1)},
+		{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"},
+	}, emps...)...)
+}
+
+func newSubmitter(st *drivers.MemoryStore) usecase.SubmitLeave {
+	return usecase.SubmitLeave{
+		EmployeesRepo: st.Employees(),
+		LeavesRepo:    st.Leaves(),
+		Mailer:        &recordingMailer{},
+		Clock:         fixedClock(importNow),
+		YearStart:     domain.FiscalYearStart,
+	}
+}
+
+func TestDeactivateCancelsRequestSubmittedDuringDeactivation(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	seedTeam(st)
+	submit := newSubmitter(st)
+	before, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	// 退職状態を保存する直前に、まだ在籍中に見える申請が届く
+	uc := newEmployeeAdmin(st)
+	var during usecase.SubmitOutput
+	uc.EmployeeWriter = &hookedWriter{EmployeeWriter: st.Employees(), before: func() {
+		during, err = submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 7, 1), To: day(2025, 7, 1)})
+		if err != nil {
+			t.Errorf("Submit during deactivation: %v", err)
+		}
+	}}
+	out, err := uc.Deactivate("hr", "alice", day(2025, 5, 31))
+	if err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+
+	if pending, _ := st.Leaves().ListPendingByEmployee("alice"); len(pending) != 0 {
+		t.Errorf("pending requests left after deactivation: %+v", pending)
+	}
+	if len(out.CancelledRequests) != 2 || out.CancelledRequests[0] != before.ID || out.CancelledRequests[1] != during.ID {
+		t.Errorf("CancelledRequests = %v, want [%s %s]", out.CancelledRequests, before.ID, during.ID)
+	}
+	// 取り消した申請は数えないので、残り申請回数は全部残る
+	e, _ := st.Employees().FindByID("alice")
+	if e.IsActive() || e.FrozenQuota != domain.MaxRequestsPerFiscalYear || out.Employee.FrozenQuota != e.FrozenQuota {
+		t.Errorf("saved %+v (returned FrozenQuota %d), want left with FrozenQuota %d", e, out.Employee.FrozenQuota, domain.MaxRequestsPerFiscalYear)
+	}
+
+	// 退職後の申請は受け付けない
+	if _, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 8, 1), To: day(2025, 8, 1)}); !errors.Is(err, usecase.ErrNotEligible) {
+		t.Errorf("Submit after deactivation: err = %v, want ErrNotEligible", err)
+	}
+	// もう一度退職処理をしても、取り消すものがなければ退職済みとして断る
+	if _, err := uc.Deactivate("hr", "alice", day(2025, 5, 31)); !errors.Is(err, usecase.ErrConflict) {
+		t.Errorf("second Deactivate: err = %v, want ErrConflict", err)
+	}
+}
+
+func TestDeactivateHandsOverSubordinatesAndApprovals(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	// big ← boss ← alice, carol（boss が退職する）
+	st.Seed(domain.Employee{ID: "big", Name: "Big", HireDate: day(2010, 4, 1)})
+	seedTeam(st, domain.Employee{ID: "carol", Name: "Carol", HireDate: day(2020, 4, 1), ManagerID: "boss"})
+	if err := st.Employees().Update(&domain.Employee{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1), ManagerID: "big"}); err != nil {
+		t.Fatal(err)
+	}
+	submit := newSubmitter(st)
+	req, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	mailer := &recordingMailer{}
+	uc := newEmployeeAdmin(st)
+	uc.Mailer = mailer
+	out, err := uc.Deactivate("hr", "boss", day(2025, 5, 31))
+	if err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	if got := out.ReassignedEmployees; len(got) != 2 || got[0] != "alice" || got[1] != "carol" {
+		t.Errorf("ReassignedEmployees = %v, want [alice carol]", got)
+	}
+	for _, id := range []string{"alice", "carol"} {
+		if e, _ := st.Employees().FindByID(id); e.ManagerID != "big" {
+			t.Errorf("%s.ManagerID = %q, want big", id, e.ManagerID)
+		}
+	}
+	// 承認待ちの申請は big に回し、回ってきた日時から滞留を数え直す
+	if len(out.ReassignedRequests) != 1 || out.ReassignedRequests[0] != req.ID || out.NotifyErr != nil {
+		t.Errorf("ReassignedRequests = %v, NotifyErr = %v, want [%s]", out.ReassignedRequests, out.NotifyErr, req.ID)
+	}
+	got, _ := st.Leaves().FindByID(req.ID)
+	if got.ApproverID != "big" || !got.AssignedAt.Equal(importNow) || got.Decide("big", domain.DecisionApprove, "", importNow) != nil {
+		t.Errorf("request = %+v, want it assigned to big and decidable by big", got)
+	}
+	if want := (sentMail{Kind: "escalation", Recipient: "big", RequestID: req.ID}); len(mailer.Sent) != 1 || mailer.Sent[0] != want {
+		t.Errorf("sent %+v, want %+v", mailer.Sent, want)
+	}
+
+	// 新しい申請も big に回る
+	next, err := submit.Submit(usecase.SubmitInput{EmployeeID: "carol", Type: domain.LeavePaid, From: day(2025, 7, 1), To: day(2025, 7, 1)})
+	if err != nil {
+		t.Fatalf("Submit after deactivation: %v", err)
+	}
+	if r, _ := st.Leaves().FindByID(next.ID); r.ApproverID != "big" {
+		t.Errorf("ApproverID = %q, want big", r.ApproverID)
+	}
+}
+
+func TestDeactivateTopManagerLeavesApprovalsToHR(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	seedTeam(st)
+	req, err := newSubmitter(st).Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+
+	out, err := newEmployeeAdmin(st).Deactivate("hr", "boss", day(2025, 5, 31))
+	if err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	if len(out.UnassignedRequests) != 1 || out.UnassignedRequests[0] != req.ID || len(out.ReassignedRequests) != 0 {
+		t.Errorf("Unassigned = %v, Reassigned = %v, want [%s] left to HR", out.UnassignedRequests, out.ReassignedRequests, req.ID)
+	}
+	if e, _ := st.Employees().FindByID("alice"); e.ManagerID != "" {
+		t.Errorf("alice.ManagerID = %q, want none", e.ManagerID)
+	}
+}
+
+func TestSubmitSkipsManagersWhoHaveLeft(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	st.Seed(
+		domain.Employee{ID: "big", Name: "Big", HireDate: day(2010, 4, 1)},
+		domain.Employee{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1), ManagerID: "big", LeftOn: day(2025, 4, 1)},
+		domain.Employee{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"},
+		domain.Employee{ID: "solo", Name: "Solo", HireDate: day(2015, 4, 1), LeftOn: day(2025, 4, 1)},
+		domain.Employee{ID: "dave", Name: "Dave", HireDate: day(2020, 4, 1), ManagerID: "solo"},
+	)
+	submit := newSubmitter(st)
+
+	out, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)})
+	if err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	if r, _ := st.Leaves().FindByID(out.ID); r.ApproverID != "big" {
+		t.Errorf("ApproverID = %q, want big (boss has left)", r.ApproverID)
+	}
+	// たどっても在籍中の上長がいなければ、誰も判断できないので受け付けない
+	if _, err := submit.Submit(usecase.SubmitInput{EmployeeID: "dave", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)}); !errors.Is(err, usecase.ErrConflict) {
+		t.Errorf("Submit with no active manager: err = %v, want ErrConflict", err)
+	}
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import "github.com/ohagi/clean-architecture-examples/good/domain"
+
+// newNotification は申請者・宛先を取得して通知の内容を組み立てる。
+func newNotification(emps EmployeeRepo, links LinkBuilder, req domain.LeaveRequest, recipientID string) (Notification, error) {
+	requester, err := emps.FindByID(req.EmployeeID)
+	if err != nil {
+		return Notification{}, err
+	}
+	recipient, err := emps.FindByID(recipientID)
+	if err != nil {
+		return Notification{}, err
+	}
+	n := Notification{Recipient: recipient, Requester: requester, Request: req}
+	if links != nil {
+		n.Link = links.LeaveRequestURL(req.ID)
+	}
+	return n, nil
+}
@@ -11,6 +11,7 @@
 package usecase
 
 import (
+	"io"
 	"time"
 
 	"github.com/ohagi/clean-architecture-examples/good/domain"
@@ -25,15 +26,135 @@ import (
 // --------------------------------------------------------
 type Clock interface{ Now() time.Time }
 
+// EmployeeRepo：従業員を1件取得するリポジトリ
+// 見つからなければ ErrEmployeeNotFound を返す（errors.Is(err, ErrNotFound) も成り立つ）。
 type EmployeeRepo interface {
 	FindByID(id string) (domain.Employee, error)
 }
 
-type LeaveRepo interface {
+// LeaveCounter：年度内の申請回数を数えるリポジトリ（読み取りのみ）
+// CountThisFiscalYear は fiscalYearStart から1年間に作成された申請のうち、
+// 年度内の申請回数に数えるもの（domain.LeaveStatus.CountsTowardQuota）の件数を返す。
+type LeaveCounter interface {
 	CountThisFiscalYear(employeeID string, fiscalYearStart time.Time) (int, error)
+}
+
+// LeaveRepo：休暇申請を登録するリポジトリ
+type LeaveRepo interface {
+	LeaveCounter
 	Create(req *domain.LeaveRequest) error
 }
 
+// PendingLeaveRepo：承認待ちの申請を扱うリポジトリ（催促ジョブ用）
+type PendingLeaveRepo interface {
+	ListPending() ([]domain.LeaveRequest, error)
+	LeaveUpdater
+}
+
+// LeaveUpdater：休暇申請の状態を更新するリポジトリ
+// Update は保存されているステータスが expected のときだけ、申請の状態（ステータス・承認者・催促日時・決定日時・
+// 判断コメント）を更新する。読んでから更新するまでに他の操作（判断・取消など）でステータスが変わっていれば、
+// 何も変更せずに ErrLeaveRequestChanged を返す（errors.Is(err, ErrConflict) も成り立つ）。
+type LeaveUpdater interface {
+	Update(req *domain.LeaveRequest, expected domain.LeaveStatus) error
+}
+
+// Mailer：通知を送る。宛先は Notification.Recipient
 type Mailer interface {
-	NotifyManagerNewRequest(requestID string) error
+	NotifyManagerNewRequest(n Notification) error
+	NotifyApproverReminder(n Notification) error  // 承認待ちの催促
+	NotifyEscalation(n Notification) error        // 次の承認者へ回ったことの通知
+	NotifyRequesterDecision(n Notification) error // 申請者への判断結果（承認・却下・差し戻し）の通知
+	NotifyTeamAbsence(n Notification) error       // チームのメンバーへの休暇（承認済み）の通知
+}
+
+// Notification：通知の内容（宛先・申請・申請者と、申請を開くためのリンク）
+// 文面や言語（Recipient.PreferredLocale）の扱いは Mailer の実装に任せる。
+type Notification struct {
+	Recipient domain.Employee
+	Requester domain.Employee
+	Request   domain.LeaveRequest
+	Approver  domain.Employee // 判断した承認者（判断結果・休暇の通知のときだけ）
+	Link      string          // 申請の詳細画面のURL（LinkBuilder がなければ空）
+}
+
+// LinkBuilder：通知に載せるリンクを作る
+type LinkBuilder interface {
+	LeaveRequestURL(requestID string) string
+}
+
+// LeaveBatchCreator：休暇申請をまとめて保存できるリポジトリ（任意）
+// LeaveRepo の実装がこれも満たしていれば、一括取込でまとめて書き込む。
+// 1件でも失敗したらまとめて取り消す（全件成功か全件失敗）ことを期待する。
+type LeaveBatchCreator interface {
+	CreateBatch(reqs []*domain.LeaveRequest) error
+}
+
+// LeaveFinder：休暇申請を1件取得するリポジトリ
+// 見つからなければ ErrLeaveRequestNotFound を返す。
+type LeaveFinder interface {
+	FindByID(id string) (domain.LeaveRequest, error)
+}
+
+// LeaveLister：従業員ごとの休暇申請の一覧を取得するリポジトリ
+// ステータスを問わず、作成日時の古い順（同じ日時は登録順）に返す。
+type LeaveLister interface {
+	ListByEmployee(employeeID string) ([]domain.LeaveRequest, error)
+}
+
+// AttachmentRepo：添付ファイルの情報（中身以外）を扱うリポジトリ
+type AttachmentRepo interface {
+	Create(a *domain.Attachment) error
+	FindByID(id string) (domain.Attachment, error)
+	ListByRequest(requestID string) ([]domain.Attachment, error)
+}
+
+// BlobStore：ファイルの中身を保存する場所（ファイルシステム・クラウドストレージ等）
+type BlobStore interface {
+	Put(r io.Reader) (key string, err error)
+	Get(key string) (io.ReadCloser, error)
+	Delete(key string) error
+}
+
+// EmployeeLister：従業員の一覧を取得するリポジトリ
+type EmployeeLister interface {
+	List() ([]domain.Employee, error)
+}
+
+// LeavePeriodRepo：期間に重なる休暇申請を取得するリポジトリ（集計用）
+type LeavePeriodRepo interface {
+	ListOverlapping(from, to time.Time) ([]domain.LeaveRequest, error)
+}
+
+// EmployeeWriter：従業員情報を登録・更新するリポジトリ
+type EmployeeWriter interface {
+	Create(e *domain.Employee) error
+	Update(e *domain.Employee) error
+}
+
+// EmployeeLeavesRepo：承認待ち申請を扱うリポジトリ（退職処理用）
+// 退職者の申請の取消と、退職者が承認者になっている申請の付け替えに使う。
+type EmployeeLeavesRepo interface {
+	ListPendingByEmployee(employeeID string) ([]domain.LeaveRequest, error)
+	ListPending() ([]domain.LeaveRequest, error)
+	LeaveUpdater
+}
+
+// EmployeeCacheInvalidator：従業員情報のキャッシュから古い情報を捨てる
+// 従業員情報を変更するユースケースは、保存した後に InvalidateEmployee を呼ぶ。
+type EmployeeCacheInvalidator interface {
+	InvalidateEmployee(id string)
+}
+
+// DecisionLeaveRepo：承認者の判断を記録するリポジトリ
+type DecisionLeaveRepo interface {
+	FindByID(id string) (domain.LeaveRequest, error)
+	LeaveUpdater
+}
+
+// FeedTokens：カレンダーの購読 URL に載せる秘密のトークンを発行・検証する
+// feed はフィードを区別するキー（"employee:<ID>" など）。同じ feed には常に同じトークンを返す。
+type FeedTokens interface {
+	Token(feed string) string
+	Verify(feed, token string) bool
 }
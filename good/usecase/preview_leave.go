@@ -0,0 +1,57 @@
+package usecase
+
+// 休暇申請の事前確認ユースケース
+// --------------------------------------------------------
+// 「この日程で申請したら通るか？」を、実際に申請する前に確認する。
+// - SubmitLeave.Submit と同じデータを読み、同じルールで判定する
+// - 保存（LeaveRepo.Create）や通知（Mailer）は一切行わない（読み取り系のポートしか持たない）
+// --------------------------------------------------------
+
+import (
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// PreviewLeave：休暇申請の事前確認ユースケースの実行構造体
+// 書き込み系のポート（LeaveRepo.Create・Mailer 等）はあえて持たせず、副作用が起きないことを型で保証する。
+type PreviewLeave struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    LeaveCounter // 読み取りのみ（Create を持たない）
+	Clock         Clock
+	YearStart     func(now time.Time) time.Time // 会計年度開始日の計算
+}
+
+// PreviewOutput：事前確認の結果
+type PreviewOutput struct {
+	Eligible       bool                      // 申請可能か
+	Reasons        []domain.IneligibleReason // 申請できない理由
+	RemainingQuota int                       // 年度内の残り申請回数（この申請を含まない）
+	WorkingDays    float64                   // この申請で消費する平日の日数（半日休は 0.5 日）
+	Warnings       []domain.Warning          // 申請はできるが注意が必要な点
+}
+
+// Preview：休暇申請の事前確認
+// --------------------------------------------------------
+// 処理フロー：
+// 1〜4. 申請可否の判定（SubmitLeave と共通。eligibility.go を参照）
+// 5. 残り回数・消費日数・注意点の算出
+// --------------------------------------------------------
+func (uc PreviewLeave) Preview(in SubmitInput) (PreviewOutput, error) {
+	now := uc.Clock.Now()
+
+	// 1〜4. 申請可否の判定
+	a, err := assessLeave(uc.EmployeesRepo, uc.LeavesRepo, uc.YearStart, in, now)
+	if err != nil {
+		return PreviewOutput{}, err
+	}
+
+	// 5. 残り回数・消費日数・注意点の算出
+	return PreviewOutput{
+		Eligible:       len(a.Reasons) == 0,
+		Reasons:        a.Reasons,
+		RemainingQuota: domain.RemainingQuota(a.SubmittedCount),
+		WorkingDays:    domain.LeaveDays(in.From, in.To, in.DayPart),
+		Warnings:       domain.Warnings(in.Type, in.From, in.To, a.SubmittedCount, now),
+	}, nil
+}
@@ -0,0 +1,106 @@
+package usecase
+
+// 記録できなかった変更の補完（起動時に実行する）
+// --------------------------------------------------------
+// イベントは申請を保存した後に記録するので、保存の直後にプロセスが落ちた・記録に失敗した変更は
+// イベントログに残らない。保存されている申請を正本として、ログと食い違う申請を leave.recovered として記録し直す。
+// - ログにある最後のイベントの申請と、保存されている申請の状態（ステータス・承認者・各日時・判断コメント）を比べる
+// - ログにない・状態が違う申請だけを、保存されている今の状態で記録する（途中の変更は1件にまとまる）
+// これで保存した変更は、遅くとも次の起動時にはログに記録される（記録は at-least-once）。
+// --------------------------------------------------------
+// ログを読んでから申請を読むので、その間に保存された変更は二重に記録されることはあっても、取りこぼさない。
+// ただし記録し直すまでに別のプロセスが同じ申請を変更すると、古い状態が後に記録されうるので、
+// 申請を受け付け始める前（サーバの起動時）に実行する。
+// --------------------------------------------------------
+
+import (
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// recoverReadBatch：ログを読むときの1回の件数
+const recoverReadBatch = 1000
+
+// RecoverEvents：記録できなかった変更の補完の実行構造体
+type RecoverEvents struct {
+	EmployeeList EmployeeLister // 申請を従業員ごとに読むための一覧
+	LeavesRepo   LeaveLister
+	Log          EventFeed      // 記録済みのイベント
+	Events       EventPublisher // 補ったイベントの記録先（Log に記録されるもの）
+	Clock        Clock
+}
+
+// RecoverOutput：補完の結果
+type RecoverOutput struct {
+	Checked   int      // 比べた申請の数
+	Recovered []string // leave.recovered を記録した申請ID
+}
+
+// Run：記録できなかった変更の補完
+// --------------------------------------------------------
+// 処理フロー：
+// 1. ログを先頭から読み、申請ごとに最後に記録された状態を集める
+// 2. 従業員ごとに保存されている申請を読み、ログと状態が違うものを集める
+// 3. 集めた申請を leave.recovered としてまとめて記録する（失敗したらエラーを返し、次の起動でやり直す）
+// --------------------------------------------------------
+func (uc RecoverEvents) Run() (RecoverOutput, error) {
+	var out RecoverOutput
+
+	// 1. 申請ごとに最後に記録された状態を集める
+	logged := map[string]domain.LeaveRequest{}
+	for from := int64(1); ; {
+		events, err := uc.Log.Read(from, recoverReadBatch)
+		if err != nil {
+			return out, err
+		}
+		if len(events) == 0 {
+			break
+		}
+		for _, e := range events {
+			logged[e.Request.ID] = e.Request
+		}
+		from = events[len(events)-1].Offset + 1
+	}
+
+	// 2. 保存されている申請のうち、ログと状態が違うものを集める
+	emps, err := uc.EmployeeList.List()
+	if err != nil {
+		return out, err
+	}
+	now := uc.Clock.Now()
+	var missing []Event
+	for _, emp := range emps {
+		reqs, err := uc.LeavesRepo.ListByEmployee(emp.ID)
+		if err != nil {
+			return out, err
+		}
+		for _, req := range reqs {
+			out.Checked++
+			if last, ok := logged[req.ID]; ok && sameLeaveState(last, req) {
+				continue
+			}
+			missing = append(missing, leaveEvent(EventLeaveRecovered, "", req, now))
+		}
+	}
+
+	// 3. まとめて記録する
+	if len(missing) == 0 {
+		return out, nil
+	}
+	if err := uc.Events.Publish(missing...); err != nil {
+		return out, err
+	}
+	for _, e := range missing {
+		out.Recovered = append(out.Recovered, e.Request.ID)
+	}
+	return out, nil
+}
+
+// sameLeaveState は2つの申請の、変更されうる状態が同じかを返す。
+// 日時は保存先によって精度が違う（秒までしか持たない DB もある）ので、秒単位で比べる。
+func sameLeaveState(a, b domain.LeaveRequest) bool {
+	sameTime := func(x, y time.Time) bool { return x.Truncate(time.Second).Equal(y.Truncate(time.Second)) }
+	return a.Status == b.Status && a.ApproverID == b.ApproverID && a.DecisionComment == b.DecisionComment &&
+		sameTime(a.AssignedAt, b.AssignedAt) && sameTime(a.RemindedAt, b.RemindedAt) && sameTime(a.DecidedAt, b.DecidedAt)
+}
@@ -0,0 +1,74 @@
+package usecase_test
+
+// RecoverEvents のテスト（メモリの保存先とファイルのイベントログを使う）
+// --------------------------------------------------------
+// 保存した後に記録できなかった変更を、保存されている申請から leave.recovered として記録し直す。
+// --------------------------------------------------------
+
+import (
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+func TestRecoverEventsRecordsChangesMissingFromTheLog(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	seedTeam(st)
+	log, err := drivers.OpenEventLog(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer log.Close()
+	bus := drivers.NewEventBus(log, nil)
+
+	// 1. 記録できた申請・判断の記録だけが抜けた申請・まったく記録されていない申請
+	submit := newSubmitter(st)
+	submit.Events = bus
+	if _, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2)}); err != nil {
+		t.Fatal(err)
+	}
+	decided, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 7, 1), To: day(2025, 7, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	decide := usecase.DecideLeave{EmployeesRepo: st.Employees(), LeavesRepo: st.Leaves(), Mailer: &recordingMailer{}, Clock: fixedClock(importNow)}
+	if _, err := decide.Decide(usecase.DecideInput{ActorID: "boss", RequestID: decided.ID, Decision: domain.DecisionApprove}); err != nil {
+		t.Fatal(err)
+	}
+	submit.Events = nil
+	unlogged, err := submit.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 8, 1), To: day(2025, 8, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2. ログと食い違う申請だけを、今の状態で記録する
+	uc := usecase.RecoverEvents{EmployeeList: st.Employees(), LeavesRepo: st.Leaves(), Log: log, Events: bus, Clock: fixedClock(importNow)}
+	out, err := uc.Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if out.Checked != 3 || len(out.Recovered) != 2 || out.Recovered[0] != decided.ID || out.Recovered[1] != unlogged.ID {
+		t.Errorf("out = %+v, want %s and %s recovered out of 3", out, decided.ID, unlogged.ID)
+	}
+	events, err := log.Read(1, 10)
+	if err != nil || len(events) != 4 {
+		t.Fatalf("Read = %d events, %v, want 4", len(events), err)
+	}
+	if e := events[2]; e.Type != usecase.EventLeaveRecovered || e.Request.ID != decided.ID || e.Request.Status != domain.StatusApproved {
+		t.Errorf("events[2] = %s %s %s, want %s recovered as APPROVED", e.Type, e.Request.ID, e.Request.Status, decided.ID)
+	}
+	if e := events[3]; e.Type != usecase.EventLeaveRecovered || e.Request.ID != unlogged.ID || e.Request.Status != domain.StatusPending {
+		t.Errorf("events[3] = %s %s %s, want %s recovered as PENDING", e.Type, e.Request.ID, e.Request.Status, unlogged.ID)
+	}
+
+	// 3. もう一度実行しても、食い違いがなければ何も記録しない
+	out, err = uc.Run()
+	if err != nil || len(out.Recovered) != 0 {
+		t.Errorf("second Run = %+v, %v, want nothing recovered", out, err)
+	}
+	if log.LastOffset() != 4 {
+		t.Errorf("LastOffset = %d, want 4", log.LastOffset())
+	}
+}
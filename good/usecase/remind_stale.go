@@ -0,0 +1,202 @@
+package usecase
+
+// 滞留している申請の催促・エスカレーションユースケース（定期実行ジョブ）
+// --------------------------------------------------------
+// 承認待ちのまま放置された申請を探し、ポリシーに従って
+// - 現在の承認者へ催促する
+// - 次の承認者（承認者の上長）へ回す
+// - 開始日の前日になったら自動承認する
+// - 開始日を過ぎても承認待ちなら期限切れとして取り消す
+// のいずれかを行う。自動承認・期限切れにした申請は、申請者に結果を知らせる。どれを行うかの判断は domain.StalePolicy に任せる。
+// --------------------------------------------------------
+// 1件の失敗で他の申請の処理を止めないよう、失敗は記録して続行する。
+// 一覧を取得してから保存するまでに判断・取消された申請（ErrLeaveRequestChanged）は、
+// 上書きせずに見送り（Skipped）として扱う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+// errNoApprover：承認者が設定されていない（上長のいない従業員の申請など）
+var errNoApprover = &Error{Kind: ErrConflict, Msg: "request has no approver"}
+
+// RemindStaleRequests：催促ジョブの実行構造体
+type RemindStaleRequests struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    PendingLeaveRepo
+	Mailer        Mailer
+	Links         LinkBuilder    // 通知に載せるリンク（nil ならリンクなし）
+	Events        EventPublisher // 変更の発行先（nil なら発行しない）
+	Clock         Clock
+	Policy        domain.StalePolicy
+}
+
+// RequestError：申請1件分のエラー
+type RequestError struct {
+	RequestID string
+	Err       error
+}
+
+// RemindOutput：催促ジョブの結果（処理した申請ID）
+type RemindOutput struct {
+	Reminded     []string // エスカレーションの時期だが回す先がなく、同じ承認者に催促した申請も含む
+	Escalated    []string
+	AutoApproved []string
+	Expired      []string
+	Skipped      []string // 処理中に他の操作でステータスが変わったため見送った申請
+	Failed       []RequestError
+}
+
+// Run：催促ジョブの実行
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 承認待ちの申請を取得
+// 2. 申請ごとにポリシーで処理を決定
+// 3. 催促・エスカレーション・自動承認・期限切れのいずれかを実行し、保存した変更を発行
+// --------------------------------------------------------
+func (uc RemindStaleRequests) Run() (RemindOutput, error) {
+	now := uc.Clock.Now()
+	var out RemindOutput
+
+	// 1. 承認待ちの申請を取得
+	reqs, err := uc.LeavesRepo.ListPending()
+	if err != nil {
+		return out, err
+	}
+
+	for i := range reqs {
+		req := &reqs[i]
+		// 2. 申請ごとにポリシーで処理を決定
+		// 3. 決定した処理を実行
+		switch uc.Policy.Next(*req, now) {
+		case domain.StaleRemind:
+			err = uc.remind(req)
+			out.Reminded = appendIfOK(out.Reminded, req.ID, err)
+		case domain.StaleEscalate:
+			var escalated bool
+			escalated, err = uc.escalate(req)
+			if escalated {
+				out.Escalated = appendIfOK(out.Escalated, req.ID, err)
+			} else {
+				out.Reminded = appendIfOK(out.Reminded, req.ID, err)
+			}
+		case domain.StaleAutoApprove:
+			err = uc.autoApprove(req, now)
+			out.AutoApproved = appendIfOK(out.AutoApproved, req.ID, err)
+		case domain.StaleExpire:
+			err = uc.expire(req, now)
+			out.Expired = appendIfOK(out.Expired, req.ID, err)
+		default:
+			continue
+		}
+		switch {
+		case errors.Is(err, ErrLeaveRequestChanged):
+			out.Skipped = append(out.Skipped, req.ID)
+		case err != nil:
+			out.Failed = append(out.Failed, RequestError{RequestID: req.ID, Err: err})
+		}
+	}
+	return out, nil
+}
+
+// remind は現在の承認者へ催促し、催促日時を記録する。
+// 通知してから記録するため、記録に失敗した場合は次回もう一度催促される（取りこぼすよりよい）。
+// 通知の直前に判断・取消された申請は、催促だけ届いて記録は見送られる。
+func (uc RemindStaleRequests) remind(req *domain.LeaveRequest) error {
+	if req.ApproverID == "" {
+		return errNoApprover
+	}
+	n, err := newNotification(uc.EmployeesRepo, uc.Links, *req, req.ApproverID)
+	if err != nil {
+		return err
+	}
+	if err := uc.Mailer.NotifyApproverReminder(n); err != nil {
+		return err
+	}
+	req.RemindedAt = uc.Clock.Now()
+	if err := uc.LeavesRepo.Update(req, domain.StatusPending); err != nil {
+		return err
+	}
+	publish(uc.Events, leaveEvent(EventLeaveReminded, "", *req, req.RemindedAt))
+	return nil
+}
+
+// escalate は申請を現在の承認者の在籍中の上長へ回し、新しい承認者へ通知する（回したかどうかを返す）。
+// 上長がいない（最上位の承認者）場合は、回す先がないので同じ承認者に回し直して催促する。
+// remind と同じく通知してから保存するため、通知に失敗した申請は回さずに残し、次回もう一度回して通知する
+// （保存してから通知すると、通知に失敗しても回ってきた日時が新しくなり、新しい承認者が知らないまま滞留する）。
+func (uc RemindStaleRequests) escalate(req *domain.LeaveRequest) (bool, error) {
+	if req.ApproverID == "" {
+		return false, errNoApprover
+	}
+	approver, err := uc.EmployeesRepo.FindByID(req.ApproverID)
+	if err != nil {
+		return false, err
+	}
+	next, err := activeApprover(uc.EmployeesRepo, approver.ManagerID)
+	if err != nil {
+		return false, err
+	}
+	if next == "" {
+		req.AssignedAt = uc.Clock.Now()
+		return false, uc.remind(req)
+	}
+	now := uc.Clock.Now()
+	req.ApproverID = next
+	req.AssignedAt = now
+	req.RemindedAt = now
+	n, err := newNotification(uc.EmployeesRepo, uc.Links, *req, req.ApproverID)
+	if err != nil {
+		return true, err
+	}
+	if err := uc.Mailer.NotifyEscalation(n); err != nil {
+		return true, err
+	}
+	if err := uc.LeavesRepo.Update(req, domain.StatusPending); err != nil {
+		return true, err
+	}
+	publish(uc.Events, leaveEvent(EventLeaveEscalated, "", *req, now))
+	return true, nil
+}
+
+// autoApprove は開始日が迫った申請を承認し、申請者に知らせる（承認者はいないので ActorID は空）。
+// 承認してよいかの判断は domain.StalePolicy.AutoApprove に任せる。
+func (uc RemindStaleRequests) autoApprove(req *domain.LeaveRequest, now time.Time) error {
+	if err := uc.Policy.AutoApprove(req, now); err != nil {
+		return decisionError(err)
+	}
+	return uc.closeAndNotify(req, EventLeaveDecided, now)
+}
+
+// expire は開始日を過ぎても承認待ちの申請を期限切れとして取り消し、申請者に知らせる。
+func (uc RemindStaleRequests) expire(req *domain.LeaveRequest, now time.Time) error {
+	if err := req.Cancel(now); err != nil {
+		return decisionError(err)
+	}
+	return uc.closeAndNotify(req, EventLeaveExpired, now)
+}
+
+// closeAndNotify は判断の済んだ申請を保存して発行し、申請者に結果を知らせる。
+func (uc RemindStaleRequests) closeAndNotify(req *domain.LeaveRequest, t EventType, now time.Time) error {
+	if err := uc.LeavesRepo.Update(req, domain.StatusPending); err != nil {
+		return err
+	}
+	publish(uc.Events, leaveEvent(t, "", *req, now))
+	n, err := newNotification(uc.EmployeesRepo, uc.Links, *req, req.EmployeeID)
+	if err != nil {
+		return err
+	}
+	return uc.Mailer.NotifyRequesterDecision(n)
+}
+
+// appendIfOK は処理が成功したときだけ申請IDを追加する。
+func appendIfOK(ids []string, id string, err error) []string {
+	if err != nil {
+		return ids
+	}
+	return append(ids, id)
+}
@@ -0,0 +1,101 @@
+package usecase_test
+
+// RemindStaleRequests のテスト（メモリの保存先を使う）
+// --------------------------------------------------------
+// - 回す先のない承認者への「エスカレーション」は催促として数える
+// - 新しい承認者へ通知できなかった申請は回さずに残し、次回もう一度回して通知する
+// --------------------------------------------------------
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+// staleNow：催促ジョブを実行する日時（申請は6営業日前に回ってきている）
+var staleNow = time.Date(2025, 5, 12, 9, 0, 0, 0, time.UTC)
+
+func newStaleStore(t *testing.T, emps ...domain.Employee) (*drivers.MemoryStore, string) {
+	t.Helper()
+	st := drivers.NewMemoryStore()
+	st.Seed(emps...)
+	assigned := day(2025, 5, 2)
+	req := &domain.LeaveRequest{
+		EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 2),
+		Status: domain.StatusPending, CreatedAt: assigned, ApproverID: "boss", AssignedAt: assigned,
+	}
+	if err := st.Leaves().Create(req); err != nil {
+		t.Fatal(err)
+	}
+	return st, req.ID
+}
+
+func newReminder(st *drivers.MemoryStore, m usecase.Mailer) usecase.RemindStaleRequests {
+	return usecase.RemindStaleRequests{
+		EmployeesRepo: st.Employees(),
+		LeavesRepo:    st.Leaves(),
+		Mailer:        m,
+		Clock:         fixedClock(staleNow),
+		Policy:        domain.DefaultStalePolicy,
+	}
+}
+
+func TestRemindEscalationWithoutManagerCountsAsReminder(t *testing.T) {
+	st, id := newStaleStore(t,
+		domain.Employee{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1)},
+		domain.Employee{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"},
+	)
+	mailer := &recordingMailer{}
+
+	out, err := newReminder(st, mailer).Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(out.Escalated) != 0 || len(out.Reminded) != 1 || out.Reminded[0] != id {
+		t.Errorf("Escalated = %v, Reminded = %v, want %s reminded", out.Escalated, out.Reminded, id)
+	}
+	if want := (sentMail{Kind: "reminder", Recipient: "boss", RequestID: id}); len(mailer.Sent) != 1 || mailer.Sent[0] != want {
+		t.Errorf("sent %+v, want %+v", mailer.Sent, want)
+	}
+}
+
+func TestRemindEscalationRetriesUntilNotified(t *testing.T) {
+	st, id := newStaleStore(t,
+		domain.Employee{ID: "big", Name: "Big", HireDate: day(2010, 4, 1)},
+		domain.Employee{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1), ManagerID: "big"},
+		domain.Employee{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"},
+	)
+	mailer := &recordingMailer{Fail: true}
+	uc := newReminder(st, mailer)
+
+	// 1. 通知できなければ回さない
+	out, err := uc.Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(out.Failed) != 1 || len(out.Escalated) != 0 {
+		t.Errorf("Failed = %v, Escalated = %v, want the escalation failed", out.Failed, out.Escalated)
+	}
+	if req, _ := st.Leaves().FindByID(id); req.ApproverID != "boss" || !req.AssignedAt.Equal(day(2025, 5, 2)) {
+		t.Errorf("after a failed notification: ApproverID = %q, AssignedAt = %v, want it still with boss", req.ApproverID, req.AssignedAt)
+	}
+
+	// 2. 次回、通知できたら回す
+	mailer.Fail = false
+	out, err = uc.Run()
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(out.Escalated) != 1 || out.Escalated[0] != id {
+		t.Errorf("Escalated = %v, want [%s]", out.Escalated, id)
+	}
+	if req, _ := st.Leaves().FindByID(id); req.ApproverID != "big" || !req.AssignedAt.Equal(staleNow) {
+		t.Errorf("ApproverID = %q, AssignedAt = %v, want big at %v", req.ApproverID, req.AssignedAt, staleNow)
+	}
+	if want := (sentMail{Kind: "escalation", Recipient: "big", RequestID: id}); len(mailer.Sent) != 1 || mailer.Sent[0] != want {
+		t.Errorf("sent %+v, want %+v", mailer.Sent, want)
+	}
+}
@@ -13,14 +13,14 @@ package usecase
 // --------------------------------------------------------
 
 import (
-	"errors"
 	"time"
 
 	"github.com/ohagi/clean-architecture-examples/good/domain"
 )
 
 var (
-	ErrNotEligible = errors.New("employee not eligible")
+	// 申請条件を満たさない（種類は ErrForbidden）
+	ErrNotEligible = &Error{Kind: ErrForbidden, Msg: "employee not eligible"}
 )
 
 // SubmitLeave：休暇申請ユースケースの実行構造体
@@ -32,6 +32,8 @@ type SubmitLeave struct {
 	EmployeesRepo EmployeeRepo
 	LeavesRepo    LeaveRepo
 	Mailer        Mailer
+	Links         LinkBuilder    // 通知に載せるリンク（nil ならリンクなし）
+	Events        EventPublisher // 変更の発行先（nil なら発行しない）
 	Clock         Clock
 	YearStart     func(now time.Time) time.Time // 会計年度開始日の計算
 }
@@ -43,59 +45,84 @@ type SubmitLeave struct {
 // --------------------------------------------------------
 type SubmitInput struct {
 	EmployeeID string
+	Type       domain.LeaveType
 	Reason     string
 	From       time.Time
 	To         time.Time
+	DayPart    domain.DayPart // 半日休の時間帯（終日なら空）
 }
 
 type SubmitOutput struct {
-	ID     string
-	Status domain.LeaveStatus
+	ID        string
+	Status    domain.LeaveStatus
+	NotifyErr error // 保存後の管理者への通知の失敗（nil なら通知した）。申請自体は保存済み
 }
 
 // Exec：休暇申請ユースケースの実行
 // --------------------------------------------------------
 // 処理フロー：
-// 1. 従業員情報の取得
-// 2. 年度内の申請回数の取得
-// 3. ドメインルールによる申請可否判定
-// 4. 申請データの生成と保存
-// 5. 管理者への通知（失敗は致命エラーにしない）
+// 1. 期間の妥当性チェック
+// 2. 従業員情報の取得
+// 3. 年度内の申請回数の取得
+// 4. ドメインルールによる申請可否判定
+// （1〜4 は PreviewLeave と共通。eligibility.go を参照）
+// 5. 申請データの生成（承認者は在籍中の上長。approvers.go を参照）と保存
+// 6. 変更の発行（leave.submitted）
+// 7. 管理者への通知（失敗は致命エラーにしない）
+// --------------------------------------------------------
+// 保存した後の通知の失敗はエラーとして返さず、SubmitOutput.NotifyErr で知らせる
+// （エラーを返すと、保存済みの申請を呼び出し側が再送して二重に申請してしまうため）。
 // --------------------------------------------------------
 func (uc SubmitLeave) Submit(in SubmitInput) (SubmitOutput, error) {
 	now := uc.Clock.Now()
 
-	// 1. 従業員情報の取得
-	emp, err := uc.EmployeesRepo.FindByID(in.EmployeeID)
+	// 1〜4. 申請可否の判定
+	a, err := assessLeave(uc.EmployeesRepo, uc.LeavesRepo, uc.YearStart, in, now)
 	if err != nil {
 		return SubmitOutput{}, err
 	}
-
-	// 2. 年度内の申請回数の取得
-	count, err := uc.LeavesRepo.CountThisFiscalYear(in.EmployeeID, uc.YearStart(now))
+	if len(a.Reasons) > 0 {
+		return SubmitOutput{}, ErrNotEligible
+	}
+	// 5. 休暇申請データの生成（上長が退職していればその上長へ回す。たどっても誰もいなければ受け付けない）
+	approverID, err := activeApprover(uc.EmployeesRepo, a.Employee.ManagerID)
 	if err != nil {
 		return SubmitOutput{}, err
 	}
-
-	// 3. ドメインルールによる申請可否判定
-	if !domain.CanSubmit(emp, count, now) {
-		return SubmitOutput{}, ErrNotEligible
+	if a.Employee.ManagerID != "" && approverID == "" {
+		return SubmitOutput{}, errNoActiveApprover
 	}
-	// 休暇申請データの生成
 	req := &domain.LeaveRequest{
 		EmployeeID: in.EmployeeID,
+		Type:       in.Type,
 		Reason:     in.Reason,
 		From:       in.From,
 		To:         in.To,
+		DayPart:    in.DayPart,
 		Status:     domain.StatusPending,
 		CreatedAt:  now,
+		ApproverID: approverID,
+		AssignedAt: now,
 	}
-	// 4. 申請データの生成と保存
+	// 申請データの保存
 	if err := uc.LeavesRepo.Create(req); err != nil {
 		return SubmitOutput{}, err
 	}
-	if err := uc.Mailer.NotifyManagerNewRequest(req.ID); err != nil {
-		return SubmitOutput{}, err
+	// 6. 変更の発行
+	publish(uc.Events, leaveEvent(EventLeaveSubmitted, in.EmployeeID, *req, now))
+	// 7. 管理者への通知（上長のいない最上位の従業員は通知先がない）
+	out := SubmitOutput{ID: req.ID, Status: req.Status}
+	if req.ApproverID != "" {
+		out.NotifyErr = uc.notifyManager(*req)
+	}
+	return out, nil
+}
+
+// notifyManager は承認者に新しい申請を知らせる。
+func (uc SubmitLeave) notifyManager(req domain.LeaveRequest) error {
+	n, err := newNotification(uc.EmployeesRepo, uc.Links, req, req.ApproverID)
+	if err != nil {
+		return err
 	}
-	return SubmitOutput{ID: req.ID, Status: req.Status}, nil
+	return uc.Mailer.NotifyManagerNewRequest(n)
 }
@@ -0,0 +1,53 @@
+package usecase_test
+
+// SubmitLeave のテスト（メモリの保存先を使う）
+// --------------------------------------------------------
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+	"github.com/ohagi/clean-architecture-examples/good/drivers"
+	"github.com/ohagi/clean-architecture-examples/good/usecase"
+)
+
+func TestSubmitLeaveNotificationFailureIsNotFatal(t *testing.T) {
+	st := drivers.NewMemoryStore()
+	st.Seed(
+		domain.Employee{ID: "boss", Name: "Boss", HireDate: day(2015, 4, 1)},
+		domain.Employee{ID: "alice", Name: "Alice", HireDate: day(2020, 4, 1), ManagerID: "boss"},
+	)
+	mailer := &recordingMailer{Fail: true}
+	uc := usecase.SubmitLeave{
+		EmployeesRepo: st.Employees(),
+		LeavesRepo:    st.Leaves(),
+		Mailer:        mailer,
+		Clock:         fixedClock(importNow),
+		YearStart:     domain.FiscalYearStart,
+	}
+
+	out, err := uc.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 6, 2), To: day(2025, 6, 3)})
+	if err != nil {
+		t.Fatalf("Submit: %v, want the saved request even though the mail failed", err)
+	}
+	if out.ID == "" || out.Status != domain.StatusPending {
+		t.Errorf("out = %+v, want the saved pending request", out)
+	}
+	if !errors.Is(out.NotifyErr, usecase.ErrUnavailable) {
+		t.Errorf("NotifyErr = %v, want the mail failure", out.NotifyErr)
+	}
+	if req, err := st.Leaves().FindByID(out.ID); err != nil || req.ApproverID != "boss" {
+		t.Errorf("FindByID = %+v, %v, want the request assigned to boss", req, err)
+	}
+
+	// 通知できたときは NotifyErr は nil
+	mailer.Fail = false
+	out, err = uc.Submit(usecase.SubmitInput{EmployeeID: "alice", Type: domain.LeavePaid, From: day(2025, 7, 1), To: day(2025, 7, 1)})
+	if err != nil || out.NotifyErr != nil {
+		t.Fatalf("Submit = %+v, %v, want no error", out, err)
+	}
+	if len(mailer.Sent) != 1 || mailer.Sent[0] != (sentMail{Kind: "new", Recipient: "boss", RequestID: out.ID}) {
+		t.Errorf("sent %+v, want one mail to boss", mailer.Sent)
+	}
+}
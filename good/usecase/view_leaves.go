@@ -0,0 +1,143 @@
+package usecase
+
+// 休暇申請の参照・取り下げユースケース
+// --------------------------------------------------------
+// - ViewLeaves ：休暇申請の一覧（従業員ごと）と1件の取得
+// - CancelLeave：申請者による承認待ちの申請の取り下げ
+// --------------------------------------------------------
+// 誰が閲覧・取り下げできるかは Domain層のルールに従う。
+// --------------------------------------------------------
+
+import (
+	"errors"
+
+	"github.com/ohagi/clean-architecture-examples/good/domain"
+)
+
+var (
+	// errLeaveViewDenied：閲覧の権限がない
+	errLeaveViewDenied = &Error{Kind: ErrForbidden, Msg: "not allowed to view this leave request"}
+	// errLeaveCancelDenied：取り下げの権限がない
+	errLeaveCancelDenied = &Error{Kind: ErrForbidden, Msg: "not allowed to cancel this leave request"}
+)
+
+// ViewLeaves：参照ユースケースの実行構造体
+type ViewLeaves struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    LeaveFinder
+	Lister        LeaveLister
+}
+
+// ListLeavesInput：一覧の条件
+type ListLeavesInput struct {
+	ActorID    string             // 操作している従業員
+	EmployeeID string             // 誰の申請か（空なら操作者本人）
+	Status     domain.LeaveStatus // 空ならすべてのステータス
+}
+
+// List：一覧の取得
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 操作者と対象の従業員の取得
+// 2. ドメインルールによる権限チェック（本人・直属の上長・人事）
+// 3. 申請の取得（作成日時の古い順）とステータスでの絞り込み
+// --------------------------------------------------------
+func (uc ViewLeaves) List(in ListLeavesInput) ([]domain.LeaveRequest, error) {
+	// 1. 操作者と対象の従業員の取得
+	actor, err := findActor(uc.EmployeesRepo, in.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	owner := actor
+	if in.EmployeeID != "" && in.EmployeeID != actor.ID {
+		if owner, err = uc.EmployeesRepo.FindByID(in.EmployeeID); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. ドメインルールによる権限チェック
+	if !domain.CanListLeaveRequests(actor, owner) {
+		return nil, errLeaveViewDenied
+	}
+
+	// 3. 申請の取得と絞り込み
+	reqs, err := uc.Lister.ListByEmployee(owner.ID)
+	if err != nil || in.Status == "" {
+		return reqs, err
+	}
+	var hits []domain.LeaveRequest
+	for _, req := range reqs {
+		if req.Status == in.Status {
+			hits = append(hits, req)
+		}
+	}
+	return hits, nil
+}
+
+// Get：1件の取得（申請者本人・承認者・人事のみ）
+func (uc ViewLeaves) Get(actorID, requestID string) (domain.LeaveRequest, error) {
+	req, actor, err := findRequestAndActor(uc.LeavesRepo, uc.EmployeesRepo, requestID, actorID)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	if !domain.CanViewLeaveRequest(actor, req) {
+		return domain.LeaveRequest{}, errLeaveViewDenied
+	}
+	return req, nil
+}
+
+// CancelLeave：取り下げユースケースの実行構造体
+type CancelLeave struct {
+	EmployeesRepo EmployeeRepo
+	LeavesRepo    DecisionLeaveRepo // FindByID・Update を使う
+	Events        EventPublisher    // 変更の発行先（nil なら発行しない）
+	Clock         Clock
+}
+
+// Cancel：取り下げの実行
+// --------------------------------------------------------
+// 処理フロー：
+// 1. 休暇申請と操作者の取得
+// 2. ドメインルールによる権限チェック（申請者本人・人事）
+// 3. 取消の反映（承認待ちの申請だけ）
+// 4. 申請の保存と変更の発行（leave.cancelled）
+// --------------------------------------------------------
+// 承認者への通知は行わない（承認待ちの一覧から消えるだけで、判断の必要がなくなるため）。
+// --------------------------------------------------------
+func (uc CancelLeave) Cancel(actorID, requestID string) (domain.LeaveRequest, error) {
+	// 1. 休暇申請と操作者の取得
+	req, actor, err := findRequestAndActor(uc.LeavesRepo, uc.EmployeesRepo, requestID, actorID)
+	if err != nil {
+		return domain.LeaveRequest{}, err
+	}
+
+	// 2. ドメインルールによる権限チェック
+	if !domain.CanCancelLeaveRequest(actor, req) {
+		return domain.LeaveRequest{}, errLeaveCancelDenied
+	}
+
+	// 3. 取消の反映
+	now := uc.Clock.Now()
+	if err := req.Cancel(now); err != nil {
+		return domain.LeaveRequest{}, decisionError(err)
+	}
+
+	// 4. 申請の保存と変更の発行
+	if err := uc.LeavesRepo.Update(&req, domain.StatusPending); err != nil {
+		return domain.LeaveRequest{}, err
+	}
+	publish(uc.Events, leaveEvent(EventLeaveCancelled, actor.ID, req, now))
+	return req, nil
+}
+
+// findActor は操作者を取得する（特定できなければ ErrUnauthenticated）。
+func findActor(emps EmployeeRepo, actorID string) (domain.Employee, error) {
+	if actorID == "" {
+		return domain.Employee{}, &Error{Kind: ErrUnauthenticated, Msg: "authentication required"}
+	}
+	actor, err := emps.FindByID(actorID)
+	if errors.Is(err, ErrNotFound) {
+		return domain.Employee{}, &Error{Kind: ErrUnauthenticated, Msg: "unknown user", Err: err}
+	}
+	return actor, err
+}